@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/jasonlabz/potato/configx/file"
@@ -29,6 +31,43 @@ type CryptoConfig struct {
 	Key  string `mapstructure:"key" json:"key" ini:"key" yaml:"key"`
 }
 
+// SigningKeyConfig 签名密钥配置
+type SigningKeyConfig struct {
+	KeyID     string `mapstructure:"key_id" json:"key_id" ini:"key_id" yaml:"key_id"`             // 密钥标识，签名与校验时按此选择密钥
+	Algorithm string `mapstructure:"algorithm" json:"algorithm" ini:"algorithm" yaml:"algorithm"` // hmac-sha256|hmac-sha512，默认 hmac-sha256
+	Secret    string `mapstructure:"secret" json:"secret" ini:"secret" yaml:"secret"`
+	SecretEnv string `mapstructure:"secret_env" json:"secret_env" ini:"secret_env" yaml:"secret_env"` // 保存密钥的环境变量，设置时优先于 secret
+}
+
+// GetSecret 签名密钥，优先读取 secret_env 指定的环境变量，未配置时为空
+func (k SigningKeyConfig) GetSecret() string {
+	if k.SecretEnv != "" {
+		if secret := os.Getenv(k.SecretEnv); secret != "" {
+			return secret
+		}
+	}
+	return k.Secret
+}
+
+// RedactionConfig 请求日志脱敏配置
+type RedactionConfig struct {
+	Enable    bool     `mapstructure:"enable" json:"enable" ini:"enable" yaml:"enable"`
+	Style     string   `mapstructure:"style" json:"style" ini:"style" yaml:"style"`                 // 检测器命中片段的遮盖方式 full|partial|hash，默认 partial；键名与路径命中的值整体遮盖
+	Keys      []string `mapstructure:"keys" json:"keys" ini:"keys" yaml:"keys"`                     // 敏感键名通配模式，为空时使用内置列表
+	Paths     []string `mapstructure:"paths" json:"paths" ini:"paths" yaml:"paths"`                 // 需要整体遮盖的路径模式
+	Detectors []string `mapstructure:"detectors" json:"detectors" ini:"detectors" yaml:"detectors"` // phone|idcard|email|bankcard|jwt，为空时全部启用
+}
+
+// ProtobufConfig protobuf 编解码配置
+type ProtobufConfig struct {
+	IDLDir string `mapstructure:"idl_dir" json:"idl_dir" yaml:"idl_dir" ini:"idl_dir"` // .proto 文件目录，与 script/generate_idl.sh 的 IDL_DIR 一致
+}
+
+// CodegenConfig 代码生成配置
+type CodegenConfig struct {
+	TemplateDir string `mapstructure:"template_dir" json:"template_dir" yaml:"template_dir" ini:"template_dir"` // 上传的代码模板保存目录
+}
+
 // KafkaConfig 配置
 type KafkaConfig struct {
 	Topic            []string `mapstructure:"topic" json:"topic" yaml:"topic" ini:"topic"`
@@ -206,14 +245,18 @@ type Application struct {
 
 // Config 更新主配置结构体
 type Config struct {
-	Application Application    `mapstructure:"application" json:"application" yaml:"application" ini:"application"`
-	DataSource  DataSource     `mapstructure:"datasource" json:"datasource" yaml:"datasource" ini:"datasource"`
-	Crypto      []CryptoConfig `mapstructure:"crypto" json:"crypto" yaml:"crypto" ini:"crypto"`
-	Kafka       KafkaConfig    `mapstructure:"kafka" json:"kafka" yaml:"kafka" ini:"kafka"`
-	Rabbitmq    RabbitMQConf   `mapstructure:"rabbitmq" json:"rabbitmq" yaml:"rabbitmq" ini:"rabbitmq"`
-	Redis       RedisConfig    `mapstructure:"redis" json:"redis" yaml:"redis" ini:"redis"`
-	ES          Elasticsearch  `mapstructure:"es" json:"es" yaml:"es" ini:"es"`
-	Mongodb     MongodbConf    `mapstructure:"mongodb" json:"mongodb" yaml:"mongodb" ini:"mongodb"`
+	Application Application        `mapstructure:"application" json:"application" yaml:"application" ini:"application"`
+	DataSource  DataSource         `mapstructure:"datasource" json:"datasource" yaml:"datasource" ini:"datasource"`
+	Crypto      []CryptoConfig     `mapstructure:"crypto" json:"crypto" yaml:"crypto" ini:"crypto"`
+	Signing     []SigningKeyConfig `mapstructure:"signing" json:"signing" yaml:"signing" ini:"signing"`
+	Redaction   RedactionConfig    `mapstructure:"redaction" json:"redaction" yaml:"redaction" ini:"redaction"`
+	Protobuf    ProtobufConfig     `mapstructure:"protobuf" json:"protobuf" yaml:"protobuf" ini:"protobuf"`
+	Codegen     CodegenConfig      `mapstructure:"codegen" json:"codegen" yaml:"codegen" ini:"codegen"`
+	Kafka       KafkaConfig        `mapstructure:"kafka" json:"kafka" yaml:"kafka" ini:"kafka"`
+	Rabbitmq    RabbitMQConf       `mapstructure:"rabbitmq" json:"rabbitmq" yaml:"rabbitmq" ini:"rabbitmq"`
+	Redis       RedisConfig        `mapstructure:"redis" json:"redis" yaml:"redis" ini:"redis"`
+	ES          Elasticsearch      `mapstructure:"es" json:"es" yaml:"es" ini:"es"`
+	Mongodb     MongodbConf        `mapstructure:"mongodb" json:"mongodb" yaml:"mongodb" ini:"mongodb"`
 }
 
 var applicationConfig = new(Config)
@@ -276,6 +319,42 @@ func (c *Config) GetHTTPWriteTimeout() time.Duration {
 	return time.Duration(math.MaxInt64) // 默认值
 }
 
+// GetCryptoKey 按加密类型查找密钥，同一类型配置多个时取第一个非空密钥
+func (c *Config) GetCryptoKey(cryptoType CryptoType) (string, bool) {
+	for _, conf := range c.Crypto {
+		if CryptoType(strings.ToLower(conf.Type)) == cryptoType && conf.Key != "" {
+			return conf.Key, true
+		}
+	}
+	return "", false
+}
+
+// GetSigningKey 按标识查找签名密钥
+func (c *Config) GetSigningKey(keyID string) (SigningKeyConfig, bool) {
+	for _, key := range c.Signing {
+		if key.KeyID == keyID {
+			return key, true
+		}
+	}
+	return SigningKeyConfig{}, false
+}
+
+// GetIDLDir .proto 文件目录，默认 idl
+func (c *Config) GetIDLDir() string {
+	if c.Protobuf.IDLDir != "" {
+		return c.Protobuf.IDLDir
+	}
+	return "idl"
+}
+
+// GetTemplateDir 代码模板目录，默认 templates
+func (c *Config) GetTemplateDir() string {
+	if c.Codegen.TemplateDir != "" {
+		return c.Codegen.TemplateDir
+	}
+	return "templates"
+}
+
 func (c *Config) GetGRPCPort() int {
 	if c.Application.Server.GRPC.Port > 0 {
 		return c.Application.Server.GRPC.Port
@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"log"
+
+	"github.com/jasonlabz/potato/configx/file"
+	"github.com/jasonlabz/potato/utils"
+)
+
+var namingPaths = []string{"./conf/naming.yaml", "./conf/naming.yml"}
+
+// NamingOverride 按 JSON 键指定生成代码中的字段名
+type NamingOverride struct {
+	Key  string `mapstructure:"key" json:"key" yaml:"key" ini:"key"`     // JSON 键，区分大小写
+	Name string `mapstructure:"name" json:"name" yaml:"name" ini:"name"` // 字段名，原样使用
+}
+
+// ReservedConfig 某种语言追加的保留字
+type ReservedConfig struct {
+	Words  []string `mapstructure:"words" json:"words" yaml:"words" ini:"words"`
+	Escape string   `mapstructure:"escape" json:"escape" yaml:"escape" ini:"escape"` // 转义模板，{name} 为原名，为空时沿用内置的转义方式
+}
+
+// StructNamingConfig 结构体名规则
+type StructNamingConfig struct {
+	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix" ini:"prefix"`
+	Suffix string `mapstructure:"suffix" json:"suffix" yaml:"suffix" ini:"suffix"`
+}
+
+// NamingConfig 代码生成的命名词典，与 application.yaml 同目录的 naming.yaml
+type NamingConfig struct {
+	Initialisms []string                  `mapstructure:"initialisms" json:"initialisms" yaml:"initialisms" ini:"initialisms"` // 追加的缩略词，如 SKU、OSS、VPC
+	Overrides   []NamingOverride          `mapstructure:"overrides" json:"overrides" yaml:"overrides" ini:"overrides"`
+	Reserved    map[string]ReservedConfig `mapstructure:"reserved" json:"reserved" yaml:"reserved" ini:"reserved"` // 语言: go|typescript|java|python|kotlin|rust
+	Struct      StructNamingConfig        `mapstructure:"struct" json:"struct" yaml:"struct" ini:"struct"`
+}
+
+var namingConfig = new(NamingConfig)
+
+func GetNamingConfig() *NamingConfig {
+	return namingConfig
+}
+
+func init() {
+	// 读取命名词典，不存在时只使用内置规则
+	for _, path := range namingPaths {
+		if !utils.IsExist(path) {
+			continue
+		}
+		if err := file.ParseConfigByViper(path, namingConfig); err != nil {
+			log.Printf("[init] -- failed to read naming config: %s, err:%v", path, err)
+			continue
+		}
+		return
+	}
+}
@@ -0,0 +1,630 @@
+package codegenx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/pinyinx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+)
+
+// Langs 内置模板支持的语言，与前端 LANGUAGES 一致
+var Langs = []string{"go", "typescript", "java", "python", "kotlin", "rust"}
+
+// ParseLang 校验目标语言
+func ParseLang(name string) (string, error) {
+	lang := strings.ToLower(strings.TrimSpace(name))
+	for _, l := range Langs {
+		if l == lang {
+			return lang, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported lang: %q, expected %s", name, strings.Join(Langs, ", "))
+}
+
+// GoTags Go 结构体标签选项，与前端 goTags 一致
+type GoTags struct {
+	JSON         bool `json:"json"`
+	Mapstructure bool `json:"mapstructure"`
+	GORM         bool `json:"gorm"`
+	YAML         bool `json:"yaml"`
+	XML          bool `json:"xml"`
+	Validate     bool `json:"validate"`
+	OmitEmpty    bool `json:"omitempty"`
+	String       bool `json:"string"` // 超出双精度的 64 位整数加 ,string，更大的数字使用 string 类型
+}
+
+// Options 类型推断选项
+type Options struct {
+	Lang          string
+	Name          string                       // 根类型名，默认 Response
+	Case          transformx.KeyCase           // 字段名与类型名的命名格式，默认 pascal
+	Pinyin        pinyinx.Style                // 非空时将键中的汉字转为拼音
+	PinyinComment bool                         // 转为拼音的字段以原键作为注释
+	Dictionary    *transformx.NamingDictionary // 命名词典，为空时只使用内置规则
+	GoTags        GoTags
+	Inline        bool // Go 的嵌套对象内联
+	DetectTime    bool // 按取值与键名识别时间字段
+	MergeArrays   bool // 数组中的对象按所有元素的字段并集推断，否则只取第一个元素
+	Comments      bool // 使用原文中的注释
+}
+
+// 与前端 TIME_PATTERNS 一致的时间取值与键名
+var (
+	timeValuePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?$`),
+		regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`),
+		regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+		regexp.MustCompile(`^\d{13}$`),
+		regexp.MustCompile(`^\d{10}$`),
+	}
+	timeKeyPattern = regexp.MustCompile(`(?i)(time|date|timestamp|created|updated|start|end|at)$`)
+)
+
+// 各语言的类型名，与前端 inferType 一致
+var (
+	timeTypes = map[string]string{
+		"go": "time.Time", "typescript": "Date", "java": "java.time.LocalDateTime",
+		"python": "datetime.datetime", "kotlin": "java.time.LocalDateTime", "rust": "chrono::DateTime<chrono::Utc>",
+	}
+	nullTypes = map[string]string{
+		"go": "any", "typescript": "null", "java": "Object", "python": "any", "kotlin": "Any?", "rust": "Option<serde_json::Value>",
+	}
+	anyTypes = map[string]string{
+		"go": "interface{}", "typescript": "any", "java": "Object", "python": "Any", "kotlin": "Any?", "rust": "serde_json::Value",
+	}
+	objectTypes = map[string]string{
+		"typescript": "any", "java": "Object", "python": "dict", "kotlin": "Any?", "rust": "serde_json::Value",
+	}
+	mapTypes = map[string]string{
+		"go": "map[string]interface{}", "typescript": "Record<string, any>", "java": "Map<String, Object>",
+		"python": "Dict[str, Any]", "kotlin": "Map<String, Any?>", "rust": "HashMap<String, serde_json::Value>",
+	}
+	scalarTypes = map[string]map[string]string{
+		"string":  {"go": "string", "typescript": "string", "java": "String", "python": "str", "kotlin": "String", "rust": "String"},
+		"boolean": {"go": "bool", "typescript": "boolean", "java": "Boolean", "python": "bool", "kotlin": "Boolean", "rust": "bool"},
+		"int":     {"go": "int", "typescript": "number", "java": "Integer", "python": "int", "kotlin": "Int", "rust": "i32"},
+		"long":    {"go": "int64", "typescript": "number", "java": "Long", "python": "int", "kotlin": "Long", "rust": "i64"},
+		"float":   {"go": "float64", "typescript": "number", "java": "Double", "python": "float", "kotlin": "Double", "rust": "f64"},
+	}
+	// 超出双精度的数字
+	preciseTypes = map[string]map[string]string{
+		"int64":   {"go": "int64", "typescript": "bigint", "java": "Long", "kotlin": "Long", "rust": "i64", "python": "int"},
+		"uint64":  {"go": "uint64", "typescript": "bigint", "java": "java.math.BigInteger", "kotlin": "java.math.BigInteger", "rust": "u64", "python": "int"},
+		"bigint":  {"go": "*big.Int", "typescript": "bigint", "java": "java.math.BigInteger", "kotlin": "java.math.BigInteger", "rust": "serde_json::Number", "python": "int"},
+		"decimal": {"go": "json.Number", "typescript": "string", "java": "java.math.BigDecimal", "kotlin": "java.math.BigDecimal", "rust": "serde_json::Number", "python": "decimal.Decimal"},
+	}
+)
+
+// arrayType 按语言包装数组类型
+func arrayType(lang, elem string) string {
+	switch lang {
+	case "go":
+		return "[]" + elem
+	case "typescript":
+		return elem + "[]"
+	case "java", "kotlin":
+		return "List<" + elem + ">"
+	case "rust":
+		return "Vec<" + elem + ">"
+	default:
+		return "List[" + elem + "]"
+	}
+}
+
+// Build 从文档推断类型树。根为数组时按元素的字段并集推断，根不是对象时返回错误
+func Build(doc *formatx.Document, opts *Options) (*Model, error) {
+	b := &builder{
+		opts:     opts,
+		lang:     opts.Lang,
+		dict:     opts.Dictionary,
+		comments: make(map[string]string),
+		kinds:    make(map[string]string),
+		types:    make(map[string]*Type),
+		building: make(map[string]bool),
+	}
+	if b.dict == nil {
+		b.dict = transformx.NewNamingDictionary(nil, nil, nil, "", "")
+	}
+	keyCase := opts.Case
+	if keyCase == "" {
+		keyCase = transformx.CasePascal
+	}
+	b.namer = b.dict.Namer(keyCase)
+	b.namer.Pinyin = opts.Pinyin
+	if opts.Comments {
+		b.collectComments(doc, doc.Value, "", "")
+	}
+	b.collectNumbers(doc.Value, "")
+
+	root := doc.Ordered(nil)
+	if items, ok := root.([]any); ok {
+		root = mergeItems(items)
+	}
+	record, ok := root.(*formatx.Record)
+	if !ok {
+		return nil, fmt.Errorf("code generation requires an object or an array of objects, got %s", kindOf(root))
+	}
+	if opts.MergeArrays {
+		record = mergeArrays(record).(*formatx.Record)
+	}
+	name := opts.Name
+	if name == "" {
+		name = "Response"
+	}
+	model := &Model{Lang: b.lang, Name: b.dict.StructName(b.lang, b.namer, name), Inline: opts.Inline && b.lang == "go"}
+	model.Root = b.buildType(record, model.Name, "", 0, false)
+	model.Root.Comment = b.comments[""]
+	for _, t := range b.order {
+		if t != model.Root {
+			model.Types = append(model.Types, t)
+		}
+	}
+	model.Types = append(model.Types, model.Root)
+	model.Imports, model.HasTime = b.imports(model.Root)
+	return model, nil
+}
+
+type builder struct {
+	opts     *Options
+	lang     string
+	dict     *transformx.NamingDictionary
+	namer    *transformx.KeyNamer
+	comments map[string]string // 字段路径 → 注释，同一路径取第一处
+	kinds    map[string]string // 字段路径 → 数字类型: int|int64|uint64|bigint|float|decimal
+	types    map[string]*Type  // 具名类型，同名的对象只推断一次
+	building map[string]bool   // 正在推断的具名类型
+	order    []*Type           // 具名类型，被引用的类型在前
+}
+
+// buildType 推断对象的类型；inline 为内联的 Go 结构体，不登记为具名类型
+func (b *builder) buildType(record *formatx.Record, name, path string, depth int, inline bool) *Type {
+	if !inline {
+		depth = 0
+	}
+	t := &Type{Name: name, Path: path, Depth: depth, Comment: b.comments[path]}
+	if !inline {
+		b.types[name] = t
+		b.building[name] = true
+	}
+	for _, key := range record.Keys {
+		t.Fields = append(t.Fields, b.buildField(key, record.Values[key], joinPath(path, key), depth, inline))
+	}
+	if !inline {
+		b.building[name] = false
+		b.order = append(b.order, t)
+	}
+	return t
+}
+
+// ref 对象字段引用的类型，已推断过的同名类型直接复用
+func (b *builder) ref(record *formatx.Record, name, path string, depth int, inline bool) *Type {
+	if inline {
+		return b.buildType(record, name, path, depth, true)
+	}
+	if b.building[name] {
+		// 与正在推断的祖先类型同名时改用带序号的类型名，避免类型引用自身
+		base := name
+		for i := 2; b.types[name] != nil; i++ {
+			name = base + strconv.Itoa(i)
+		}
+	}
+	if t, ok := b.types[name]; ok {
+		return t
+	}
+	return b.buildType(record, name, path, depth, false)
+}
+
+func (b *builder) buildField(key string, value any, path string, depth int, inline bool) *Field {
+	f := &Field{Key: key, Name: b.dict.FieldName(b.lang, b.namer, key), Path: path, Comment: b.comments[path]}
+	if b.opts.PinyinComment && b.opts.Pinyin != "" && pinyinx.HasHan(key) {
+		f.Comment = strings.TrimSpace(key + " " + f.Comment)
+	}
+	inlineChild := b.opts.Inline && b.lang == "go"
+	switch v := value.(type) {
+	case *formatx.Record:
+		if v.Len() == 0 {
+			f.Kind, f.Type = "map", mapTypes[b.lang]
+			break
+		}
+		f.Kind = "object"
+		f.Ref = b.ref(v, b.dict.StructName(b.lang, b.namer, key), path, depth+1, inlineChild || inline)
+		f.Inline = inlineChild || inline
+		if b.lang == "go" {
+			if !f.Inline {
+				f.Type = f.Ref.Name
+			}
+		} else {
+			f.Type = objectTypes[b.lang]
+		}
+	case []any:
+		b.arrayField(f, key, v, path, depth, inlineChild || inline)
+	default:
+		f.Kind, f.Type = b.scalar(key, value, path)
+		f.IsTime = f.Kind == "time"
+		f.Number = b.precise(value, path)
+		f.Example = example(value)
+	}
+	if b.lang == "go" {
+		f.Tags = b.tags(key, value, path, inline)
+	}
+	return f
+}
+
+// arrayField 数组字段：多维数组与对象数组的元素类型名为 键Item，一维对象数组的键以 s 结尾时去掉 s
+func (b *builder) arrayField(f *Field, key string, items []any, path string, depth int, inline bool) {
+	f.Kind, f.Dims = "array", 1
+	if len(items) == 0 {
+		f.Elem, f.Type = "any", arrayType(b.lang, anyTypes[b.lang])
+		return
+	}
+	first := items[0]
+	if inner, ok := first.([]any); ok {
+		// 多维数组只展开两层，与前端一致
+		f.Dims = 2
+		var nested any
+		if len(inner) > 0 {
+			nested = inner[0]
+		}
+		if record, ok := nested.(*formatx.Record); ok && record.Len() > 0 {
+			f.Elem = "object"
+			f.Ref = b.ref(record, b.dict.StructName(b.lang, b.namer, key)+"Item", path, depth+1, false)
+			elem := anyTypes[b.lang]
+			if b.lang == "go" {
+				elem = f.Ref.Name
+			}
+			f.Type = arrayType(b.lang, arrayType(b.lang, elem))
+			return
+		}
+		elem := anyTypes[b.lang]
+		if nested != nil || len(inner) > 0 {
+			var kind string
+			kind, elem = b.elemType(key, nested, path)
+			f.Elem = kind
+		} else {
+			f.Elem = "any"
+		}
+		f.Type = arrayType(b.lang, arrayType(b.lang, elem))
+		return
+	}
+	if record, ok := first.(*formatx.Record); ok {
+		f.Elem = "object"
+		itemKey := key + "Item"
+		if strings.HasSuffix(key, "s") {
+			itemKey = strings.TrimSuffix(key, "s")
+		}
+		f.Ref = b.ref(record, b.dict.StructName(b.lang, b.namer, itemKey), path, depth+1, inline)
+		if b.lang != "go" {
+			f.Type = arrayType(b.lang, anyTypes[b.lang])
+			return
+		}
+		f.Inline = inline
+		if !inline {
+			f.Type = "[]" + f.Ref.Name
+		}
+		return
+	}
+	f.Elem, f.Type = b.scalar(key, first, path)
+	f.IsTime = f.Elem == "time"
+	f.Number = b.precise(first, path)
+	f.Type = arrayType(b.lang, f.Type)
+}
+
+// elemType 多维数组最内层元素的类型，对象与数组为任意类型
+func (b *builder) elemType(key string, value any, path string) (string, string) {
+	switch value.(type) {
+	case *formatx.Record, []any:
+		return "any", anyTypes[b.lang]
+	}
+	return b.scalar(key, value, path)
+}
+
+// scalar 标量的类别与类型，与前端 inferType 一致
+func (b *builder) scalar(key string, value any, path string) (string, string) {
+	if b.opts.DetectTime && isTime(key, value) {
+		return "time", timeTypes[b.lang]
+	}
+	switch value.(type) {
+	case nil:
+		return "null", nullTypes[b.lang]
+	case string:
+		return "string", scalarTypes["string"][b.lang]
+	case bool:
+		return "boolean", scalarTypes["boolean"][b.lang]
+	}
+	if precise := b.precise(value, path); precise != "" {
+		if b.lang == "go" && b.opts.GoTags.String && (precise == "bigint" || precise == "decimal") {
+			return "string", "string"
+		}
+		kind := "integer"
+		if precise == "decimal" {
+			kind = "float"
+		}
+		return kind, preciseTypes[precise][b.lang]
+	}
+	literal, ok := numberLiteral(value)
+	if !ok {
+		return "any", anyTypes[b.lang]
+	}
+	if strings.ContainsAny(literal, ".eE") {
+		if f, err := strconv.ParseFloat(literal, 64); err != nil || f != math.Trunc(f) {
+			return "float", scalarTypes["float"][b.lang]
+		}
+	}
+	if n, err := strconv.ParseFloat(literal, 64); err == nil && (n > math.MaxInt32 || n < math.MinInt32) {
+		return "integer", scalarTypes["long"][b.lang]
+	}
+	return "integer", scalarTypes["int"][b.lang]
+}
+
+// precise 字段路径上的数字超出双精度时返回 int64|uint64|bigint|decimal
+func (b *builder) precise(value any, path string) string {
+	if _, ok := numberLiteral(value); !ok {
+		return ""
+	}
+	switch kind := b.kinds[path]; kind {
+	case "int64", "uint64", "bigint", "decimal":
+		return kind
+	}
+	return ""
+}
+
+// tags Go 结构体标签；内联结构体中只有 json 与 mapstructure，与前端一致
+func (b *builder) tags(key string, value any, path string, inline bool) []Tag {
+	opts := b.opts.GoTags
+	var tags []Tag
+	if opts.JSON {
+		option := ""
+		if opts.OmitEmpty {
+			option += ",omitempty"
+		}
+		if precise := b.precise(value, path); opts.String && (precise == "int64" || precise == "uint64") {
+			option += ",string"
+		}
+		tags = append(tags, Tag{Key: "json", Value: key + option})
+	}
+	if opts.Mapstructure {
+		tags = append(tags, Tag{Key: "mapstructure", Value: key})
+	}
+	if inline {
+		return tags
+	}
+	if opts.GORM {
+		tags = append(tags, Tag{Key: "gorm", Value: "column:" + key})
+	}
+	if opts.YAML {
+		tags = append(tags, Tag{Key: "yaml", Value: key})
+	}
+	if opts.XML {
+		tags = append(tags, Tag{Key: "xml", Value: key})
+	}
+	if opts.Validate {
+		var rules []string
+		if s, ok := value.(string); ok {
+			if strings.Contains(s, "@") {
+				rules = append(rules, "email")
+			}
+			if strings.Contains(strings.ToLower(key), "url") {
+				rules = append(rules, "url")
+			}
+		}
+		if _, ok := numberLiteral(value); ok {
+			rules = append(rules, "numeric")
+		}
+		if len(rules) > 0 {
+			tags = append(tags, Tag{Key: "validate", Value: strings.Join(rules, ",")})
+		}
+	}
+	return tags
+}
+
+// imports Go 按使用到的类型导入 encoding/json、math/big 与 time
+func (b *builder) imports(root *Type) ([]string, bool) {
+	used := make(map[string]bool)
+	hasTime := false
+	seen := make(map[*Type]bool)
+	var walk func(t *Type)
+	walk = func(t *Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		for _, f := range t.Fields {
+			hasTime = hasTime || f.IsTime
+			switch {
+			case strings.Contains(f.Type, "json.Number"):
+				used["encoding/json"] = true
+			case strings.Contains(f.Type, "big.Int"):
+				used["math/big"] = true
+			case strings.Contains(f.Type, "time.Time"):
+				used["time"] = true
+			}
+			if f.Ref != nil {
+				walk(f.Ref)
+			}
+		}
+	}
+	walk(root)
+	if b.lang != "go" {
+		return nil, hasTime
+	}
+	imports := make([]string, 0, len(used))
+	for pkg := range used {
+		imports = append(imports, pkg)
+	}
+	sort.Strings(imports)
+	return imports, hasTime
+}
+
+// collectComments 按字段路径记录注释，行尾注释优先于之前独占一行的注释
+func (b *builder) collectComments(doc *formatx.Document, value any, pointer, path string) {
+	if _, ok := b.comments[path]; !ok {
+		if c := doc.Comments[pointer]; c != nil {
+			if text := strings.TrimSpace(c.Line); text != "" {
+				b.comments[path] = text
+			} else if len(c.Head) > 0 {
+				b.comments[path] = strings.TrimSpace(strings.Join(c.Head, " "))
+			}
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			b.collectComments(doc, item, pointer+"/"+escapePointer(key), joinPath(path, key))
+		}
+	case []any:
+		for i, item := range v {
+			b.collectComments(doc, item, pointer+"/"+strconv.Itoa(i), path)
+		}
+	}
+}
+
+// collectNumbers 按字段路径合并数字字面量的类型，取能同时容纳所有取值的最小类型
+func (b *builder) collectNumbers(value any, path string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			b.collectNumbers(item, joinPath(path, key))
+		}
+	case []any:
+		for _, item := range v {
+			b.collectNumbers(item, path)
+		}
+	default:
+		literal, ok := numberLiteral(value)
+		if !ok {
+			return
+		}
+		b.kinds[path] = widenNumberKind(b.kinds[path], numberKind(literal))
+	}
+}
+
+// numberKind 字面量的数字类型，int 与 float 可以由双精度无损表示
+func numberKind(literal string) string {
+	switch formatx.ClassifyNumber(literal) {
+	case formatx.NumberInt64:
+		if n, _ := strconv.ParseInt(literal, 10, 64); n >= -(1<<53-1) && n <= 1<<53-1 {
+			return "int"
+		}
+		return "int64"
+	case formatx.NumberUint64:
+		return "uint64"
+	case formatx.NumberBigInt:
+		return "bigint"
+	case formatx.NumberFloat64:
+		return "float"
+	default:
+		return "decimal"
+	}
+}
+
+var integerKinds = []string{"int", "int64", "uint64", "bigint"}
+
+// widenNumberKind 与前端 widenNumberKind 一致
+func widenNumberKind(a, b string) string {
+	if a == "" || a == b {
+		return b
+	}
+	ia, ib := indexOf(integerKinds, a), indexOf(integerKinds, b)
+	if ia >= 0 && ib >= 0 {
+		return integerKinds[max(ia, ib)]
+	}
+	if a == "decimal" || b == "decimal" {
+		return "decimal"
+	}
+	integer := b
+	if ia >= 0 {
+		integer = a
+	}
+	if integer == "int" {
+		return "float"
+	}
+	return "decimal"
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// numberLiteral 数字的十进制字面量，非数字返回 false
+func numberLiteral(value any) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), true
+	case int:
+		return strconv.Itoa(v), true
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// isTime 取值为时间格式的字符串或 time.Time，或键名以 time、date、at 等结尾
+func isTime(key string, value any) bool {
+	switch v := value.(type) {
+	case time.Time:
+		return true
+	case string:
+		for _, p := range timeValuePatterns {
+			if p.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return timeKeyPattern.MatchString(key)
+}
+
+// example 标量的示例取值，数字保留原文
+func example(value any) any {
+	if literal, ok := numberLiteral(value); ok {
+		return json.Number(literal)
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+func kindOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	}
+	if _, ok := numberLiteral(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
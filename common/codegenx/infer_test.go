@@ -0,0 +1,210 @@
+package codegenx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+)
+
+// generate 按内置模板生成代码
+func generate(t *testing.T, from formatx.Format, input string, opts *Options) (*Model, string) {
+	t.Helper()
+	doc, err := formatx.Parse(from, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	model, err := Build(doc, opts)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	text, err := Builtin(opts.Lang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Render(model, opts.Lang, text)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return model, code
+}
+
+// fieldNames 类型的字段名，以逗号连接
+func fieldNames(typ *Type) string {
+	names := make([]string, len(typ.Fields))
+	for i, f := range typ.Fields {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// NDJSON 按所有行的字段并集推断，后面行中的字段与超出双精度的数字同样生效
+func TestBuildNDJSON(t *testing.T) {
+	input := "{\"id\":1,\"name\":\"a\"}\n" +
+		"{\"id\":2,\"tags\":[\"x\"]}\n" +
+		"\n" +
+		"{\"id\":9007199254740993,\"profile\":{\"age\":3}}\n" +
+		"{\"id\":4,\"profile\":{\"city\":\"b\"}}\n"
+	model, code := generate(t, formatx.FormatNDJSON, input, &Options{Lang: "go", GoTags: GoTags{JSON: true}})
+	if got := fieldNames(model.Root); got != "ID,Name,Tags,Profile" {
+		t.Errorf("root fields: %s", got)
+	}
+	if len(model.Types) != 2 || fieldNames(model.Types[0]) != "Age,City" {
+		t.Fatalf("types: %d", len(model.Types))
+	}
+	for _, want := range []string{"ID int64 `json:\"id\"`", "Tags []string", "Profile Profile", "type Profile struct"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("missing %q in:\n%s", want, code)
+		}
+	}
+}
+
+// fieldByKey 按原键查找字段
+func fieldByKey(t *testing.T, typ *Type, key string) *Field {
+	t.Helper()
+	for _, f := range typ.Fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	t.Fatalf("field %s not found in %s", key, typ.Name)
+	return nil
+}
+
+// 超出双精度的数字按取值范围选择 int64、uint64、大整数或高精度小数类型，数组与多条记录取最宽的类型
+func TestPreciseTypes(t *testing.T) {
+	input := `[{
+		"safe": 9007199254740991,
+		"over": 9007199254740993,
+		"min": -9223372036854775808,
+		"unsigned": 9223372036854775808,
+		"max_uint": 18446744073709551615,
+		"huge": 18446744073709551616,
+		"price": 0.1,
+		"amount": 3.14159265358979323846264338327950288,
+		"ids": [1, 9007199254740993],
+		"widened": 1
+	}, {"widened": -18446744073709551616}]`
+	kinds := map[string]string{
+		"safe": "", "over": "int64", "min": "int64", "unsigned": "uint64", "max_uint": "uint64",
+		"huge": "bigint", "price": "", "amount": "decimal", "ids": "int64", "widened": "bigint",
+	}
+	want := map[string]map[string]string{
+		"go": {"safe": "int64", "over": "int64", "unsigned": "uint64", "huge": "*big.Int", "price": "float64",
+			"amount": "json.Number", "ids": "[]int64", "widened": "*big.Int"},
+		"typescript": {"safe": "number", "over": "bigint", "unsigned": "bigint", "huge": "bigint", "price": "number",
+			"amount": "string", "ids": "bigint[]", "widened": "bigint"},
+		"java": {"safe": "Long", "over": "Long", "unsigned": "java.math.BigInteger", "huge": "java.math.BigInteger",
+			"price": "Double", "amount": "java.math.BigDecimal", "ids": "List<Long>", "widened": "java.math.BigInteger"},
+		"kotlin": {"safe": "Long", "over": "Long", "unsigned": "java.math.BigInteger", "huge": "java.math.BigInteger",
+			"price": "Double", "amount": "java.math.BigDecimal", "ids": "List<Long>", "widened": "java.math.BigInteger"},
+		"rust": {"safe": "i64", "over": "i64", "unsigned": "u64", "huge": "serde_json::Number", "price": "f64",
+			"amount": "serde_json::Number", "ids": "Vec<i64>", "widened": "serde_json::Number"},
+		"python": {"safe": "int", "over": "int", "unsigned": "int", "huge": "int", "price": "float",
+			"amount": "decimal.Decimal", "ids": "List[int]", "widened": "int"},
+	}
+	for _, lang := range Langs {
+		t.Run(lang, func(t *testing.T) {
+			model, code := generate(t, formatx.FormatJSON, input, &Options{Lang: lang, GoTags: GoTags{JSON: true}})
+			for key, kind := range kinds {
+				if f := fieldByKey(t, model.Root, key); f.Number != kind {
+					t.Errorf("%s: number kind %q, want %q", key, f.Number, kind)
+				}
+			}
+			for key, typ := range want[lang] {
+				if f := fieldByKey(t, model.Root, key); f.Type != typ {
+					t.Errorf("%s: type %q, want %q", key, f.Type, typ)
+				}
+			}
+			if lang == "go" {
+				if strings.Join(model.Imports, ",") != "encoding/json,math/big" {
+					t.Errorf("imports: %v", model.Imports)
+				}
+				if !strings.Contains(code, "Huge *big.Int") || !strings.Contains(code, `"math/big"`) {
+					t.Errorf("code:\n%s", code)
+				}
+			}
+		})
+	}
+}
+
+// GoTags.String：int64 与 uint64 加 ,string，大整数与高精度小数改为 string 类型，其余字段不变
+func TestPreciseTypesGoString(t *testing.T) {
+	input := `{"id": 9007199254740993, "seq": 18446744073709551615, "huge": 18446744073709551616,
+		"amount": 3.14159265358979323846264338327950288, "count": 1, "price": 0.5}`
+	model, code := generate(t, formatx.FormatJSON, input, &Options{Lang: "go", GoTags: GoTags{JSON: true, OmitEmpty: true, String: true}})
+	cases := []struct{ key, typ, tag string }{
+		{"id", "int64", `json:"id,omitempty,string"`},
+		{"seq", "uint64", `json:"seq,omitempty,string"`},
+		{"huge", "string", `json:"huge,omitempty"`},
+		{"amount", "string", `json:"amount,omitempty"`},
+		{"count", "int", `json:"count,omitempty"`},
+		{"price", "float64", `json:"price,omitempty"`},
+	}
+	for _, c := range cases {
+		f := fieldByKey(t, model.Root, c.key)
+		if f.Type != c.typ || f.TagString() != c.tag {
+			t.Errorf("%s: %s `%s`, want %s `%s`", c.key, f.Type, f.TagString(), c.typ, c.tag)
+		}
+	}
+	if len(model.Imports) != 0 || strings.Contains(code, "math/big") {
+		t.Errorf("imports: %v", model.Imports)
+	}
+	// 其他语言不受 Go 标签选项影响
+	model, _ = generate(t, formatx.FormatJSON, input, &Options{Lang: "java", GoTags: GoTags{String: true}})
+	if f := fieldByKey(t, model.Root, "huge"); f.Type != "java.math.BigInteger" || len(f.Tags) != 0 {
+		t.Errorf("java huge: %s %v", f.Type, f.Tags)
+	}
+}
+
+// 命名词典的缩略词、字段名映射、保留字转义与结构体名前后缀进入各语言生成的代码；已带前缀的结构体名不重复添加
+func TestNamingDictionary(t *testing.T) {
+	dict := transformx.NewNamingDictionary(
+		[]string{"sku"},
+		map[string]string{"user_name": "Login"},
+		map[string]transformx.ReservedWords{
+			"go":     {Words: []string{"Status"}},
+			"python": {Words: []string{"self"}, Escape: "{name}_field"},
+		},
+		"Api", "DTO",
+	)
+	input := `{"sku_id": 1, "user_name": "x", "status": "ok", "class": 1, "from": 2, "self": 3,
+		"order": {"api_url": "u"}, "api_items": [{"id": 1}]}`
+	cases := []struct {
+		lang    string
+		keyCase transformx.KeyCase
+		fields  string
+		code    []string
+	}{
+		{"go", "", "SKUID,Login,Status_,Class,From,Self,Order,APIItems", []string{
+			"type ApiOrderDTO struct", "APIURL string `json:\"api_url\"`",
+			"type ApiItemDTO struct", "APIItems []ApiItemDTO `json:\"api_items\"`",
+			"type ApiResponseDTO struct", "Login string `json:\"user_name\"`", "Status_ string `json:\"status\"`",
+		}},
+		{"java", transformx.CaseCamel, "skuID,Login,status,class_,from,self,order,apiItems", []string{
+			"class ApiresponseDTO", "private Integer skuID;", "private String Login;", "private Integer class_;",
+		}},
+		{"python", transformx.CaseSnake, "sku_id,Login,status,class_field,from_field,self_field,order,api_items", []string{
+			"class ApiresponseDTO", "    Login: str", "    from_field: int", "    self_field: int",
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.lang, func(t *testing.T) {
+			model, code := generate(t, formatx.FormatJSON, input, &Options{Lang: c.lang, Case: c.keyCase, Dictionary: dict, GoTags: GoTags{JSON: true}})
+			if got := fieldNames(model.Root); got != c.fields {
+				t.Errorf("fields: %s", got)
+			}
+			for _, want := range c.code {
+				if !strings.Contains(code, want) {
+					t.Errorf("missing %q in:\n%s", want, code)
+				}
+			}
+		})
+	}
+	// 没有词典时使用内置缩略词与保留字，结构体名不加前后缀
+	model, _ := generate(t, formatx.FormatJSON, input, &Options{Lang: "go"})
+	if got := fieldNames(model.Root); model.Name != "Response" || got != "SkuID,UserName,Status,Class,From,Self,Order,APIItems" {
+		t.Errorf("default dictionary: %s %s", model.Name, got)
+	}
+}
@@ -0,0 +1,106 @@
+package codegenx
+
+import "github.com/jasonlabz/json-converter-server/common/formatx"
+
+// mergeItems 将数组元素合并为一个元素：对象取所有元素的字段并集，多维数组展平后合并，与前端 mergeArrayItems 一致
+func mergeItems(items []any) any {
+	if len(items) == 0 {
+		return nil
+	}
+	switch items[0].(type) {
+	case *formatx.Record:
+		var merged any = formatx.NewRecord()
+		for _, item := range items {
+			if record, ok := item.(*formatx.Record); ok {
+				merged = mergeValue(merged, record)
+			}
+		}
+		return mergeArrays(merged)
+	case []any:
+		var flattened []any
+		for _, item := range items {
+			if inner, ok := item.([]any); ok {
+				flattened = append(flattened, inner...)
+			} else {
+				flattened = append(flattened, item)
+			}
+		}
+		return []any{mergeItems(flattened)}
+	}
+	return items[0]
+}
+
+// mergeArrays 将值中的每个数组替换为只含合并后元素的数组
+func mergeArrays(value any) any {
+	switch v := value.(type) {
+	case *formatx.Record:
+		out := formatx.NewRecord()
+		for _, key := range v.Keys {
+			out.Set(key, mergeArrays(v.Values[key]))
+		}
+		return out
+	case []any:
+		if len(v) == 0 {
+			return v
+		}
+		switch v[0].(type) {
+		case *formatx.Record, []any:
+			return []any{mergeItems(v)}
+		}
+		return v
+	}
+	return value
+}
+
+// mergeValue 深度合并：对象按字段合并，已有字段为空值时取后来的非空值；数组取第一个非空数组，对象数组合并元素
+func mergeValue(target, source any) any {
+	switch s := source.(type) {
+	case *formatx.Record:
+		t, ok := target.(*formatx.Record)
+		if !ok {
+			return s
+		}
+		out := formatx.NewRecord()
+		for _, key := range t.Keys {
+			out.Set(key, t.Values[key])
+		}
+		for _, key := range s.Keys {
+			value := s.Values[key]
+			existing, ok := out.Get(key)
+			switch {
+			case !ok:
+				out.Set(key, value)
+			case isContainer(value):
+				out.Set(key, mergeValue(existing, value))
+			case isEmpty(existing) && !isEmpty(value):
+				out.Set(key, value)
+			}
+		}
+		return out
+	case []any:
+		t, ok := target.([]any)
+		if !ok || len(t) == 0 {
+			return s
+		}
+		if len(s) > 0 && isContainer(s[0]) {
+			return []any{mergeItems(append(append([]any(nil), t...), s...))}
+		}
+		return t
+	}
+	if isContainer(target) || source == nil {
+		return target
+	}
+	return source
+}
+
+func isContainer(value any) bool {
+	switch value.(type) {
+	case *formatx.Record, []any:
+		return true
+	}
+	return false
+}
+
+func isEmpty(value any) bool {
+	return value == nil || value == ""
+}
@@ -0,0 +1,66 @@
+package codegenx
+
+import "strings"
+
+// Model 代码模板的数据，由 Build 从文档推断。模板中以 {{.Root}}、{{range .Types}} 等方式访问
+type Model struct {
+	Lang    string   // 目标语言: go|typescript|java|python|kotlin|rust，决定 Field.Type 的写法
+	Name    string   // 根类型名，已按命名格式与命名词典改写
+	Root    *Type    // 根类型
+	Types   []*Type  // 全部具名类型，被引用的类型在前、根类型在最后；内联的类型不在其中
+	Imports []string // Go 需要导入的包，按字典序
+	HasTime bool     // 是否含时间字段
+	Inline  bool     // 嵌套对象是否内联，内联的字段 Field.Inline 为 true
+}
+
+// Type 对象推断出的类型，数组中的对象按所有元素的字段并集推断
+type Type struct {
+	Name    string   // 类型名
+	Path    string   // 对象所在路径，键以 . 连接、不含数组下标，根为空
+	Comment string   // 对象上的注释
+	Depth   int      // 内联结构体的嵌套层数，具名类型为 0
+	Fields  []*Field // 字段，按原文顺序
+}
+
+// Field 对象的字段
+type Field struct {
+	Key     string // 原键，写入 json、yaml 等标签
+	Name    string // 字段名：字段名映射、拼音转写、命名格式与保留字转义之后的结果
+	Type    string // 目标语言的类型，如 string、[]OrderItem、List<String>；内联的 Go 字段为空，由模板展开 Ref
+	Kind    string // 取值类别: string|integer|float|boolean|null|time|object|map|array|any
+	Elem    string // 数组最内层元素的类别，同 Kind，非数组为空
+	Dims    int    // 数组维数，非数组为 0
+	Ref     *Type  // 对象或对象数组的元素类型，其他为 nil
+	Inline  bool   // Ref 以内联结构体输出
+	Path    string // 字段路径，键以 . 连接、不含数组下标
+	Comment string // 字段注释，转写为拼音时含原键
+	IsTime  bool   // 按取值或键名识别为时间
+	Number  string // 超出双精度的数字类型: int64|uint64|bigint|decimal，其他为空
+	Tags    []Tag  // Go 结构体标签，按 json、mapstructure、gorm、yaml、xml、validate 的顺序
+	Example any    // 示例取值，对象与数组为 nil
+}
+
+// Tag 一个结构体标签，如 json:"user_id,omitempty"
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// String 标签的写法 key:"value"
+func (t Tag) String() string {
+	return t.Key + `:"` + t.Value + `"`
+}
+
+// TagString 全部标签以空格连接，没有标签时为空
+func (f *Field) TagString() string {
+	parts := make([]string, len(f.Tags))
+	for i, tag := range f.Tags {
+		parts[i] = tag.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// IsArray 字段是否为数组
+func (f *Field) IsArray() bool {
+	return f.Dims > 0
+}
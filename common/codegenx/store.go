@@ -0,0 +1,125 @@
+package codegenx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const templateExt = ".tmpl"
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// TemplateStore 上传的代码模板，每个模板保存为目录下的 <name>.tmpl
+type TemplateStore struct {
+	Dir string
+	mu  sync.RWMutex
+}
+
+// NewTemplateStore 创建模板存储，目录在首次保存时创建
+func NewTemplateStore(dir string) *TemplateStore {
+	return &TemplateStore{Dir: dir}
+}
+
+// CheckName 校验模板名：字母、数字、_、.、-，不能与内置模板重名
+func CheckName(name string) error {
+	if !templateNamePattern.MatchString(name) || strings.HasSuffix(name, templateExt) {
+		return fmt.Errorf("invalid template name: %q, expected letters, digits, _, . or - and at most 64 characters", name)
+	}
+	if IsBuiltin(name) {
+		return fmt.Errorf("template name %q is reserved for the builtin template", name)
+	}
+	return nil
+}
+
+// List 已保存的模板名，按字典序；目录不存在时为空
+func (s *TemplateStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), templateExt)
+		if ok && !entry.IsDir() && CheckName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Get 读取模板内容
+func (s *TemplateStore) Get(name string) (string, error) {
+	if err := CheckName(name); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Save 校验后保存模板，同名模板被覆盖
+func (s *TemplateStore) Save(name, text string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	if err := Validate(name, text); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免读到写了一半的模板
+	tmp, err := os.CreateTemp(s.Dir, "."+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.WriteString(text); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+// Delete 删除模板
+func (s *TemplateStore) Delete(name string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("template %q not found", name)
+	}
+	return err
+}
+
+func (s *TemplateStore) path(name string) string {
+	return filepath.Join(s.Dir, name+templateExt)
+}
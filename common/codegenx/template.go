@@ -0,0 +1,197 @@
+package codegenx
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+//go:embed templates/*.tmpl
+var builtinFS embed.FS
+
+// BuiltinNames 内置模板名，与目标语言同名
+func BuiltinNames() []string {
+	return append([]string(nil), Langs...)
+}
+
+// IsBuiltin 是否为内置模板名
+func IsBuiltin(name string) bool {
+	for _, lang := range Langs {
+		if lang == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Builtin 内置模板的内容，输出与前端生成的代码一致
+func Builtin(name string) (string, error) {
+	if !IsBuiltin(name) {
+		return "", fmt.Errorf("builtin template %q not found", name)
+	}
+	data, err := builtinFS.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+const (
+	maxRepeat     = 1000    // repeat 的最大次数
+	maxOutputSize = 4 << 20 // 渲染结果的大小上限
+	maxSteps      = 200000  // range 迭代与模板调用的总次数上限
+)
+
+// stepFunc 执行计数函数，只在执行前注册，模板文本中无法调用
+const stepFunc = "_step"
+
+// Funcs 模板中可用的函数
+var Funcs = template.FuncMap{
+	// last 下标是否为列表的最后一个元素，用于省略最后一个字段后的逗号
+	"last": func(i int, list any) bool {
+		v := reflect.ValueOf(list)
+		return v.Kind() == reflect.Slice && i == v.Len()-1
+	},
+	"repeat":    repeat,
+	"add":       func(a, b int) int { return a + b },
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"join":      strings.Join,
+	"replace":   strings.ReplaceAll,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"quote":     strconv.Quote,
+}
+
+// repeat 限制次数与结果长度，模板由用户提供，不能申请任意大小的内存
+func repeat(s string, count int) (string, error) {
+	if count < 0 || count > maxRepeat {
+		return "", fmt.Errorf("repeat count %d is out of range [0, %d]", count, maxRepeat)
+	}
+	if len(s)*count > maxOutputSize {
+		return "", fmt.Errorf("repeat result exceeds %d MiB", maxOutputSize>>20)
+	}
+	return strings.Repeat(s, count), nil
+}
+
+// limitedBuffer 写入超过 maxOutputSize 时返回错误，终止模板执行
+type limitedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > maxOutputSize {
+		return 0, fmt.Errorf("rendered output exceeds %d MiB", maxOutputSize>>20)
+	}
+	return b.buf.Write(p)
+}
+
+// Parse 解析模板，引用不存在的字段时执行报错
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// limitSteps 在每个模板与每个 range 循环体的开头插入计数调用，总次数超过 maxSteps 时终止执行：
+// range 整数、递归调用模板等写法不产生输出也可以无限执行，输出大小限制无法终止
+func limitSteps(tmpl *template.Template) {
+	steps := 0
+	tmpl.Funcs(template.FuncMap{stepFunc: func() (string, error) {
+		if steps++; steps > maxSteps {
+			return "", fmt.Errorf("template execution exceeds %d steps", maxSteps)
+		}
+		return "", nil
+	}})
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			countSteps(t.Tree, t.Tree.Root, true)
+		}
+	}
+}
+
+// countSteps 遍历节点列表，counted 为 true 时在列表开头插入计数调用
+func countSteps(tree *parse.Tree, list *parse.ListNode, counted bool) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.RangeNode:
+			countSteps(tree, n.List, true)
+			countSteps(tree, n.ElseList, false)
+		case *parse.IfNode:
+			countSteps(tree, n.List, false)
+			countSteps(tree, n.ElseList, false)
+		case *parse.WithNode:
+			countSteps(tree, n.List, false)
+			countSteps(tree, n.ElseList, false)
+		}
+	}
+	if counted {
+		pos := list.Pos
+		step := &parse.ActionNode{NodeType: parse.NodeAction, Pos: pos, Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe, Pos: pos,
+			Cmds: []*parse.CommandNode{{NodeType: parse.NodeCommand, Pos: pos, Args: []parse.Node{
+				parse.NewIdentifier(stepFunc).SetTree(tree).SetPos(pos),
+			}}},
+		}}
+		list.Nodes = append([]parse.Node{step}, list.Nodes...)
+	}
+}
+
+// Render 以模型执行模板，输出大小与执行步数均有上限
+func Render(model *Model, name, text string) (string, error) {
+	tmpl, err := Parse(name, text)
+	if err != nil {
+		return "", err
+	}
+	limitSteps(tmpl)
+	var buf limitedBuffer
+	if err = tmpl.Execute(&buf, model); err != nil {
+		return "", fmt.Errorf("execute template %s: %w", name, err)
+	}
+	return buf.buf.String(), nil
+}
+
+// sampleDocument 校验模板时使用的示例数据，覆盖嵌套对象、对象数组、多维数组与各类标量
+const sampleDocument = `{
+  "id": 1,
+  "name": "sample",
+  "price": 9.5,
+  "active": true,
+  "remark": null,
+  "created_at": "2024-01-01T00:00:00Z",
+  "tags": ["a"],
+  "matrix": [[1]],
+  "meta": {},
+  "owner": {"user_id": 9007199254740993, "email": "a@b.c"},
+  "items": [{"sku": "x", "qty": 1}]
+}`
+
+// Validate 解析模板并以示例数据试执行，检查语法与引用的字段
+func Validate(name, text string) error {
+	doc, err := formatx.Parse(formatx.FormatJSON, []byte(sampleDocument), nil)
+	if err != nil {
+		return err
+	}
+	for _, inline := range []bool{false, true} {
+		model, err := Build(doc, &Options{Lang: "go", Inline: inline, DetectTime: true, GoTags: GoTags{JSON: true}})
+		if err != nil {
+			return err
+		}
+		if _, err = Render(model, name, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package codegenx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTemplatesValidate(t *testing.T) {
+	for _, name := range BuiltinNames() {
+		text, err := Builtin(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err = Validate(name, text); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// 用户模板不能通过 repeat 或循环输出申请任意大小的内存
+func TestTemplateLimits(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"repeat count", `{{repeat "x" 10000000000}}`, "out of range"},
+		{"negative repeat", `{{repeat "x" -1}}`, "out of range"},
+		{"repeat size", `{{repeat (repeat "x" 1000) 1000}}{{repeat (repeat (repeat "x" 1000) 1000) 1000}}`, "exceeds"},
+		{"output size", `{{range .Types}}{{range .Fields}}` + strings.Repeat(`{{repeat "x" 1000}}`, 1000) + `{{end}}{{end}}`, "exceeds"},
+		{"range integer", `{{range 300000000}}{{end}}ok`, "exceeds 200000 steps"},
+		{"nested range", `{{range .Root.Fields}}` + strings.Repeat(`{{range $.Root.Fields}}`, 5) + strings.Repeat(`{{end}}`, 6), "exceeds 200000 steps"},
+		{"recursive template", `{{define "a"}}{{if lt . 40}}{{template "a" (add . 1)}}{{template "a" (add . 1)}}{{end}}{{end}}{{template "a" 0}}`, "exceeds 200000 steps"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Validate(c.name, c.text)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+	if err := Validate("small", `{{repeat "  " 3}}`); err != nil {
+		t.Errorf("small repeat: %v", err)
+	}
+}
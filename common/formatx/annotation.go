@@ -0,0 +1,214 @@
+package formatx
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 二进制格式中 JSON 无法直接表示的值使用 MongoDB Extended JSON v2 风格的注解对象表示，
+// 如 {"$binary": {"base64": "...", "subType": "00"}}、{"$date": "2024-01-02T03:04:05Z"}；
+// MessagePack 扩展类型为 {"$ext": {"type": 5, "base64": "..."}}，CBOR 标签为 {"$tag": 37, "value": ...}
+const (
+	annotationBinary  = "$binary"
+	annotationDate    = "$date"
+	annotationExt     = "$ext"
+	annotationTag     = "$tag"
+	annotationDouble  = "$numberDouble"
+	annotationLong    = "$numberLong"
+	annotationInt     = "$numberInt"
+	annotationDecimal = "$numberDecimal"
+)
+
+func binaryValue(data []byte, subType byte) map[string]any {
+	return map[string]any{annotationBinary: map[string]any{
+		"base64":  base64.StdEncoding.EncodeToString(data),
+		"subType": hex.EncodeToString([]byte{subType}),
+	}}
+}
+
+// dateValue 1970 至 9999 年之间的时间输出为 RFC 3339 文本，其余输出毫秒数
+func dateValue(t time.Time) map[string]any {
+	t = t.UTC()
+	if t.Year() >= 1970 && t.Year() <= 9999 {
+		return map[string]any{annotationDate: t.Format(time.RFC3339Nano)}
+	}
+	return map[string]any{annotationDate: map[string]any{annotationLong: strconv.FormatInt(t.UnixMilli(), 10)}}
+}
+
+// floatValue NaN 与 Infinity 无法用 JSON 数字表示，输出为 $numberDouble
+func floatValue(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return map[string]any{annotationDouble: "NaN"}
+	case math.IsInf(f, 1):
+		return map[string]any{annotationDouble: "Infinity"}
+	case math.IsInf(f, -1):
+		return map[string]any{annotationDouble: "-Infinity"}
+	}
+	return f
+}
+
+// specialFloatFormats 可以表示 NaN 与 Infinity 的格式，解析时转为 $numberDouble 注解，输出时还原
+var specialFloatFormats = map[Format]bool{
+	FormatJSON5: true, FormatYAML: true, FormatTOML: true, FormatMsgpack: true, FormatCBOR: true, FormatBSON: true,
+}
+
+// warnSpecialDoubles 源格式中的 NaN 与 Infinity 输出为无法表示它们的格式时保留为 $numberDouble 注解，逐个记录提示
+func (d *Document) warnSpecialDoubles(to Format) {
+	if !specialFloatFormats[d.Format] || specialFloatFormats[to] {
+		return
+	}
+	var walk func(value any, path string)
+	walk = func(value any, path string) {
+		if text, ok := json5Special(value); ok {
+			d.Warn(0, path, "%s cannot be represented in %s, kept as {%q: %q}", text, to, annotationDouble, text)
+			return
+		}
+		switch v := value.(type) {
+		case *Record:
+			for _, key := range v.Keys {
+				walk(v.Values[key], childPath(path, key))
+			}
+		case []any:
+			for i, item := range v {
+				walk(item, indexPath(path, i))
+			}
+		}
+	}
+	walk(d.Ordered(nil), "")
+}
+
+// doubleAnnotation $numberDouble 注解表示的 NaN 或 Infinity，输出为支持这些字面量的格式时还原
+func doubleAnnotation(value any) (float64, bool) {
+	text, ok := json5Special(value)
+	if !ok {
+		return 0, false
+	}
+	f, err := parseDoubleAnnotation(text)
+	return f, err == nil
+}
+
+// annotation 判断对象是否为注解对象（只有一个以 $ 开头的键，$code 可附带 $scope，$tag 附带 value），返回注解名
+func annotation(m map[string]any) (string, bool) {
+	if len(m) == 2 {
+		_, hasCode := m["$code"]
+		_, hasScope := m["$scope"]
+		if hasCode && hasScope {
+			return "$code", true
+		}
+		_, hasTag := m[annotationTag]
+		_, hasValue := m["value"]
+		return annotationTag, hasTag && hasValue
+	}
+	if len(m) != 1 {
+		return "", false
+	}
+	for key := range m {
+		return key, strings.HasPrefix(key, "$")
+	}
+	return "", false
+}
+
+func parseBinaryAnnotation(value any) ([]byte, byte, error) {
+	spec, ok := value.(map[string]any)
+	if !ok {
+		return nil, 0, fmt.Errorf("$binary must be an object with base64 and subType")
+	}
+	text, _ := spec["base64"].(string)
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid $binary base64: %w", err)
+	}
+	var subType byte
+	if s, ok := spec["subType"].(string); ok && s != "" {
+		n, err := strconv.ParseUint(s, 16, 8)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid $binary subType %q", s)
+		}
+		subType = byte(n)
+	}
+	return data, subType, nil
+}
+
+func parseDateAnnotation(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	case json.Number:
+		ms, err := v.Int64()
+		return time.UnixMilli(ms).UTC(), err
+	case map[string]any:
+		if text, ok := v[annotationLong].(string); ok {
+			ms, err := strconv.ParseInt(text, 10, 64)
+			return time.UnixMilli(ms).UTC(), err
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid $date value")
+}
+
+func parseDoubleAnnotation(value any) (float64, error) {
+	text, _ := value.(string)
+	switch text {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+func parseIntAnnotation(value any) (int64, error) {
+	switch v := value.(type) {
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case json.Number:
+		return v.Int64()
+	case int64:
+		return v, nil
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid integer annotation")
+}
+
+// jsonNumber 将 json.Number 转为整数或浮点数，超出 int64/uint64 范围的整数与超出 float64 精度的小数记录提示
+func jsonNumber(n json.Number, path string, doc *Document) any {
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil {
+		doc.Warn(0, path, "invalid number %s encoded as string", n)
+		return n.String()
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		doc.Warn(0, path, "integer %s out of 64-bit range, encoded as float", n)
+	} else if !Float64Exact(n.String()) {
+		doc.Warn(0, path, "number %s rounded to float64", n)
+	}
+	return f
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
@@ -0,0 +1,352 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"time"
+
+	ugorji "github.com/ugorji/go/codec"
+)
+
+// CBOR 正/负大整数标签
+const (
+	cborTagPositiveBignum = 2
+	cborTagNegativeBignum = 3
+)
+
+func msgpackHandle() *ugorji.MsgpackHandle {
+	h := &ugorji.MsgpackHandle{WriteExt: true}
+	h.Canonical = true
+	h.MapType = reflect.TypeOf(map[any]any(nil))
+	return h
+}
+
+func cborHandle() *ugorji.CborHandle {
+	h := &ugorji.CborHandle{}
+	h.Canonical = true
+	h.MapType = reflect.TypeOf(map[any]any(nil))
+	return h
+}
+
+func parseMsgpack(doc *Document, data []byte, opts *Options) error {
+	return decodeBinary(doc, data, msgpackHandle(), msgpackContainer, opts.duplicateKeys(FormatMsgpack))
+}
+
+func marshalMsgpack(doc *Document, opts *Options) ([]byte, error) {
+	return encodeBinary(doc, opts, msgpackHandle(), FormatMsgpack)
+}
+
+func parseCBOR(doc *Document, data []byte, opts *Options) error {
+	return decodeBinary(doc, data, cborHandle(), cborContainer, opts.duplicateKeys(FormatCBOR))
+}
+
+func marshalCBOR(doc *Document, opts *Options) ([]byte, error) {
+	return encodeBinary(doc, opts, cborHandle(), FormatCBOR)
+}
+
+// binaryContainer 根据值的首字节判断是否为 map 或数组
+type binaryContainer func(first byte) (isMap, isArray bool)
+
+func msgpackContainer(first byte) (bool, bool) {
+	isMap := first >= 0x80 && first <= 0x8f || first == 0xde || first == 0xdf
+	isArray := first >= 0x90 && first <= 0x9f || first == 0xdc || first == 0xdd
+	return isMap, isArray
+}
+
+func cborContainer(first byte) (bool, bool) {
+	return first>>5 == 5, first>>5 == 4
+}
+
+// decodeBinary 解码 MessagePack/CBOR，连续存放的多个值解码为数组
+func decodeBinary(doc *Document, data []byte, handle ugorji.Handle, container binaryContainer, duplicates DuplicateKeyPolicy) error {
+	reader := bytes.NewReader(data)
+	decoder := ugorji.NewDecoder(reader, handle)
+	var values []any
+	for reader.Len() > 0 {
+		offset := len(data) - reader.Len()
+		var raw ugorji.Raw
+		err := decoder.Decode(&raw)
+		var value any
+		if err == nil {
+			value, err = decodeOrdered(raw, handle, container)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("offset %d: %w", offset, err)
+		}
+		values = append(values, value)
+	}
+	var value any = values
+	switch len(values) {
+	case 0:
+		return errors.New("empty input")
+	case 1:
+		value = values[0]
+	default:
+		doc.Warn(0, "", "%d concatenated values decoded as an array", len(values))
+	}
+	annotated, err := annotateBinary(value, "", doc, duplicates)
+	if err != nil {
+		return err
+	}
+	doc.SetOrdered(annotated)
+	return nil
+}
+
+// decodeOrdered 编码库将 map 解码为 Go map，丢失键顺序；map 与数组逐层按原始字节解码为 orderedMap 与切片
+func decodeOrdered(raw ugorji.Raw, handle ugorji.Handle, container binaryContainer) (any, error) {
+	if len(raw) == 0 { // 编码库将 nil 解码为空的原始字节
+		return nil, nil
+	}
+	isMap, isArray := container(raw[0])
+	switch {
+	case isMap:
+		var pairs rawPairs
+		if err := ugorji.NewDecoderBytes(raw, handle).Decode(&pairs); err != nil {
+			return nil, err
+		}
+		m := make(orderedMap, len(pairs))
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if err := ugorji.NewDecoderBytes(pairs[i], handle).Decode(&m[i]); err != nil {
+				return nil, err
+			}
+			item, err := decodeOrdered(pairs[i+1], handle, container)
+			if err != nil {
+				return nil, err
+			}
+			m[i+1] = item
+		}
+		return m, nil
+	case isArray:
+		var raws []ugorji.Raw
+		if err := ugorji.NewDecoderBytes(raw, handle).Decode(&raws); err != nil {
+			return nil, err
+		}
+		items := make([]any, len(raws))
+		for i, item := range raws {
+			value, err := decodeOrdered(item, handle, container)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	default:
+		var value any
+		err := ugorji.NewDecoderBytes(raw, handle).Decode(&value)
+		return value, err
+	}
+}
+
+// rawPairs map 的键值按原始字节交替存放
+type rawPairs []ugorji.Raw
+
+func (rawPairs) MapBySlice() {}
+
+// annotateBinary 将解码结果转为 JSON 数据模型，对象转为 *Record，字节串、时间、扩展类型等转为注解对象；
+// 二进制格式无法取得键的偏移量，重复键以其在对象中的序号定位
+func annotateBinary(value any, path string, doc *Document, duplicates DuplicateKeyPolicy) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, string, int64, uint64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int8, int16, int32, uint, uint8, uint16, uint32:
+		return v, nil
+	case float32:
+		return floatValue(float64(v)), nil
+	case float64:
+		return floatValue(v), nil
+	case []byte:
+		return binaryValue(v, 0), nil
+	case time.Time:
+		return dateValue(v), nil
+	case orderedMap:
+		record := &Record{Values: make(map[string]any, len(v)/2)}
+		seen := newRecordKeySet(doc, duplicates)
+		for i := 0; i+1 < len(v); i += 2 {
+			name := binaryKey(v[i], path, doc)
+			item, err := annotateBinary(v[i+1], childPath(path, name), doc, duplicates)
+			if err != nil {
+				return nil, err
+			}
+			if err := seen.set(record, path, name, sourcePos{entry: i/2 + 1}, item); err != nil {
+				return nil, err
+			}
+		}
+		return record, nil
+	case map[any]any:
+		// 标签内的 map 由编码库直接解码，不保留键顺序
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			name := binaryKey(key, path, doc)
+			annotated, err := annotateBinary(item, childPath(path, name), doc, duplicates)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = annotated
+		}
+		return m, nil
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			annotated, err := annotateBinary(item, indexPath(path, i), doc, duplicates)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = annotated
+		}
+		return items, nil
+	case ugorji.RawExt:
+		if v.Data != nil || v.Value == nil {
+			return map[string]any{annotationExt: map[string]any{
+				"type":   v.Tag,
+				"base64": base64.StdEncoding.EncodeToString(v.Data),
+			}}, nil
+		}
+		if b, ok := v.Value.([]byte); ok && (v.Tag == cborTagPositiveBignum || v.Tag == cborTagNegativeBignum) {
+			n := new(big.Int).SetBytes(b)
+			if v.Tag == cborTagNegativeBignum {
+				n.Neg(n).Sub(n, big.NewInt(1))
+			}
+			return json.Number(n.String()), nil
+		}
+		tagged, err := annotateBinary(v.Value, path, doc, duplicates)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{annotationTag: v.Tag, "value": tagged}, nil
+	default:
+		doc.Warn(0, path, "unsupported value of type %T converted to string", v)
+		return fmt.Sprint(v), nil
+	}
+}
+
+// binaryKey 非字符串键转为字符串并记录提示
+func binaryKey(key any, path string, doc *Document) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		name := string(k)
+		doc.Warn(0, childPath(path, name), "non-string key %v (%T) converted to string", key, key)
+		return name
+	default:
+		name := fmt.Sprint(key)
+		doc.Warn(0, childPath(path, name), "non-string key %v (%T) converted to string", key, key)
+		return name
+	}
+}
+
+func encodeBinary(doc *Document, opts *Options, handle ugorji.Handle, format Format) ([]byte, error) {
+	value := deannotateBinary(doc.Ordered(opts), "", doc, format)
+	var buf []byte
+	if err := ugorji.NewEncoderBytes(&buf, handle).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// deannotateBinary 将注解对象还原为编码库识别的类型，目标格式不支持的注解保留为普通对象并记录提示
+func deannotateBinary(value any, path string, doc *Document, format Format) any {
+	switch v := value.(type) {
+	case json.Number:
+		if format == FormatCBOR {
+			if n, ok := new(big.Int).SetString(v.String(), 10); ok && !n.IsInt64() && !n.IsUint64() {
+				return cborBignum(n)
+			}
+		}
+		return jsonNumber(v, path, doc)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = deannotateBinary(item, indexPath(path, i), doc, format)
+		}
+		return items
+	case map[string]any:
+		if name, ok := annotation(v); ok {
+			decoded, err := binaryAnnotation(name, v, path, doc, format)
+			if err == nil {
+				return decoded
+			}
+			doc.Warn(0, path, "%v, kept as object", err)
+		}
+		m := make(map[string]any, len(v))
+		for _, key := range sortedKeys(v) {
+			m[key] = deannotateBinary(v[key], childPath(path, key), doc, format)
+		}
+		return m
+	case *Record:
+		if _, ok := annotation(v.Values); ok {
+			return deannotateBinary(unorderedValue(v), path, doc, format)
+		}
+		pairs := make(orderedMap, 0, 2*len(v.Keys))
+		for _, key := range v.Keys {
+			pairs = append(pairs, key, deannotateBinary(v.Values[key], childPath(path, key), doc, format))
+		}
+		return pairs
+	default:
+		return v
+	}
+}
+
+// orderedMap 键值交替存放，编码库按顺序输出为 map，解码时按原文顺序存放
+type orderedMap []any
+
+func (orderedMap) MapBySlice() {}
+
+// cborBignum 超出 64 位范围的整数编码为 CBOR 大整数标签
+func cborBignum(n *big.Int) ugorji.RawExt {
+	if n.Sign() < 0 {
+		return ugorji.RawExt{Tag: cborTagNegativeBignum, Value: new(big.Int).Sub(new(big.Int).Neg(n), big.NewInt(1)).Bytes()}
+	}
+	return ugorji.RawExt{Tag: cborTagPositiveBignum, Value: n.Bytes()}
+}
+
+func binaryAnnotation(name string, m map[string]any, path string, doc *Document, format Format) (any, error) {
+	value := m[name]
+	switch name {
+	case annotationBinary:
+		data, subType, err := parseBinaryAnnotation(value)
+		if err == nil && subType != 0 {
+			doc.Warn(0, path, "binary subtype %02x is not supported in %s and was dropped", subType, format)
+		}
+		return data, err
+	case annotationDate:
+		return parseDateAnnotation(value)
+	case annotationDouble:
+		return parseDoubleAnnotation(value)
+	case annotationLong, annotationInt:
+		return parseIntAnnotation(value)
+	case annotationExt:
+		spec, _ := value.(map[string]any)
+		if format != FormatMsgpack || spec == nil {
+			return nil, fmt.Errorf("%s is not supported in %s", name, format)
+		}
+		tag, err := parseIntAnnotation(spec["type"])
+		if err != nil || tag < -128 || tag > 127 {
+			return nil, fmt.Errorf("invalid $ext type")
+		}
+		data, _, err := parseBinaryAnnotation(map[string]any{"base64": spec["base64"]})
+		if err != nil {
+			return nil, err
+		}
+		return ugorji.RawExt{Tag: uint64(tag), Data: data}, nil
+	case annotationTag:
+		tag, err := parseIntAnnotation(value)
+		if format != FormatCBOR || err != nil || tag < 0 {
+			return nil, fmt.Errorf("%s is not supported in %s", name, format)
+		}
+		return ugorji.RawExt{Tag: uint64(tag), Value: deannotateBinary(m["value"], childPath(path, "value"), doc, format)}, nil
+	default:
+		return nil, fmt.Errorf("%s is not supported in %s", name, format)
+	}
+}
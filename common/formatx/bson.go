@@ -0,0 +1,663 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BSON 元素类型
+const (
+	bsonDouble     byte = 0x01
+	bsonString     byte = 0x02
+	bsonDocument   byte = 0x03
+	bsonArray      byte = 0x04
+	bsonBinary     byte = 0x05
+	bsonUndefined  byte = 0x06
+	bsonObjectID   byte = 0x07
+	bsonBoolean    byte = 0x08
+	bsonDateTime   byte = 0x09
+	bsonNull       byte = 0x0A
+	bsonRegex      byte = 0x0B
+	bsonDBPointer  byte = 0x0C
+	bsonJavaScript byte = 0x0D
+	bsonSymbol     byte = 0x0E
+	bsonCodeScope  byte = 0x0F
+	bsonInt32      byte = 0x10
+	bsonTimestamp  byte = 0x11
+	bsonInt64      byte = 0x12
+	bsonDecimal128 byte = 0x13
+	bsonMinKey     byte = 0xFF
+	bsonMaxKey     byte = 0x7F
+)
+
+const bsonMaxDepth = 1000
+
+// BSON 特有类型的注解名，与 MongoDB Extended JSON v2 一致
+const (
+	annotationObjectID  = "$oid"
+	annotationRegex     = "$regularExpression"
+	annotationTimestamp = "$timestamp"
+	annotationCode      = "$code"
+	annotationScope     = "$scope"
+	annotationSymbol    = "$symbol"
+	annotationDBPointer = "$dbPointer"
+	annotationMinKey    = "$minKey"
+	annotationMaxKey    = "$maxKey"
+	annotationUndefined = "$undefined"
+)
+
+// parseBSON 解析 BSON 文档，连续存放的多个文档（如 mongodump 输出）解析为数组
+func parseBSON(doc *Document, data []byte, opts *Options) error {
+	r := &bsonReader{data: data, doc: doc, duplicates: opts.duplicateKeys(FormatBSON)}
+	var docs []any
+	for r.pos < len(data) {
+		value, err := r.document("", 0, false)
+		if IsDuplicateKeyError(err) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("offset %d: %w", r.pos, err)
+		}
+		docs = append(docs, value)
+	}
+	switch len(docs) {
+	case 0:
+		return errors.New("empty input")
+	case 1:
+		doc.SetOrdered(docs[0])
+	default:
+		doc.Warn(0, "", "%d concatenated documents decoded as an array", len(docs))
+		doc.SetOrdered(docs)
+	}
+	return nil
+}
+
+type bsonReader struct {
+	data       []byte
+	pos        int
+	doc        *Document
+	duplicates DuplicateKeyPolicy
+}
+
+func (r *bsonReader) need(n int) error {
+	if n < 0 || r.pos+n > len(r.data) {
+		return errors.New("unexpected end of data")
+	}
+	return nil
+}
+
+func (r *bsonReader) bytes(n int) ([]byte, error) {
+	if err := r.need(n); err != nil {
+		return nil, err
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *bsonReader) int32() (int32, error) {
+	b, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (r *bsonReader) uint64() (uint64, error) {
+	b, err := r.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+func (r *bsonReader) cstring() (string, error) {
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		return "", errors.New("unterminated cstring")
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return s, nil
+}
+
+func (r *bsonReader) string() (string, error) {
+	n, err := r.int32()
+	if err != nil {
+		return "", err
+	}
+	if n < 1 {
+		return "", fmt.Errorf("invalid string length %d", n)
+	}
+	b, err := r.bytes(int(n))
+	if err != nil {
+		return "", err
+	}
+	if b[n-1] != 0 {
+		return "", errors.New("string is not null terminated")
+	}
+	return string(b[:n-1]), nil
+}
+
+// document 读取文档或数组，数组的键为下标
+func (r *bsonReader) document(path string, depth int, array bool) (any, error) {
+	if depth > bsonMaxDepth {
+		return nil, errors.New("document nested too deeply")
+	}
+	start := r.pos
+	size, err := r.int32()
+	if err != nil {
+		return nil, err
+	}
+	if size < 5 || start+int(size) > len(r.data) {
+		return nil, fmt.Errorf("invalid document size %d", size)
+	}
+	end := start + int(size)
+	m := NewRecord()
+	seen := newRecordKeySet(r.doc, r.duplicates)
+	var items []any
+	for {
+		if r.pos >= end {
+			return nil, errors.New("document is not null terminated")
+		}
+		kind := r.data[r.pos]
+		keyPos := r.pos
+		r.pos++
+		if kind == 0 {
+			break
+		}
+		key, err := r.cstring()
+		if err != nil {
+			return nil, err
+		}
+		elemPath := childPath(path, key)
+		if array {
+			elemPath = indexPath(path, len(items))
+		}
+		value, err := r.element(kind, elemPath, depth)
+		if IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", elemPath, err)
+		}
+		if array {
+			items = append(items, value)
+			continue
+		}
+		if err = seen.set(m, path, key, sourcePos{offset: int64(keyPos)}, value); err != nil {
+			return nil, err
+		}
+	}
+	if r.pos != end {
+		return nil, fmt.Errorf("document size %d does not match content", size)
+	}
+	if array {
+		if items == nil {
+			items = []any{}
+		}
+		return items, nil
+	}
+	return m, nil
+}
+
+func (r *bsonReader) element(kind byte, path string, depth int) (any, error) {
+	switch kind {
+	case bsonDouble:
+		bits, err := r.uint64()
+		return floatValue(math.Float64frombits(bits)), err
+	case bsonString:
+		return r.string()
+	case bsonDocument:
+		return r.document(path, depth+1, false)
+	case bsonArray:
+		return r.document(path, depth+1, true)
+	case bsonBinary:
+		n, err := r.int32()
+		if err != nil {
+			return nil, err
+		}
+		subType, err := r.bytes(1)
+		if err != nil {
+			return nil, err
+		}
+		data, err := r.bytes(int(n))
+		if err != nil {
+			return nil, err
+		}
+		// 旧版二进制子类型 0x02 内部还有一层长度前缀
+		if subType[0] == 0x02 && len(data) >= 4 {
+			data = data[4:]
+		}
+		return binaryValue(data, subType[0]), nil
+	case bsonUndefined:
+		return map[string]any{annotationUndefined: true}, nil
+	case bsonObjectID:
+		b, err := r.bytes(12)
+		return map[string]any{annotationObjectID: hex.EncodeToString(b)}, err
+	case bsonBoolean:
+		b, err := r.bytes(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case bsonDateTime:
+		ms, err := r.uint64()
+		return dateValue(time.UnixMilli(int64(ms))), err
+	case bsonNull:
+		return nil, nil
+	case bsonRegex:
+		pattern, err := r.cstring()
+		if err != nil {
+			return nil, err
+		}
+		options, err := r.cstring()
+		return map[string]any{annotationRegex: map[string]any{"pattern": pattern, "options": options}}, err
+	case bsonDBPointer:
+		ref, err := r.string()
+		if err != nil {
+			return nil, err
+		}
+		id, err := r.bytes(12)
+		return map[string]any{annotationDBPointer: map[string]any{
+			"$ref": ref,
+			"$id":  map[string]any{annotationObjectID: hex.EncodeToString(id)},
+		}}, err
+	case bsonJavaScript:
+		code, err := r.string()
+		return map[string]any{annotationCode: code}, err
+	case bsonSymbol:
+		symbol, err := r.string()
+		return map[string]any{annotationSymbol: symbol}, err
+	case bsonCodeScope:
+		if _, err := r.int32(); err != nil {
+			return nil, err
+		}
+		code, err := r.string()
+		if err != nil {
+			return nil, err
+		}
+		scope, err := r.document(path, depth+1, false)
+		return map[string]any{annotationCode: code, annotationScope: scope}, err
+	case bsonInt32:
+		n, err := r.int32()
+		return int64(n), err
+	case bsonTimestamp:
+		v, err := r.uint64()
+		return map[string]any{annotationTimestamp: map[string]any{"t": v >> 32, "i": v & math.MaxUint32}}, err
+	case bsonInt64:
+		v, err := r.uint64()
+		return int64(v), err
+	case bsonDecimal128:
+		low, err := r.uint64()
+		if err != nil {
+			return nil, err
+		}
+		high, err := r.uint64()
+		return map[string]any{annotationDecimal: decimal128String(high, low)}, err
+	case bsonMinKey:
+		return map[string]any{annotationMinKey: 1}, nil
+	case bsonMaxKey:
+		return map[string]any{annotationMaxKey: 1}, nil
+	default:
+		return nil, fmt.Errorf("unknown element type 0x%02x", kind)
+	}
+}
+
+// marshalBSON 序列化为 BSON，根节点须为对象，对象数组输出为连续存放的多个文档
+func marshalBSON(doc *Document, opts *Options) ([]byte, error) {
+	w := &bsonWriter{doc: doc}
+	switch root := doc.Ordered(opts).(type) {
+	case *Record:
+		if err := w.document("", root); err != nil {
+			return nil, err
+		}
+	case []any:
+		for i, item := range root {
+			m, ok := item.(*Record)
+			if !ok {
+				return nil, fmt.Errorf("%s: bson document must be an object, got %T", indexPath("", i), item)
+			}
+			if err := w.document(indexPath("", i), m); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("bson root must be an object or an array of objects, got %T", doc.Value)
+	}
+	return w.buf.Bytes(), nil
+}
+
+type bsonWriter struct {
+	buf bytes.Buffer
+	doc *Document
+}
+
+func (w *bsonWriter) int32(n int32) {
+	_ = binary.Write(&w.buf, binary.LittleEndian, n)
+}
+
+func (w *bsonWriter) uint64(n uint64) {
+	_ = binary.Write(&w.buf, binary.LittleEndian, n)
+}
+
+func (w *bsonWriter) cstring(path, s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return fmt.Errorf("%s: key or pattern contains a null byte", path)
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteByte(0)
+	return nil
+}
+
+func (w *bsonWriter) string(s string) {
+	w.int32(int32(len(s) + 1))
+	w.buf.WriteString(s)
+	w.buf.WriteByte(0)
+}
+
+// document 写入文档，先占位长度，写完元素后回填
+func (w *bsonWriter) document(path string, m *Record) error {
+	start := w.buf.Len()
+	w.int32(0)
+	for _, key := range m.Keys {
+		if err := w.element(childPath(path, key), key, m.Values[key]); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte(0)
+	binary.LittleEndian.PutUint32(w.buf.Bytes()[start:], uint32(w.buf.Len()-start))
+	return nil
+}
+
+func (w *bsonWriter) array(path string, items []any) error {
+	start := w.buf.Len()
+	w.int32(0)
+	for i, item := range items {
+		if err := w.element(indexPath(path, i), strconv.Itoa(i), item); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte(0)
+	binary.LittleEndian.PutUint32(w.buf.Bytes()[start:], uint32(w.buf.Len()-start))
+	return nil
+}
+
+// header 写入元素类型与键名
+func (w *bsonWriter) header(path string, kind byte, key string) error {
+	w.buf.WriteByte(kind)
+	return w.cstring(path, key)
+}
+
+func (w *bsonWriter) element(path, key string, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return w.header(path, bsonNull, key)
+	case bool:
+		if err := w.header(path, bsonBoolean, key); err != nil {
+			return err
+		}
+		if v {
+			w.buf.WriteByte(1)
+		} else {
+			w.buf.WriteByte(0)
+		}
+		return nil
+	case string:
+		if err := w.header(path, bsonString, key); err != nil {
+			return err
+		}
+		w.string(v)
+		return nil
+	case json.Number:
+		return w.element(path, key, jsonNumber(v, path, w.doc))
+	case int:
+		return w.integer(path, key, int64(v))
+	case int64:
+		return w.integer(path, key, v)
+	case uint64:
+		if v > math.MaxInt64 {
+			w.doc.Warn(0, path, "integer %d out of int64 range, encoded as double", v)
+			return w.double(path, key, float64(v))
+		}
+		return w.integer(path, key, int64(v))
+	case float64:
+		return w.double(path, key, v)
+	case time.Time:
+		if err := w.header(path, bsonDateTime, key); err != nil {
+			return err
+		}
+		w.uint64(uint64(v.UnixMilli()))
+		return nil
+	case []any:
+		if err := w.header(path, bsonArray, key); err != nil {
+			return err
+		}
+		return w.array(path, v)
+	case map[string]any:
+		return w.element(path, key, &Record{Keys: sortedKeys(v), Values: v})
+	case *Record:
+		if name, ok := annotation(v.Values); ok {
+			handled, err := w.annotated(path, key, name, unorderedValue(v).(map[string]any))
+			if handled || err != nil {
+				return err
+			}
+		}
+		if err := w.header(path, bsonDocument, key); err != nil {
+			return err
+		}
+		return w.document(path, v)
+	default:
+		w.doc.Warn(0, path, "unsupported value of type %T encoded as string", v)
+		return w.element(path, key, fmt.Sprint(v))
+	}
+}
+
+func (w *bsonWriter) integer(path, key string, n int64) error {
+	if n >= math.MinInt32 && n <= math.MaxInt32 {
+		if err := w.header(path, bsonInt32, key); err != nil {
+			return err
+		}
+		w.int32(int32(n))
+		return nil
+	}
+	if err := w.header(path, bsonInt64, key); err != nil {
+		return err
+	}
+	w.uint64(uint64(n))
+	return nil
+}
+
+func (w *bsonWriter) double(path, key string, f float64) error {
+	if err := w.header(path, bsonDouble, key); err != nil {
+		return err
+	}
+	w.uint64(math.Float64bits(f))
+	return nil
+}
+
+// annotated 写入注解对象对应的 BSON 类型，无法识别或取值非法时返回 false，按普通对象写入
+func (w *bsonWriter) annotated(path, key, name string, m map[string]any) (bool, error) {
+	value := m[name]
+	var err error
+	switch name {
+	case annotationBinary:
+		var data []byte
+		var subType byte
+		if data, subType, err = parseBinaryAnnotation(value); err == nil {
+			if err = w.header(path, bsonBinary, key); err != nil {
+				return true, err
+			}
+			if subType == 0x02 {
+				w.int32(int32(len(data) + 4))
+				w.buf.WriteByte(subType)
+				w.int32(int32(len(data)))
+			} else {
+				w.int32(int32(len(data)))
+				w.buf.WriteByte(subType)
+			}
+			w.buf.Write(data)
+			return true, nil
+		}
+	case annotationDate:
+		var t time.Time
+		if t, err = parseDateAnnotation(value); err == nil {
+			return true, w.element(path, key, t)
+		}
+	case annotationDouble:
+		var f float64
+		if f, err = parseDoubleAnnotation(value); err == nil {
+			return true, w.double(path, key, f)
+		}
+	case annotationLong, annotationInt:
+		var n int64
+		if n, err = parseIntAnnotation(value); err == nil {
+			if name == annotationInt {
+				if n < math.MinInt32 || n > math.MaxInt32 {
+					err = fmt.Errorf("$numberInt %d out of int32 range", n)
+					break
+				}
+				return true, w.integer(path, key, n)
+			}
+			if err = w.header(path, bsonInt64, key); err == nil {
+				w.uint64(uint64(n))
+			}
+			return true, err
+		}
+	case annotationDecimal:
+		text, _ := value.(string)
+		var high, low uint64
+		if high, low, err = parseDecimal128(text); err == nil {
+			if err = w.header(path, bsonDecimal128, key); err == nil {
+				w.uint64(low)
+				w.uint64(high)
+			}
+			return true, err
+		}
+	case annotationObjectID:
+		text, _ := value.(string)
+		var id []byte
+		if id, err = hex.DecodeString(text); err == nil && len(id) == 12 {
+			if err = w.header(path, bsonObjectID, key); err == nil {
+				w.buf.Write(id)
+			}
+			return true, err
+		}
+		err = fmt.Errorf("invalid $oid %q", text)
+	case annotationRegex:
+		spec, _ := value.(map[string]any)
+		pattern, ok1 := spec["pattern"].(string)
+		options, ok2 := spec["options"].(string)
+		if ok1 && ok2 {
+			if err = w.header(path, bsonRegex, key); err != nil {
+				return true, err
+			}
+			if err = w.cstring(path, pattern); err != nil {
+				return true, err
+			}
+			return true, w.cstring(path, options)
+		}
+		err = errors.New("$regularExpression requires pattern and options")
+	case annotationTimestamp:
+		spec, _ := value.(map[string]any)
+		var t, i int64
+		if t, err = timestampPart(spec["t"]); err == nil {
+			if i, err = timestampPart(spec["i"]); err == nil {
+				if err = w.header(path, bsonTimestamp, key); err == nil {
+					w.uint64(uint64(t)<<32 | uint64(i))
+				}
+				return true, err
+			}
+		}
+	case annotationCode:
+		code, ok := value.(string)
+		if !ok {
+			err = errors.New("$code must be a string")
+			break
+		}
+		scope, hasScope := m[annotationScope].(map[string]any)
+		if !hasScope {
+			if _, present := m[annotationScope]; present {
+				err = errors.New("$scope must be an object")
+				break
+			}
+			if err = w.header(path, bsonJavaScript, key); err == nil {
+				w.string(code)
+			}
+			return true, err
+		}
+		if err = w.header(path, bsonCodeScope, key); err != nil {
+			return true, err
+		}
+		start := w.buf.Len()
+		w.int32(0)
+		w.string(code)
+		if err = w.document(childPath(path, annotationScope), &Record{Keys: sortedKeys(scope), Values: scope}); err != nil {
+			return true, err
+		}
+		binary.LittleEndian.PutUint32(w.buf.Bytes()[start:], uint32(w.buf.Len()-start))
+		return true, nil
+	case annotationSymbol:
+		symbol, ok := value.(string)
+		if ok {
+			if err = w.header(path, bsonSymbol, key); err == nil {
+				w.string(symbol)
+			}
+			return true, err
+		}
+		err = errors.New("$symbol must be a string")
+	case annotationDBPointer:
+		spec, _ := value.(map[string]any)
+		ref, _ := spec["$ref"].(string)
+		idSpec, _ := spec["$id"].(map[string]any)
+		text, _ := idSpec[annotationObjectID].(string)
+		id, decodeErr := hex.DecodeString(text)
+		if ref != "" && decodeErr == nil && len(id) == 12 {
+			if err = w.header(path, bsonDBPointer, key); err == nil {
+				w.string(ref)
+				w.buf.Write(id)
+			}
+			return true, err
+		}
+		err = errors.New("$dbPointer requires $ref and $id")
+	case annotationMinKey:
+		return true, w.header(path, bsonMinKey, key)
+	case annotationMaxKey:
+		return true, w.header(path, bsonMaxKey, key)
+	case annotationUndefined:
+		return true, w.header(path, bsonUndefined, key)
+	default:
+		err = fmt.Errorf("%s is not supported in bson", name)
+	}
+	w.doc.Warn(0, path, "%v, kept as object", err)
+	return false, nil
+}
+
+func timestampPart(value any) (int64, error) {
+	var n int64
+	var err error
+	switch v := value.(type) {
+	case json.Number:
+		n, err = v.Int64()
+	case int64:
+		n = v
+	case uint64:
+		n = int64(v)
+	case float64:
+		n = int64(v)
+	default:
+		return 0, errors.New("$timestamp requires numeric t and i")
+	}
+	if err != nil || n < 0 || n > math.MaxUint32 {
+		return 0, errors.New("$timestamp t and i must be uint32")
+	}
+	return n, nil
+}
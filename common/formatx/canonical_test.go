@@ -0,0 +1,109 @@
+package formatx
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// 数字按 ECMAScript Number.prototype.toString 输出，用例取自 RFC 8785 附录 B
+func TestCanonicalNumbers(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"1", "1"},
+		{"4.50", "4.5"},
+		{"2e-3", "0.002"},
+		{"1e-7", "1e-7"},
+		{"0.000001", "0.000001"},
+		{"1e21", "1e+21"},
+		{"1e20", "100000000000000000000"},
+		{"1E30", "1e+30"},
+		{"333333333.33333329", "333333333.3333333"},
+		{"0.000000000000000000000000001", "1e-27"},
+		{"9007199254740993", "9007199254740992"},
+		{"-1.7976931348623157e308", "-1.7976931348623157e+308"},
+		{"5e-324", "5e-324"},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			doc, err := Parse(FormatJSON, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// 键按 UTF-16 码元排序，与按 UTF-8 字节或码点排序的结果不同，用例取自 RFC 8785 3.2.3
+func TestCanonicalKeyOrder(t *testing.T) {
+	input := `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh",
+		"1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`
+	doc, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","` + "\u00f6" + `":"Latin Small Letter O With Diaeresis",` +
+		`"` + "\u20ac" + `":"Euro Sign","` + "\U0001F600" + `":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`
+	if got := canonical(t, doc); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
+
+// 字符串只转义引号、反斜杠与控制字符，其余字符原样输出
+func TestCanonicalStrings(t *testing.T) {
+	input := `{"s": "\u0000\b\f\n\r\t\u000f\u001f\"\\/\u007f</script> é😀"}`
+	doc, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := `{"s":"\u0000\b\f\n\r\t\u000f\u001f\"\\/` + "\u007f</script> é😀" + `"}`
+	if got := canonical(t, doc); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
+
+// 嵌套结构按相同规则递归输出，不含空白，与原文的键顺序与格式无关
+func TestCanonicalNested(t *testing.T) {
+	a, err := Parse(FormatJSON, []byte(`{"b": [1.0, {"z": null, "y": true}], "a": "x"}`), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	b, err := Parse(FormatYAML, []byte("a: x\nb:\n  - 1\n  - y: true\n    z: null\n"), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := `{"a":"x","b":[1,{"y":true,"z":null}]}`
+	if got := canonical(t, a); got != want {
+		t.Errorf("json: got %s, want %s", got, want)
+	}
+	if got := canonical(t, b); got != want {
+		t.Errorf("yaml: got %s, want %s", got, want)
+	}
+}
+
+func TestCanonicalErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"nan", map[string]any{"a": []any{math.NaN()}}, "a[0]"},
+		{"inf", math.Inf(1), "NaN and Infinity"},
+		{"unsupported", map[string]any{"a": struct{}{}}, "unsupported value"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := CanonicalJSON(c.value)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
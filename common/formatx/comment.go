@@ -0,0 +1,137 @@
+package formatx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Comment 附着在节点上的注释，文本不含注释符
+type Comment struct {
+	Head []string // 节点之前独占一行的注释
+	Line string   // 节点所在行的行尾注释，对象与数组为起始行的注释
+	Foot []string // 对象或数组最后一个成员之后的注释，根节点还包括文档末尾的注释
+}
+
+// Comments 节点注释，按节点路径（JSON Pointer，根节点为空字符串）记录
+type Comments map[string]*Comment
+
+// Comment 返回 pointer 处节点的注释，没有时返回 nil
+func (d *Document) Comment(pointer string) *Comment {
+	if d == nil {
+		return nil
+	}
+	return d.Comments[pointer]
+}
+
+// comment 返回 pointer 处节点的注释，不存在时创建
+func (d *Document) comment(pointer string) *Comment {
+	if d.Comments == nil {
+		d.Comments = make(Comments)
+	}
+	c, ok := d.Comments[pointer]
+	if !ok {
+		c = &Comment{}
+		d.Comments[pointer] = c
+	}
+	return c
+}
+
+// AddHeadComment 追加 pointer 处节点的前置注释
+func (d *Document) AddHeadComment(pointer string, lines ...string) {
+	if len(lines) > 0 {
+		c := d.comment(pointer)
+		c.Head = append(c.Head, validLines(lines)...)
+	}
+}
+
+// AddLineComment 追加 pointer 处节点的行尾注释，已有注释时以空格连接
+func (d *Document) AddLineComment(pointer, text string) {
+	if text == "" {
+		return
+	}
+	text = validText(text)
+	c := d.comment(pointer)
+	if c.Line != "" {
+		text = c.Line + " " + text
+	}
+	c.Line = text
+}
+
+// AddFootComment 追加 pointer 处对象或数组的末尾注释
+func (d *Document) AddFootComment(pointer string, lines ...string) {
+	if len(lines) > 0 {
+		c := d.comment(pointer)
+		c.Foot = append(c.Foot, validLines(lines)...)
+	}
+}
+
+// validText 将注释中的非法 UTF-8 字节（如 GBK 编码的中文注释）与制表符以外的控制字符替换为 U+FFFD，
+// 否则 YAML、TOML 等输出无法写出或无法读回
+func validText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\t' || r == '\uFEFF' {
+			return '\uFFFD'
+		}
+		return r
+	}, strings.ToValidUTF8(text, "\uFFFD"))
+}
+
+func validLines(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = validText(line)
+	}
+	return out
+}
+
+// moveComments 将 src 中 from 及其下级节点的注释移到 to 路径下，src 为 d 本身时删除原路径的记录
+func (d *Document) moveComments(src *Document, from, to string) {
+	moved := make(Comments)
+	for p, c := range src.Comments {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			moved[to+p[len(from):]] = c
+			if src == d {
+				delete(d.Comments, p)
+			}
+		}
+	}
+	for p, c := range moved {
+		if d.Comments == nil {
+			d.Comments = make(Comments)
+		}
+		d.Comments[p] = c
+	}
+}
+
+// dropComments 删除 pointer 及其下级节点的注释
+func (d *Document) dropComments(pointer string) {
+	for p := range d.Comments {
+		if p == pointer || strings.HasPrefix(p, pointer+"/") {
+			delete(d.Comments, p)
+		}
+	}
+}
+
+// commentLines 按行拆分注释文本，去掉 # 与 // 注释符、块注释每行开头的 * 以及首尾的空行
+func commentLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#"):
+			line = line[1:]
+		case strings.HasPrefix(line, "//"):
+			line = line[2:]
+		case strings.HasPrefix(line, "*") && !strings.HasPrefix(line, "*/"):
+			line = line[1:]
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,170 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// 注释中的非法 UTF-8（如 GBK 编码的中文注释）替换为 U+FFFD，转为 YAML 时不再触发 yaml.v3 的 panic
+func TestInvalidUTF8Comments(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"jsonc", FormatJSON, "{\n // \xff\n \"a\": 1\n}"},
+		{"json line", FormatJSON, "{\n \"a\": 1 // \xce\xd2\n}"},
+		{"json5", FormatJSON5, "// \xff\n{a: 1}"},
+		{"hjson", FormatHJSON, "{\n # \xff\n a: 1\n}"},
+		{"ini", FormatINI, "; \xff\na=1"},
+		{"control character", FormatJSON, "0 /* \x16 */"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			for pointer, comment := range doc.Comments {
+				for _, line := range append(append(append([]string(nil), comment.Head...), comment.Line), comment.Foot...) {
+					if !utf8.ValidString(line) {
+						t.Errorf("comment at %q is not valid UTF-8: %q", pointer, line)
+					}
+				}
+			}
+			out, err := Marshal(FormatYAML, doc, nil)
+			if err != nil {
+				t.Fatalf("marshal yaml: %v", err)
+			}
+			if !strings.Contains(string(out), "�") {
+				t.Errorf("expected replacement character in output, got %q", out)
+			}
+		})
+	}
+}
+
+// 没有结束符的块注释报错，不再吞掉之后的全部内容
+func TestUnterminatedBlockComment(t *testing.T) {
+	_, err := Parse(FormatJSON, []byte("{\n\"a\": 1 /* c\n}"), nil)
+	if err == nil || !strings.Contains(err.Error(), "line 2: unterminated block comment") {
+		t.Errorf("expected unterminated block comment error, got %v", err)
+	}
+}
+
+// FuzzConvertComments 带注释的文本格式转为 YAML、TOML、INI 与 JSON 时只能返回错误，不能 panic
+func FuzzConvertComments(f *testing.F) {
+	seeds := []struct {
+		format Format
+		input  string
+	}{
+		{FormatJSON, "{\n // \xff\n \"a\": 1\n}"},
+		{FormatJSON, "{\"a\": [1, 2, {\"b\": null}]} // end"},
+		{FormatJSON5, "// \xff\n{a: 1, b: 'x', c: Infinity}"},
+		{FormatHJSON, "{\n # \xff\n a: 1\n b: text\n}"},
+		{FormatINI, "; \xff\na=1"},
+		{FormatINI, "[s]\na=1 ; c\n[s.t]\nb=2"},
+		{FormatYAML, "# c\na: &x 1\nb: *x\n"},
+		{FormatTOML, "# c\na = 1\n[t]\nb = 'x'"},
+	}
+	for _, seed := range seeds {
+		f.Add(string(seed.format), seed.input)
+	}
+	f.Fuzz(func(t *testing.T, format, input string) {
+		from, err := ParseFormat(format)
+		if err != nil || from.IsBinary() {
+			return
+		}
+		doc, err := Parse(from, []byte(input), nil)
+		if err != nil {
+			return
+		}
+		for _, to := range []Format{FormatYAML, FormatJSON, FormatTOML, FormatINI} {
+			_, _ = Marshal(to, doc, nil)
+		}
+	})
+}
+
+// json 输出始终为不含注释的标准 JSON，注释只在 jsonc 输出中保留
+func TestJSONOutputDropsComments(t *testing.T) {
+	cases := []struct {
+		format Format
+		input  string
+	}{
+		{FormatYAML, "# head\na: 1 # line\n"},
+		{FormatJSON5, "// lead\n{a: 1}"},
+		{FormatJSON, "{\n // c\n \"a\": 1\n}"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(FormatJSON, doc, nil)
+			if err != nil {
+				t.Fatalf("marshal json: %v", err)
+			}
+			if strings.Contains(string(out), "//") {
+				t.Errorf("json output contains comments: %s", out)
+			}
+			out, err = Marshal(FormatJSONC, doc, nil)
+			if err != nil {
+				t.Fatalf("marshal jsonc: %v", err)
+			}
+			if !strings.Contains(string(out), "//") {
+				t.Errorf("jsonc output lost comments: %s", out)
+			}
+		})
+	}
+}
+
+// TOML 解析记录前置、行尾与末尾注释，JSONC 经 TOML 转回 JSONC 时注释不变
+func TestTOMLCommentsRoundTrip(t *testing.T) {
+	input := `{
+  // 服务名
+  "name": "api", // 行尾
+  // 数据库
+  "db": {
+    // 主机
+    "host": "localhost",
+    "port": 5432 // 端口
+  },
+  "items": [
+    // 第一个
+    {"id": 1, "tags": ["a", "b"]}
+  ]
+  // 末尾
+}`
+	doc, err := Parse(FormatJSONC, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want, err := Marshal(FormatJSONC, doc, nil)
+	if err != nil {
+		t.Fatalf("marshal jsonc: %v", err)
+	}
+	toml, err := Marshal(FormatTOML, doc, nil)
+	if err != nil {
+		t.Fatalf("marshal toml: %v", err)
+	}
+	back, err := Parse(FormatTOML, toml, nil)
+	if err != nil {
+		t.Fatalf("parse toml: %v\n%s", err, toml)
+	}
+	got, err := Marshal(FormatJSONC, back, nil)
+	if err != nil {
+		t.Fatalf("marshal jsonc: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("comments changed through toml:\n%s\nwant:\n%s\ntoml:\n%s", got, want, toml)
+	}
+	// 数组内部的注释不保留，也不影响元素
+	arr, err := Parse(FormatTOML, []byte("a = [\n  # first\n  1.50, # x\n  2,\n]\n"), nil)
+	if err != nil {
+		t.Fatalf("parse toml array: %v", err)
+	}
+	if out, _ := Marshal(FormatJSON, arr, &Options{Indent: 0}); !strings.Contains(string(out), "1.50") {
+		t.Errorf("array with comments parsed as %s", out)
+	}
+}
@@ -0,0 +1,153 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// parseCSV 首行作为表头，每行转为一个对象，值均为字符串
+func parseCSV(doc *Document, data []byte, _ *Options) error {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		doc.Value = []any{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	header = uniqueColumns(header)
+	rows := make([]any, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		row := NewRecord()
+		for i, name := range header {
+			if i < len(record) {
+				row.Set(name, record[i])
+			} else {
+				row.Set(name, "")
+			}
+		}
+		if len(record) > len(header) {
+			line, _ := reader.FieldPos(0)
+			doc.Warn(line, "", "%d extra fields dropped", len(record)-len(header))
+		}
+		rows = append(rows, row)
+	}
+	doc.SetOrdered(rows)
+	return nil
+}
+
+// uniqueColumns 空列名以列号命名，重复列名追加 _n 后缀
+func uniqueColumns(header []string) []string {
+	used := make(map[string]int, len(header))
+	names := make([]string, len(header))
+	for i, name := range header {
+		if name == "" {
+			name = columnName(i)
+		}
+		names[i] = uniqueName(used, name, 1)
+	}
+	return names
+}
+
+// uniqueName 重名时从 start 起追加 _n 后缀，跳过已被占用的名称；used 记录每个名称下一个可尝试的序号
+func uniqueName(used map[string]int, name string, start int) string {
+	if used[name] == 0 {
+		used[name] = start
+		return name
+	}
+	for n := used[name]; ; n++ {
+		candidate := name + "_" + strconv.Itoa(n)
+		if used[candidate] == 0 {
+			used[name] = n + 1
+			used[candidate] = start
+			return candidate
+		}
+	}
+}
+
+// marshalCSV 数组中的每个对象展开为一行，列为所有对象字段的并集（按出现顺序）
+func marshalCSV(doc *Document, opts *Options) ([]byte, error) {
+	value := doc.Ordered(opts)
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+	records := make([]*Record, len(items))
+	columns := opts.columns()
+	infer := len(columns) == 0
+	seen := make(map[string]bool)
+	for i, item := range items {
+		records[i] = Flatten(item)
+		if !infer {
+			continue
+		}
+		for _, key := range records[i].Keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	writer := newCSVWriter(&buf, columns)
+	for i, record := range records {
+		if dropped := writer.write(record); dropped > 0 {
+			doc.Warn(0, fmt.Sprintf("[%d]", i), "%d fields not in columns dropped", dropped)
+		}
+	}
+	if err := writer.flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvWriter 按固定列输出展开后的记录，首次写入时输出表头
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []string) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), columns: columns}
+}
+
+// write 写入一行，返回不在列中而被丢弃的字段数
+func (c *csvWriter) write(record *Record) int {
+	if !c.started {
+		c.started = true
+		_ = c.writer.Write(c.columns)
+	}
+	row := make([]string, len(c.columns))
+	matched := 0
+	for i, column := range c.columns {
+		if value, ok := record.Get(column); ok {
+			row[i] = scalarString(value)
+			matched++
+		}
+	}
+	_ = c.writer.Write(row)
+	return record.Len() - matched
+}
+
+func (c *csvWriter) flush() error {
+	if !c.started && len(c.columns) > 0 {
+		c.started = true
+		_ = c.writer.Write(c.columns)
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
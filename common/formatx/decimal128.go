@@ -0,0 +1,121 @@
+package formatx
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// IEEE 754-2008 decimal128（BID 编码）参数
+const (
+	decimal128Bias        = 6176
+	decimal128MinExponent = -6176
+	decimal128MaxExponent = 6111
+	decimal128MaxDigits   = 34
+)
+
+var decimal128MaxCoefficient = new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimal128MaxDigits), nil), big.NewInt(1))
+
+// decimal128String 按 BSON 规范将 decimal128 转为字符串
+func decimal128String(high, low uint64) string {
+	sign := ""
+	if high>>63 == 1 {
+		sign = "-"
+	}
+	switch (high >> 58) & 0x1f {
+	case 0x1f:
+		return "NaN"
+	case 0x1e:
+		return sign + "Infinity"
+	}
+	var exponent int
+	coefficient := new(big.Int)
+	if (high>>61)&0x3 == 0x3 {
+		// 组合字段以 11 开头时系数超出 34 位，按规范视为 0
+		exponent = int((high>>47)&0x3fff) - decimal128Bias
+	} else {
+		exponent = int((high>>49)&0x3fff) - decimal128Bias
+		coefficient.SetUint64(high & (1<<49 - 1))
+		coefficient.Lsh(coefficient, 64).Or(coefficient, new(big.Int).SetUint64(low))
+		if coefficient.Cmp(decimal128MaxCoefficient) > 0 {
+			coefficient.SetInt64(0)
+		}
+	}
+	digits := coefficient.String()
+	adjusted := exponent + len(digits) - 1
+	if exponent <= 0 && adjusted >= -6 {
+		if exponent == 0 {
+			return sign + digits
+		}
+		point := len(digits) + exponent
+		if point <= 0 {
+			return sign + "0." + strings.Repeat("0", -point) + digits
+		}
+		return sign + digits[:point] + "." + digits[point:]
+	}
+	text := digits[:1]
+	if len(digits) > 1 {
+		text += "." + digits[1:]
+	}
+	return fmt.Sprintf("%s%sE%+d", sign, text, adjusted)
+}
+
+// parseDecimal128 将十进制字符串编码为 decimal128
+func parseDecimal128(text string) (high, low uint64, err error) {
+	s := strings.TrimSpace(text)
+	negative := strings.HasPrefix(s, "-")
+	if negative || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	var signBit uint64
+	if negative {
+		signBit = 1 << 63
+	}
+	switch strings.ToLower(s) {
+	case "nan":
+		return 0x1f << 58, 0, nil
+	case "inf", "infinity":
+		return signBit | 0x1e<<58, 0, nil
+	}
+	exponent := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exponent, err = strconv.Atoi(s[i+1:]); err != nil {
+			return 0, 0, fmt.Errorf("invalid decimal128 %q", text)
+		}
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exponent -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+	coefficient, ok := new(big.Int).SetString(s, 10)
+	if !ok || s == "" || strings.ContainsAny(s, "+-") {
+		return 0, 0, fmt.Errorf("invalid decimal128 %q", text)
+	}
+	if coefficient.Sign() == 0 {
+		exponent = max(min(exponent, decimal128MaxExponent), decimal128MinExponent)
+	}
+	// 补零或去掉多余的尾零以适配指数范围，系数超过 34 位时无法精确表示
+	ten := big.NewInt(10)
+	for exponent > decimal128MaxExponent && coefficient.Sign() != 0 && new(big.Int).Mul(coefficient, ten).Cmp(decimal128MaxCoefficient) <= 0 {
+		coefficient.Mul(coefficient, ten)
+		exponent--
+	}
+	for exponent < decimal128MinExponent || coefficient.Cmp(decimal128MaxCoefficient) > 0 {
+		quotient, remainder := new(big.Int).QuoRem(coefficient, ten, new(big.Int))
+		if remainder.Sign() != 0 {
+			return 0, 0, fmt.Errorf("decimal128 %q cannot be represented exactly", text)
+		}
+		coefficient = quotient
+		exponent++
+	}
+	if exponent > decimal128MaxExponent {
+		return 0, 0, fmt.Errorf("decimal128 %q out of range", text)
+	}
+	mask := new(big.Int).SetUint64(^uint64(0))
+	low = new(big.Int).And(coefficient, mask).Uint64()
+	high = new(big.Int).Rsh(coefficient, 64).Uint64()
+	high |= signBit | uint64(exponent+decimal128Bias)<<49
+	return high, low, nil
+}
@@ -0,0 +1,166 @@
+package formatx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// parseDotenv 解析 .env：支持 export 前缀、单引号（原样）、双引号（转义与多行）、行尾 # 注释，
+// 双引号与无引号的值支持 ${VAR}、${VAR:-default} 与 $VAR 插值，只引用文件中已定义的变量，不读取进程环境变量
+func parseDotenv(doc *Document, data []byte, _ *Options) error {
+	vars := make(map[string]any)
+	var keys []string
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("line %d: invalid line %q", lineNo, line)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "'"):
+			end := strings.Index(rest[1:], "'")
+			if end < 0 {
+				return fmt.Errorf("line %d: unterminated single quote", lineNo)
+			}
+			value = rest[1 : end+1]
+		case strings.HasPrefix(rest, `"`):
+			// 双引号的值可以跨行，直到遇到未转义的引号
+			raw, consumed, err := readQuoted(rest[1:], lines[i+1:])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			i += consumed
+			value = expandEnv(unescapeEnv(raw), vars, doc, lineNo)
+		default:
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = rest[:idx]
+			}
+			value = expandEnv(strings.TrimSpace(rest), vars, doc, lineNo)
+		}
+		if _, exists := vars[key]; !exists {
+			keys = append(keys, key)
+		}
+		vars[key] = value
+	}
+	doc.Value = vars
+	if len(keys) > 0 {
+		doc.SetKeyOrder("", keys)
+	}
+	return nil
+}
+
+// readQuoted 读取双引号内的原始内容，返回额外消耗的行数
+func readQuoted(first string, rest []string) (string, int, error) {
+	text := first
+	for consumed := 0; ; consumed++ {
+		escaped := false
+		for i := 0; i < len(text); i++ {
+			switch {
+			case escaped:
+				escaped = false
+			case text[i] == '\\':
+				escaped = true
+			case text[i] == '"':
+				return text[:i], consumed, nil
+			}
+		}
+		if consumed >= len(rest) {
+			return "", 0, fmt.Errorf("unterminated double quote")
+		}
+		text += "\n" + strings.TrimRight(rest[consumed], "\r")
+	}
+}
+
+func unescapeEnv(s string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, "\x00")
+	return replacer.Replace(s)
+}
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?-[^}]*)?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandEnv 替换变量引用，\$ 转义的美元符号在 unescapeEnv 中以 \x00 占位，替换后还原
+func expandEnv(s string, vars map[string]any, doc *Document, line int) string {
+	expanded := envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envRefPattern.FindStringSubmatch(ref)
+		name, fallback := match[1], match[2]
+		if name == "" {
+			name = match[3]
+		}
+		value, ok := vars[name].(string)
+		switch {
+		case strings.HasPrefix(fallback, ":-") && value == "":
+			return fallback[2:]
+		case strings.HasPrefix(fallback, "-") && !ok:
+			return fallback[1:]
+		case !ok:
+			doc.Warn(line, name, "undefined variable %s expanded to empty string", name)
+		}
+		return value
+	})
+	return strings.ReplaceAll(expanded, "\x00", "$")
+}
+
+// marshalDotenv 嵌套对象的键以下划线连接，数组与对象以外的值转为文本，需要时加双引号并转义 $ 防止插值，null 写为空值并记录提示
+func marshalDotenv(doc *Document, opts *Options) ([]byte, error) {
+	root, ok := doc.Ordered(opts).(*Record)
+	if !ok {
+		return nil, fmt.Errorf("env document root must be an object")
+	}
+	var buf bytes.Buffer
+	writeDotenv(&buf, "", root, doc)
+	return buf.Bytes(), nil
+}
+
+func writeDotenv(buf *bytes.Buffer, prefix string, values *Record, doc *Document) {
+	for _, key := range values.Keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "_" + key
+		}
+		if nested, ok := values.Values[key].(*Record); ok {
+			writeDotenv(buf, name, nested, doc)
+			continue
+		}
+		if !envKeyPattern.MatchString(name) {
+			fixed := strings.Map(func(r rune) rune {
+				if r == '_' || r == '.' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+					return r
+				}
+				return '_'
+			}, name)
+			if fixed == "" || fixed[0] >= '0' && fixed[0] <= '9' || fixed[0] == '.' || fixed[0] == '-' {
+				fixed = "_" + fixed
+			}
+			doc.Warn(0, name, "invalid variable name renamed to %s", fixed)
+			name = fixed
+		}
+		if values.Values[key] == nil {
+			doc.Warn(0, name, "null written as empty string")
+		}
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(quoteEnv(scalarString(values.Values[key])))
+		buf.WriteByte('\n')
+	}
+}
+
+func quoteEnv(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"'\\$#=`") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+	return `"` + replacer.Replace(value) + `"`
+}
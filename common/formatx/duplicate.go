@@ -0,0 +1,224 @@
+package formatx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DuplicateKeyPolicy 同一对象中出现重复键时的处理方式
+type DuplicateKeyPolicy string
+
+const (
+	DuplicateKeyError     DuplicateKeyPolicy = "error"            // 报错并指出两次出现的位置
+	DuplicateKeyKeepFirst DuplicateKeyPolicy = "keep-first"       // 保留第一次出现的值
+	DuplicateKeyKeepLast  DuplicateKeyPolicy = "keep-last"        // 保留最后一次出现的值，与 JSON.parse 一致
+	DuplicateKeyMerge     DuplicateKeyPolicy = "merge-into-array" // 所有值按出现顺序合并为数组
+)
+
+// ParseDuplicateKeyPolicy 校验重复键策略，空字符串表示使用格式的默认策略
+func ParseDuplicateKeyPolicy(name string) (DuplicateKeyPolicy, error) {
+	switch policy := DuplicateKeyPolicy(name); policy {
+	case "", DuplicateKeyError, DuplicateKeyKeepFirst, DuplicateKeyKeepLast, DuplicateKeyMerge:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid duplicate key policy: %q, expected error, keep-first, keep-last or merge-into-array", name)
+	}
+}
+
+// sourcePos 键在原文中的位置，流式读取 JSON 无法回溯行号，以字节偏移定位；
+// MessagePack/CBOR 无法取得键的偏移量，以键在对象中的序号定位
+type sourcePos struct {
+	line   int
+	offset int64
+	entry  int // 从 1 开始
+}
+
+func (p sourcePos) String() string {
+	switch {
+	case p.line > 0:
+		return fmt.Sprintf("line %d", p.line)
+	case p.entry > 0:
+		return fmt.Sprintf("entry %d", p.entry)
+	}
+	return fmt.Sprintf("offset %d", p.offset)
+}
+
+// lineIndex 记录换行符的位置，按字节偏移查找行号
+type lineIndex struct {
+	first    int // 第一行的行号
+	newlines []int64
+}
+
+func newLineIndex(data []byte, first int) *lineIndex {
+	index := &lineIndex{first: first}
+	for i, b := range data {
+		if b == '\n' {
+			index.newlines = append(index.newlines, int64(i))
+		}
+	}
+	return index
+}
+
+// pos 偏移量所在的位置，index 为 nil 时只记录偏移量
+func (l *lineIndex) pos(offset int64) sourcePos {
+	if l == nil {
+		return sourcePos{offset: offset}
+	}
+	line := l.first + sort.Search(len(l.newlines), func(i int) bool { return l.newlines[i] >= offset })
+	return sourcePos{line: line, offset: offset}
+}
+
+// keySet 记录一个对象中已出现的键，按策略处理重复键
+type keySet struct {
+	doc    *Document
+	policy DuplicateKeyPolicy
+	seen   map[string]sourcePos
+	merged map[string]bool // 已由重复键合并成的数组，区别于原本就是数组的值
+}
+
+func newKeySet(doc *Document, policy DuplicateKeyPolicy) *keySet {
+	return &keySet{doc: doc, policy: policy, seen: make(map[string]sourcePos)}
+}
+
+// target 解析 key 的值时使用的文档：重复出现的键解析到单独的文档中，取舍后再合并键顺序、注释与提示
+func (s *keySet) target(key string) *Document {
+	if _, exists := s.seen[key]; exists {
+		return &Document{}
+	}
+	return s.doc
+}
+
+// add 登记 key 的一次出现并返回应保存的值，pointer 与 path 为对象所在路径，src 为 target 返回的文档；
+// 重复键按策略取舍并记录提示，error 策略返回同时指出两处位置的错误
+func (s *keySet) add(pointer, path, key string, pos sourcePos, old, value any, src *Document) (any, error) {
+	first, exists := s.seen[key]
+	if !exists {
+		s.seen[key] = pos
+		return value, nil
+	}
+	keyPath, keyPointer := childPath(path, key), childPointer(pointer, key)
+	if s.policy == DuplicateKeyError {
+		return nil, &duplicateKeyError{key: keyPath, first: first, second: pos}
+	}
+	if src != s.doc {
+		s.doc.Warnings = append(s.doc.Warnings, src.Warnings...)
+	}
+	s.doc.warnDuplicate(s.policy, keyPath, pos, first)
+	switch s.policy {
+	case DuplicateKeyKeepFirst:
+		return old, nil
+	case DuplicateKeyMerge:
+		if !s.merged[key] {
+			if s.merged == nil {
+				s.merged = make(map[string]bool)
+			}
+			s.merged[key] = true
+			s.doc.moveKeyOrder(s.doc, keyPointer, indexPointer(keyPointer, 0))
+			s.doc.moveComments(s.doc, keyPointer, indexPointer(keyPointer, 0))
+			old = []any{old}
+		}
+		items := old.([]any)
+		s.doc.moveKeyOrder(src, keyPointer, indexPointer(keyPointer, len(items)))
+		s.doc.moveComments(src, keyPointer, indexPointer(keyPointer, len(items)))
+		return append(items, value), nil
+	default:
+		s.doc.dropKeyOrder(keyPointer)
+		s.doc.dropComments(keyPointer)
+		s.doc.moveKeyOrder(src, keyPointer, keyPointer)
+		s.doc.moveComments(src, keyPointer, keyPointer)
+		return value, nil
+	}
+}
+
+// warnDuplicate 记录重复键的两处位置以及按策略保留的值
+func (d *Document) warnDuplicate(policy DuplicateKeyPolicy, keyPath string, pos, first sourcePos) {
+	kept := "last value kept"
+	switch policy {
+	case DuplicateKeyKeepFirst:
+		kept = "first value kept"
+	case DuplicateKeyMerge:
+		kept = "values merged into array"
+	}
+	d.Warn(pos.line, keyPath, "duplicate key at %s, first defined at %s, %s", pos, first, kept)
+}
+
+// duplicateKeyError 错误信息已包含两处位置，解析器不再补充行列号
+type duplicateKeyError struct {
+	key           string
+	first, second sourcePos
+}
+
+func (e *duplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: duplicate key %q, first defined at %s", e.second, e.key, e.first)
+}
+
+// IsDuplicateKeyError 判断解析错误是否由 DuplicateKeyError 策略下的重复键引起
+func IsDuplicateKeyError(err error) bool {
+	var duplicateErr *duplicateKeyError
+	return errors.As(err, &duplicateErr)
+}
+
+// recordKeySet 直接构建 *Record 的二进制解码器按策略处理一个对象中的重复键
+type recordKeySet struct {
+	doc    *Document
+	policy DuplicateKeyPolicy
+	seen   map[string]sourcePos
+	merged map[string]bool // 已由重复键合并成的数组，区别于原本就是数组的值
+}
+
+func newRecordKeySet(doc *Document, policy DuplicateKeyPolicy) *recordKeySet {
+	return &recordKeySet{doc: doc, policy: policy, seen: make(map[string]sourcePos)}
+}
+
+// set 写入 key 的一次出现，path 为对象所在路径；重复键按策略取舍并记录提示，error 策略返回同时指出两处位置的错误
+func (s *recordKeySet) set(record *Record, path, key string, pos sourcePos, value any) error {
+	first, exists := s.seen[key]
+	if !exists {
+		s.seen[key] = pos
+		record.Set(key, value)
+		return nil
+	}
+	keyPath := childPath(path, key)
+	if s.policy == DuplicateKeyError {
+		return &duplicateKeyError{key: keyPath, first: first, second: pos}
+	}
+	s.doc.warnDuplicate(s.policy, keyPath, pos, first)
+	switch s.policy {
+	case DuplicateKeyKeepFirst:
+	case DuplicateKeyMerge:
+		if !s.merged[key] {
+			if s.merged == nil {
+				s.merged = make(map[string]bool)
+			}
+			s.merged[key] = true
+			record.Values[key] = []any{record.Values[key]}
+		}
+		record.Values[key] = append(record.Values[key].([]any), value)
+	default:
+		record.Values[key] = value
+	}
+	return nil
+}
+
+// duplicateWarnings 流式读取时保留的重复键提示，超出 maxStreamWarnings 的部分只计数
+type duplicateWarnings struct {
+	list  []Warning
+	count int
+}
+
+func (d *duplicateWarnings) add(warnings []Warning) {
+	for _, warning := range warnings {
+		d.count++
+		if len(d.list) < maxStreamWarnings {
+			d.list = append(d.list, warning)
+		}
+	}
+}
+
+func (d *duplicateWarnings) result() []Warning {
+	if d.count > len(d.list) {
+		return append(d.list, Warning{Message: fmt.Sprintf("%d more duplicate keys", d.count-len(d.list))})
+	}
+	return d.list
+}
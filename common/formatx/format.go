@@ -0,0 +1,426 @@
+package formatx
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format 数据格式
+type Format string
+
+const (
+	FormatJSON       Format = "json"
+	FormatJSONC      Format = "jsonc" // 带 // 注释的 JSON，输出 json 时始终为不含注释的 RFC 8259 JSON
+	FormatXML        Format = "xml"
+	FormatYAML       Format = "yaml"
+	FormatTOML       Format = "toml"
+	FormatINI        Format = "ini"
+	FormatText       Format = "text"
+	FormatXLSX       Format = "xlsx"
+	FormatNDJSON     Format = "ndjson"
+	FormatCSV        Format = "csv"
+	FormatProperties Format = "properties"
+	FormatDotenv     Format = "env"
+	FormatHCL        Format = "hcl"
+	FormatMsgpack    Format = "msgpack"
+	FormatCBOR       Format = "cbor"
+	FormatBSON       Format = "bson"
+	FormatJSON5      Format = "json5"
+	FormatHJSON      Format = "hjson"
+)
+
+// LineErrorMode NDJSON 行解析失败的处理方式
+type LineErrorMode string
+
+const (
+	LineErrorFail LineErrorMode = "fail" // 遇到错误行立即失败
+	LineErrorSkip LineErrorMode = "skip" // 跳过错误行并记录提示
+)
+
+const defaultIndent = 2
+
+// Options 解析与序列化选项
+type Options struct {
+	Indent      int           // 缩进空格数，默认 2
+	OnLineError LineErrorMode // NDJSON 行解析失败的处理方式，默认 fail
+	Columns     []string      // CSV 输出列，为空时根据数据推断
+	Canonical   bool          // JSON 输出为 RFC 8785 规范形式，忽略缩进
+	SortKeys    bool          // 对象键按字典序输出，默认保持原文顺序
+	// DuplicateKeys JSON、YAML、TOML、INI、BSON、MessagePack、CBOR 中重复键的处理方式，
+	// 默认 TOML 按规范报错，其他格式保留最后一个值并记录提示
+	DuplicateKeys DuplicateKeyPolicy
+	// DropComments 输出时丢弃注释，默认保留 JSONC、JSON5、HJSON、YAML、TOML、INI 中解析到的注释，
+	// TOML 内联表与数组内部的注释除外；JSON 输出不含注释
+	DropComments bool
+	// XML XML 与 JSON 之间的映射约定，零值为 prefix 约定
+	XML XMLOptions
+	// YAMLAnchors 输出 YAML 时还原源 YAML 中的锚点、别名与 << 合并键，默认展开
+	YAMLAnchors bool
+	// MaxAliasNodes 展开 YAML 别名时最多复制的节点数，默认且最大为 1000000，超出时报错
+	MaxAliasNodes int
+	// MaxRecordSize 流式转换时单条记录（NDJSON 行、JSON 数组元素、YAML 文档）的字节数上限，默认且最大为 32 MiB
+	MaxRecordSize int
+	// TOMLFallback 输出 TOML 时 null、混合类型数组与超出 float64 精度的小数的处理方式，默认 omit
+	TOMLFallback TOMLFallback
+	// INI INI 方言，零值为不拆分节名、以 = 分隔键值
+	INI INIOptions
+}
+
+func (o *Options) indent() int {
+	if o == nil || o.Indent <= 0 {
+		return defaultIndent
+	}
+	return o.Indent
+}
+
+func (o *Options) columns() []string {
+	if o == nil {
+		return nil
+	}
+	return append([]string(nil), o.Columns...)
+}
+
+func (o *Options) sortKeys() bool {
+	return o != nil && o.SortKeys
+}
+
+func (o *Options) duplicateKeys(format Format) DuplicateKeyPolicy {
+	switch {
+	case o != nil && o.DuplicateKeys != "":
+		return o.DuplicateKeys
+	case format == FormatTOML:
+		return DuplicateKeyError
+	default:
+		return DuplicateKeyKeepLast
+	}
+}
+
+// comments 序列化时输出的注释，DropComments 时为 nil
+func (o *Options) comments(doc *Document) Comments {
+	if doc == nil || (o != nil && o.DropComments) {
+		return nil
+	}
+	return doc.Comments
+}
+
+func (o *Options) yamlAnchors() bool {
+	return o != nil && o.YAMLAnchors
+}
+
+func (o *Options) maxAliasNodes() int {
+	if o == nil || o.MaxAliasNodes <= 0 {
+		return maxYAMLAliasNodes
+	}
+	// 请求只能调低上限，不能关闭 billion laughs 防护
+	return min(o.MaxAliasNodes, maxYAMLAliasNodes)
+}
+
+func (o *Options) maxRecordSize() int {
+	if o == nil || o.MaxRecordSize <= 0 {
+		return maxStreamRecordSize
+	}
+	return min(o.MaxRecordSize, maxStreamRecordSize)
+}
+
+func (o *Options) lineErrorMode() LineErrorMode {
+	if o == nil || o.OnLineError == "" {
+		return LineErrorFail
+	}
+	return o.OnLineError
+}
+
+// Warning 转换过程中的提示信息
+type Warning struct {
+	Line    int    `json:"line,omitempty"` // 所在行号
+	Path    string `json:"path,omitempty"` // 数据路径
+	Message string `json:"message"`        // 提示内容
+}
+
+// Document 解析后的文档
+type Document struct {
+	Format   Format    // 原始格式
+	Value    any       // 数据，对象为 map[string]any，数组为 []any
+	Order    KeyOrder  // 对象键在原文中的顺序
+	Comments Comments  // 附着在节点上的注释，JSON 与 YAML 解析时记录
+	Warnings []Warning // 解析与序列化过程中的提示
+	Stream   bool      // YAML 多文档流，Value 为各文档组成的数组
+
+	refs *yamlRefs // YAML 锚点、别名与合并键的位置
+}
+
+// Warn 记录提示信息
+func (d *Document) Warn(line int, path, format string, args ...any) {
+	d.Warnings = append(d.Warnings, Warning{Line: line, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+type parseFunc func(doc *Document, data []byte, opts *Options) error
+
+type marshalFunc func(doc *Document, opts *Options) ([]byte, error)
+
+type codec struct {
+	parse   parseFunc
+	marshal marshalFunc
+}
+
+// codecs 各格式的解析与序列化实现，xlsx 走单独的读写接口
+var codecs = map[Format]codec{
+	FormatJSON:       {parseJSON, marshalJSON},
+	FormatJSONC:      {parseJSON, marshalJSONC},
+	FormatXML:        {parseXML, marshalXML},
+	FormatYAML:       {parseYAML, marshalYAML},
+	FormatTOML:       {parseTOML, marshalTOML},
+	FormatINI:        {parseINI, marshalINI},
+	FormatText:       {parseText, marshalText},
+	FormatNDJSON:     {parseNDJSON, marshalNDJSON},
+	FormatCSV:        {parseCSV, marshalCSV},
+	FormatProperties: {parseProperties, marshalProperties},
+	FormatDotenv:     {parseDotenv, marshalDotenv},
+	FormatHCL:        {parseHCL, marshalHCL},
+	FormatMsgpack:    {parseMsgpack, marshalMsgpack},
+	FormatCBOR:       {parseCBOR, marshalCBOR},
+	FormatBSON:       {parseBSON, marshalBSON},
+	FormatJSON5:      {parseJSON5, marshalJSON5},
+	FormatHJSON:      {parseHJSON, marshalHJSON},
+}
+
+// formatAliases 格式别名
+var formatAliases = map[string]Format{
+	"yml":         FormatYAML,
+	"jsonl":       FormatNDJSON,
+	"txt":         FormatText,
+	"dotenv":      FormatDotenv,
+	"tf":          FormatHCL,
+	"tfvars":      FormatHCL,
+	"messagepack": FormatMsgpack,
+	"mpk":         FormatMsgpack,
+}
+
+// ParseFormat 校验并识别格式名，支持 yml、jsonl 等别名
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if format, ok := formatAliases[name]; ok {
+		return format, nil
+	}
+	if _, ok := codecs[Format(name)]; ok {
+		return Format(name), nil
+	}
+	return "", fmt.Errorf("unsupported format: %q", name)
+}
+
+// Parse 按格式解析文本数据
+func Parse(format Format, data []byte, opts *Options) (*Document, error) {
+	c, ok := codecs[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %q", format)
+	}
+	doc := &Document{Format: format}
+	if err := c.parse(doc, data, opts); err != nil {
+		return nil, fmt.Errorf("parse %s error: %w", format, err)
+	}
+	return doc, nil
+}
+
+// ParseNamed 按格式名解析接口中的内容，格式名为空时按 JSON 处理，二进制格式的内容为 base64 编码；
+// 返回文档与解码后的原始数据，供接口按请求中的 format 参数解析内容
+func ParseNamed(name, content string, opts *Options) (*Document, []byte, error) {
+	format := FormatJSON
+	if name != "" {
+		var err error
+		if format, err = ParseFormat(name); err != nil {
+			return nil, nil, err
+		}
+	}
+	data, err := DecodeContent(format, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Parse(format, data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, data, nil
+}
+
+// Remarshal 以处理后的数据替换文档内容，按解析时的格式序列化为接口返回的内容，二进制格式输出 base64 编码；
+// 沿用解析时记录的键顺序与注释
+func Remarshal(doc *Document, value any, opts *Options) (string, error) {
+	doc.Value = value
+	data, err := Marshal(doc.Format, doc, opts)
+	if err != nil {
+		return "", err
+	}
+	return EncodeContent(doc.Format, data), nil
+}
+
+// DecodeContent 还原接口中的内容：二进制格式为 base64 编码，其他格式原样返回
+func DecodeContent(format Format, content string) ([]byte, error) {
+	if !format.IsBinary() {
+		return []byte(content), nil
+	}
+	data, err := DecodeBase64(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s content must be base64 encoded: %w", format, err)
+	}
+	return data, nil
+}
+
+// EncodeContent 生成接口返回的内容：二进制格式编码为标准 base64，其他格式原样返回
+func EncodeContent(format Format, data []byte) string {
+	if format.IsBinary() {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// DecodeBase64 兼容标准与 URL 安全字母表、有无填充以及换行
+func DecodeBase64(text string) ([]byte, error) {
+	text = strings.Join(strings.Fields(text), "")
+	text = strings.TrimRight(text, "=")
+	if strings.ContainsAny(text, "-_") {
+		return base64.RawURLEncoding.DecodeString(text)
+	}
+	return base64.RawStdEncoding.DecodeString(text)
+}
+
+// Marshal 将文档序列化为指定格式，序列化过程中的提示追加到 doc.Warnings
+func Marshal(format Format, doc *Document, opts *Options) ([]byte, error) {
+	c, ok := codecs[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %q", format)
+	}
+	doc.warnSpecialDoubles(format)
+	data, err := c.marshal(doc, opts)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s error: %w", format, err)
+	}
+	return data, nil
+}
+
+// Convert 在两种格式之间转换
+func Convert(from, to Format, data []byte, opts *Options) ([]byte, *Document, error) {
+	doc, err := Parse(from, data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := Marshal(to, doc, opts)
+	if err != nil {
+		return nil, doc, err
+	}
+	return out, doc, nil
+}
+
+// ConvertDocuments 与 Convert 相同，但 YAML 多文档流中的各文档分别转换，返回每个文档的结果
+func ConvertDocuments(from, to Format, data []byte, opts *Options) ([][]byte, *Document, error) {
+	doc, err := Parse(from, data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts := doc.Split()
+	outs := make([][]byte, len(parts))
+	for i, part := range parts {
+		if outs[i], err = Marshal(to, part, opts); err != nil {
+			return nil, doc, err
+		}
+		if part != doc {
+			doc.Warnings = append(doc.Warnings, part.Warnings...)
+		}
+	}
+	return outs, doc, nil
+}
+
+// FormatFromExt 根据文件后缀识别数据格式，与前端 handleFileUpload 的映射保持一致
+func FormatFromExt(filename string) Format {
+	// .env、.env.local 等 dotenv 文件
+	if base := strings.ToLower(filepath.Base(filename)); base == ".env" || strings.HasPrefix(base, ".env.") {
+		return FormatDotenv
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	switch ext {
+	case "json", "txt":
+		return FormatJSON
+	case "jsonc":
+		return FormatJSONC
+	case "xml":
+		return FormatXML
+	case "yaml", "yml":
+		return FormatYAML
+	case "toml":
+		return FormatTOML
+	case "ini", "conf", "cfg":
+		return FormatINI
+	case "properties":
+		return FormatProperties
+	case "env":
+		return FormatDotenv
+	case "hcl", "tf", "tfvars":
+		return FormatHCL
+	case "xlsx", "xlsm":
+		return FormatXLSX
+	case "ndjson", "jsonl":
+		return FormatNDJSON
+	case "csv":
+		return FormatCSV
+	case "msgpack", "mpk":
+		return FormatMsgpack
+	case "cbor":
+		return FormatCBOR
+	case "bson":
+		return FormatBSON
+	case "json5":
+		return FormatJSON5
+	case "hjson":
+		return FormatHJSON
+	default:
+		return FormatText
+	}
+}
+
+// IsBinary 是否为二进制格式（不能直接放入编辑器）
+func (f Format) IsBinary() bool {
+	switch f {
+	case FormatXLSX, FormatMsgpack, FormatCBOR, FormatBSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentType 格式对应的 MIME 类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON, FormatJSONC:
+		return "application/json; charset=utf-8"
+	case FormatJSON5:
+		return "application/json5; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson; charset=utf-8"
+	case FormatXML:
+		return "application/xml; charset=utf-8"
+	case FormatYAML:
+		return "application/yaml; charset=utf-8"
+	case FormatTOML:
+		return "application/toml; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatMsgpack:
+		return "application/vnd.msgpack"
+	case FormatCBOR:
+		return "application/cbor"
+	case FormatBSON:
+		return "application/bson"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Ext 格式对应的文件后缀（不含点号）
+func (f Format) Ext() string {
+	if f == FormatText {
+		return "txt"
+	}
+	return string(f)
+}
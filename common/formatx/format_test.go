@@ -0,0 +1,431 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// sampleJSON 覆盖嵌套对象、数组、各类标量、大整数与非 ASCII 文本
+const sampleJSON = `{
+  "id": 9007199254740993,
+  "name": "名称 \"quoted\"",
+  "price": 9.5,
+  "ratio": -1.25e-7,
+  "active": true,
+  "remark": null,
+  "tags": ["a", "b"],
+  "matrix": [[1, 2], [3]],
+  "owner": {"email": "a@b.c", "level": 0},
+  "items": [{"sku": "x", "qty": 1}, {"sku": "y", "qty": 2}]
+}`
+
+// canonical 文档数据的 RFC 8785 形式，用于比较两次解析得到的数据是否相同
+func canonical(t *testing.T, doc *Document) string {
+	t.Helper()
+	data, err := CanonicalJSON(doc.Value)
+	if err != nil {
+		t.Fatalf("canonical json: %v", err)
+	}
+	return string(data)
+}
+
+// 同一格式解析后再序列化、再解析，数据不变
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"json", FormatJSON, sampleJSON},
+		{"json5", FormatJSON5, "{a: 0x1F, b: 'x', c: [1, 2,], d: {e: null}, // c\n}"},
+		{"hjson", FormatHJSON, "{\n a: 1\n b: text value\n c: [true, false]\n}"},
+		{"yaml", FormatYAML, "a: 1\nb: [x, y]\nc:\n  d: null\n  e: 1.5\nf: &x {g: 1}\nh: *x\n"},
+		{"toml", FormatTOML, "a = 1\nb = 'x'\nc = [1, 2]\nd = 1979-05-27T07:32:00Z\n[t]\ne = 1.5\n[[arr]]\nf = 1\n[[arr]]\nf = 2\n"},
+		{"ini", FormatINI, "a=1\n[s]\nb=text\nc=true\n"},
+		{"properties", FormatProperties, "a.b=1\na.c=x\nd=y\n"},
+		{"env", FormatDotenv, "A=1\nB=\"x y\"\n"},
+		{"ndjson", FormatNDJSON, "{\"a\":1}\n{\"a\":2}\n"},
+		{"xml", FormatXML, "<root><a>1</a><b>x</b></root>"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(c.format, doc, nil)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			again, err := Parse(c.format, out, nil)
+			if err != nil {
+				t.Fatalf("parse output: %v\n%s", err, out)
+			}
+			if got, want := canonical(t, again), canonical(t, doc); got != want {
+				t.Errorf("round trip changed data\n got: %s\nwant: %s\noutput:\n%s", got, want, out)
+			}
+		})
+	}
+}
+
+// JSON 转为其他格式再转回 JSON，能完整表示 JSON 数据模型的格式数据不变
+func TestConvertRoundTrip(t *testing.T) {
+	src, err := Parse(FormatJSON, []byte(sampleJSON), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, format := range []Format{FormatJSON, FormatJSONC, FormatJSON5, FormatHJSON, FormatYAML, FormatMsgpack, FormatCBOR, FormatBSON} {
+		t.Run(string(format), func(t *testing.T) {
+			out, err := Marshal(format, src, nil)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			doc, err := Parse(format, out, nil)
+			if err != nil {
+				t.Fatalf("parse output: %v\n%s", err, out)
+			}
+			if got, want := canonical(t, doc), canonical(t, src); got != want {
+				t.Errorf("round trip changed data\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// 键顺序按原文保留，不按字典序重排
+func TestKeyOrder(t *testing.T) {
+	cases := []struct {
+		format Format
+		input  string
+	}{
+		{FormatJSON, `{"z": 1, "a": {"y": 2, "b": 3}}`},
+		{FormatYAML, "z: 1\na:\n  y: 2\n  b: 3\n"},
+		{FormatTOML, "z = 1\n[a]\ny = 2\nb = 3\n"},
+		{FormatMsgpack, "\x82\xa1z\x01\xa1a\x82\xa1y\x02\xa1b\x03"},
+		{FormatCBOR, "\xa2\x61z\x01\x61a\xa2\x61y\x02\x61b\x03"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(FormatJSON, doc, &Options{Indent: 0})
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			text := strings.Join(strings.Fields(string(out)), "")
+			if want := `{"z":1,"a":{"y":2,"b":3}}`; text != want {
+				t.Errorf("got %s, want %s", text, want)
+			}
+		})
+	}
+}
+
+// YAML 与 TOML 的 inf、nan 转为 $numberDouble 注解，JSON 输出不再报错并按目标格式记录提示，转回 YAML、TOML 时还原为字面量
+func TestSpecialFloats(t *testing.T) {
+	cases := []struct {
+		format Format
+		input  string
+		output string
+	}{
+		{FormatYAML, "a: .inf\nb: -.inf\nc: .nan\n", "a: .inf\nb: -.inf\nc: .nan\n"},
+		{FormatTOML, "a = inf\nb = -inf\nc = nan\n", "a = inf\nb = -inf\nc = nan\n"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			// 输出为可以表示 NaN 与 Infinity 的格式时不记录提示
+			for _, format := range []Format{c.format, FormatJSON5, FormatMsgpack} {
+				out, err := Marshal(format, doc, nil)
+				if err != nil {
+					t.Fatalf("marshal %s: %v", format, err)
+				}
+				if format == c.format && string(out) != c.output {
+					t.Errorf("%s: got %q, want %q", format, out, c.output)
+				}
+			}
+			if len(doc.Warnings) != 0 {
+				t.Errorf("unexpected warnings: %v", doc.Warnings)
+			}
+			out, err := Marshal(FormatJSON, doc, &Options{Indent: 0})
+			if err != nil {
+				t.Fatalf("marshal json: %v", err)
+			}
+			text := strings.Join(strings.Fields(string(out)), "")
+			if want := `{"a":{"$numberDouble":"Infinity"},"b":{"$numberDouble":"-Infinity"},"c":{"$numberDouble":"NaN"}}`; text != want {
+				t.Errorf("json: got %s, want %s", text, want)
+			}
+			if len(doc.Warnings) != 3 || doc.Warnings[0].Path != "a" || !strings.Contains(doc.Warnings[0].Message, "Infinity cannot be represented in json") {
+				t.Errorf("expected 3 json warnings, got %v", doc.Warnings)
+			}
+		})
+	}
+}
+
+// bsonDuplicate 同一文档中出现两次 int32 字段 a，取值为 1 与 2
+const bsonDuplicate = "\x13\x00\x00\x00\x10a\x00\x01\x00\x00\x00\x10a\x00\x02\x00\x00\x00\x00"
+
+// 重复键按策略处理，error 策略返回可由 IsDuplicateKeyError 识别的错误
+func TestDuplicateKeys(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+		policy DuplicateKeyPolicy
+		want   string // 为空时期望报错
+	}{
+		{"json keep-last", FormatJSON, `{"a": 1, "a": 2}`, "", `{"a":2}`},
+		{"json keep-first", FormatJSON, `{"a": 1, "a": 2}`, DuplicateKeyKeepFirst, `{"a":1}`},
+		{"json merge", FormatJSON, `{"a": 1, "a": 2}`, DuplicateKeyMerge, `{"a":[1,2]}`},
+		{"json error", FormatJSON, `{"a": 1, "a": 2}`, DuplicateKeyError, ""},
+		{"yaml keep-last", FormatYAML, "a: 1\na: 2\n", "", `{"a":2}`},
+		{"toml default", FormatTOML, "a = 1\na = 2\n", "", ""},
+		{"ini repeated section", FormatINI, "[s]\na=1\n[s]\nb=3\n", DuplicateKeyError, `{"s":{"a":1,"b":3}}`},
+		{"ini key in repeated section", FormatINI, "[s]\na=1\n[s]\na=3\n", DuplicateKeyMerge, `{"s":{"a":[1,3]}}`},
+		{"ini key error", FormatINI, "[s]\na=1\n[s]\na=3\n", DuplicateKeyError, ""},
+		{"bson keep-last", FormatBSON, bsonDuplicate, "", `{"a":2}`},
+		{"bson keep-first", FormatBSON, bsonDuplicate, DuplicateKeyKeepFirst, `{"a":1}`},
+		{"bson merge", FormatBSON, bsonDuplicate, DuplicateKeyMerge, `{"a":[1,2]}`},
+		{"bson error", FormatBSON, bsonDuplicate, DuplicateKeyError, ""},
+		{"msgpack keep-last", FormatMsgpack, "\x82\xa1a\x01\xa1a\x02", "", `{"a":2}`},
+		{"msgpack merge", FormatMsgpack, "\x82\xa1a\x01\xa1a\x02", DuplicateKeyMerge, `{"a":[1,2]}`},
+		{"cbor keep-first", FormatCBOR, "\xa2\x61a\x01\x61a\x02", DuplicateKeyKeepFirst, `{"a":1}`},
+		{"cbor error", FormatCBOR, "\xa2\x61a\x01\x61a\x02", DuplicateKeyError, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := Parse(c.format, []byte(c.input), &Options{DuplicateKeys: c.policy})
+			if c.want == "" {
+				if err == nil {
+					t.Fatalf("expected duplicate key error, got %s", canonical(t, doc))
+				}
+				if !IsDuplicateKeyError(err) {
+					t.Errorf("error is not a duplicate key error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// 语法错误返回带行号的错误
+func TestSyntaxErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+		want   string
+	}{
+		{"ini unterminated section", FormatINI, "a=1\n[unterminated\nb=2\n", "line 2"},
+		{"json missing comma", FormatJSON, "{\n\"a\": 1\n\"b\": 2\n}", "line 3"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse(c.format, []byte(c.input), nil)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+// FuzzJSONRoundTrip 合法的 JSON 转为 YAML、JSON5、MessagePack、CBOR 再转回时数据不变，转为其他格式时只能返回错误，不能 panic
+func FuzzJSONRoundTrip(f *testing.F) {
+	for _, seed := range []string{
+		sampleJSON,
+		`[]`, `{}`, `null`, `"text"`, `1e400`, `-0`, `[1, [2, [3]]]`,
+		`{"a": {"b": {"c": [null, true, 1.5, "x"]}}}`,
+		`{"": 1, "a.b": 2, "[0]": 3, "$numberDouble": "NaN"}`,
+		`{"k": "line1\nline2\t\u0000😀"}`,
+		`{"0": "\n"}`, `["\n\nx\n\n"]`,
+	} {
+		f.Add(seed)
+	}
+	lossless := map[Format]bool{FormatYAML: true, FormatJSON5: true, FormatMsgpack: true, FormatCBOR: true}
+	f.Fuzz(func(t *testing.T, input string) {
+		src, err := Parse(FormatJSON, []byte(input), nil)
+		if err != nil {
+			return
+		}
+		want, err := CanonicalJSON(src.Value)
+		if err != nil {
+			return
+		}
+		for _, format := range []Format{FormatYAML, FormatJSON5, FormatMsgpack, FormatCBOR, FormatTOML, FormatINI, FormatXML, FormatHJSON, FormatProperties, FormatDotenv, FormatHCL} {
+			out, err := Marshal(format, src, nil)
+			if err != nil {
+				continue
+			}
+			doc, err := Parse(format, out, nil)
+			if !lossless[format] {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: parse output: %v\n%s", format, err, out)
+			}
+			got, err := CanonicalJSON(doc.Value)
+			if err != nil {
+				t.Fatalf("%s: canonical json: %v", format, err)
+			}
+			if string(got) != string(want) {
+				t.Fatalf("%s: round trip changed data\n got: %s\nwant: %s", format, got, want)
+			}
+		}
+	})
+}
+
+// FuzzParse 任意输入按各格式解析只能返回错误，不能 panic
+func FuzzParse(f *testing.F) {
+	seeds := []struct {
+		format Format
+		input  string
+	}{
+		{FormatJSON, sampleJSON},
+		{FormatJSON5, "{a: Infinity, b: .5, c: 0x10,}"},
+		{FormatHJSON, "{\n a: 1\n b: '''\n  x\n  '''\n}"},
+		{FormatYAML, "a: &x [1, 2]\nb: *x\n<<: {c: 3}\n"},
+		{FormatTOML, "a = 1\n[b]\nc = [1, 2]\n[[d]]\ne = nan\n"},
+		{FormatINI, "; c\n[s]\na=1\n[s.t]\nb=2\n[s\n"},
+		{FormatXML, "<a x=\"1\"><b>2</b><b>3</b></a>"},
+		{FormatProperties, "a.b=1\na.c\\:d=2\n"},
+		{FormatDotenv, "A=1\nexport B='x'\n"},
+		{FormatHCL, "a = 1\nb {\n c = \"x\"\n}\n"},
+		{FormatCSV, "a,b\n1,2\n"},
+		{FormatNDJSON, "{\"a\":1}\n[1]\n"},
+	}
+	formats := make([]Format, 0, len(seeds))
+	for i, seed := range seeds {
+		f.Add(i, seed.input)
+		formats = append(formats, seed.format)
+	}
+	f.Fuzz(func(t *testing.T, index int, input string) {
+		format := formats[uint(index)%uint(len(formats))]
+		doc, err := Parse(format, []byte(input), nil)
+		if err != nil {
+			return
+		}
+		for _, to := range []Format{FormatJSON, FormatYAML, format} {
+			_, _ = Marshal(to, doc, nil)
+		}
+	})
+}
+
+// 二进制格式的字节串、时间、扩展类型与大整数经注解对象往返不变
+func TestBinaryAnnotations(t *testing.T) {
+	cases := []struct {
+		format Format
+		input  string
+	}{
+		{FormatMsgpack, `{"bin": {"$binary": {"base64": "AQID", "subType": "00"}}, "at": {"$date": "2024-01-02T03:04:05Z"}, "ext": {"$ext": {"type": 5, "base64": "qg=="}}}`},
+		{FormatCBOR, `{"bin": {"$binary": {"base64": "AQID", "subType": "00"}}, "at": {"$date": "2024-01-02T03:04:05Z"}, "big": 123456789012345678901234567890, "neg": -123456789012345678901234567890, "tagged": {"$tag": 37, "value": "x"}}`},
+		{FormatBSON, `{"bin": {"$binary": {"base64": "AQID", "subType": "04"}}, "at": {"$date": "2024-01-02T03:04:05Z"}, "id": {"$oid": "65a1b2c3d4e5f60718293a4b"}}`},
+	}
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			src, err := Parse(FormatJSON, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(c.format, src, nil)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if len(src.Warnings) != 0 {
+				t.Errorf("unexpected warnings: %v", src.Warnings)
+			}
+			doc, err := Parse(c.format, out, nil)
+			if err != nil {
+				t.Fatalf("parse output: %v", err)
+			}
+			if got, want := canonical(t, doc), canonical(t, src); got != want {
+				t.Errorf("round trip changed data\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// 目标格式不支持的注解保留为普通对象并记录提示
+func TestBinaryUnsupportedAnnotation(t *testing.T) {
+	_, paths := marshalWarnings(t, FormatMsgpack, `{"t": {"$tag": 37, "value": "x"}}`)
+	if strings.Join(paths, ",") != "t" {
+		t.Errorf("unexpected warning paths %v", paths)
+	}
+}
+
+// 连续存放的多个值解码为数组并记录提示，截断的输入报错
+func TestBinaryConcatenatedValues(t *testing.T) {
+	for _, format := range []Format{FormatMsgpack, FormatCBOR, FormatBSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var data []byte
+			for _, input := range []string{`{"a": 1}`, `{"b": 2}`} {
+				src, err := Parse(FormatJSON, []byte(input), nil)
+				if err != nil {
+					t.Fatalf("parse: %v", err)
+				}
+				out, err := Marshal(format, src, nil)
+				if err != nil {
+					t.Fatalf("marshal: %v", err)
+				}
+				data = append(data, out...)
+			}
+			doc, err := Parse(format, data, nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != `[{"a":1},{"b":2}]` || len(doc.Warnings) != 1 {
+				t.Errorf("got %s, warnings %v", got, doc.Warnings)
+			}
+			if _, err = Parse(format, data[:len(data)-1], nil); err == nil {
+				t.Error("expected error for truncated input")
+			}
+		})
+	}
+}
+
+// 接口中二进制格式的内容为 base64 编码，ParseNamed 解码后解析，Remarshal 输出 base64
+func TestNamedContent(t *testing.T) {
+	src, err := Parse(FormatJSON, []byte(`{"b": 1, "a": "x"}`), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, format := range []Format{FormatMsgpack, FormatCBOR, FormatBSON} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Marshal(format, src, nil)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			// URL 安全字母表、无填充与换行同样可以解码
+			content := base64.RawURLEncoding.EncodeToString(data)
+			content = content[:len(content)/2] + "\n" + content[len(content)/2:]
+			doc, raw, err := ParseNamed(string(format), content, nil)
+			if err != nil {
+				t.Fatalf("parse named: %v", err)
+			}
+			if !bytes.Equal(raw, data) || canonical(t, doc) != canonical(t, src) {
+				t.Errorf("unexpected document %s", canonical(t, doc))
+			}
+			out, err := Remarshal(doc, doc.Value, nil)
+			if err != nil {
+				t.Fatalf("remarshal: %v", err)
+			}
+			// 解码保留键顺序，原样写回
+			if out != base64.StdEncoding.EncodeToString(data) {
+				t.Errorf("remarshal output changed: %q", out)
+			}
+		})
+	}
+	if _, _, err := ParseNamed("msgpack", "not base64!", nil); err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("expected base64 error, got %v", err)
+	}
+}
@@ -0,0 +1,768 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hclLabelDepths 顶层块的标签个数（Terraform 约定），序列化时据此将嵌套对象还原为带标签的块
+var hclLabelDepths = map[string]int{
+	"resource": 2,
+	"data":     2,
+	"variable": 1,
+	"output":   1,
+	"module":   1,
+	"provider": 1,
+}
+
+var hclIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// parseHCL 解析 HCL2 原生语法，映射规则与 Terraform JSON 语法一致：属性为键值，块按类型与标签逐层嵌套为对象，
+// 同一路径出现多个块时转为数组；字面量直接转换，变量引用、函数调用等表达式保留为 "${表达式}" 字符串
+func parseHCL(doc *Document, data []byte, _ *Options) error {
+	p := &hclParser{src: data}
+	body, err := p.parseBody(false)
+	if err != nil {
+		return err
+	}
+	doc.SetOrdered(body)
+	return nil
+}
+
+type hclParser struct {
+	src []byte
+	pos int
+}
+
+func (p *hclParser) errorf(format string, args ...any) error {
+	line, column := position(p.src, int64(p.pos))
+	return fmt.Errorf("line %d column %d: %s", line, column, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hclParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.src[p.pos:], []byte(prefix))
+}
+
+// skipSpace 跳过空白与注释，newlines 为 false 时停在换行符前
+func (p *hclParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch ch := p.peek(); {
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			p.pos++
+		case ch == '\n':
+			if !newlines {
+				return
+			}
+			p.pos++
+		case ch == '#' || p.hasPrefix("//"):
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case p.hasPrefix("/*"):
+			end := bytes.Index(p.src[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func (p *hclParser) ident() string {
+	start := p.pos
+	for !p.eof() {
+		ch := p.peek()
+		if ch == '_' || ch == '-' && p.pos > start || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' && p.pos > start {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return string(p.src[start:p.pos])
+}
+
+// parseBody 解析属性与块，nested 为 true 时以 } 结束
+func (p *hclParser) parseBody(nested bool) (*Record, error) {
+	body := NewRecord()
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			if nested {
+				return nil, p.errorf("unclosed block, expected }")
+			}
+			return body, nil
+		}
+		if p.peek() == '}' {
+			if !nested {
+				return nil, p.errorf("unexpected }")
+			}
+			p.pos++
+			return body, nil
+		}
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("expected attribute or block name, found %q", p.peek())
+		}
+		p.skipSpace(false)
+		if p.peek() == '=' && !p.hasPrefix("==") {
+			p.pos++
+			p.skipSpace(false)
+			if _, exists := body.Get(name); exists {
+				return nil, p.errorf("duplicate attribute %q", name)
+			}
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			body.Set(name, value)
+		} else {
+			labels, err := p.parseLabels()
+			if err != nil {
+				return nil, err
+			}
+			block, err := p.parseBody(true)
+			if err != nil {
+				return nil, err
+			}
+			if err = addHCLBlock(body, append([]string{name}, labels...), block); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		}
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '}' {
+			return nil, p.errorf("expected newline after %q", name)
+		}
+	}
+}
+
+func (p *hclParser) parseLabels() ([]string, error) {
+	var labels []string
+	for {
+		p.skipSpace(false)
+		switch ch := p.peek(); {
+		case ch == '{':
+			p.pos++
+			return labels, nil
+		case ch == '"':
+			label, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			labels = append(labels, label)
+		default:
+			label := p.ident()
+			if label == "" {
+				return nil, p.errorf("expected block label or {, found %q", ch)
+			}
+			labels = append(labels, label)
+		}
+	}
+}
+
+// addHCLBlock 按块类型与标签逐层写入，重复的块转为数组
+func addHCLBlock(body *Record, path []string, block *Record) error {
+	node := body
+	for i, key := range path {
+		existing, exists := node.Get(key)
+		if i == len(path)-1 {
+			switch v := existing.(type) {
+			case nil:
+				node.Set(key, block)
+			case *Record:
+				node.Set(key, []any{v, block})
+			case []any:
+				node.Set(key, append(v, block))
+			}
+			return nil
+		}
+		if !exists {
+			child := NewRecord()
+			node.Set(key, child)
+			node = child
+			continue
+		}
+		child, ok := existing.(*Record)
+		if !ok {
+			return fmt.Errorf("block %q conflicts with an existing value", strings.Join(path[:i+1], "."))
+		}
+		node = child
+	}
+	return nil
+}
+
+// parseExpr 解析表达式：字面量转换为对应的值，其余表达式原样保留为 "${表达式}"
+func (p *hclParser) parseExpr() (any, error) {
+	start := p.pos
+	value, literal, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if literal {
+		save := p.pos
+		p.skipSpace(false)
+		if p.atExprEnd() {
+			p.pos = save
+			return value, nil
+		}
+	}
+	// 非字面量或字面量参与运算，整体作为表达式保留
+	p.pos = start
+	raw, err := p.scanExpr()
+	if err != nil {
+		return nil, err
+	}
+	return "${" + raw + "}", nil
+}
+
+func (p *hclParser) atExprEnd() bool {
+	if p.eof() {
+		return true
+	}
+	switch p.peek() {
+	case '\n', ',', ']', '}', ')', '#':
+		return true
+	}
+	return p.hasPrefix("//") || p.hasPrefix("/*")
+}
+
+func (p *hclParser) parseLiteral() (any, bool, error) {
+	switch ch := p.peek(); {
+	case ch == '"':
+		s, err := p.parseString()
+		return s, err == nil, err
+	case p.hasPrefix("<<"):
+		s, err := p.parseHeredoc()
+		return s, err == nil, err
+	case ch == '[':
+		if p.isForExpr() {
+			return nil, false, nil
+		}
+		return p.parseTuple()
+	case ch == '{':
+		if p.isForExpr() {
+			return nil, false, nil
+		}
+		return p.parseObject()
+	case ch == '-' || ch >= '0' && ch <= '9':
+		if number := hclNumberPattern.Find(p.src[p.pos:]); number != nil {
+			p.pos += len(number)
+			return json.Number(number), true, nil
+		}
+	default:
+		start := p.pos
+		switch p.ident() {
+		case "true":
+			return true, true, nil
+		case "false":
+			return false, true, nil
+		case "null":
+			return nil, true, nil
+		}
+		p.pos = start
+	}
+	return nil, false, nil
+}
+
+var hclNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?`)
+
+// isForExpr [for ...] 与 {for ...} 推导式按表达式保留
+func (p *hclParser) isForExpr() bool {
+	rest := bytes.TrimLeft(p.src[p.pos+1:], " \t\r\n")
+	return bytes.HasPrefix(rest, []byte("for ")) || bytes.HasPrefix(rest, []byte("for\t"))
+}
+
+func (p *hclParser) parseTuple() (any, bool, error) {
+	p.pos++
+	items := make([]any, 0)
+	for {
+		p.skipSpace(true)
+		if p.peek() == ']' {
+			p.pos++
+			return items, true, nil
+		}
+		if p.eof() {
+			return nil, false, p.errorf("unclosed tuple, expected ]")
+		}
+		item, err := p.parseExpr()
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, item)
+		p.skipSpace(true)
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, false, p.errorf("expected , or ] in tuple")
+		}
+	}
+}
+
+func (p *hclParser) parseObject() (any, bool, error) {
+	p.pos++
+	obj := NewRecord()
+	for {
+		p.skipSpace(true)
+		if p.peek() == '}' {
+			p.pos++
+			return obj, true, nil
+		}
+		if p.eof() {
+			return nil, false, p.errorf("unclosed object, expected }")
+		}
+		var key string
+		switch p.peek() {
+		case '"':
+			s, err := p.parseString()
+			if err != nil {
+				return nil, false, err
+			}
+			key = s
+		case '(':
+			raw, err := p.scanExpr()
+			if err != nil {
+				return nil, false, err
+			}
+			key = "${" + raw + "}"
+		default:
+			key = p.ident()
+			if key == "" {
+				return nil, false, p.errorf("expected object key, found %q", p.peek())
+			}
+		}
+		p.skipSpace(false)
+		if p.peek() != '=' && p.peek() != ':' {
+			return nil, false, p.errorf("expected = or : after object key %q", key)
+		}
+		p.pos++
+		p.skipSpace(false)
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, false, err
+		}
+		obj.Set(key, value)
+		p.skipSpace(false)
+		if p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
+
+// parseString 解析双引号字符串，模板插值 ${...} 与 %{...} 原样保留
+func (p *hclParser) parseString() (string, error) {
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.peek()
+		switch {
+		case ch == '"':
+			p.pos++
+			return sb.String(), nil
+		case ch == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch esc := p.peek(); esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'u', 'U':
+				size := 4
+				if esc == 'U' {
+					size = 8
+				}
+				var code rune
+				if _, err := fmt.Sscanf(string(p.src[p.pos+1:min(p.pos+1+size, len(p.src))]), "%x", &code); err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				sb.WriteRune(code)
+				p.pos += size
+			default:
+				sb.WriteByte(esc)
+			}
+			p.pos++
+		case (ch == '$' || ch == '%') && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+			start := p.pos
+			p.pos++
+			if err := p.skipBrackets(); err != nil {
+				return "", err
+			}
+			sb.Write(p.src[start:p.pos])
+		default:
+			sb.WriteByte(ch)
+			p.pos++
+		}
+	}
+}
+
+// parseHeredoc 解析 <<EOT 与 <<-EOT，后者去除公共缩进
+func (p *hclParser) parseHeredoc() (string, error) {
+	p.pos += 2
+	indented := false
+	if p.peek() == '-' {
+		indented = true
+		p.pos++
+	}
+	marker := p.ident()
+	if marker == "" {
+		return "", p.errorf("expected heredoc marker")
+	}
+	p.skipSpace(false)
+	if p.peek() != '\n' {
+		return "", p.errorf("expected newline after heredoc marker")
+	}
+	p.pos++
+	var lines []string
+	for !p.eof() {
+		end := bytes.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		line := strings.TrimRight(string(p.src[p.pos:p.pos+end]), "\r")
+		p.pos += end
+		if strings.TrimSpace(line) == marker {
+			if indented {
+				lines = trimCommonIndent(lines)
+			}
+			if len(lines) == 0 {
+				return "", nil
+			}
+			return strings.Join(lines, "\n") + "\n", nil
+		}
+		lines = append(lines, line)
+		if !p.eof() {
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated heredoc %s", marker)
+}
+
+func trimCommonIndent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+	}
+	return lines
+}
+
+// scanExpr 读取表达式原文，直到同层的换行、逗号或右括号
+func (p *hclParser) scanExpr() (string, error) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		ch := p.peek()
+		switch {
+		case ch == '"':
+			if _, err := p.parseString(); err != nil {
+				return "", err
+			}
+			continue
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			if depth == 0 {
+				return p.rawExpr(start)
+			}
+			depth--
+		case depth == 0 && (ch == '\n' || ch == ',' || ch == '#' || p.hasPrefix("//")):
+			return p.rawExpr(start)
+		}
+		p.pos++
+	}
+	if depth > 0 {
+		return "", p.errorf("unclosed bracket in expression")
+	}
+	return p.rawExpr(start)
+}
+
+func (p *hclParser) rawExpr(start int) (string, error) {
+	raw := strings.TrimSpace(string(p.src[start:p.pos]))
+	if raw == "" {
+		return "", p.errorf("expected expression")
+	}
+	return raw, nil
+}
+
+// skipBrackets 跳过从当前位置开始的 ${...} 或 %{...}，支持嵌套与内部字符串
+func (p *hclParser) skipBrackets() error {
+	depth := 0
+	for !p.eof() {
+		switch p.peek() {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		case '"':
+			if _, err := p.parseString(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.pos++
+	}
+	return p.errorf("unclosed template interpolation")
+}
+
+// marshalHCL 顶层对象写为块，标签个数按 hclLabelDepths；嵌套对象中含有对象或对象数组时写为块，否则写为属性；
+// 整体为 "${表达式}" 的字符串还原为表达式。来源不是 HCL 时字符串中其余的 ${ 与 %{ 为字面文本，转义为 $${ 与 %%{。
+// 属性名不是标识符时只能加引号写出，原生 HCL2 解析器不接受，记录提示
+func marshalHCL(doc *Document, opts *Options) ([]byte, error) {
+	root, ok := doc.Ordered(opts).(*Record)
+	if !ok {
+		return nil, fmt.Errorf("hcl document root must be an object")
+	}
+	w := &hclWriter{indent: strings.Repeat(" ", opts.indent()), doc: doc}
+	w.writeBody(root, "", "", true)
+	return bytes.TrimLeft(w.buf.Bytes(), "\n"), nil
+}
+
+type hclWriter struct {
+	buf    bytes.Buffer
+	indent string
+	doc    *Document
+}
+
+func (w *hclWriter) writeBody(values *Record, path, current string, top bool) {
+	var attributes, blocks []string
+	width := 0
+	for _, key := range values.Keys {
+		// 块类型必须是标识符，否则只能写为属性
+		if isHCLBlock(values.Values[key], top) && hclIdentPattern.MatchString(key) {
+			blocks = append(blocks, key)
+			continue
+		}
+		attributes = append(attributes, key)
+		width = max(width, len(hclKey(key)))
+	}
+	// 与 terraform fmt 一致，同一层的属性等号对齐
+	for _, key := range attributes {
+		name := w.attributeName(key, joinPath(path, key))
+		w.buf.WriteString(current + name + strings.Repeat(" ", width-len(name)) + " = ")
+		w.writeValue(values.Values[key], current)
+		w.buf.WriteByte('\n')
+	}
+	for _, key := range blocks {
+		depth := 0
+		if top {
+			depth = hclLabelDepths[key]
+		}
+		w.writeBlocks(key, nil, depth, values.Values[key], joinPath(path, key), current)
+	}
+}
+
+// isHCLBlock 顶层对象与对象数组均为块；嵌套层中只有包含对象或对象数组的对象才视为块。
+// 只有一个元素的对象数组写为块后读回为对象，因此写为属性 name = [{...}]
+func isHCLBlock(value any, top bool) bool {
+	switch v := value.(type) {
+	case *Record:
+		if top {
+			return true
+		}
+		for _, item := range v.Values {
+			if isHCLBlock(item, true) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		if len(v) < 2 {
+			return false
+		}
+		for _, item := range v {
+			if _, ok := item.(*Record); !ok {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *hclWriter) writeBlocks(name string, labels []string, depth int, value any, path, current string) {
+	switch v := value.(type) {
+	case *Record:
+		if len(labels) < depth {
+			for _, label := range v.Keys {
+				w.writeBlocks(name, append(labels, label), depth, v.Values[label], joinPath(path, label), current)
+			}
+			return
+		}
+		w.buf.WriteString("\n" + current + name)
+		for _, label := range labels {
+			w.buf.WriteString(" " + hclQuote(label))
+		}
+		if v.Len() == 0 {
+			w.buf.WriteString(" {}\n")
+			return
+		}
+		w.buf.WriteString(" {\n")
+		w.writeBody(v, path, current+w.indent, false)
+		w.buf.WriteString(current + "}\n")
+	case []any:
+		if len(v) == 1 {
+			w.doc.Warn(0, path, "single-element array written as one block, reads back as an object")
+		}
+		for i, item := range v {
+			w.writeBlocks(name, labels, len(labels), item, fmt.Sprintf("%s[%d]", path, i), current)
+		}
+	default:
+		// 标签层级下出现非对象值，无法表示为块，退化为属性
+		w.buf.WriteString(current + w.attributeName(strings.Join(append([]string{name}, labels...), "."), path) + " = ")
+		w.writeValue(value, current)
+		w.buf.WriteByte('\n')
+	}
+}
+
+func (w *hclWriter) writeValue(value any, current string) {
+	switch v := value.(type) {
+	case nil:
+		w.buf.WriteString("null")
+	case string:
+		if expr, ok := hclExpression(v); ok {
+			w.buf.WriteString(expr)
+			return
+		}
+		v = w.escapeTemplate(v)
+		if heredoc, ok := hclHeredoc(v, current+w.indent); ok {
+			w.buf.WriteString(heredoc + current + hclHeredocMarker)
+			return
+		}
+		w.buf.WriteString(hclQuote(v))
+	case []any:
+		if len(v) == 0 {
+			w.buf.WriteString("[]")
+			return
+		}
+		w.buf.WriteString("[\n")
+		for _, item := range v {
+			w.buf.WriteString(current + w.indent)
+			w.writeValue(item, current+w.indent)
+			w.buf.WriteString(",\n")
+		}
+		w.buf.WriteString(current + "]")
+	case *Record:
+		if v.Len() == 0 {
+			w.buf.WriteString("{}")
+			return
+		}
+		width := 0
+		for _, key := range v.Keys {
+			width = max(width, len(hclKey(key)))
+		}
+		w.buf.WriteString("{\n")
+		for _, key := range v.Keys {
+			name := hclKey(key)
+			w.buf.WriteString(current + w.indent + name + strings.Repeat(" ", width-len(name)) + " = ")
+			w.writeValue(v.Values[key], current+w.indent)
+			w.buf.WriteByte('\n')
+		}
+		w.buf.WriteString(current + "}")
+	default:
+		w.buf.WriteString(scalarString(v))
+	}
+}
+
+// hclExpression 判断字符串是否整体为一个 ${...} 插值，是则返回表达式原文
+func hclExpression(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	p := &hclParser{src: []byte(s), pos: 1}
+	if err := p.skipBrackets(); err != nil || p.pos != len(s) {
+		return "", false
+	}
+	expr := strings.TrimSpace(s[2 : len(s)-1])
+	return expr, expr != ""
+}
+
+var hclTemplateEscaper = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// escapeTemplate 字符串值在 HCL 中是模板，来源为 HCL 时取值保留了模板原文，其他来源的 ${ 与 %{ 需要转义
+func (w *hclWriter) escapeTemplate(s string) string {
+	if w.doc.Format == FormatHCL {
+		return s
+	}
+	return hclTemplateEscaper.Replace(s)
+}
+
+const hclHeredocMarker = "EOT"
+
+// hclHeredoc 以换行结尾的多行文本写为 <<-EOT，内容整体缩进，解析时去除公共缩进还原
+func hclHeredoc(s, indent string) (string, bool) {
+	if !strings.HasSuffix(s, "\n") || strings.Count(s, "\n") < 2 || strings.Contains(s, "\r") {
+		return "", false
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	var sb strings.Builder
+	sb.WriteString("<<-" + hclHeredocMarker + "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == hclHeredocMarker {
+			return "", false
+		}
+		// 首行有缩进时无法区分公共缩进，放弃使用 heredoc
+		if line != "" && (line[0] == ' ' || line[0] == '\t') && line == lines[0] {
+			return "", false
+		}
+		if line != "" {
+			sb.WriteString(indent + line)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String(), true
+}
+
+// attributeName 块体中的属性名必须是标识符，其他键名加引号写出并记录提示
+func (w *hclWriter) attributeName(key, path string) string {
+	if !hclIdentPattern.MatchString(key) {
+		w.doc.Warn(0, path, "attribute name %q is not an identifier, native hcl parsers such as terraform reject the quoted name", key)
+	}
+	return hclKey(key)
+}
+
+func hclKey(key string) string {
+	if hclIdentPattern.MatchString(key) {
+		return key
+	}
+	return hclQuote(key)
+}
+
+func hclQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
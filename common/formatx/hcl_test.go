@@ -0,0 +1,129 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+)
+
+// marshalWarnings 将 JSON 序列化为指定格式，返回输出与序列化过程中的提示路径
+func marshalWarnings(t *testing.T, format Format, input string) (string, []string) {
+	t.Helper()
+	doc, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	doc.Warnings = nil
+	out, err := Marshal(format, doc, nil)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var paths []string
+	for _, w := range doc.Warnings {
+		paths = append(paths, w.Path)
+	}
+	return string(out), paths
+}
+
+// Terraform 风格的块与属性写出后按原生语法读回
+func TestHCLRoundTrip(t *testing.T) {
+	input := `{"resource": {"aws_instance": {"web": {"ami": "ami-1", "count": 2, "tags": {"Name": "web", "a b": "c"}}}}, "variable": {"region": {"default": "${var.x}"}}}`
+	src, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out, err := Marshal(FormatHCL, src, nil)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if len(src.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", src.Warnings)
+	}
+	if !strings.Contains(string(out), `resource "aws_instance" "web" {`) || !strings.Contains(string(out), "default = var.x") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	doc, err := Parse(FormatHCL, out, nil)
+	if err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out)
+	}
+	if got, want := canonical(t, doc), canonical(t, src); got != want {
+		t.Errorf("round trip changed data\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// 块体中不是标识符的属性名记录提示，属性值为对象时其中的键可以加引号，不提示
+func TestHCLNonIdentifierAttribute(t *testing.T) {
+	out, paths := marshalWarnings(t, FormatHCL, `{"1bad key": 1, "ok": {"x.y": 2}, "block": {"inner": {"a b": 3}}}`)
+	if strings.Join(paths, ",") != "1bad key,ok.x.y" {
+		t.Errorf("unexpected warning paths %v\n%s", paths, out)
+	}
+	if !strings.Contains(string(out), `"1bad key" = 1`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+// properties 与 dotenv 没有 null，写为空值时记录提示
+func TestNullWrittenAsEmpty(t *testing.T) {
+	for _, c := range []struct {
+		format Format
+		want   string
+	}{
+		{FormatProperties, "a.b=\nc=1\n"},
+		{FormatDotenv, "a_b=\"\"\nc=1\n"},
+	} {
+		out, paths := marshalWarnings(t, c.format, `{"a": {"b": null}, "c": 1}`)
+		if out != c.want || len(paths) != 1 {
+			t.Errorf("%s: output %q, warnings %v", c.format, out, paths)
+		}
+	}
+}
+
+// 只有一个元素的对象数组写为属性，读回仍为数组
+func TestHCLSingleElementBlockArray(t *testing.T) {
+	input := `{"m": [{"a": 1}], "n": {"inner": [{"b": 2}]}}`
+	src, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out, err := Marshal(FormatHCL, src, nil)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	doc, err := Parse(FormatHCL, out, nil)
+	if err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out)
+	}
+	if got, want := canonical(t, doc), canonical(t, src); got != want {
+		t.Errorf("round trip changed data\n got: %s\nwant: %s\n%s", got, want, out)
+	}
+}
+
+// 其他格式中的 ${ 与 %{ 是字面文本，转义后不会成为插值；整体为 ${表达式} 的字符串仍还原为表达式
+func TestHCLEscapeTemplate(t *testing.T) {
+	out, _ := marshalWarnings(t, FormatHCL, `{"cmd": "echo ${HOME} 100%{x}", "ref": "${var.x}"}`)
+	if !strings.Contains(out, `cmd = "echo $${HOME} 100%%{x}"`) || !strings.Contains(out, "ref = var.x") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	// 来源为 HCL 时模板原样保留
+	src := "name = \"web-${var.env}\"\n"
+	doc, err := Parse(FormatHCL, []byte(src), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got, err := Marshal(FormatHCL, doc, nil); err != nil || string(got) != src {
+		t.Errorf("hcl round trip: %q %v", got, err)
+	}
+}
+
+// 数组下标相对元素个数过于稀疏时报错，不按下标分配数组
+func TestPropertiesSparseIndex(t *testing.T) {
+	if _, err := Parse(FormatProperties, []byte("a[2000000000]=x\n"), nil); err == nil || !strings.Contains(err.Error(), "too sparse") {
+		t.Errorf("expected sparse index error, got %v", err)
+	}
+	doc, err := Parse(FormatProperties, []byte("a[2]=z\na[0]=x\nb[5]=y\n"), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got, want := canonical(t, doc), `{"a":["x",null,"z"],"b":[null,null,null,null,null,"y"]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package formatx
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseHJSON 按 HJSON 语法（https://hjson.github.io/syntax.html）严格解析：根对象可以省略括号，
+// 键与字符串可以不加引号，成员可以用换行分隔，支持 #、// 与 /* */ 注释以及三个单引号包围的多行字符串
+func parseHJSON(doc *Document, data []byte, opts *Options) error {
+	return newJSON5Parser(doc, data, opts.duplicateKeys(FormatHJSON), true).parse()
+}
+
+// marshalHJSON JSON 是合法的 HJSON，输出带 // 注释的 JSON
+func marshalHJSON(doc *Document, opts *Options) ([]byte, error) {
+	w := &jsoncWriter{comments: opts.comments(doc), indent: strings.Repeat(" ", opts.indent())}
+	return w.encode(doc.Ordered(opts))
+}
+
+// hjsonNumberPattern HJSON 的数字与 JSON 相同
+var hjsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+
+// hjsonPunctuators 不能出现在无引号键中、也不能作为无引号字符串开头的字符
+const hjsonPunctuators = "{}[],:"
+
+// bracelessRoot 根节点不以括号开头、第一个键之后是冒号时，按省略括号的对象解析
+func (p *json5Parser) bracelessRoot() bool {
+	if ch := p.data[p.pos]; ch == '{' || ch == '[' {
+		return false
+	}
+	save := p.pos
+	defer func() { p.pos = save }()
+	if _, err := p.key(); err != nil {
+		return false
+	}
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+	return p.pos < len(p.data) && p.data[p.pos] == ':'
+}
+
+// quotelessKey 无引号的键，不能包含空白与 {}[],:
+func (p *json5Parser) quotelessKey() (string, error) {
+	start := p.pos
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if strings.ContainsRune(hjsonPunctuators, r) || r == ' ' || r == '\t' || r == '\n' || r == '\r' || isJSON5Space(r) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		r, _ := utf8.DecodeRune(p.data[p.pos:])
+		return "", p.errorf(start, "invalid character %q, expected key", r)
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+func (p *json5Parser) multiline() bool {
+	return strings.HasPrefix(string(p.data[p.pos:]), "'''")
+}
+
+// hjsonScalar 引号字符串、多行字符串、true/false/null 与数字；其后除空白与注释外还有内容时，
+// 整行作为无引号字符串
+func (p *json5Parser) hjsonScalar() (any, error) {
+	switch ch := p.data[p.pos]; {
+	case p.multiline():
+		return p.multilineString()
+	case ch == '"' || ch == '\'':
+		return p.quoted(ch)
+	case strings.IndexByte(hjsonPunctuators, ch) >= 0:
+		return nil, p.errorf(p.pos, "invalid character %q, expected value", ch)
+	}
+	rest := string(p.data[p.pos:])
+	for _, literal := range []struct {
+		text  string
+		value any
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if strings.HasPrefix(rest, literal.text) && p.terminated(p.pos+len(literal.text)) {
+			p.pos += len(literal.text)
+			return literal.value, nil
+		}
+	}
+	if number := hjsonNumberPattern.FindString(rest); number != "" && p.terminated(p.pos+len(number)) {
+		p.pos += len(number)
+		return json.Number(number), nil
+	}
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+		p.pos++
+	}
+	return strings.TrimRight(string(p.data[start:p.pos]), " \t"), nil
+}
+
+// terminated offset 之后只有空白，随后是行尾、分隔符、结束括号或注释
+func (p *json5Parser) terminated(offset int) bool {
+	for offset < len(p.data) && (p.data[offset] == ' ' || p.data[offset] == '\t') {
+		offset++
+	}
+	if offset >= len(p.data) {
+		return true
+	}
+	rest := string(p.data[offset:])
+	switch rest[0] {
+	case '\n', '\r', ',', ']', '}', '#':
+		return true
+	}
+	return strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*")
+}
+
+// multilineString ”' 多行字符串：开头 ”' 之后的换行与每行不超过 ”' 所在列的缩进被去掉，结尾的换行不计入
+func (p *json5Parser) multilineString() (string, error) {
+	start := p.pos
+	indent := start - (strings.LastIndexByte(string(p.data[:start]), '\n') + 1)
+	skipIndent := func() {
+		for i := 0; i < indent && p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t'); i++ {
+			p.pos++
+		}
+	}
+	p.pos += 3
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t' || p.data[p.pos] == '\r') {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+		skipIndent()
+	}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf(start, "unterminated multiline string")
+		}
+		if p.multiline() {
+			p.pos += 3
+			return strings.TrimSuffix(b.String(), "\n"), nil
+		}
+		switch ch := p.data[p.pos]; ch {
+		case '\r':
+			p.pos++
+		case '\n':
+			b.WriteByte('\n')
+			p.pos++
+			skipIndent()
+		default:
+			b.WriteByte(ch)
+			p.pos++
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// INIOptions INI 方言选项，零值与前端 iniToJson 一致：节名不拆分，以 = 分隔键值，区分大小写
+type INIOptions struct {
+	NestedSections  bool   // 节名按 . 拆分为嵌套对象，如 [server.http]
+	Subsections     bool   // 识别 git config 风格的子节 [remote "origin"]，子节名区分大小写，支持 \" 与 \\ 转义
+	Delimiters      string // 键值分隔符，由 = 与 : 组成，解析时任一字符均可分隔，输出使用第一个，默认 =
+	Multiline       bool   // 以空白开头的行接续上一个值，各行以换行连接
+	ArrayKeys       bool   // key[]=value 逐个追加为数组，输出数组时每个元素写为一行 key[]=
+	InlineComments  bool   // 值或节名之后以 ; 或 # 开始（前有空白）的内容为行尾注释
+	CaseInsensitive bool   // 节名与键名转为小写，子节名除外
+}
+
+// ParseINIDelimiters 校验键值分隔符，空字符串表示 =
+func ParseINIDelimiters(delimiters string) (string, error) {
+	if delimiters == "" {
+		return "=", nil
+	}
+	if strings.Trim(delimiters, "=:") != "" {
+		return "", fmt.Errorf("invalid ini delimiters: %q, expected =, : or both", delimiters)
+	}
+	return delimiters, nil
+}
+
+func (o *Options) iniDialect() INIOptions {
+	var dialect INIOptions
+	if o != nil {
+		dialect = o.INI
+	}
+	if dialect.Delimiters == "" {
+		dialect.Delimiters = "="
+	}
+	return dialect
+}
+
+// parseINI 按方言选项解析 INI，值的处理与前端 iniToJson 一致：去除两侧引号，识别布尔值与数字。
+// 重复的节合并为一个节，节内重复的键按策略处理；整行注释归属到下一个键或节，文件末尾的注释归属到根节点
+func parseINI(doc *Document, data []byte, opts *Options) error {
+	p := &iniParser{
+		doc:     doc,
+		dialect: opts.iniDialect(),
+		policy:  opts.duplicateKeys(FormatINI),
+		root:    NewRecord(),
+		keys:    make(map[string]*keySet),
+		arrays:  make(map[string]bool),
+	}
+	if err := p.parse(strings.Split(string(data), "\n")); err != nil {
+		return err
+	}
+	doc.SetOrdered(p.root)
+	doc.AddFootComment("", p.pending...)
+	return nil
+}
+
+// iniParser 逐行解析 INI，current 为当前节对应的对象
+type iniParser struct {
+	doc     *Document
+	dialect INIOptions
+	policy  DuplicateKeyPolicy
+	root    *Record
+	keys    map[string]*keySet // 各对象中已出现的键，按 JSON Pointer
+	arrays  map[string]bool    // 由 key[] 创建的数组
+	current *Record
+	pointer string
+	path    string
+	pending []string // 尚未归属的整行注释
+}
+
+func (p *iniParser) parse(lines []string) error {
+	p.current = p.root
+	for i := 0; i < len(lines); i++ {
+		pos := sourcePos{line: i + 1}
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if line[0] == ';' || line[0] == '#' {
+			p.pending = append(p.pending, strings.TrimSpace(line[1:]))
+			continue
+		}
+		if line[0] == '[' {
+			ok, err := p.header(line, pos)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+		}
+		index := strings.IndexAny(line, p.dialect.Delimiters)
+		if index < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:index])
+		value, comment := p.cutComment(strings.TrimSpace(line[index+1:]))
+		for p.dialect.Multiline && i+1 < len(lines) && isINIContinuation(lines[i+1]) {
+			i++
+			text, more := p.cutComment(strings.TrimSpace(lines[i]))
+			value += "\n" + text
+			comment = strings.TrimSpace(comment + " " + more)
+		}
+		if err := p.set(key, iniValue(value), comment, pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isINIContinuation 以空白开头的非空行，且不是注释
+func isINIContinuation(line string) bool {
+	text := strings.TrimSpace(line)
+	return text != "" && (line[0] == ' ' || line[0] == '\t') && text[0] != ';' && text[0] != '#'
+}
+
+// header 解析 [节] 表头，缺少 ] 时报错；] 之后还有其他内容时不是表头，返回 false，按键值对处理。
+// 重复的节与由 [a.b] 隐式创建的节合并到已有的对象，不按重复键处理
+func (p *iniParser) header(line string, pos sourcePos) (bool, error) {
+	if strings.IndexByte(line, ']') < 0 {
+		return true, fmt.Errorf("line %d: unterminated section header %s, expected ]", pos.line, line)
+	}
+	inner, comment, ok := p.cutClosing(line[1:], ']')
+	if !ok {
+		return false, nil
+	}
+	names, err := p.sectionNames(strings.TrimSpace(inner))
+	if err != nil {
+		return true, fmt.Errorf("line %d: %w", pos.line, err)
+	}
+	current, pointer, path := p.root, "", ""
+	for _, name := range names {
+		record := NewRecord()
+		if old, exists := current.Get(name); exists {
+			var ok bool
+			if record, ok = old.(*Record); !ok {
+				return true, fmt.Errorf("line %d: section [%s] conflicts with key %s", pos.line, inner, childPath(path, name))
+			}
+		} else {
+			// 登记节名，节内之后出现的同名键按重复键处理
+			p.keySet(pointer).seen[name] = pos
+			current.Set(name, record)
+		}
+		current, pointer, path = record, childPointer(pointer, name), childPath(path, name)
+	}
+	p.current, p.pointer, p.path = current, pointer, path
+	p.attach(pointer, comment)
+	return true, nil
+}
+
+// sectionNames 表头中的各级节名：按选项拆分 . 分隔的节名，子节名为最后一级
+func (p *iniParser) sectionNames(inner string) ([]string, error) {
+	base, sub, hasSub := inner, "", false
+	if p.dialect.Subsections {
+		if i := strings.IndexByte(inner, '"'); i >= 0 {
+			quoted := inner[i:]
+			if len(quoted) < 2 || quoted[len(quoted)-1] != '"' {
+				return nil, fmt.Errorf("unterminated subsection name in [%s]", inner)
+			}
+			var err error
+			if sub, err = unquoteINISubsection(quoted[1 : len(quoted)-1]); err != nil {
+				return nil, fmt.Errorf("%w in [%s]", err, inner)
+			}
+			base, hasSub = strings.TrimSpace(inner[:i]), true
+		}
+	}
+	if p.dialect.CaseInsensitive {
+		base = strings.ToLower(base)
+	}
+	names := []string{base}
+	if p.dialect.NestedSections {
+		names = strings.Split(base, ".")
+		for i, name := range names {
+			names[i] = strings.TrimSpace(name)
+		}
+	}
+	if hasSub {
+		names = append(names, sub)
+	}
+	return names, nil
+}
+
+func unquoteINISubsection(text string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\' && i+1 < len(text):
+			i++
+			b.WriteByte(text[i])
+		case ch == '\\' || ch == '"':
+			return "", fmt.Errorf("invalid character %q in subsection name", ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String(), nil
+}
+
+// set 在当前节中写入键值，key[] 追加为数组元素，其他键按重复键策略处理
+func (p *iniParser) set(key string, value any, comment string, pos sourcePos) error {
+	if p.dialect.CaseInsensitive {
+		key = strings.ToLower(key)
+	}
+	arrayKey := p.dialect.ArrayKeys && strings.HasSuffix(key, "[]")
+	if arrayKey {
+		key = strings.TrimSpace(strings.TrimSuffix(key, "[]"))
+	}
+	pointer := childPointer(p.pointer, key)
+	if arrayKey {
+		if items, ok := p.current.Values[key].([]any); ok && p.arrays[pointer] {
+			p.current.Set(key, append(items, value))
+			p.attach(indexPointer(pointer, len(items)), comment)
+			return nil
+		}
+		value = []any{value}
+	}
+	old, exists := p.current.Get(key)
+	merged, err := p.keySet(p.pointer).add(p.pointer, p.path, key, pos, old, value, p.doc)
+	if err != nil {
+		return err
+	}
+	p.current.Set(key, merged)
+	if arrayKey && (!exists || p.policy == DuplicateKeyKeepLast) {
+		p.arrays[pointer] = true
+	}
+	p.attach(pointer, comment)
+	return nil
+}
+
+func (p *iniParser) keySet(pointer string) *keySet {
+	keys, ok := p.keys[pointer]
+	if !ok {
+		keys = newKeySet(p.doc, p.policy)
+		p.keys[pointer] = keys
+	}
+	return keys
+}
+
+// attach 之前的整行注释作为前置注释，comment 作为行尾注释
+func (p *iniParser) attach(pointer, comment string) {
+	p.doc.AddHeadComment(pointer, p.pending...)
+	p.pending = nil
+	p.doc.AddLineComment(pointer, comment)
+}
+
+// cutComment 开启行尾注释时拆分值与注释：引号包围的值在结束引号之后查找，其余在第一个前有空白的 ; 或 # 处拆分
+func (p *iniParser) cutComment(value string) (string, string) {
+	if !p.dialect.InlineComments || value == "" {
+		return value, ""
+	}
+	if value[0] == '"' || value[0] == '\'' {
+		if inner, comment, ok := p.cutClosing(value[1:], value[0]); ok {
+			return value[:len(inner)+2], comment
+		}
+	}
+	for i := 0; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+		}
+	}
+	return value, ""
+}
+
+// cutClosing 查找其后只有空白（或开启行尾注释时只有注释）的第一个 closing，返回之前的内容与注释
+func (p *iniParser) cutClosing(text string, closing byte) (string, string, bool) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != closing {
+			continue
+		}
+		rest := strings.TrimSpace(text[i+1:])
+		if rest == "" {
+			return text[:i], "", true
+		}
+		if p.dialect.InlineComments && (rest[0] == ';' || rest[0] == '#') {
+			return text[:i], strings.TrimSpace(rest[1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func iniValue(value string) any {
+	if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"' || value[0] == '\'' && value[len(value)-1] == '\'') {
+		return value[1 : len(value)-1]
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "":
+		return value
+	}
+	if isNumberLiteral(value) {
+		return json.Number(value)
+	}
+	return value
+}
+
+// isNumberLiteral 是否为合法的 JSON 数字字面量
+func isNumberLiteral(value string) bool {
+	return json.Valid([]byte(value)) && strings.IndexFunc(value, func(r rune) bool {
+		return !strings.ContainsRune("0123456789+-.eE", r)
+	}) < 0
+}
+
+// marshalINI 顶层基本类型写在全局区，对象写为 [section]，嵌套对象按方言写为 [a.b] 或 [a "b"]。
+// 输出固定为先键值对后子节，按同一方言读回得到相同的数据：字符串在读回会变为其他类型或丢失空白时加引号，
+// 无法表示的值（如未开启嵌套节时的嵌套对象、未开启数组键时的数组、null）报错并指出路径
+func marshalINI(doc *Document, opts *Options) ([]byte, error) {
+	root, ok := doc.Ordered(opts).(*Record)
+	if !ok {
+		return nil, fmt.Errorf("ini document root must be an object")
+	}
+	w := &iniWriter{doc: doc, dialect: opts.iniDialect(), comments: opts.comments(doc)}
+	w.delimiter = w.dialect.Delimiters[:1]
+	if w.delimiter == ":" {
+		w.delimiter = ": "
+	}
+	if c := w.comments[""]; c != nil {
+		w.writeComments(c.Head)
+	}
+	w.section(nil, "", "", root)
+	if w.err != nil {
+		return nil, w.err
+	}
+	if c := w.comments[""]; c != nil && len(c.Foot) > 0 {
+		w.buf.WriteByte('\n')
+		w.writeComments(c.Foot)
+	}
+	return bytes.TrimLeft(w.buf.Bytes(), "\n"), nil
+}
+
+type iniWriter struct {
+	buf       bytes.Buffer
+	doc       *Document
+	dialect   INIOptions
+	comments  Comments
+	delimiter string
+	err       error // 第一个无法读回的值
+}
+
+// fail 记录第一个无法按同一方言读回的值
+func (w *iniWriter) fail(path, format string, args ...any) {
+	if w.err == nil {
+		w.err = fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...))
+	}
+}
+
+func (w *iniWriter) section(names []string, pointer, path string, values *Record) {
+	var pairs, sections []string
+	for _, key := range values.Keys {
+		if _, ok := values.Values[key].(*Record); ok {
+			sections = append(sections, key)
+		} else {
+			pairs = append(pairs, key)
+		}
+	}
+	// 只含子节的节由子节的表头创建，不再输出空的 [a]
+	if len(names) > 0 && (len(pairs) > 0 || len(sections) == 0 || w.comments[pointer] != nil) {
+		w.buf.WriteByte('\n')
+		w.line("["+w.header(names, path)+"]", pointer)
+	} else if len(names) > 0 {
+		w.header(names, path)
+	}
+	for _, key := range pairs {
+		w.pair(key, childPointer(pointer, key), childPath(path, key), values.Values[key])
+	}
+	for _, key := range sections {
+		child := append(append([]string(nil), names...), key)
+		w.section(child, childPointer(pointer, key), childPath(path, key), values.Values[key].(*Record))
+	}
+}
+
+// header 表头中的节名：开启子节时最后一级写为子节，其余以 . 连接
+func (w *iniWriter) header(names []string, path string) string {
+	base := names
+	var sub string
+	subsection := w.dialect.Subsections && len(names) > 1
+	if subsection {
+		base, sub = names[:len(names)-1], names[len(names)-1]
+	}
+	readable := len(base) == 1 || w.dialect.NestedSections
+	for _, name := range base {
+		if strings.ContainsAny(name, "]\"\r\n") || w.dialect.NestedSections && strings.Contains(name, ".") ||
+			w.dialect.CaseInsensitive && name != strings.ToLower(name) || name != strings.TrimSpace(name) {
+			readable = false
+		}
+	}
+	header := strings.Join(base, ".")
+	if subsection {
+		header += ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(sub) + `"`
+		if strings.ContainsAny(sub, "\r\n") {
+			readable = false
+		}
+	}
+	if !readable {
+		w.fail(path, "section [%s] does not read back as the same structure, enable nested sections or subsections", header)
+	}
+	return header
+}
+
+// pair 写出键值对；开启数组键时数组逐个元素写为 key[]=
+func (w *iniWriter) pair(key, pointer, path string, value any) {
+	if strings.ContainsAny(key, w.dialect.Delimiters+"\r\n") || key != strings.TrimSpace(key) || key == "" ||
+		strings.IndexAny(key, "[;#") == 0 || w.dialect.CaseInsensitive && key != strings.ToLower(key) ||
+		w.dialect.ArrayKeys && strings.HasSuffix(key, "[]") {
+		w.fail(path, "key %q does not read back unchanged", key)
+	}
+	items, ok := value.([]any)
+	switch {
+	case !ok:
+		w.line(key+w.delimiter+w.value(value, path), pointer)
+	case !w.dialect.ArrayKeys:
+		w.fail(path, "array cannot be written without array keys (key[]=)")
+	case len(items) == 0:
+		w.fail(path, "empty array cannot be written as ini")
+	default:
+		if c := w.comments[pointer]; c != nil {
+			w.writeComments(c.Head)
+		}
+		for i, item := range items {
+			w.line(key+"[]"+w.delimiter+w.value(item, indexPath(path, i)), indexPointer(pointer, i))
+		}
+	}
+}
+
+// value 字符串按需加引号或续行，null 与数组中的数组、对象无法表示
+func (w *iniWriter) value(value any, path string) string {
+	switch v := value.(type) {
+	case nil:
+		w.fail(path, "null cannot be written as ini")
+		return ""
+	case string:
+		return w.quote(v, path)
+	case *Record, []any:
+		w.fail(path, "nested value in an array cannot be written as ini")
+		return ""
+	default:
+		return scalarString(v)
+	}
+}
+
+func (w *iniWriter) quote(s, path string) string {
+	if strings.ContainsAny(s, "\r\n") {
+		if w.dialect.Multiline && w.continuable(s) {
+			return strings.ReplaceAll(s, "\n", "\n    ")
+		}
+		w.fail(path, "string with line breaks does not read back unchanged, enable multiline values")
+		return ""
+	}
+	if text, ok := iniValue(s).(string); ok && text == s && s == strings.TrimSpace(s) && !w.commentLike(s) {
+		return s
+	}
+	if strings.Contains(s, `"`) && !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+// continuable 多行字符串可以写为续行：各行非空、首尾没有空白且不会被识别为注释或其他类型
+func (w *iniWriter) continuable(s string) bool {
+	if _, ok := iniValue(s).(string); !ok || strings.Contains(s, "\r") {
+		return false
+	}
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" || line != strings.TrimSpace(line) || w.commentLike(line) || line[0] == ';' || line[0] == '#' {
+			return false
+		}
+	}
+	return s[0] != '"' && s[0] != '\''
+}
+
+// commentLike 开启行尾注释时值中含有会被识别为注释的 ; 或 #
+func (w *iniWriter) commentLike(s string) bool {
+	if !w.dialect.InlineComments {
+		return false
+	}
+	return strings.IndexAny(s, ";#") == 0 || strings.Contains(s, " ;") || strings.Contains(s, " #") ||
+		strings.Contains(s, "\t;") || strings.Contains(s, "\t#")
+}
+
+// line 写出一行及其注释，未开启行尾注释时行尾注释写在前一行
+func (w *iniWriter) line(text, pointer string) {
+	c := w.comments[pointer]
+	if c == nil {
+		w.buf.WriteString(text + "\n")
+		return
+	}
+	w.writeComments(c.Head)
+	if c.Line != "" && !w.dialect.InlineComments {
+		w.writeComments([]string{c.Line})
+	}
+	w.buf.WriteString(text)
+	if c.Line != "" && w.dialect.InlineComments {
+		w.buf.WriteString(" ; " + c.Line)
+	}
+	w.buf.WriteByte('\n')
+}
+
+func (w *iniWriter) writeComments(lines []string) {
+	for _, line := range lines {
+		w.buf.WriteString("; " + line + "\n")
+	}
+}
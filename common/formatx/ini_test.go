@@ -0,0 +1,87 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+)
+
+// 各方言输出后按同一方言读回，数据不变
+func TestINIDialectRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect INIOptions
+		input   string
+	}{
+		{"basic", INIOptions{}, `{"a": 1, "s": {"b": "text", "c": "true", "d": " padded ", "e": ""}}`},
+		{"nested", INIOptions{NestedSections: true, ArrayKeys: true}, `{"a": {"b": {"c": 1, "d": [1, "x"]}}, "e": {}}`},
+		{"subsections", INIOptions{Subsections: true}, `{"remote": {"origin": {"url": "git@x:y.git"}, "name": "r"}}`},
+		{"multiline", INIOptions{Multiline: true, Delimiters: ":="}, `{"s": {"text": "line1\nline2"}}`},
+		{"case insensitive", INIOptions{CaseInsensitive: true, InlineComments: true}, `{"s": {"k": "a ; b"}}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, err := Parse(FormatJSON, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			opts := &Options{INI: c.dialect}
+			out, err := Marshal(FormatINI, src, opts)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			doc, err := Parse(FormatINI, out, opts)
+			if err != nil {
+				t.Fatalf("parse output: %v\n%s", err, out)
+			}
+			if got, want := canonical(t, doc), canonical(t, src); got != want {
+				t.Errorf("round trip changed data\n got: %s\nwant: %s\noutput:\n%s", got, want, out)
+			}
+		})
+	}
+}
+
+// 只含子节的节不再输出空的 [a]
+func TestININestedSectionsSkipEmptyParent(t *testing.T) {
+	src, err := Parse(FormatJSON, []byte(`{"a": {"b": {"c": 1}}}`), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out, err := Marshal(FormatINI, src, &Options{INI: INIOptions{NestedSections: true}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(out) != "[a.b]\nc=1\n" {
+		t.Errorf("got %q", out)
+	}
+}
+
+// 按所选方言无法读回为相同数据的值报错并指出路径
+func TestINIIrreversible(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect INIOptions
+		input   string
+		path    string
+	}{
+		{"nested object", INIOptions{}, `{"a": {"b": {"c": 1}}}`, "a.b"},
+		{"array", INIOptions{}, `{"a": [1, 2]}`, "a"},
+		{"empty array", INIOptions{ArrayKeys: true}, `{"a": []}`, "a"},
+		{"null", INIOptions{}, `{"a": null}`, "a"},
+		{"nested array", INIOptions{ArrayKeys: true}, `{"a": [[1]]}`, "a[0]"},
+		{"line break", INIOptions{}, `{"a": "x\ny"}`, "a"},
+		{"key with delimiter", INIOptions{}, `{"a=b": 1}`, "a=b"},
+		{"dotted section", INIOptions{NestedSections: true}, `{"a.b": {"c": 1}}`, "a.b"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, err := Parse(FormatJSON, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(FormatINI, src, &Options{INI: c.dialect})
+			if err == nil || !strings.Contains(err.Error(), c.path+":") {
+				t.Errorf("expected error for path %s, got %v\n%s", c.path, err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// parseJSON 解析 JSON，允许 // 与 /* */ 注释，注释按位置附着到节点
+func parseJSON(doc *Document, data []byte, opts *Options) error {
+	cleaned, comments := stripJSONComments(data)
+	if n := len(comments); n > 0 && comments[n-1].unterminated {
+		return fmt.Errorf("line %d: unterminated block comment, expected */", comments[n-1].line)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(cleaned))
+	decoder.UseNumber()
+	lines := newLineIndex(cleaned, 1)
+	values := newJSONValueDecoder(decoder, doc, opts.duplicateKeys(FormatJSON), lines)
+	values.comments = newJSONComments(comments, lines)
+	value, err := values.decode("", "")
+	if err != nil {
+		return jsonPositionError(cleaned, decoder, err)
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return jsonPositionError(cleaned, decoder, errors.New("unexpected data after top-level value"))
+	}
+	values.comments.before(doc, int64(len(cleaned))+1)
+	values.comments.foot(doc, "")
+	doc.Value = value
+	return nil
+}
+
+// jsonValueDecoder 使用 Token 接口逐个解码，记录对象键的顺序，数字保留原始字面量，按策略处理重复键
+type jsonValueDecoder struct {
+	decoder  *json.Decoder
+	doc      *Document
+	policy   DuplicateKeyPolicy
+	lines    *lineIndex    // 为 nil 时重复键以字节偏移定位
+	comments *jsonComments // 为 nil 时不记录注释
+}
+
+func newJSONValueDecoder(decoder *json.Decoder, doc *Document, policy DuplicateKeyPolicy, lines *lineIndex) *jsonValueDecoder {
+	return &jsonValueDecoder{decoder: decoder, doc: doc, policy: policy, lines: lines}
+}
+
+func (j *jsonValueDecoder) decode(pointer, path string) (any, error) {
+	token, err := j.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	j.comments.before(j.doc, j.decoder.InputOffset())
+	j.comments.head(j.doc, pointer)
+	delim, ok := token.(json.Delim)
+	if !ok {
+		j.comments.end(pointer, j.decoder.InputOffset())
+		return token, nil
+	}
+	j.comments.end(pointer, j.decoder.InputOffset())
+	switch delim {
+	case '{':
+		obj := make(map[string]any)
+		var keys []string
+		seen := newKeySet(j.doc, j.policy)
+		for j.decoder.More() {
+			token, err = j.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := token.(string)
+			pos := j.lines.pos(j.decoder.InputOffset())
+			j.comments.before(j.doc, j.decoder.InputOffset())
+			doc, target := j.doc, seen.target(key)
+			j.doc = target
+			j.comments.head(j.doc, childPointer(pointer, key))
+			value, err := j.decode(childPointer(pointer, key), childPath(path, key))
+			j.doc = doc
+			if err != nil {
+				return nil, err
+			}
+			old, exists := obj[key]
+			if !exists {
+				keys = append(keys, key)
+			}
+			if obj[key], err = seen.add(pointer, path, key, pos, old, value, target); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = j.decoder.Token(); err != nil {
+			return nil, err
+		}
+		j.closeComments(pointer)
+		if len(keys) > 0 {
+			j.doc.SetKeyOrder(pointer, keys)
+		}
+		return obj, nil
+	case '[':
+		items := make([]any, 0)
+		for j.decoder.More() {
+			item, err := j.decode(indexPointer(pointer, len(items)), indexPath(path, len(items)))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if _, err = j.decoder.Token(); err != nil {
+			return nil, err
+		}
+		j.closeComments(pointer)
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", rune(delim))
+	}
+}
+
+// closeComments 容器结束时，结束符之前剩余的注释作为末尾注释
+func (j *jsonValueDecoder) closeComments(pointer string) {
+	j.comments.before(j.doc, j.decoder.InputOffset()-1)
+	j.comments.foot(j.doc, pointer)
+	j.comments.end(pointer, j.decoder.InputOffset())
+}
+
+// marshalJSON 输出严格的 RFC 8259 JSON，不含注释；需要保留注释时输出 jsonc
+func marshalJSON(doc *Document, opts *Options) ([]byte, error) {
+	if opts != nil && opts.Canonical {
+		// JCS 的数字统一按 float64 输出，超出精度的字面量会被舍入
+		doc.Warnings = append(doc.Warnings, PrecisionWarnings(doc.Value, func(literal string) bool {
+			return !Float64Exact(literal)
+		}, "number %s rounded to float64 in canonical json")...)
+		return CanonicalJSON(doc.Value)
+	}
+	return encodeJSON(doc.Ordered(opts), strings.Repeat(" ", opts.indent()))
+}
+
+// marshalJSONC 输出带 // 注释的 JSON，没有注释或 DropComments 时与 json 相同
+func marshalJSONC(doc *Document, opts *Options) ([]byte, error) {
+	if comments := opts.comments(doc); len(comments) > 0 && (opts == nil || !opts.Canonical) {
+		return encodeJSONC(doc.Ordered(opts), comments, strings.Repeat(" ", opts.indent()))
+	}
+	return marshalJSON(doc, opts)
+}
+
+// encodeJSONC 输出带 // 注释的 JSON：前置注释与末尾注释独占一行，行尾注释放在逗号之后，
+// 对象与数组的行尾注释放在起始括号之后
+func encodeJSONC(value any, comments Comments, indent string) ([]byte, error) {
+	w := &jsoncWriter{comments: comments, indent: indent}
+	return w.encode(value)
+}
+
+type jsoncWriter struct {
+	buf      bytes.Buffer
+	comments Comments
+	indent   string
+	json5    bool // $numberDouble 注解输出为 JSON5 的 NaN 与 Infinity
+}
+
+func (w *jsoncWriter) encode(value any) ([]byte, error) {
+	c := w.comments[""]
+	if c != nil {
+		for _, line := range c.Head {
+			w.buf.WriteString("// " + line + "\n")
+		}
+	}
+	if err := w.value(value, "", 0); err != nil {
+		return nil, err
+	}
+	if c != nil && !w.isContainer(value) {
+		if c.Line != "" {
+			w.buf.WriteString(" // " + c.Line)
+		}
+		for _, line := range c.Foot {
+			w.buf.WriteString("\n// " + line)
+		}
+	}
+	return w.buf.Bytes(), nil
+}
+
+// isContainer 非空的对象与数组逐个成员换行输出
+func (w *jsoncWriter) isContainer(value any) bool {
+	if _, ok := json5Special(value); ok && w.json5 {
+		return false
+	}
+	switch v := value.(type) {
+	case *Record:
+		return len(v.Keys) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+func (w *jsoncWriter) newline(depth int) {
+	w.buf.WriteByte('\n')
+	w.buf.WriteString(strings.Repeat(w.indent, depth))
+}
+
+func (w *jsoncWriter) value(value any, pointer string, depth int) error {
+	var keys []string
+	var items []any
+	switch v := value.(type) {
+	case *Record:
+		keys = v.Keys
+	case []any:
+		items = v
+	}
+	if text, ok := json5Special(value); ok && w.json5 {
+		w.buf.WriteString(text)
+		return nil
+	}
+	if !w.isContainer(value) {
+		data, err := encodeJSON(value, "")
+		if err != nil {
+			return err
+		}
+		w.buf.Write(data)
+		return nil
+	}
+	open, close, n := byte('['), byte(']'), len(items)
+	if keys != nil {
+		open, close, n = '{', '}', len(keys)
+	}
+	w.buf.WriteByte(open)
+	c := w.comments[pointer]
+	if c != nil && c.Line != "" {
+		w.buf.WriteString(" // " + c.Line)
+	}
+	for i := 0; i < n; i++ {
+		var child string
+		var item any
+		if keys != nil {
+			child, item = childPointer(pointer, keys[i]), value.(*Record).Values[keys[i]]
+		} else {
+			child, item = indexPointer(pointer, i), items[i]
+		}
+		cc := w.comments[child]
+		if cc != nil {
+			for _, line := range cc.Head {
+				w.newline(depth + 1)
+				w.buf.WriteString("// " + line)
+			}
+		}
+		w.newline(depth + 1)
+		if keys != nil {
+			key, err := encodeJSON(keys[i], "")
+			if err != nil {
+				return err
+			}
+			w.buf.Write(key)
+			w.buf.WriteString(": ")
+		}
+		if err := w.value(item, child, depth+1); err != nil {
+			return err
+		}
+		if i < n-1 {
+			w.buf.WriteByte(',')
+		}
+		if cc != nil && cc.Line != "" && !w.isContainer(item) {
+			w.buf.WriteString(" // " + cc.Line)
+		}
+	}
+	if c != nil {
+		for _, line := range c.Foot {
+			w.newline(depth + 1)
+			w.buf.WriteString("// " + line)
+		}
+	}
+	w.newline(depth)
+	w.buf.WriteByte(close)
+	return nil
+}
+
+// encodeJSON 序列化 JSON，不转义 HTML 字符
+func encodeJSON(value any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent != "" {
+		encoder.SetIndent("", indent)
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// jsonPositionError 为 JSON 错误补充行列号，格式与前端错误定位的正则一致
+func jsonPositionError(data []byte, decoder *json.Decoder, err error) error {
+	var duplicateErr *duplicateKeyError
+	if errors.As(err, &duplicateErr) {
+		return err
+	}
+	offset := decoder.InputOffset()
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		offset = int64(len(data))
+	}
+	line, column := position(data, offset)
+	return fmt.Errorf("line %d column %d: %w", line, column, err)
+}
+
+// position 根据字节偏移计算行列号（从 1 开始）
+func position(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, column = 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return line, column
+}
+
+// StripJSONComments 将字符串外的 // 与 /* */ 注释替换为空白，保留换行，使错误位置与原文一致
+func StripJSONComments(data []byte) []byte {
+	out, _ := stripJSONComments(data)
+	return out
+}
+
+// jsonComment 原文中的一段注释
+type jsonComment struct {
+	offset       int64    // 注释起始的字节偏移
+	line         int      // 注释起始的行号
+	ownLine      bool     // 注释之前没有其他内容
+	unterminated bool     // /* 注释直到文本末尾都没有 */
+	text         []string // 去掉注释符后的各行
+}
+
+// stripJSONComments 替换注释为空白并返回按出现顺序排列的注释
+func stripJSONComments(data []byte) ([]byte, []jsonComment) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	var comments []jsonComment
+	line, lineHasCode := 1, false
+	inString, escaped := false, false
+	for i := 0; i < len(out); i++ {
+		ch := out[i]
+		if ch == '\n' {
+			line++
+			lineHasCode = false
+		}
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		if ch == '"' {
+			inString, lineHasCode = true, true
+			continue
+		}
+		if ch != '/' || i+1 >= len(out) || (out[i+1] != '/' && out[i+1] != '*') {
+			if ch != ' ' && ch != '\t' && ch != '\r' && ch != '\n' {
+				lineHasCode = true
+			}
+			continue
+		}
+		comment := jsonComment{offset: int64(i), line: line, ownLine: !lineHasCode}
+		start := i
+		switch out[i+1] {
+		case '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+			comment.text = commentLines(string(data[start:i]))
+			i--
+		case '*':
+			out[i], out[i+1] = ' ', ' '
+			comment.unterminated = true
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					comment.unterminated = false
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] == '\n' {
+					line++
+					lineHasCode = false
+				} else {
+					out[i] = ' '
+				}
+			}
+			end := min(i+1, len(data))
+			comment.text = commentLines(strings.TrimSuffix(string(data[start+2:end]), "*/"))
+		}
+		comments = append(comments, comment)
+	}
+	return out, comments
+}
+
+// jsonComments 解析时按位置将注释附着到节点：与上一个结束的节点同一行的注释为其行尾注释，
+// 其余注释作为下一个节点的前置注释，对象或数组末尾剩余的注释作为容器的末尾注释
+type jsonComments struct {
+	list     []jsonComment
+	next     int
+	pending  []string
+	last     string // 最近结束的节点
+	lastLine int    // 最近结束的节点所在行，0 表示没有
+	lines    *lineIndex
+}
+
+func newJSONComments(list []jsonComment, lines *lineIndex) *jsonComments {
+	if len(list) == 0 {
+		return nil
+	}
+	return &jsonComments{list: list, lines: lines}
+}
+
+// before 处理 offset 之前的注释，nil 表示不记录注释
+func (c *jsonComments) before(doc *Document, offset int64) {
+	if c == nil {
+		return
+	}
+	for ; c.next < len(c.list) && c.list[c.next].offset < offset; c.next++ {
+		comment := c.list[c.next]
+		if !comment.ownLine && comment.line == c.lastLine {
+			doc.AddLineComment(c.last, strings.Join(comment.text, " "))
+			continue
+		}
+		c.pending = append(c.pending, comment.text...)
+	}
+}
+
+// head 暂存的注释作为 pointer 处节点的前置注释
+func (c *jsonComments) head(doc *Document, pointer string) {
+	if c != nil {
+		doc.AddHeadComment(pointer, c.pending...)
+		c.pending = nil
+	}
+}
+
+// foot 暂存的注释作为 pointer 处容器的末尾注释
+func (c *jsonComments) foot(doc *Document, pointer string) {
+	if c != nil {
+		doc.AddFootComment(pointer, c.pending...)
+		c.pending = nil
+	}
+}
+
+// end 记录 pointer 处节点结束于 offset 所在行
+func (c *jsonComments) end(pointer string, offset int64) {
+	if c != nil {
+		c.last, c.lastLine = pointer, c.lines.pos(offset).line
+	}
+}
+
+var jsonFieldPattern = regexp.MustCompile(`"([^"]+)"\s*:`)
+
+// ExtractJSONComments 提取 // 行注释作为字段注释：独占一行的注释归属于下一个字段，行尾注释归属于本行字段
+func ExtractJSONComments(content string) map[string]string {
+	comments := make(map[string]string)
+	var pending []string
+	for _, line := range strings.Split(content, "\n") {
+		code, comment := splitLineComment(line)
+		if match := jsonFieldPattern.FindStringSubmatch(code); match != nil {
+			if comment != "" {
+				comments[match[1]] = comment
+			} else if len(pending) > 0 {
+				comments[match[1]] = strings.Join(pending, " ")
+			}
+			pending = nil
+			continue
+		}
+		switch {
+		case strings.TrimSpace(code) != "":
+			pending = nil
+		case comment != "":
+			pending = append(pending, comment)
+		}
+	}
+	return comments
+}
+
+// splitLineComment 拆分行内代码与字符串外的 // 注释
+func splitLineComment(line string) (code, comment string) {
+	inString, escaped := false, false
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == '"':
+			inString = !inString
+		case !inString && ch == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i], strings.TrimSpace(line[i+2:])
+		}
+	}
+	return line, ""
+}
@@ -0,0 +1,584 @@
+package formatx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// parseJSON5 按 JSON5 规范（https://spec.json5.org）严格解析：键可以不加引号，字符串可以使用单引号与续行，
+// 数字支持十六进制、前后小数点、正号、Infinity 与 NaN，允许尾随逗号与注释。
+// 十六进制转为十进制并记录提示，Infinity 与 NaN 转为 $numberDouble 注解，输出为不支持它们的格式时记录提示
+func parseJSON5(doc *Document, data []byte, opts *Options) error {
+	return newJSON5Parser(doc, data, opts.duplicateKeys(FormatJSON5), false).parse()
+}
+
+// marshalJSON5 输出 JSON 并保留注释，$numberDouble 注解还原为 NaN 与 Infinity 字面量
+func marshalJSON5(doc *Document, opts *Options) ([]byte, error) {
+	w := &jsoncWriter{comments: opts.comments(doc), indent: strings.Repeat(" ", opts.indent()), json5: true}
+	return w.encode(doc.Ordered(opts))
+}
+
+// json5Parser JSON5 与 HJSON 的递归下降解析器，数字保留字面量，记录键顺序与注释，按策略处理重复键
+type json5Parser struct {
+	data     []byte
+	pos      int
+	doc      *Document
+	policy   DuplicateKeyPolicy
+	hjson    bool // 按 HJSON 语法解析
+	lines    *lineIndex
+	comments *jsonComments
+}
+
+func newJSON5Parser(doc *Document, data []byte, policy DuplicateKeyPolicy, hjson bool) *json5Parser {
+	data = []byte(strings.TrimPrefix(string(data), "\ufeff"))
+	lines := newLineIndex(data, 1)
+	return &json5Parser{
+		data:     data,
+		doc:      doc,
+		policy:   policy,
+		hjson:    hjson,
+		lines:    lines,
+		comments: &jsonComments{lines: lines},
+	}
+}
+
+func (p *json5Parser) parse() error {
+	if _, err := p.skip(); err != nil {
+		return err
+	}
+	if p.pos >= len(p.data) {
+		return p.errorf(p.pos, "unexpected end of input")
+	}
+	var value any
+	var err error
+	if p.hjson && p.bracelessRoot() {
+		value, err = p.object("", "", false)
+	} else {
+		value, err = p.value("", "")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = p.skip(); err != nil {
+		return err
+	}
+	if p.pos < len(p.data) {
+		return p.errorf(p.pos, "unexpected data after top-level value")
+	}
+	p.comments.before(p.doc, int64(len(p.data))+1)
+	p.comments.foot(p.doc, "")
+	p.doc.Value = value
+	return nil
+}
+
+// errorf 错误信息带上行列号，格式与 JSON 的错误一致
+func (p *json5Parser) errorf(offset int, format string, args ...any) error {
+	line, column := position(p.data, int64(offset))
+	return fmt.Errorf("line %d column %d: %s", line, column, fmt.Sprintf(format, args...))
+}
+
+func (p *json5Parser) peek(n int) byte {
+	if p.pos+n < len(p.data) {
+		return p.data[p.pos+n]
+	}
+	return 0
+}
+
+// skip 跳过空白与注释并记录注释，返回是否跨过了换行
+func (p *json5Parser) skip() (bool, error) {
+	newline := false
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		switch {
+		case r == '/' && p.peek(1) == '/', r == '#' && p.hjson:
+			start := p.pos
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+			p.addComment(start, string(p.data[start:p.pos]))
+		case r == '/' && p.peek(1) == '*':
+			start := p.pos
+			end := strings.Index(string(p.data[p.pos+2:]), "*/")
+			if end < 0 {
+				return newline, p.errorf(start, "unterminated block comment")
+			}
+			p.pos += 2 + end + 2
+			p.addComment(start, string(p.data[start+2:p.pos-2]))
+		case r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029':
+			newline = true
+			p.pos += size
+		case isJSON5Space(r):
+			p.pos += size
+		default:
+			return newline, nil
+		}
+	}
+	return newline, nil
+}
+
+// isJSON5Space JSON5 的空白字符，包括 Unicode Zs 类别与 BOM
+func isJSON5Space(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', ' ', '\u00a0', '\ufeff':
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+func (p *json5Parser) addComment(start int, text string) {
+	lineStart := strings.LastIndexByte(string(p.data[:start]), '\n') + 1
+	p.comments.list = append(p.comments.list, jsonComment{
+		offset:  int64(start),
+		line:    p.lines.pos(int64(start)).line,
+		ownLine: strings.TrimSpace(string(p.data[lineStart:start])) == "",
+		text:    commentLines(text),
+	})
+}
+
+func (p *json5Parser) value(pointer, path string) (any, error) {
+	if _, err := p.skip(); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.data) {
+		return nil, p.errorf(p.pos, "unexpected end of input")
+	}
+	p.comments.before(p.doc, int64(p.pos)+1)
+	p.comments.head(p.doc, pointer)
+	switch p.data[p.pos] {
+	case '{':
+		p.pos++
+		p.comments.end(pointer, int64(p.pos))
+		return p.object(pointer, path, true)
+	case '[':
+		p.pos++
+		p.comments.end(pointer, int64(p.pos))
+		return p.array(pointer, path)
+	}
+	value, err := p.scalar(path)
+	if err != nil {
+		return nil, err
+	}
+	p.comments.end(pointer, int64(p.pos))
+	return value, nil
+}
+
+// object 解析对象成员直到 }，braces 为 false 时为 HJSON 省略括号的根对象，直到输入结束
+func (p *json5Parser) object(pointer, path string, braces bool) (any, error) {
+	obj := make(map[string]any)
+	var keys []string
+	seen := newKeySet(p.doc, p.policy)
+	for {
+		if _, err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			if braces {
+				return nil, p.errorf(p.pos, "unexpected end of input, expected '}'")
+			}
+			break
+		}
+		if braces && p.data[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		keyStart := p.pos
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		p.comments.before(p.doc, int64(p.pos))
+		if _, err = p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorf(p.pos, "expected ':' after key %q", key)
+		}
+		p.pos++
+		doc, target := p.doc, seen.target(key)
+		p.doc = target
+		p.comments.head(p.doc, childPointer(pointer, key))
+		value, err := p.value(childPointer(pointer, key), childPath(path, key))
+		p.doc = doc
+		if err != nil {
+			return nil, err
+		}
+		old, exists := obj[key]
+		if !exists {
+			keys = append(keys, key)
+		}
+		pos := p.lines.pos(int64(keyStart))
+		if obj[key], err = seen.add(pointer, path, key, pos, old, value, target); err != nil {
+			return nil, err
+		}
+		if err = p.separator('}', braces); err != nil {
+			return nil, err
+		}
+	}
+	p.comments.before(p.doc, int64(p.pos)-1)
+	p.comments.foot(p.doc, pointer)
+	p.comments.end(pointer, int64(p.pos))
+	if len(keys) > 0 {
+		p.doc.SetKeyOrder(pointer, keys)
+	}
+	return obj, nil
+}
+
+func (p *json5Parser) array(pointer, path string) (any, error) {
+	items := make([]any, 0)
+	for {
+		if _, err := p.skip(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf(p.pos, "unexpected end of input, expected ']'")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			break
+		}
+		item, err := p.value(indexPointer(pointer, len(items)), indexPath(path, len(items)))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if err = p.separator(']', true); err != nil {
+			return nil, err
+		}
+	}
+	p.comments.before(p.doc, int64(p.pos)-1)
+	p.comments.foot(p.doc, pointer)
+	p.comments.end(pointer, int64(p.pos))
+	return items, nil
+}
+
+// separator 成员之间的逗号，允许尾随逗号；HJSON 也可以用换行分隔
+func (p *json5Parser) separator(closing byte, braces bool) error {
+	newline, err := p.skip()
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == ',':
+		p.pos++
+		return nil
+	case p.pos < len(p.data) && p.data[p.pos] == closing && braces:
+		return nil
+	case p.pos >= len(p.data) && !braces:
+		return nil
+	case p.hjson && newline:
+		return nil
+	case p.hjson:
+		return p.errorf(p.pos, "expected ',', newline or '%c'", closing)
+	default:
+		return p.errorf(p.pos, "expected ',' or '%c'", closing)
+	}
+}
+
+func (p *json5Parser) key() (string, error) {
+	switch ch := p.data[p.pos]; {
+	case ch == '"', ch == '\'' && (!p.hjson || !p.multiline()):
+		return p.quoted(ch)
+	case p.hjson:
+		return p.quotelessKey()
+	default:
+		return p.identifier()
+	}
+}
+
+// identifier ECMAScript 5.1 的 IdentifierName，可以包含 \uXXXX 转义
+func (p *json5Parser) identifier() (string, error) {
+	var b strings.Builder
+	start := p.pos
+	for p.pos < len(p.data) {
+		at := p.pos
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r == '\\' {
+			if p.peek(1) != 'u' {
+				return "", p.errorf(at, "invalid escape in identifier")
+			}
+			p.pos += 2
+			var err error
+			if r, err = p.hex4(); err != nil {
+				return "", err
+			}
+		} else {
+			p.pos += size
+		}
+		if !isIdentifierRune(r, b.Len() == 0) {
+			if b.Len() == 0 {
+				return "", p.errorf(at, "invalid character %q, expected key", r)
+			}
+			p.pos = at
+			break
+		}
+		b.WriteRune(r)
+	}
+	if p.pos == start {
+		return "", p.errorf(start, "unexpected end of input, expected key")
+	}
+	return b.String(), nil
+}
+
+func isIdentifierRune(r rune, first bool) bool {
+	switch {
+	case r == '$', r == '_', unicode.IsLetter(r), unicode.Is(unicode.Nl, r):
+		return true
+	case first:
+		return false
+	case r == '\u200c', r == '\u200d':
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+// quoted 单引号或双引号字符串
+func (p *json5Parser) quoted(quote byte) (string, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf(start, "unterminated string")
+		}
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		switch {
+		case r == rune(quote):
+			p.pos++
+			return b.String(), nil
+		case r == '\n' || r == '\r':
+			return "", p.errorf(p.pos, "unescaped line break in string")
+		case r == '\\':
+			if err := p.escape(&b, quote); err != nil {
+				return "", err
+			}
+		case r == utf8.RuneError && size == 1:
+			return "", p.errorf(p.pos, "invalid character %q in string", r)
+		default:
+			b.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// escape 解析转义序列：JSON5 支持 \v、\0、\xHH、续行与任意字符的自身转义，HJSON 与 JSON 相同并允许 \'
+func (p *json5Parser) escape(b *strings.Builder, quote byte) error {
+	at := p.pos
+	p.pos++
+	if p.pos >= len(p.data) {
+		return p.errorf(at, "unterminated string")
+	}
+	r, size := utf8.DecodeRune(p.data[p.pos:])
+	p.pos += size
+	switch r {
+	case '"', '\\', '/':
+		b.WriteRune(r)
+	case '\'':
+		if p.hjson && quote != '\'' {
+			return p.errorf(at, "invalid escape \\'")
+		}
+		b.WriteRune(r)
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		r, err := p.hex4()
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) && p.peek(0) == '\\' && p.peek(1) == 'u' {
+			save := p.pos
+			p.pos += 2
+			if low, err := p.hex4(); err == nil && utf16.DecodeRune(r, low) != unicode.ReplacementChar {
+				r = utf16.DecodeRune(r, low)
+			} else {
+				p.pos = save
+			}
+		}
+		b.WriteRune(r)
+	default:
+		if p.hjson {
+			return p.errorf(at, "invalid escape \\%c", r)
+		}
+		return p.json5Escape(b, at, r)
+	}
+	return nil
+}
+
+func (p *json5Parser) json5Escape(b *strings.Builder, at int, r rune) error {
+	switch {
+	case r == 'v':
+		b.WriteByte('\v')
+	case r == '0':
+		if p.peek(0) >= '0' && p.peek(0) <= '9' {
+			return p.errorf(at, "invalid escape \\0 followed by a digit")
+		}
+		b.WriteByte(0)
+	case r == 'x':
+		if p.pos+2 > len(p.data) {
+			return p.errorf(at, "invalid \\x escape")
+		}
+		n, err := strconv.ParseUint(string(p.data[p.pos:p.pos+2]), 16, 8)
+		if err != nil {
+			return p.errorf(at, "invalid \\x escape")
+		}
+		p.pos += 2
+		b.WriteRune(rune(n))
+	case r == '\r':
+		// 续行：反斜杠后的换行不计入字符串
+		if p.peek(0) == '\n' {
+			p.pos++
+		}
+	case r == '\n', r == '\u2028', r == '\u2029':
+	case r >= '1' && r <= '9':
+		return p.errorf(at, "invalid escape \\%c", r)
+	default:
+		b.WriteRune(r)
+	}
+	return nil
+}
+
+func (p *json5Parser) hex4() (rune, error) {
+	if p.pos+4 > len(p.data) {
+		return 0, p.errorf(p.pos, "invalid \\u escape")
+	}
+	n, err := strconv.ParseUint(string(p.data[p.pos:p.pos+4]), 16, 16)
+	if err != nil {
+		return 0, p.errorf(p.pos, "invalid \\u escape")
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+func (p *json5Parser) scalar(path string) (any, error) {
+	if p.hjson {
+		return p.hjsonScalar()
+	}
+	switch ch := p.data[p.pos]; {
+	case ch == '"' || ch == '\'':
+		return p.quoted(ch)
+	case ch == '+' || ch == '-' || ch == '.' || ch >= '0' && ch <= '9' || ch == 'I' || ch == 'N':
+		return p.number(path)
+	}
+	for _, literal := range []struct {
+		text  string
+		value any
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if strings.HasPrefix(string(p.data[p.pos:]), literal.text) {
+			p.pos += len(literal.text)
+			return literal.value, nil
+		}
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return nil, p.errorf(p.pos, "invalid character %q, expected value", r)
+}
+
+// number JSON5 数字，十进制数字规范为 JSON 数字字面量
+func (p *json5Parser) number(path string) (any, error) {
+	start := p.pos
+	sign := ""
+	if ch := p.data[p.pos]; ch == '+' || ch == '-' {
+		if ch == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+	rest := string(p.data[p.pos:])
+	switch {
+	case strings.HasPrefix(rest, "Infinity"):
+		p.pos += len("Infinity")
+		return floatValue(math.Inf(map[string]int{"": 1, "-": -1}[sign])), nil
+	case strings.HasPrefix(rest, "NaN"):
+		p.pos += len("NaN")
+		return floatValue(math.NaN()), nil
+	case strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X"):
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.data) && isHexDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == digits {
+			return nil, p.errorf(start, "invalid hexadecimal number")
+		}
+		n, _ := new(big.Int).SetString(string(p.data[digits:p.pos]), 16)
+		literal := sign + n.String()
+		if literal == "-0" {
+			literal = "0"
+		}
+		p.doc.Warn(p.lines.pos(int64(start)).line, path, "hexadecimal number %s converted to %s", p.data[start:p.pos], literal)
+		return json.Number(literal), nil
+	}
+	intPart := p.digits()
+	if len(intPart) > 1 && intPart[0] == '0' {
+		return nil, p.errorf(start, "invalid number with leading zero")
+	}
+	var fraction string
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		fraction = p.digits()
+	}
+	if intPart == "" && fraction == "" {
+		return nil, p.errorf(start, "invalid number")
+	}
+	var exponent string
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		expSign := ""
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			expSign = string(p.data[p.pos])
+			p.pos++
+		}
+		digits := p.digits()
+		if digits == "" {
+			return nil, p.errorf(start, "invalid number exponent")
+		}
+		exponent = "e" + expSign + digits
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	literal := sign + intPart
+	if fraction != "" {
+		literal += "." + fraction
+	}
+	return json.Number(literal + exponent), nil
+}
+
+func (p *json5Parser) digits() string {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+func isHexDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F'
+}
+
+// json5Special JSON5 输出时 $numberDouble 注解还原为 NaN 与 Infinity 字面量
+func json5Special(value any) (string, bool) {
+	record, ok := value.(*Record)
+	if !ok || len(record.Keys) != 1 || record.Keys[0] != annotationDouble {
+		return "", false
+	}
+	text, _ := record.Values[annotationDouble].(string)
+	switch text {
+	case "NaN", "Infinity", "-Infinity":
+		return text, true
+	}
+	return "", false
+}
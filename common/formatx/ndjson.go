@@ -0,0 +1,153 @@
+package formatx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LineError NDJSON 行解析错误
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	var duplicateErr *duplicateKeyError
+	if errors.As(e.Err, &duplicateErr) {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONReader 逐行读取 NDJSON（JSON Lines），不会一次性加载整个输入
+type NDJSONReader struct {
+	reader     *bufio.Reader
+	mode       LineErrorMode
+	duplicates DuplicateKeyPolicy
+	line       int
+	skipped    int
+	warnings   []Warning
+	duplicated duplicateWarnings
+}
+
+// NewNDJSONReader 创建 NDJSON 读取器，mode 为 skip 时错误行被跳过并记录到 Warnings
+func NewNDJSONReader(r io.Reader, mode LineErrorMode) *NDJSONReader {
+	return &NDJSONReader{reader: bufio.NewReaderSize(r, 64*1024), mode: mode}
+}
+
+// Next 读取下一条记录，对象以 *Record 返回并保持键顺序，读取完毕时返回 io.EOF，行解析失败时返回 *LineError
+func (r *NDJSONReader) Next() (any, error) {
+	for {
+		raw, err := r.reader.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		r.line++
+		if r.line == 1 {
+			raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		value, warnings, decodeErr := decodeLine(raw, r.line, r.duplicates)
+		if decodeErr == nil {
+			r.duplicated.add(warnings)
+			return value, nil
+		}
+		lineErr := &LineError{Line: r.line, Err: decodeErr}
+		if r.mode != LineErrorSkip {
+			return nil, lineErr
+		}
+		r.skipped++
+		if len(r.warnings) < maxStreamWarnings {
+			r.warnings = append(r.warnings, Warning{Line: r.line, Message: "skipped invalid line: " + decodeErr.Error()})
+		}
+	}
+}
+
+// Line 当前已读取的行号
+func (r *NDJSONReader) Line() int {
+	return r.line
+}
+
+// Skipped 被跳过的错误行数
+func (r *NDJSONReader) Skipped() int {
+	return r.skipped
+}
+
+// Warnings 被跳过的错误行与重复键，超出上限的部分合并为一条
+func (r *NDJSONReader) Warnings() []Warning {
+	warnings := r.warnings
+	if r.skipped > len(r.warnings) {
+		warnings = append(warnings, Warning{Message: fmt.Sprintf("%d more invalid lines skipped", r.skipped-len(r.warnings))})
+	}
+	return append(warnings, r.duplicated.result()...)
+}
+
+func decodeLine(raw []byte, line int, duplicates DuplicateKeyPolicy) (any, []Warning, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	doc := &Document{}
+	value, err := newJSONValueDecoder(decoder, doc, duplicates, newLineIndex(raw, line)).decode("", "")
+	if errors.Is(err, io.EOF) {
+		// 行内容不完整，不能让调用方误认为输入已读完
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("more than one value in a line")
+	}
+	return doc.ordered(value, "", nil), doc.Warnings, nil
+}
+
+// parseNDJSON 每行一条记录，解析结果为数组
+func parseNDJSON(doc *Document, data []byte, opts *Options) error {
+	reader := NewNDJSONReader(bytes.NewReader(data), opts.lineErrorMode())
+	reader.duplicates = opts.duplicateKeys(FormatNDJSON)
+	values := make([]any, 0)
+	for {
+		value, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+	doc.SetOrdered(values)
+	doc.Warnings = append(doc.Warnings, reader.Warnings()...)
+	return nil
+}
+
+// marshalNDJSON 数组的每个元素输出为一行，非数组输出为单行
+func marshalNDJSON(doc *Document, opts *Options) ([]byte, error) {
+	items, ok := doc.Ordered(opts).([]any)
+	if !ok {
+		items = []any{doc.Ordered(opts)}
+	}
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := encodeJSON(item, "")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
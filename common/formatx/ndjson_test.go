@@ -0,0 +1,125 @@
+package formatx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 错误行报告行号，空行与 CRLF 行计入行号但不产生记录
+func TestNDJSONReader(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		mode  LineErrorMode
+		want  string // 记录的 JSON，以空格分隔
+		err   string
+		warns []string
+	}{
+		{"plain", "{\"a\":1}\n{\"a\":2}\n", LineErrorFail, `{"a":1} {"a":2}`, "", nil},
+		{"no trailing newline", "{\"a\":1}\n[1,2]", LineErrorFail, `{"a":1} [1,2]`, "", nil},
+		{"blank lines", "\n{\"a\":1}\n\n  \n{\"a\":2}\n\n", LineErrorFail, `{"a":1} {"a":2}`, "", nil},
+		{"crlf", "{\"a\":1}\r\n{\"a\":2}\r\n", LineErrorFail, `{"a":1} {"a":2}`, "", nil},
+		{"bom", "\xef\xbb\xbf{\"a\":1}\n", LineErrorFail, `{"a":1}`, "", nil},
+		{"key order", "{\"b\":1,\"a\":2}\n", LineErrorFail, `{"b":1,"a":2}`, "", nil},
+		{"scalars", "1\n\"x\"\nnull\n", LineErrorFail, `1 "x" null`, "", nil},
+		{"fail", "{\"a\":1}\n\n{\"a\":\n{\"a\":3}\n", LineErrorFail, `{"a":1}`, "line 3:", nil},
+		{"fail crlf", "{\"a\":1}\r\n\r\n{oops}\r\n", LineErrorFail, `{"a":1}`, "line 3:", nil},
+		{"two values", "{\"a\":1} {\"a\":2}\n", LineErrorFail, ``, "line 1: more than one value in a line", nil},
+		{"skip", "{\"a\":1}\n{\"a\":\n\n[1,\n{\"a\":3}\n", LineErrorSkip, `{"a":1} {"a":3}`, "",
+			[]string{"2:skipped invalid line", "4:skipped invalid line"}},
+		{"skip crlf", "{\"a\":1}\r\nnope\r\n{\"a\":3}\r\n", LineErrorSkip, `{"a":1} {"a":3}`, "",
+			[]string{"2:skipped invalid line"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reader := NewNDJSONReader(strings.NewReader(c.input), c.mode)
+			var got []string
+			var err error
+			for {
+				var value any
+				value, err = reader.Next()
+				if err != nil {
+					break
+				}
+				data, marshalErr := encodeJSON(value, "")
+				if marshalErr != nil {
+					t.Fatal(marshalErr)
+				}
+				got = append(got, string(data))
+			}
+			if c.err == "" && !errors.Is(err, io.EOF) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.err != "" {
+				var lineErr *LineError
+				if !errors.As(err, &lineErr) || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected line error %q, got %v", c.err, err)
+				}
+			}
+			if strings.Join(got, " ") != c.want {
+				t.Errorf("records: %s", strings.Join(got, " "))
+			}
+			var warns []string
+			for _, w := range reader.Warnings() {
+				warns = append(warns, fmt.Sprintf("%d:%s", w.Line, w.Message))
+			}
+			if len(warns) != len(c.warns) {
+				t.Fatalf("warnings: %q", warns)
+			}
+			for i, want := range c.warns {
+				if !strings.HasPrefix(warns[i], want) {
+					t.Errorf("warning %d: %q, want prefix %q", i, warns[i], want)
+				}
+			}
+			if c.mode == LineErrorSkip && reader.Skipped() != len(c.warns) {
+				t.Errorf("skipped: %d", reader.Skipped())
+			}
+		})
+	}
+}
+
+// 跳过的错误行超出上限时只保留前 maxStreamWarnings 条，其余合并为一条
+func TestNDJSONSkipLimit(t *testing.T) {
+	input := strings.Repeat("bad\n", maxStreamWarnings+5) + "{\"a\":1}\n"
+	doc, err := Parse(FormatNDJSON, []byte(input), &Options{OnLineError: LineErrorSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canonical(t, doc); got != `[{"a":1}]` {
+		t.Errorf("value: %s", got)
+	}
+	if len(doc.Warnings) != maxStreamWarnings+1 || doc.Warnings[maxStreamWarnings].Message != "5 more invalid lines skipped" {
+		t.Errorf("warnings: %d, last %+v", len(doc.Warnings), doc.Warnings[len(doc.Warnings)-1])
+	}
+}
+
+// Parse 按 on_line_error 失败或跳过错误行，结果为所有行组成的数组
+func TestParseNDJSON(t *testing.T) {
+	input := "{\"a\":1}\r\n\r\n{\"a\":}\r\n{\"b\":\"x\"}\r\n"
+	if _, err := Parse(FormatNDJSON, []byte(input), nil); err == nil || !strings.Contains(err.Error(), "line 3:") {
+		t.Errorf("fail mode: %v", err)
+	}
+	if _, err := Parse(FormatNDJSON, []byte("{\"a\":1}\n{\"a\":\n{\"a\":2}\n"), nil); err == nil || !strings.Contains(err.Error(), "line 2: unexpected EOF") {
+		t.Errorf("truncated line: %v", err)
+	}
+	doc, err := Parse(FormatNDJSON, []byte(input), &Options{OnLineError: LineErrorSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canonical(t, doc); got != `[{"a":1},{"b":"x"}]` {
+		t.Errorf("value: %s", got)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Line != 3 {
+		t.Errorf("warnings: %+v", doc.Warnings)
+	}
+	out, _, err := Convert(FormatJSON, FormatNDJSON, []byte(`[{"b":1,"a":2},[1],"x"]`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "{\"b\":1,\"a\":2}\n[1]\n\"x\"\n" {
+		t.Errorf("marshal: %q", out)
+	}
+}
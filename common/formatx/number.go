@@ -0,0 +1,121 @@
+package formatx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// NumberKind 能够无损保存数字字面量的最小类型
+type NumberKind int
+
+const (
+	NumberInt64   NumberKind = iota // int64 范围内的整数
+	NumberUint64                    // 超出 int64、在 uint64 范围内的正整数
+	NumberBigInt                    // 超出 64 位范围的整数
+	NumberFloat64                   // 转为 float64 后可以还原原文有效数字的小数
+	NumberDecimal                   // 有效数字或指数超出 float64 的小数
+)
+
+func (k NumberKind) String() string {
+	switch k {
+	case NumberInt64:
+		return "int64"
+	case NumberUint64:
+		return "uint64"
+	case NumberBigInt:
+		return "bigint"
+	case NumberFloat64:
+		return "float64"
+	default:
+		return "decimal"
+	}
+}
+
+// ClassifyNumber 按字面量的大小与精度判断数字类型，整数不会被归为浮点数
+func ClassifyNumber(literal string) NumberKind {
+	if !strings.ContainsAny(literal, ".eE") {
+		if _, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			return NumberInt64
+		}
+		if _, err := strconv.ParseUint(literal, 10, 64); err == nil {
+			return NumberUint64
+		}
+		if _, ok := new(big.Int).SetString(literal, 10); ok {
+			return NumberBigInt
+		}
+		return NumberDecimal
+	}
+	if Float64Exact(literal) {
+		return NumberFloat64
+	}
+	return NumberDecimal
+}
+
+// Float64Exact 字面量转为 float64 后按最短形式输出，有效数字与指数不变时返回 true。
+// 0.1 这类无法精确表示但可以原样还原的小数视为无损
+func Float64Exact(literal string) bool {
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return false
+	}
+	digits, exp := decimalDigits(literal)
+	rounded, roundedExp := decimalDigits(strconv.FormatFloat(f, 'e', -1, 64))
+	if digits == "" || rounded == "" {
+		return digits == rounded
+	}
+	return digits == rounded && exp == roundedExp
+}
+
+// decimalDigits 返回字面量去掉符号与首尾零后的有效数字和十进制指数，数值为 digits × 10^exp
+func decimalDigits(literal string) (string, int) {
+	s := strings.TrimLeft(literal, "+-")
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, _ = strconv.Atoi(s[i+1:])
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+	trimmed := strings.TrimRight(s, "0")
+	exp += len(s) - len(trimmed)
+	return strings.TrimLeft(trimmed, "0"), exp
+}
+
+// SignificantDigits 字面量的有效数字位数
+func SignificantDigits(literal string) int {
+	digits, _ := decimalDigits(literal)
+	return len(digits)
+}
+
+// PrecisionWarnings 返回 lossy 判定会丢失精度的数字，message 中的 %s 为数字字面量
+func PrecisionWarnings(value any, lossy func(literal string) bool, message string) []Warning {
+	var warnings []Warning
+	var walk func(value any, path string)
+	walk = func(value any, path string) {
+		switch v := value.(type) {
+		case map[string]any:
+			for _, key := range sortedKeys(v) {
+				walk(v[key], childPath(path, key))
+			}
+		case *Record:
+			for _, key := range v.Keys {
+				walk(v.Values[key], childPath(path, key))
+			}
+		case []any:
+			for i, item := range v {
+				walk(item, indexPath(path, i))
+			}
+		case json.Number:
+			if lossy(v.String()) {
+				warnings = append(warnings, Warning{Path: path, Message: fmt.Sprintf(message, v)})
+			}
+		}
+	}
+	walk(value, "")
+	return warnings
+}
@@ -0,0 +1,242 @@
+package formatx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyOrder 对象键在原文中的顺序，按对象所在路径（JSON Pointer，根对象为空字符串）记录。
+// 数据仍为 map[string]any，序列化时按记录的顺序输出，未记录的键按字典序追加在后
+type KeyOrder map[string][]string
+
+// SetKeyOrder 记录 pointer 处对象的键顺序
+func (d *Document) SetKeyOrder(pointer string, keys []string) {
+	if d.Order == nil {
+		d.Order = make(KeyOrder)
+	}
+	d.Order[pointer] = keys
+}
+
+// Keys 按文档记录的顺序返回对象的键，sortKeys 为 true 或没有记录时按字典序
+func (d *Document) Keys(pointer string, m map[string]any, sortKeys bool) []string {
+	var recorded []string
+	if d != nil && !sortKeys {
+		recorded = d.Order[pointer]
+	}
+	if len(recorded) == 0 {
+		return sortedKeys(m)
+	}
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, key := range recorded {
+		if _, ok := m[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	if len(keys) == len(m) {
+		return keys
+	}
+	var rest []string
+	for key := range m {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// keys 序列化时使用的键顺序
+func (d *Document) keys(pointer string, m map[string]any, opts *Options) []string {
+	return d.Keys(pointer, m, opts.sortKeys())
+}
+
+// SetOrdered 写入有序形式的数据，*Record 转回 map[string]any 并记录键顺序
+func (d *Document) SetOrdered(value any) {
+	d.Value = d.unordered(value, "")
+}
+
+func (d *Document) unordered(value any, pointer string) any {
+	switch v := value.(type) {
+	case *Record:
+		m := make(map[string]any, len(v.Keys))
+		for _, key := range v.Keys {
+			m[key] = d.unordered(v.Values[key], childPointer(pointer, key))
+		}
+		if d != nil && len(v.Keys) > 0 {
+			d.SetKeyOrder(pointer, append([]string(nil), v.Keys...))
+		}
+		return m
+	case map[string]any:
+		for key, item := range v {
+			v[key] = d.unordered(item, childPointer(pointer, key))
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = d.unordered(item, indexPointer(pointer, i))
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// orderRecorder 按出现顺序逐个记录键，供以 map 构建数据的解析器使用
+type orderRecorder struct {
+	doc  *Document
+	seen map[string]map[string]bool
+}
+
+func newOrderRecorder(doc *Document) *orderRecorder {
+	return &orderRecorder{doc: doc, seen: make(map[string]map[string]bool)}
+}
+
+// add 记录 pointer 处对象的键，已记录过的键保持首次出现的位置
+func (r *orderRecorder) add(pointer, key string) {
+	seen, ok := r.seen[pointer]
+	if !ok {
+		seen = make(map[string]bool)
+		r.seen[pointer] = seen
+	}
+	if !seen[key] {
+		seen[key] = true
+		r.doc.SetKeyOrder(pointer, append(r.doc.Order[pointer], key))
+	}
+}
+
+// copyKeyOrder 复制 src 中 pointer 及其下级对象的键顺序
+func (d *Document) copyKeyOrder(src *Document, pointer string) {
+	for p, keys := range src.Order {
+		if p == pointer || strings.HasPrefix(p, pointer+"/") {
+			d.SetKeyOrder(p, keys)
+		}
+	}
+}
+
+// moveKeyOrder 将 src 中 from 及其下级对象的键顺序移到 to 路径下，src 为 d 本身时删除原路径的记录
+func (d *Document) moveKeyOrder(src *Document, from, to string) {
+	moved := make(KeyOrder)
+	for p, keys := range src.Order {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			moved[to+p[len(from):]] = keys
+			if src == d {
+				delete(d.Order, p)
+			}
+		}
+	}
+	for p, keys := range moved {
+		d.SetKeyOrder(p, keys)
+	}
+}
+
+// dropKeyOrder 删除 pointer 及其下级对象的键顺序
+func (d *Document) dropKeyOrder(pointer string) {
+	for p := range d.Order {
+		if p == pointer || strings.HasPrefix(p, pointer+"/") {
+			delete(d.Order, p)
+		}
+	}
+}
+
+// Ordered 将数据转为按键顺序序列化的形式，对象转为 *Record，供 JSON 等按值序列化的编码器使用
+func (d *Document) Ordered(opts *Options) any {
+	return d.ordered(d.Value, "", opts)
+}
+
+// OrderedAt 按 pointer 处记录的键顺序将文档中的某个值转为有序形式，供改写单个节点时使用
+func (d *Document) OrderedAt(value any, pointer string) any {
+	return d.ordered(value, pointer, nil)
+}
+
+func (d *Document) ordered(value any, pointer string, opts *Options) any {
+	switch v := value.(type) {
+	case map[string]any:
+		record := &Record{Values: make(map[string]any, len(v))}
+		for _, key := range d.keys(pointer, v, opts) {
+			record.Keys = append(record.Keys, key)
+			record.Values[key] = d.ordered(v[key], childPointer(pointer, key), opts)
+		}
+		return record
+	case *Record:
+		keys := v.Keys
+		if opts.sortKeys() {
+			keys = append([]string(nil), keys...)
+			sort.Strings(keys)
+		}
+		record := &Record{Keys: keys, Values: make(map[string]any, len(keys))}
+		for _, key := range keys {
+			record.Values[key] = d.ordered(v.Values[key], childPointer(pointer, key), opts)
+		}
+		return record
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = d.ordered(item, indexPointer(pointer, i), opts)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+// unorderedValue 有序形式的数据转回 map[string]any，不记录键顺序
+func unorderedValue(value any) any {
+	return (*Document)(nil).unordered(value, "")
+}
+
+// orderedValue 流式读取的记录已是有序形式，只在 sortKeys 时重新排序
+func orderedValue(value any, opts *Options) any {
+	if !opts.sortKeys() {
+		return value
+	}
+	return (*Document)(nil).ordered(value, "", opts)
+}
+
+// setPointer 将 pointer 处的值替换为 value，路径不存在时返回 false
+func setPointer(root any, pointer string, value any) bool {
+	if pointer == "" {
+		return false
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	current := root
+	for i, token := range tokens {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		last := i == len(tokens)-1
+		switch v := current.(type) {
+		case map[string]any:
+			if _, ok := v[token]; !ok {
+				return false
+			}
+			if last {
+				v[token] = value
+				return true
+			}
+			current = v[token]
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return false
+			}
+			if last {
+				v[index] = value
+				return true
+			}
+			current = v[index]
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// childPointer 与 indexPointer 拼接 JSON Pointer，键中的 ~ 与 / 按 RFC 6901 转义
+func childPointer(pointer, key string) string {
+	return pointer + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func indexPointer(pointer string, i int) string {
+	return pointer + "/" + strconv.Itoa(i)
+}
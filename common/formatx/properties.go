@@ -0,0 +1,357 @@
+package formatx
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseProperties 解析 Java .properties：支持 \uXXXX 转义、反斜杠续行、= : 或空白分隔，
+// 点号分隔的键展开为嵌套对象，key[0] 形式的键展开为数组，值均为字符串
+func parseProperties(doc *Document, data []byte, _ *Options) error {
+	root := make(map[string]any)
+	order := newOrderRecorder(doc)
+	lines := strings.Split(strings.TrimPrefix(string(data), "\ufeff"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(strings.TrimRight(lines[i], "\r"), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// 以奇数个反斜杠结尾表示续行，下一行去掉前导空白后拼接
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(strings.TrimRight(lines[i], "\r"), " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		path := parseKeyPath(key)
+		if err = setPath(root, path, value); err != nil {
+			if _, exists := root[key]; len(path) > 1 && !exists {
+				doc.Warn(lineNo, key, "%v, kept as flat key", err)
+				root[key] = value
+				order.add("", key)
+			} else {
+				doc.Warn(lineNo, key, "%v, value dropped", err)
+			}
+			continue
+		}
+		pointer := ""
+		for _, segment := range path {
+			if segment.index >= 0 {
+				pointer = indexPointer(pointer, segment.index)
+				continue
+			}
+			order.add(pointer, segment.key)
+			pointer = childPointer(pointer, segment.key)
+		}
+	}
+	value, err := compactArrays(root, "")
+	if err != nil {
+		return err
+	}
+	doc.Value = value
+	return nil
+}
+
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty 按第一个未转义的 = : 或空白拆分键值，分隔符两侧的空白忽略
+func splitProperty(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if ch == '\\' {
+			i++
+			continue
+		}
+		if ch == '=' || ch == ':' || ch == ' ' || ch == '\t' || ch == '\f' {
+			end = i
+			break
+		}
+	}
+	key = line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch != '\\' || i+1 >= len(s) {
+			sb.WriteByte(ch)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx encoding")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx encoding")
+			}
+			r := rune(code)
+			// 代理对组合为一个字符
+			if r >= 0xd800 && r < 0xdc00 && i+11 <= len(s) && s[i+5:i+7] == "\\u" {
+				if low, err := strconv.ParseUint(s[i+7:i+11], 16, 16); err == nil && low >= 0xdc00 && low < 0xe000 {
+					r = (r-0xd800)<<10 + (rune(low) - 0xdc00) + 0x10000
+					i += 6
+				}
+			}
+			sb.WriteRune(r)
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// pathSegment 键路径中的一段，index 不小于 0 时表示数组下标
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// parseKeyPath 拆分 a.b[0].c 形式的键
+func parseKeyPath(key string) []pathSegment {
+	var segments []pathSegment
+	for _, part := range strings.Split(key, ".") {
+		name := part
+		var indexes []int
+		for strings.HasSuffix(name, "]") {
+			open := strings.LastIndex(name, "[")
+			if open <= 0 {
+				break
+			}
+			n, err := strconv.Atoi(name[open+1 : len(name)-1])
+			if err != nil || n < 0 {
+				break
+			}
+			indexes = append([]int{n}, indexes...)
+			name = name[:open]
+		}
+		segments = append(segments, pathSegment{key: name, index: -1})
+		for _, n := range indexes {
+			segments = append(segments, pathSegment{index: n})
+		}
+	}
+	return segments
+}
+
+// setPath 按路径写入值，数组先以 map[int]any 暂存，解析完成后由 compactArrays 转为切片
+func setPath(root map[string]any, path []pathSegment, value any) error {
+	var container any = root
+	for i, segment := range path {
+		last := i == len(path)-1
+		var next any
+		if i+1 < len(path) && path[i+1].index >= 0 {
+			next = map[int]any{}
+		} else {
+			next = map[string]any{}
+		}
+		switch c := container.(type) {
+		case map[string]any:
+			if segment.index >= 0 {
+				return fmt.Errorf("conflicting key type")
+			}
+			if last {
+				if _, exists := c[segment.key]; exists {
+					if _, isString := c[segment.key].(string); !isString {
+						return fmt.Errorf("key %q is already an object", segment.key)
+					}
+				}
+				c[segment.key] = value
+				return nil
+			}
+			if existing, ok := c[segment.key]; ok {
+				next = existing
+			} else {
+				c[segment.key] = next
+			}
+		case map[int]any:
+			if segment.index < 0 {
+				return fmt.Errorf("conflicting key type")
+			}
+			if last {
+				c[segment.index] = value
+				return nil
+			}
+			if existing, ok := c[segment.index]; ok {
+				next = existing
+			} else {
+				c[segment.index] = next
+			}
+		default:
+			return fmt.Errorf("key %q is already a value", segment.key)
+		}
+		container = next
+	}
+	return nil
+}
+
+// maxArrayGap 数组最多允许缺失的下标数，超过元素个数加此值的下标视为过于稀疏，避免 a[2000000000]=x 分配巨大的数组
+const maxArrayGap = 16
+
+// compactArrays 将暂存数组的 map[int]any 按下标顺序转为 []any，缺失的下标填 nil
+func compactArrays(value any, pointer string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			compacted, err := compactArrays(item, childPointer(pointer, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = compacted
+		}
+		return v, nil
+	case map[int]any:
+		size := 0
+		for index := range v {
+			size = max(size, index+1)
+		}
+		if size > 2*len(v)+maxArrayGap {
+			return nil, fmt.Errorf("array %s: index %d is too sparse for %d entries", pointer, size-1, len(v))
+		}
+		items := make([]any, size)
+		for index, item := range v {
+			compacted, err := compactArrays(item, indexPointer(pointer, index))
+			if err != nil {
+				return nil, err
+			}
+			items[index] = compacted
+		}
+		return items, nil
+	default:
+		return v, nil
+	}
+}
+
+// marshalProperties 嵌套对象展开为点号分隔的键，数组展开为 key[0]，非 ASCII 字符转为 \uXXXX，null 写为空值并记录提示
+func marshalProperties(doc *Document, opts *Options) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, entry := range flattenKeyPath("", doc.Ordered(opts), doc) {
+		if entry.value == nil {
+			doc.Warn(0, entry.key, "null written as empty string")
+		}
+		buf.WriteString(escapeProperty(entry.key, true))
+		buf.WriteByte('=')
+		buf.WriteString(escapeProperty(scalarString(entry.value), false))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+type flatEntry struct {
+	key   string
+	value any
+}
+
+// flattenKeyPath 展开为 a.b[0].c 形式的键，空对象与空数组无法表示，记录提示后忽略
+func flattenKeyPath(prefix string, value any, doc *Document) []flatEntry {
+	switch v := value.(type) {
+	case *Record:
+		if v.Len() == 0 && prefix != "" {
+			doc.Warn(0, prefix, "empty object dropped")
+		}
+		var entries []flatEntry
+		for _, key := range v.Keys {
+			entries = append(entries, flattenKeyPath(joinPath(prefix, key), v.Values[key], doc)...)
+		}
+		return entries
+	case []any:
+		if len(v) == 0 {
+			doc.Warn(0, prefix, "empty array dropped")
+		}
+		var entries []flatEntry
+		for i, item := range v {
+			entries = append(entries, flattenKeyPath(fmt.Sprintf("%s[%d]", prefix, i), item, doc)...)
+		}
+		return entries
+	default:
+		if prefix == "" {
+			prefix = "value"
+		}
+		return []flatEntry{{key: prefix, value: value}}
+	}
+}
+
+func escapeProperty(s string, isKey bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			if isKey || i == 0 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		case ' ':
+			if isKey || i == 0 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				if r > 0xffff {
+					high, low := utf16Surrogates(r)
+					fmt.Fprintf(&sb, `\u%04x\u%04x`, high, low)
+				} else if r != utf8.RuneError {
+					fmt.Fprintf(&sb, `\u%04x`, r)
+				}
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func utf16Surrogates(r rune) (rune, rune) {
+	r -= 0x10000
+	return 0xd800 + (r>>10)&0x3ff, 0xdc00 + r&0x3ff
+}
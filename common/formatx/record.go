@@ -0,0 +1,124 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Record 保持字段顺序的单层记录，序列化为 JSON 对象时按 Keys 的顺序输出
+type Record struct {
+	Keys   []string
+	Values map[string]any
+}
+
+// NewRecord 创建空记录
+func NewRecord() *Record {
+	return &Record{Values: make(map[string]any)}
+}
+
+// Set 设置字段，新字段追加到末尾
+func (r *Record) Set(key string, value any) {
+	if _, ok := r.Values[key]; !ok {
+		r.Keys = append(r.Keys, key)
+	}
+	r.Values[key] = value
+}
+
+// Get 获取字段
+func (r *Record) Get(key string) (any, bool) {
+	value, ok := r.Values[key]
+	return value, ok
+}
+
+// Len 字段数量
+func (r *Record) Len() int {
+	return len(r.Keys)
+}
+
+// MarshalJSON 按字段顺序序列化
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	// 不转义 HTML 字符，保持编辑器中内容可读
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, key := range r.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encoder.Encode(key); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := encoder.Encode(r.Values[key]); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Flatten 将嵌套对象展开为单层记录，嵌套字段名以点号连接，数组元素以下标连接，如 user.tags.0
+func Flatten(value any) *Record {
+	record := NewRecord()
+	switch value.(type) {
+	case map[string]any, *Record, []any:
+		flattenInto(record, "", value)
+	default:
+		record.Set("value", value)
+	}
+	return record
+}
+
+func flattenInto(record *Record, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			if prefix != "" {
+				record.Set(prefix, "{}")
+			}
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenInto(record, joinPath(prefix, key), v[key])
+		}
+	case *Record:
+		if v.Len() == 0 {
+			if prefix != "" {
+				record.Set(prefix, "{}")
+			}
+			return
+		}
+		for _, key := range v.Keys {
+			flattenInto(record, joinPath(prefix, key), v.Values[key])
+		}
+	case []any:
+		if len(v) == 0 {
+			if prefix != "" {
+				record.Set(prefix, "[]")
+			}
+			return
+		}
+		for i, item := range v {
+			flattenInto(record, joinPath(prefix, strconv.Itoa(i)), item)
+		}
+	default:
+		record.Set(prefix, value)
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
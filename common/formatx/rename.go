@@ -0,0 +1,65 @@
+package formatx
+
+import "fmt"
+
+// RenameKeys 改写对象的键，renames 按对象所在路径（改写前的 JSON Pointer）记录原键到新键的映射。
+// 键顺序与注释随节点移到新路径下，YAML 锚点记录不再保留；新键与同一对象中的其他键重名时返回错误
+func (d *Document) RenameKeys(renames map[string]map[string]string) error {
+	r := &keyRenamer{src: d, renames: renames, order: make(KeyOrder), comments: make(Comments)}
+	value, err := r.walk(d.Value, "", "")
+	if err != nil {
+		return err
+	}
+	d.Value, d.Order, d.refs = value, r.order, nil
+	if d.Comments != nil {
+		d.Comments = r.comments
+	}
+	return nil
+}
+
+type keyRenamer struct {
+	src      *Document
+	renames  map[string]map[string]string
+	order    KeyOrder
+	comments Comments
+}
+
+// walk from 为节点改写前的路径，to 为改写后的路径
+func (r *keyRenamer) walk(value any, from, to string) (any, error) {
+	if c := r.src.Comments[from]; c != nil {
+		r.comments[to] = c
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		renames := r.renames[from]
+		m := make(map[string]any, len(v))
+		for _, key := range r.src.Keys(from, v, false) {
+			name, ok := renames[key]
+			if !ok {
+				name = key
+			}
+			if _, exists := m[name]; exists {
+				return nil, fmt.Errorf("%s: key %q renamed to %q conflicts with another key", from, key, name)
+			}
+			item, err := r.walk(v[key], childPointer(from, key), childPointer(to, name))
+			if err != nil {
+				return nil, err
+			}
+			m[name] = item
+			r.order[to] = append(r.order[to], name)
+		}
+		return m, nil
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			renamed, err := r.walk(item, indexPointer(from, i), indexPointer(to, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = renamed
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,528 @@
+package formatx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxStreamWarnings 流式转换保留的提示条数上限，超出部分只计数
+	maxStreamWarnings = 100
+	// maxStreamRecordSize 流式转换时单条记录的字节数上限，记录需要整体读入内存
+	maxStreamRecordSize = 32 << 20
+)
+
+// StreamWriter 逐条写出记录，已写出的数据不会保留在内存中
+type StreamWriter interface {
+	// WriteValue 写出一条记录
+	WriteValue(value any) error
+	// Close 写出结尾并刷新缓冲，不关闭底层 io.Writer
+	Close() error
+}
+
+// streamLayout 输入数据的组织方式，决定输出的外层结构
+type streamLayout struct {
+	sequence bool // 记录来自输入根数组，YAML 输出为序列
+	single   bool // 输入只有一个非数组值，JSON 输出不包裹数组
+}
+
+// NewStreamWriter 创建流式写出器：JSON 输出为数组，YAML 输出为以 --- 分隔的多文档，
+// CSV 列取 opts.Columns，未指定时取第一条记录展开后的字段
+func NewStreamWriter(w io.Writer, format Format, opts *Options) (StreamWriter, error) {
+	return newStreamWriter(w, format, opts, streamLayout{})
+}
+
+func newStreamWriter(w io.Writer, format Format, opts *Options, layout streamLayout) (StreamWriter, error) {
+	buffered := bufio.NewWriter(w)
+	switch format {
+	case FormatJSON, FormatJSONC:
+		return &jsonArrayWriter{writer: buffered, indent: strings.Repeat(" ", opts.indent()), single: layout.single}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{writer: buffered}, nil
+	case FormatYAML:
+		if layout.sequence {
+			return &yamlSequenceWriter{writer: buffered, indent: opts.indent()}, nil
+		}
+		encoder := yaml.NewEncoder(buffered)
+		encoder.SetIndent(opts.indent())
+		return &yamlStreamWriter{writer: buffered, encoder: encoder}, nil
+	case FormatCSV:
+		return &csvStreamWriter{writer: buffered, columns: opts.columns()}, nil
+	default:
+		return nil, fmt.Errorf("streaming output to %s is not supported", format)
+	}
+}
+
+type jsonArrayWriter struct {
+	writer  *bufio.Writer
+	indent  string
+	count   int
+	single  bool
+	pending any // single 模式下暂存第一条记录，出现第二条时才确定输出数组
+}
+
+func (j *jsonArrayWriter) WriteValue(value any) error {
+	if j.single {
+		if j.count == 0 {
+			j.pending, j.count = value, 1
+			return nil
+		}
+		j.single, j.count = false, 0
+		if err := j.WriteValue(j.pending); err != nil {
+			return err
+		}
+		j.pending = nil
+	}
+	data, err := encodeJSON(value, j.indent)
+	if err != nil {
+		return err
+	}
+	if j.count == 0 {
+		_, _ = j.writer.WriteString("[\n")
+	} else {
+		_, _ = j.writer.WriteString(",\n")
+	}
+	j.count++
+	// 元素整体缩进一层
+	_, _ = j.writer.WriteString(j.indent)
+	_, err = j.writer.WriteString(strings.ReplaceAll(string(data), "\n", "\n"+j.indent))
+	return err
+}
+
+func (j *jsonArrayWriter) Close() error {
+	if j.single && j.count == 1 {
+		data, err := encodeJSON(j.pending, j.indent)
+		if err != nil {
+			return err
+		}
+		_, _ = j.writer.Write(data)
+		return j.writer.Flush()
+	}
+	if j.count == 0 {
+		_, _ = j.writer.WriteString("[]")
+	} else {
+		_, _ = j.writer.WriteString("\n]")
+	}
+	return j.writer.Flush()
+}
+
+type ndjsonWriter struct {
+	writer *bufio.Writer
+}
+
+func (n *ndjsonWriter) WriteValue(value any) error {
+	data, err := encodeJSON(value, "")
+	if err != nil {
+		return err
+	}
+	_, _ = n.writer.Write(data)
+	return n.writer.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.writer.Flush()
+}
+
+type yamlStreamWriter struct {
+	writer  *bufio.Writer
+	encoder *yaml.Encoder
+	count   int
+}
+
+func (y *yamlStreamWriter) WriteValue(value any) error {
+	node, err := yamlNode(value)
+	if err != nil {
+		return err
+	}
+	y.count++
+	return y.encoder.Encode(node)
+}
+
+func (y *yamlStreamWriter) Close() error {
+	if y.count == 0 {
+		// 没有文档时编码器无法关闭，与 JSON 一致输出空数组
+		_, _ = y.writer.WriteString("[]\n")
+		return y.writer.Flush()
+	}
+	if err := y.encoder.Close(); err != nil {
+		return err
+	}
+	return y.writer.Flush()
+}
+
+// yamlSequenceWriter 将记录逐条输出为 YAML 序列的元素
+type yamlSequenceWriter struct {
+	writer *bufio.Writer
+	indent int
+	count  int
+}
+
+func (y *yamlSequenceWriter) WriteValue(value any) error {
+	data, err := encodeYAML(value, y.indent)
+	if err != nil {
+		return err
+	}
+	y.count++
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i, line := range lines {
+		if i == 0 {
+			_, _ = y.writer.WriteString("- ")
+		} else if line != "" {
+			_, _ = y.writer.WriteString("  ")
+		}
+		_, _ = y.writer.WriteString(line)
+		_ = y.writer.WriteByte('\n')
+	}
+	return nil
+}
+
+func (y *yamlSequenceWriter) Close() error {
+	if y.count == 0 {
+		_, _ = y.writer.WriteString("[]\n")
+	}
+	return y.writer.Flush()
+}
+
+type csvStreamWriter struct {
+	writer   *bufio.Writer
+	columns  []string
+	csv      *csvWriter
+	count    int
+	warnings []Warning
+	dropped  int
+}
+
+func (c *csvStreamWriter) WriteValue(value any) error {
+	record := Flatten(value)
+	if c.csv == nil {
+		if len(c.columns) == 0 {
+			c.columns = append(c.columns, record.Keys...)
+		}
+		c.csv = newCSVWriter(c.writer, c.columns)
+	}
+	if dropped := c.csv.write(record); dropped > 0 {
+		c.dropped++
+		if len(c.warnings) < maxStreamWarnings {
+			c.warnings = append(c.warnings, Warning{
+				Path:    fmt.Sprintf("[%d]", c.count),
+				Message: fmt.Sprintf("%d fields not in columns dropped", dropped),
+			})
+		}
+	}
+	c.count++
+	return nil
+}
+
+func (c *csvStreamWriter) Close() error {
+	if c.csv == nil {
+		c.csv = newCSVWriter(c.writer, c.columns)
+	}
+	if err := c.csv.flush(); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// Warnings 记录字段被丢弃的行
+func (c *csvStreamWriter) Warnings() []Warning {
+	if c.dropped > len(c.warnings) {
+		return append(c.warnings, Warning{Message: fmt.Sprintf("%d more records had fields dropped", c.dropped-len(c.warnings))})
+	}
+	return c.warnings
+}
+
+// StreamReader 逐条读取记录，对象以 *Record 返回并保持键顺序，读取完毕时返回 io.EOF
+type StreamReader interface {
+	Next() (any, error)
+	// Warnings 读取过程中的提示，如被跳过的错误行
+	Warnings() []Warning
+}
+
+// NewStreamReader 创建流式读取器：NDJSON 逐行读取；JSON 根为数组时逐个读取元素，否则整体作为一条记录；
+// YAML 逐个读取文档，只有一个文档且为序列时逐个返回序列元素。流式读取的 JSON 不支持注释
+func NewStreamReader(r io.Reader, format Format, opts *Options) (StreamReader, error) {
+	switch format {
+	case FormatNDJSON:
+		reader := NewNDJSONReader(r, opts.lineErrorMode())
+		reader.duplicates = opts.duplicateKeys(FormatNDJSON)
+		return reader, nil
+	case FormatJSON, FormatJSONC:
+		return newJSONStreamReader(r, opts.duplicateKeys(FormatJSON)), nil
+	case FormatYAML:
+		return &yamlStreamReader{decoder: yaml.NewDecoder(r), duplicates: opts.duplicateKeys(FormatYAML), maxAliasNodes: opts.maxAliasNodes()}, nil
+	default:
+		return nil, fmt.Errorf("streaming input from %s is not supported", format)
+	}
+}
+
+// jsonStreamReader 使用 json.Decoder 的 Token 接口逐个解码根数组的元素，内存占用只与单个元素大小相关
+type jsonStreamReader struct {
+	reader     *bufio.Reader
+	decoder    *json.Decoder
+	duplicates DuplicateKeyPolicy
+	duplicated duplicateWarnings
+	started    bool
+	array      bool
+	done       bool
+}
+
+func newJSONStreamReader(r io.Reader, duplicates DuplicateKeyPolicy) *jsonStreamReader {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	if bom, err := reader.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = reader.Discard(3)
+	}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	return &jsonStreamReader{reader: reader, decoder: decoder, duplicates: duplicates}
+}
+
+func (j *jsonStreamReader) Next() (any, error) {
+	if j.done {
+		return nil, io.EOF
+	}
+	if !j.started {
+		j.started = true
+		first, err := j.peekByte()
+		if err != nil {
+			return nil, err
+		}
+		if first == '[' {
+			if _, err = j.decoder.Token(); err != nil {
+				return nil, j.offsetError(err)
+			}
+			j.array = true
+		} else {
+			j.done = true
+			value, err := j.decode()
+			if err != nil {
+				return nil, err
+			}
+			return value, j.checkEnd()
+		}
+	}
+	if !j.decoder.More() {
+		j.done = true
+		if _, err := j.decoder.Token(); err != nil {
+			return nil, j.offsetError(err)
+		}
+		if err := j.checkEnd(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return j.decode()
+}
+
+// decode 解码一个值，对象以 *Record 返回并保持键顺序，重复键以字节偏移定位
+func (j *jsonStreamReader) decode() (any, error) {
+	doc := &Document{}
+	value, err := newJSONValueDecoder(j.decoder, doc, j.duplicates, nil).decode("", "")
+	if err != nil {
+		return nil, j.offsetError(err)
+	}
+	j.duplicated.add(doc.Warnings)
+	return doc.ordered(value, "", nil), nil
+}
+
+// peekByte 读取第一个非空白字符，不消费输入
+func (j *jsonStreamReader) peekByte() (byte, error) {
+	for {
+		b, err := j.reader.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return 0, errors.New("empty json input")
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		_ = j.reader.UnreadByte()
+		return b, nil
+	}
+}
+
+func (j *jsonStreamReader) checkEnd() error {
+	if _, err := j.decoder.Token(); !errors.Is(err, io.EOF) {
+		return j.offsetError(errors.New("unexpected data after top-level value"))
+	}
+	return nil
+}
+
+// offsetError 流式读取无法回溯行号，错误以字节偏移定位
+func (j *jsonStreamReader) offsetError(err error) error {
+	var duplicateErr *duplicateKeyError
+	if errors.As(err, &duplicateErr) {
+		return err
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("offset %d: %w", j.decoder.InputOffset(), err)
+}
+
+func (j *jsonStreamReader) Sequence() bool {
+	return j.array
+}
+
+func (j *jsonStreamReader) Warnings() []Warning {
+	return j.duplicated.result()
+}
+
+// yamlStreamReader 逐个读取 YAML 文档，跳过只有 --- 或注释的空文档
+type yamlStreamReader struct {
+	decoder       *yaml.Decoder
+	duplicates    DuplicateKeyPolicy
+	maxAliasNodes int
+	duplicated    duplicateWarnings
+	documents     int
+	skipped       []Warning
+	started       bool
+	sequence      bool
+	pending       []any
+}
+
+func (y *yamlStreamReader) Next() (any, error) {
+	if !y.started {
+		y.started = true
+		first, err := y.decode()
+		if err != nil {
+			return nil, err
+		}
+		items, isSequence := first.([]any)
+		if !isSequence {
+			return first, nil
+		}
+		// 预读下一个文档，只有一个文档时才展开序列
+		second, err := y.decode()
+		if errors.Is(err, io.EOF) {
+			y.sequence, y.pending = true, items
+		} else if err != nil {
+			return nil, err
+		} else {
+			y.pending = []any{second}
+			return first, nil
+		}
+	}
+	if len(y.pending) > 0 {
+		value := y.pending[0]
+		y.pending[0] = nil
+		y.pending = y.pending[1:]
+		return value, nil
+	}
+	if y.sequence {
+		return nil, io.EOF
+	}
+	return y.decode()
+}
+
+func (y *yamlStreamReader) decode() (any, error) {
+	var node yaml.Node
+	for {
+		if err := y.decoder.Decode(&node); err != nil {
+			return nil, err
+		}
+		y.documents++
+		line, empty := emptyYAMLDocument(&node)
+		if !empty {
+			break
+		}
+		if len(y.skipped) < maxStreamWarnings {
+			y.skipped = append(y.skipped, Warning{Line: line, Message: fmt.Sprintf("empty document %d skipped", y.documents)})
+		}
+		node = yaml.Node{}
+	}
+	doc := &Document{}
+	value, err := newYAMLDecoder(doc, y.duplicates, y.maxAliasNodes).decode(&node, "", "")
+	if err != nil {
+		return nil, err
+	}
+	y.duplicated.add(doc.Warnings)
+	return doc.ordered(value, "", nil), nil
+}
+
+func (y *yamlStreamReader) Sequence() bool {
+	return y.sequence
+}
+
+func (y *yamlStreamReader) Warnings() []Warning {
+	return append(append([]Warning(nil), y.skipped...), y.duplicated.result()...)
+}
+
+// StreamConvert 逐条读取记录并写出为目标格式，返回转换过程中的提示。
+// 支持从 JSON、NDJSON、YAML 转换为 JSON、NDJSON、YAML、CSV。只有 NDJSON 的行、JSON 根数组的元素与 YAML 的多个文档逐条读取，
+// 其余输入（JSON 根对象、单个 YAML 文档或序列）整体作为一条记录读入内存；单条记录超出 opts.MaxRecordSize 时报错
+func StreamConvert(r io.Reader, from Format, w io.Writer, to Format, opts *Options) ([]Warning, error) {
+	limit := &recordLimitReader{reader: r, limit: int64(opts.maxRecordSize())}
+	reader, err := NewStreamReader(limit, from, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = newStreamWriter(io.Discard, to, opts, streamLayout{}); err != nil {
+		return nil, err
+	}
+	first, err := reader.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return reader.Warnings(), err
+	}
+	empty := errors.Is(err, io.EOF)
+	layout := streamLayout{}
+	if s, ok := reader.(interface{ Sequence() bool }); ok {
+		layout.sequence = s.Sequence()
+		layout.single = !layout.sequence && from != FormatNDJSON
+	}
+	writer, err := newStreamWriter(w, to, opts, layout)
+	if err != nil {
+		return nil, err
+	}
+	for value := first; !empty; {
+		if err = writer.WriteValue(orderedValue(value, opts)); err != nil {
+			return reader.Warnings(), err
+		}
+		limit.reset()
+		value, err = reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return reader.Warnings(), err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return reader.Warnings(), err
+	}
+	warnings := reader.Warnings()
+	if ws, ok := writer.(interface{ Warnings() []Warning }); ok {
+		warnings = append(warnings, ws.Warnings()...)
+	}
+	return warnings, nil
+}
+
+// recordLimitReader 限制读取单条记录时从输入读取的字节数。读取器会预读缓冲，计数包含预读部分，为近似值
+type recordLimitReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (l *recordLimitReader) Read(p []byte) (int, error) {
+	if l.read > l.limit {
+		return 0, fmt.Errorf("record exceeds %d bytes, only NDJSON lines, JSON array elements and YAML documents are streamed one at a time", l.limit)
+	}
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	return n, err
+}
+
+// reset 开始读取下一条记录
+func (l *recordLimitReader) reset() {
+	l.read = 0
+}
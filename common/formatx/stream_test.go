@@ -0,0 +1,235 @@
+package formatx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// streamOutput 流式转换的输出与提示
+func streamOutput(t *testing.T, from Format, input string, to Format, opts *Options) (string, []Warning) {
+	t.Helper()
+	var buf bytes.Buffer
+	warnings, err := StreamConvert(strings.NewReader(input), from, &buf, to, opts)
+	if err != nil {
+		t.Fatalf("%s -> %s: %v", from, to, err)
+	}
+	return buf.String(), warnings
+}
+
+// 每种输入与输出的组合：多条记录输出为数组、多文档或多行，单个非数组值不包裹数组
+func TestStreamConvert(t *testing.T) {
+	records := map[Format]string{
+		FormatJSON:   "[\n  {\n    \"b\": 1,\n    \"a\": {\n      \"c\": true\n    }\n  },\n  {\n    \"b\": 2\n  }\n]",
+		FormatNDJSON: "{\"b\":1,\"a\":{\"c\":true}}\n{\"b\":2}\n",
+		FormatYAML:   "b: 1\na:\n  c: true\n---\nb: 2\n",
+		FormatCSV:    "b,a.c\n1,true\n2,\n",
+	}
+	sequence := map[Format]string{
+		FormatJSON:   records[FormatJSON],
+		FormatNDJSON: records[FormatNDJSON],
+		FormatYAML:   "- b: 1\n  a:\n    c: true\n- b: 2\n",
+		FormatCSV:    records[FormatCSV],
+	}
+	single := map[Format]string{
+		FormatJSON:   "{\n  \"b\": 1,\n  \"a\": {\n    \"c\": true\n  }\n}",
+		FormatNDJSON: "{\"b\":1,\"a\":{\"c\":true}}\n",
+		FormatYAML:   "b: 1\na:\n  c: true\n",
+		FormatCSV:    "b,a.c\n1,true\n",
+	}
+	cases := []struct {
+		name  string
+		from  Format
+		input string
+		want  map[Format]string
+	}{
+		{"ndjson", FormatNDJSON, "{\"b\":1,\"a\":{\"c\":true}}\n{\"b\":2}\n", records},
+		{"ndjson single line", FormatNDJSON, "{\"b\":1,\"a\":{\"c\":true}}\n", map[Format]string{
+			FormatJSON:   "[\n  {\n    \"b\": 1,\n    \"a\": {\n      \"c\": true\n    }\n  }\n]",
+			FormatNDJSON: single[FormatNDJSON],
+			FormatYAML:   single[FormatYAML],
+			FormatCSV:    single[FormatCSV],
+		}},
+		{"json array", FormatJSON, "\xef\xbb\xbf [{\"b\":1,\"a\":{\"c\":true}},{\"b\":2}]", sequence},
+		{"json object", FormatJSON, "{\"b\":1,\"a\":{\"c\":true}}", single},
+		{"yaml documents", FormatYAML, "b: 1\na:\n  c: true\n---\nb: 2\n", records},
+		{"yaml sequence", FormatYAML, "- b: 1\n  a:\n    c: true\n- b: 2\n", sequence},
+		{"yaml sequence documents", FormatYAML, "- b: 1\n---\n- b: 2\n", map[Format]string{
+			FormatJSON:   "[\n  [\n    {\n      \"b\": 1\n    }\n  ],\n  [\n    {\n      \"b\": 2\n    }\n  ]\n]",
+			FormatNDJSON: "[{\"b\":1}]\n[{\"b\":2}]\n",
+			FormatYAML:   "- b: 1\n---\n- b: 2\n",
+		}},
+		{"yaml mapping", FormatYAML, "b: 1\na:\n  c: true\n", single},
+		{"json empty array", FormatJSON, "[]", map[Format]string{
+			FormatJSON: "[]", FormatNDJSON: "", FormatYAML: "[]\n", FormatCSV: "",
+		}},
+		{"ndjson empty", FormatNDJSON, "\n\n", map[Format]string{
+			FormatJSON: "[]", FormatNDJSON: "", FormatYAML: "[]\n", FormatCSV: "",
+		}},
+	}
+	for _, c := range cases {
+		for to, want := range c.want {
+			t.Run(fmt.Sprintf("%s to %s", c.name, to), func(t *testing.T) {
+				got, warnings := streamOutput(t, c.from, c.input, to, nil)
+				if got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+				if len(warnings) != 0 {
+					t.Errorf("warnings: %+v", warnings)
+				}
+			})
+		}
+	}
+}
+
+// 缩进、键排序与 CSV 输出列对每条记录生效，CSV 列取第一条记录展开后的字段，丢弃其余字段并提示
+func TestStreamConvertOptions(t *testing.T) {
+	input := "{\"b\":1,\"a\":[1,2]}\n{\"b\":2,\"c\":3}\n"
+	if got, _ := streamOutput(t, FormatNDJSON, input, FormatJSON, &Options{Indent: 4, SortKeys: true}); got !=
+		"[\n    {\n        \"a\": [\n            1,\n            2\n        ],\n        \"b\": 1\n    },\n    {\n        \"b\": 2,\n        \"c\": 3\n    }\n]" {
+		t.Errorf("json: %q", got)
+	}
+	if got, _ := streamOutput(t, FormatJSON, "["+strings.ReplaceAll(strings.TrimSpace(input), "\n", ",")+"]", FormatYAML, &Options{Indent: 4}); got !=
+		"- b: 1\n  a:\n      - 1\n      - 2\n- b: 2\n  c: 3\n" {
+		t.Errorf("yaml: %q", got)
+	}
+	got, warnings := streamOutput(t, FormatNDJSON, input, FormatCSV, nil)
+	if got != "b,a.0,a.1\n1,1,2\n2,,\n" {
+		t.Errorf("csv: %q", got)
+	}
+	if len(warnings) != 1 || warnings[0].Path != "[1]" || warnings[0].Message != "1 fields not in columns dropped" {
+		t.Errorf("csv warnings: %+v", warnings)
+	}
+	if got, _ = streamOutput(t, FormatNDJSON, input, FormatCSV, &Options{Columns: []string{"c", "b"}}); got != "c,b\n,1\n3,2\n" {
+		t.Errorf("csv columns: %q", got)
+	}
+}
+
+// NDJSON 错误行按 on_line_error 失败或跳过，跳过的行记录行号
+func TestStreamConvertLineErrors(t *testing.T) {
+	input := "{\"a\":1}\r\n\r\n{\"a\":\r\n{\"a\":2}\r\nnull x\r\n"
+	var buf bytes.Buffer
+	_, err := StreamConvert(strings.NewReader(input), FormatNDJSON, &buf, FormatNDJSON, nil)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Errorf("fail mode: %v", err)
+	}
+	got, warnings := streamOutput(t, FormatNDJSON, input, FormatJSON, &Options{OnLineError: LineErrorSkip, Indent: 1})
+	if got != "[\n {\n  \"a\": 1\n },\n {\n  \"a\": 2\n }\n]" {
+		t.Errorf("skip mode: %q", got)
+	}
+	if len(warnings) != 2 || warnings[0].Line != 3 || warnings[1].Line != 5 {
+		t.Errorf("skip warnings: %+v", warnings)
+	}
+	if _, err = StreamConvert(strings.NewReader("bad\n"), FormatNDJSON, &buf, FormatCSV, &Options{OnLineError: "skip"}); err != nil {
+		t.Errorf("skip only bad lines: %v", err)
+	}
+}
+
+// 重复键按策略处理并提示，JSON 与 YAML 的语法错误带位置
+func TestStreamConvertErrors(t *testing.T) {
+	_, warnings := streamOutput(t, FormatJSON, `[{"a":1,"a":2}]`, FormatNDJSON, nil)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "duplicate key") {
+		t.Errorf("json duplicate: %+v", warnings)
+	}
+	_, warnings = streamOutput(t, FormatYAML, "a: 1\na: 2\n", FormatNDJSON, nil)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "duplicate key") {
+		t.Errorf("yaml duplicate: %+v", warnings)
+	}
+	cases := []struct {
+		name  string
+		from  Format
+		input string
+		to    Format
+		opts  *Options
+		want  string
+	}{
+		{"json duplicate error", FormatJSON, `[{"a":1,"a":2}]`, FormatNDJSON, &Options{DuplicateKeys: DuplicateKeyError}, "duplicate key"},
+		{"json truncated", FormatJSON, `[{"a":1},{"a":`, FormatNDJSON, nil, "offset"},
+		{"json trailing data", FormatJSON, `{"a":1} {"a":2}`, FormatNDJSON, nil, "unexpected data after top-level value"},
+		{"json empty", FormatJSON, " \n", FormatNDJSON, nil, "empty json input"},
+		{"yaml syntax", FormatYAML, "a: 1\n---\n[a\n", FormatNDJSON, nil, "yaml"},
+		{"unsupported input", FormatTOML, "a = 1", FormatJSON, nil, "streaming input from toml is not supported"},
+		{"unsupported output", FormatJSON, "[]", FormatXML, nil, "streaming output to xml is not supported"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := StreamConvert(strings.NewReader(c.input), c.from, &buf, c.to, c.opts)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("expected error containing %q, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+// 单条记录超出 MaxRecordSize 时报错，逐条读取的记录各自计算大小
+func TestStreamConvertRecordSize(t *testing.T) {
+	item := `{"a":"` + strings.Repeat("x", 100<<10) + `"}`
+	opts := &Options{MaxRecordSize: 256 << 10}
+	inputs := []struct {
+		from  Format
+		input string
+	}{
+		{FormatNDJSON, strings.Repeat(item+"\n", 8)},
+		{FormatJSON, "[" + strings.Repeat(item+",", 7) + item + "]"},
+		{FormatYAML, strings.Repeat("---\n"+item+"\n", 8)},
+	}
+	for _, in := range inputs {
+		var buf bytes.Buffer
+		if _, err := StreamConvert(strings.NewReader(in.input), in.from, &buf, FormatNDJSON, opts); err != nil {
+			t.Errorf("%s records: %v", in.from, err)
+		}
+	}
+	large := map[Format]string{
+		FormatNDJSON: strings.Repeat("x", 1<<20) + "\n",
+		FormatJSON:   "{" + strings.Repeat(`"a":1,`, 200<<10) + `"b":1}`,
+		FormatYAML:   "- " + strings.Repeat("x", 1<<20) + "\n",
+	}
+	for from, input := range large {
+		var buf bytes.Buffer
+		_, err := StreamConvert(strings.NewReader(input), from, &buf, FormatNDJSON, opts)
+		if err == nil || !strings.Contains(err.Error(), "record exceeds 262144 bytes") {
+			t.Errorf("%s: expected record size error, got %v", from, err)
+		}
+	}
+	if got := (&Options{MaxRecordSize: 1 << 30}).maxRecordSize(); got != maxStreamRecordSize {
+		t.Errorf("raised limit: %d", got)
+	}
+}
+
+// 流式读取与 Parse 一样跳过空的 YAML 文档并提示，显式写出的 null 仍作为记录；行号为解析器报告的空值位置
+func TestStreamConvertEmptyYAMLDocuments(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		to       Format
+		want     string
+		warnings []string
+	}{
+		{"trailing separator", "a: 1\n---\n", FormatJSON, "{\n  \"a\": 1\n}", []string{"3:empty document 2 skipped"}},
+		{"trailing separator ndjson", "a: 1\n---\n", FormatNDJSON, "{\"a\":1}\n", []string{"3:empty document 2 skipped"}},
+		{"consecutive separators", "a: 1\n---\n---\nb: 2\n", FormatNDJSON, "{\"a\":1}\n{\"b\":2}\n", []string{"3:empty document 2 skipped"}},
+		{"leading separators", "---\n# note\n---\n- 1\n- 2\n", FormatNDJSON, "1\n2\n", []string{"3:empty document 1 skipped"}},
+		{"sequence with trailing separator", "- 1\n- 2\n---\n", FormatYAML, "- 1\n- 2\n", []string{"4:empty document 2 skipped"}},
+		{"explicit null", "a: 1\n--- null\n", FormatNDJSON, "{\"a\":1}\nnull\n", nil},
+		{"only separators", "---\n---\n", FormatJSON, "[]", []string{"2:empty document 1 skipped", "3:empty document 2 skipped"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, warnings := streamOutput(t, FormatYAML, c.input, c.to, nil)
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+			var messages []string
+			for _, w := range warnings {
+				messages = append(messages, fmt.Sprintf("%d:%s", w.Line, w.Message))
+			}
+			if strings.Join(messages, "|") != strings.Join(c.warnings, "|") {
+				t.Errorf("warnings: %q", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package formatx
+
+const textField = "text"
+
+// parseText 文本格式包装为 {"text": content}，与前端处理方式一致
+func parseText(doc *Document, data []byte, _ *Options) error {
+	doc.Value = map[string]any{textField: string(data)}
+	return nil
+}
+
+func marshalText(doc *Document, opts *Options) ([]byte, error) {
+	switch v := doc.Value.(type) {
+	case string:
+		return []byte(v), nil
+	case map[string]any:
+		if text, ok := v[textField].(string); ok && len(v) == 1 {
+			return []byte(text), nil
+		}
+	}
+	return marshalJSON(doc, opts)
+}
@@ -0,0 +1,701 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// parseTOML 数据由 go-toml 解析并校验，再遍历语法树记录键顺序与注释，浮点数保留原始字面量。
+// TOML 规范不允许重复的键与表，解析前先按策略处理，默认报错
+func parseTOML(doc *Document, data []byte, opts *Options) error {
+	resolver := &tomlDuplicates{doc: doc, policy: opts.duplicateKeys(FormatTOML)}
+	data, err := resolver.resolve(data)
+	if err != nil {
+		return err
+	}
+	value := make(map[string]any)
+	if err := toml.Unmarshal(data, &value); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			return errors.New(decodeErr.String())
+		}
+		return err
+	}
+	root := normalizeValue(value).(map[string]any)
+	walker := &tomlWalker{doc: doc, order: newOrderRecorder(doc), counts: make(map[string]int)}
+	walker.walk(data, root)
+	tomlSpecials(root)
+	doc.Value = root
+	return resolver.apply(root)
+}
+
+// tomlWalker 按表达式顺序遍历 TOML 语法树
+type tomlWalker struct {
+	doc    *Document
+	order  *orderRecorder
+	counts map[string]int // 数组表已出现的元素个数
+}
+
+// walk 独占一行的注释作为下一个键值对或表头的前置注释，文档末尾的注释作为根节点的末尾注释，
+// 同一行的注释作为行尾注释；内联表与数组内部的注释不保留
+func (t *tomlWalker) walk(data []byte, root map[string]any) {
+	parser := unstable.Parser{KeepComments: true}
+	parser.Reset(data)
+	current, pointer := root, ""
+	var pending []string
+	for parser.NextExpression() {
+		expr := parser.Expression()
+		target := ""
+		switch expr.Kind {
+		case unstable.Comment:
+			pending = append(pending, commentLines(string(expr.Data))...)
+			continue
+		case unstable.Table, unstable.ArrayTable:
+			current, pointer = t.resolve(root, tomlKeys(expr), expr.Kind == unstable.ArrayTable)
+			target = pointer
+		case unstable.KeyValue:
+			if current != nil {
+				target = t.keyValue(expr, current, pointer)
+			}
+		}
+		if current == nil {
+			pending = nil
+			continue
+		}
+		t.doc.AddHeadComment(target, pending...)
+		pending = nil
+		if next := expr.Next(); next != nil && next.Kind == unstable.Comment {
+			t.doc.AddLineComment(target, strings.Join(commentLines(string(next.Data)), " "))
+		}
+	}
+	t.doc.AddFootComment("", pending...)
+}
+
+// resolve 定位表头对应的对象，数组表取当前最后一个元素
+func (t *tomlWalker) resolve(root map[string]any, keys []string, arrayTable bool) (map[string]any, string) {
+	current, pointer := root, ""
+	for i, key := range keys {
+		t.order.add(pointer, key)
+		pointer = childPointer(pointer, key)
+		switch v := current[key].(type) {
+		case map[string]any:
+			current = v
+		case []any:
+			if arrayTable && i == len(keys)-1 {
+				t.counts[pointer]++
+			}
+			index := t.counts[pointer] - 1
+			if index < 0 || index >= len(v) {
+				index = len(v) - 1
+			}
+			item, ok := v[index].(map[string]any)
+			if !ok {
+				return nil, ""
+			}
+			current, pointer = item, indexPointer(pointer, index)
+		default:
+			return nil, ""
+		}
+	}
+	return current, pointer
+}
+
+// keyValue 记录键顺序并返回键的路径
+func (t *tomlWalker) keyValue(expr *unstable.Node, current map[string]any, pointer string) string {
+	keys := tomlKeys(expr)
+	for i, key := range keys[:len(keys)-1] {
+		t.order.add(pointer, key)
+		pointer = childPointer(pointer, key)
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			for _, rest := range keys[i+1:] {
+				pointer = childPointer(pointer, rest)
+			}
+			return pointer
+		}
+		current = next
+	}
+	last := keys[len(keys)-1]
+	t.order.add(pointer, last)
+	current[last] = t.value(expr.Value(), current[last], childPointer(pointer, last))
+	return childPointer(pointer, last)
+}
+
+// value 记录内联表的键顺序，数字字面量为合法 JSON 数字时保留原文，日期时间保留原文的精度与时区
+func (t *tomlWalker) value(node *unstable.Node, value any, pointer string) any {
+	switch node.Kind {
+	case unstable.Integer, unstable.Float:
+		if literal := string(node.Data); isNumberLiteral(literal) {
+			return json.Number(literal)
+		}
+	case unstable.DateTime, unstable.LocalDateTime, unstable.LocalDate, unstable.LocalTime:
+		return tomlTimeText(string(node.Data))
+	case unstable.InlineTable:
+		if m, ok := value.(map[string]any); ok {
+			it := node.Children()
+			for it.Next() {
+				t.keyValue(it.Node(), m, pointer)
+			}
+		}
+	case unstable.Array:
+		if items, ok := value.([]any); ok {
+			it := node.Children()
+			for i := 0; it.Next() && i < len(items); {
+				if it.Node().Kind == unstable.Comment {
+					continue
+				}
+				items[i] = t.value(it.Node(), items[i], indexPointer(pointer, i))
+				i++
+			}
+		}
+	}
+	return value
+}
+
+// tomlSpecials inf 与 nan 无法用 JSON 数字表示，与 JSON5 一样转为 $numberDouble 注解
+func tomlSpecials(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = tomlSpecials(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = tomlSpecials(item)
+		}
+	case float64:
+		return floatValue(v)
+	}
+	return value
+}
+
+// tomlExpr 表头或键值对在原文中的位置，键值对可能跨越多行，结束位置取下一个表达式所在行的行首
+type tomlExpr struct {
+	table   bool // [表头]，数组表不会重复
+	section int  // 表头所在段落的结束位置，即下一个表头所在行的行首
+	pointer string
+	path    string
+	keys    []string // 表头或键值对中的键
+	pos     sourcePos
+	start   int
+	end     int
+}
+
+// tomlDuplicates 按策略处理 TOML 中重复的键与表：被舍弃的部分在原文中替换为空白，
+// merge-into-array 的各个值单独解析，在 go-toml 解析完成后写回为数组
+type tomlDuplicates struct {
+	doc    *Document
+	policy DuplicateKeyPolicy
+	merges []*tomlMerge
+}
+
+// tomlMerge 合并为数组的重复键，values 为各次出现的值单独解析得到的文档
+type tomlMerge struct {
+	pointer string
+	values  []*Document
+	keys    []string
+}
+
+func (t *tomlDuplicates) resolve(data []byte) ([]byte, error) {
+	exprs := scanTOMLExpressions(data)
+	var out []byte
+	blank := func(start, end int) {
+		if out == nil {
+			out = append([]byte(nil), data...)
+		}
+		for i := start; i < end; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+	seen := make(map[string]*tomlExpr)
+	merges := make(map[string]*tomlMerge)
+	skip := -1 // 被舍弃的表所在段落的结束位置，段落内的键值对不再检查
+	for _, expr := range exprs {
+		if expr.start < skip {
+			continue
+		}
+		first, exists := seen[expr.pointer]
+		if !exists {
+			seen[expr.pointer] = expr
+			continue
+		}
+		if t.policy == DuplicateKeyError {
+			return nil, &duplicateKeyError{key: expr.path, first: first.pos, second: expr.pos}
+		}
+		t.doc.warnDuplicate(t.policy, expr.path, expr.pos, first.pos)
+		end := expr.end
+		if expr.table {
+			end = expr.section
+		}
+		switch t.policy {
+		case DuplicateKeyKeepFirst:
+			blank(expr.start, end)
+			skip = end
+		case DuplicateKeyMerge:
+			merge, ok := merges[expr.pointer]
+			if !ok {
+				value, err := t.decode(data, first)
+				if err != nil {
+					return nil, err
+				}
+				merge = &tomlMerge{pointer: expr.pointer, keys: first.keys, values: []*Document{value}}
+				merges[expr.pointer] = merge
+				t.merges = append(t.merges, merge)
+			}
+			value, err := t.decode(data, expr)
+			if err != nil {
+				return nil, err
+			}
+			merge.values = append(merge.values, value)
+			blank(expr.start, end)
+			skip = end
+		default:
+			firstEnd := first.end
+			if first.table {
+				firstEnd = first.section
+			}
+			blank(first.start, firstEnd)
+			for pointer, e := range seen {
+				if e.start >= first.start && e.start < firstEnd {
+					delete(seen, pointer)
+				}
+			}
+			seen[expr.pointer] = expr
+		}
+	}
+	if out == nil {
+		return data, nil
+	}
+	return out, nil
+}
+
+// decode 单独解析一次出现的值：键值对只包含它本身，表包含整个段落
+func (t *tomlDuplicates) decode(data []byte, expr *tomlExpr) (*Document, error) {
+	end := expr.end
+	if expr.table {
+		end = expr.section
+	}
+	doc := &Document{}
+	if err := parseTOML(doc, data[expr.start:end], nil); err != nil {
+		return nil, fmt.Errorf("%s: %w", expr.pos, err)
+	}
+	return doc, nil
+}
+
+// apply 将合并的值写回 go-toml 解析得到的数据
+func (t *tomlDuplicates) apply(root map[string]any) error {
+	for _, merge := range t.merges {
+		items := make([]any, len(merge.values))
+		t.doc.dropKeyOrder(merge.pointer)
+		local := ""
+		for _, key := range merge.keys {
+			local = childPointer(local, key)
+		}
+		for i, value := range merge.values {
+			items[i] = lookupTOMLKeys(value.Value, merge.keys)
+			t.doc.moveKeyOrder(value, local, indexPointer(merge.pointer, i))
+		}
+		if !setPointer(root, merge.pointer, items) {
+			return fmt.Errorf("cannot merge duplicate key at %s", merge.pointer)
+		}
+	}
+	return nil
+}
+
+func lookupTOMLKeys(value any, keys []string) any {
+	for _, key := range keys {
+		m, _ := value.(map[string]any)
+		value = m[key]
+	}
+	return value
+}
+
+// scanTOMLExpressions 按顺序列出表头与键值对，计算完整路径；数组表的元素以下标区分。
+// 语法错误时返回已扫描的部分，由 go-toml 报告错误
+func scanTOMLExpressions(data []byte) []*tomlExpr {
+	var parser unstable.Parser
+	parser.Reset(data)
+	lines := newLineIndex(data, 1)
+	arrays := make(map[string]int) // 数组表已出现的元素个数
+	var exprs []*tomlExpr
+	var tables []*tomlExpr
+	current, currentPath := "", ""
+	for parser.NextExpression() {
+		node := parser.Expression()
+		if node.Kind != unstable.Table && node.Kind != unstable.ArrayTable && node.Kind != unstable.KeyValue {
+			continue
+		}
+		it := node.Key()
+		if !it.Next() {
+			continue
+		}
+		offset := int(it.Node().Raw.Offset)
+		expr := &tomlExpr{keys: tomlKeys(node), pos: lines.pos(int64(offset))}
+		expr.start = bytes.LastIndexByte(data[:offset], '\n') + 1
+		if node.Kind == unstable.KeyValue {
+			expr.pointer, expr.path = current, currentPath
+			for _, key := range expr.keys {
+				expr.pointer, expr.path = childPointer(expr.pointer, key), childPath(expr.path, key)
+			}
+		} else {
+			pointer, path := "", ""
+			for i, key := range expr.keys {
+				pointer, path = childPointer(pointer, key), childPath(path, key)
+				if n, ok := arrays[pointer]; ok && (node.Kind == unstable.Table || i < len(expr.keys)-1) {
+					pointer, path = indexPointer(pointer, n-1), indexPath(path, n-1)
+				}
+			}
+			if node.Kind == unstable.ArrayTable {
+				arrays[pointer]++
+				current, currentPath = indexPointer(pointer, arrays[pointer]-1), indexPath(path, arrays[pointer]-1)
+				expr.pointer, expr.path = current, currentPath
+			} else {
+				current, currentPath = pointer, path
+				expr.pointer, expr.path, expr.table = pointer, path, true
+			}
+			tables = append(tables, expr)
+		}
+		if n := len(exprs); n > 0 {
+			exprs[n-1].end = expr.start
+		}
+		exprs = append(exprs, expr)
+	}
+	if n := len(exprs); n > 0 {
+		exprs[n-1].end = len(data)
+	}
+	for i, table := range tables {
+		table.section = len(data)
+		if i+1 < len(tables) {
+			table.section = tables[i+1].start
+		}
+	}
+	return exprs
+}
+
+func tomlKeys(expr *unstable.Node) []string {
+	var keys []string
+	it := expr.Key()
+	for it.Next() {
+		keys = append(keys, string(it.Node().Data))
+	}
+	return keys
+}
+
+// marshalTOML 按键顺序输出，每个表中先输出键值对，再输出子表与数组表；null 与混合类型的数组按 TOMLFallback 处理，
+// 日期时间字符串与 $date 输出为 TOML 日期时间，超出 int64 的整数与超出 float64 精度的小数输出为字符串以保留全部数字
+func marshalTOML(doc *Document, opts *Options) ([]byte, error) {
+	if _, ok := doc.Ordered(opts).(*Record); !ok {
+		return nil, errors.New("toml document root must be an object")
+	}
+	prepared, err := prepareTOML(doc, doc.Ordered(opts), "", opts.tomlFallback())
+	if err != nil {
+		return nil, err
+	}
+	root := prepared.(*Record)
+	var buf bytes.Buffer
+	comments := opts.comments(doc)
+	if c := comments[""]; c != nil && len(c.Head) > 0 {
+		writeTOMLComments(&buf, c.Head)
+		buf.WriteByte('\n')
+	}
+	if err := writeTOMLTable(&buf, doc, comments, nil, "", root, false); err != nil {
+		return nil, err
+	}
+	if c := comments[""]; c != nil && len(c.Foot) > 0 {
+		buf.WriteByte('\n')
+		writeTOMLComments(&buf, c.Foot)
+	}
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+// writeTOMLTable 输出表头、键值对与子表，comments 中的前置与行尾注释写为 # 注释，
+// 表的末尾注释写在键值对之后；内联表与数组内部的注释无法保留
+func writeTOMLTable(buf *bytes.Buffer, doc *Document, comments Comments, path []string, pointer string, table *Record, arrayTable bool) error {
+	var tables []string
+	var pairs bytes.Buffer
+	for _, key := range table.Keys {
+		value := table.Values[key]
+		if value == nil {
+			continue
+		}
+		if isTOMLTable(value) || isTOMLArrayTable(value) {
+			tables = append(tables, key)
+			continue
+		}
+		c := comments[childPointer(pointer, key)]
+		if c != nil {
+			writeTOMLComments(&pairs, c.Head)
+		}
+		pairs.WriteString(tomlKey(key) + " = ")
+		if err := writeTOMLInline(&pairs, doc, append(path, key), value); err != nil {
+			return err
+		}
+		if c != nil && c.Line != "" {
+			pairs.WriteString(" # " + c.Line)
+		}
+		pairs.WriteByte('\n')
+	}
+	var head []string
+	if arrayTable && strings.HasSuffix(pointer, "/0") {
+		// 数组表的注释写在第一个元素之前
+		if c := comments[pointer[:len(pointer)-2]]; c != nil {
+			head = append(head, c.Head...)
+		}
+	}
+	c := comments[pointer]
+	if c != nil && len(path) > 0 {
+		head = append(head, c.Head...)
+	}
+	hasHeader := arrayTable || len(path) > 0 && (pairs.Len() > 0 || len(tables) == 0)
+	if hasHeader || len(head) > 0 {
+		buf.WriteByte('\n')
+	}
+	writeTOMLComments(buf, head)
+	if hasHeader {
+		header := tomlPath(path)
+		if arrayTable {
+			buf.WriteString("[[" + header + "]]")
+		} else {
+			buf.WriteString("[" + header + "]")
+		}
+		if c != nil && c.Line != "" {
+			buf.WriteString(" # " + c.Line)
+		}
+		buf.WriteByte('\n')
+	}
+	buf.Write(pairs.Bytes())
+	if c != nil && len(path) > 0 {
+		writeTOMLComments(buf, c.Foot)
+	}
+	for _, key := range tables {
+		childPath := append(append([]string(nil), path...), key)
+		child := childPointer(pointer, key)
+		switch v := table.Values[key].(type) {
+		case *Record:
+			if err := writeTOMLTable(buf, doc, comments, childPath, child, v, false); err != nil {
+				return err
+			}
+		case []any:
+			for i, item := range v {
+				if err := writeTOMLTable(buf, doc, comments, childPath, indexPointer(child, i), item.(*Record), true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTOMLComments 每行注释输出为一行 # 注释
+func writeTOMLComments(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString("# " + line + "\n")
+	}
+}
+
+// isTOMLTable 对象输出为表，$date 与 $numberDouble 注解对象除外
+func isTOMLTable(value any) bool {
+	record, ok := value.(*Record)
+	if !ok {
+		return false
+	}
+	_, date := tomlDateAnnotation(record)
+	_, double := doubleAnnotation(record)
+	return !date && !double
+}
+
+// tomlDateAnnotation $date 注解对象输出为偏移日期时间
+func tomlDateAnnotation(record *Record) (string, bool) {
+	value, ok := record.Values[annotationDate]
+	if !ok || record.Len() != 1 {
+		return "", false
+	}
+	if inner, ok := value.(*Record); ok {
+		value = inner.Values
+	}
+	t, err := parseDateAnnotation(value)
+	if err != nil {
+		return "", false
+	}
+	return t.Format(time.RFC3339Nano), true
+}
+
+// isTOMLArrayTable 元素全部为对象的非空数组输出为 [[数组表]]
+func isTOMLArrayTable(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOMLInline 输出行内的值，对象输出为内联表
+func writeTOMLInline(buf *bytes.Buffer, doc *Document, path []string, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return fmt.Errorf("%s: null is not supported in toml arrays", tomlPath(path))
+	case *Record:
+		if literal, ok := tomlDateAnnotation(v); ok {
+			buf.WriteString(literal)
+			return nil
+		}
+		if f, ok := doubleAnnotation(v); ok {
+			buf.WriteString(tomlFloat(f))
+			return nil
+		}
+		buf.WriteByte('{')
+		first := true
+		for _, key := range v.Keys {
+			if v.Values[key] == nil {
+				continue
+			}
+			if !first {
+				buf.WriteString(", ")
+			}
+			first = false
+			buf.WriteString(tomlKey(key) + " = ")
+			if err := writeTOMLInline(buf, doc, append(path, key), v.Values[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLInline(buf, doc, append(path, strconv.Itoa(i)), item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		buf.WriteString(tomlScalar(doc, path, v))
+	}
+	return nil
+}
+
+func tomlScalar(doc *Document, path []string, value any) string {
+	switch v := value.(type) {
+	case string:
+		if literal, ok := tomlDateTime(v); ok {
+			return literal
+		}
+		return tomlString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		literal := v.String()
+		if kind := ClassifyNumber(literal); kind == NumberUint64 || kind == NumberBigInt {
+			doc.Warn(0, tomlPath(path), "integer %s out of toml int64 range, written as string", literal)
+			return tomlString(literal)
+		}
+		return literal
+	case float32:
+		return tomlFloat(float64(v))
+	case float64:
+		return tomlFloat(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			doc.Warn(0, tomlPath(path), "integer %d out of toml int64 range, written as string", v)
+			return tomlString(strconv.FormatUint(v, 10))
+		}
+		return strconv.FormatUint(v, 10)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		return fmt.Sprint(v)
+	default:
+		return tomlString(scalarString(v))
+	}
+}
+
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+var tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if tomlBareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlString 含双引号且可以写成字面量字符串时使用单引号，否则输出转义后的基本字符串
+func tomlString(s string) string {
+	literal := strings.Contains(s, `"`) && !strings.ContainsRune(s, '\'') && strings.IndexFunc(s, func(r rune) bool {
+		return r < 0x20 && r != '\t' || r == 0x7f
+	}) < 0
+	if literal {
+		return "'" + s + "'"
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
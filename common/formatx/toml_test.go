@@ -0,0 +1,106 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+)
+
+func tomlOutput(t *testing.T, input string, fallback TOMLFallback) (string, []Warning, error) {
+	t.Helper()
+	doc, err := Parse(FormatJSON, []byte(input), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	data, err := Marshal(FormatTOML, doc, &Options{TOMLFallback: fallback})
+	return string(data), doc.Warnings, err
+}
+
+// 整数与浮点数同为数值，不算混合类型
+func TestTOMLNumericArray(t *testing.T) {
+	for _, fallback := range []TOMLFallback{TOMLFallbackError, TOMLFallbackOmit, TOMLFallbackString} {
+		out, warnings, err := tomlOutput(t, `{"a": [1, 2.5, 3]}`, fallback)
+		if err != nil {
+			t.Fatalf("%s: %v", fallback, err)
+		}
+		if !strings.Contains(out, "a = [1, 2.5, 3]") || len(warnings) != 0 {
+			t.Errorf("%s: got %q, warnings %v", fallback, out, warnings)
+		}
+	}
+}
+
+// 混合类型数组：error 报错并指出路径，omit 按 TOML 1.0 输出并记录提示，string 输出为字符串数组
+func TestTOMLMixedArray(t *testing.T) {
+	input := `{"a": {"b": [1, "x", true]}}`
+	if _, _, err := tomlOutput(t, input, TOMLFallbackError); err == nil || !strings.Contains(err.Error(), "a.b") {
+		t.Errorf("error: expected error naming a.b, got %v", err)
+	}
+	out, warnings, err := tomlOutput(t, input, TOMLFallbackOmit)
+	if err != nil {
+		t.Fatalf("omit: %v", err)
+	}
+	if !strings.Contains(out, `b = [1, "x", true]`) || len(warnings) != 1 || warnings[0].Path != "a.b" {
+		t.Errorf("omit: got %q, warnings %v", out, warnings)
+	}
+	out, warnings, err = tomlOutput(t, input, TOMLFallbackString)
+	if err != nil {
+		t.Fatalf("string: %v", err)
+	}
+	if !strings.Contains(out, `b = ["1", "x", "true"]`) || len(warnings) != 1 {
+		t.Errorf("string: got %q, warnings %v", out, warnings)
+	}
+}
+
+func TestTOMLNull(t *testing.T) {
+	input := `{"a": null, "b": [1, null]}`
+	if _, _, err := tomlOutput(t, input, TOMLFallbackError); err == nil {
+		t.Error("error: expected error for null")
+	}
+	out, warnings, err := tomlOutput(t, input, TOMLFallbackOmit)
+	if err != nil || strings.Contains(out, "a =") || !strings.Contains(out, "b = [1]") || len(warnings) != 2 {
+		t.Errorf("omit: got %q, warnings %v, err %v", out, warnings, err)
+	}
+}
+
+// 超出 float64 范围或精度的小数：error 报错，其他写为字符串并能读回原文
+func TestTOMLDecimal(t *testing.T) {
+	input := `{"big": 1e400, "precise": 1.10000000000000000001, "plain": 0.1}`
+	if _, _, err := tomlOutput(t, input, TOMLFallbackError); err == nil || !strings.Contains(err.Error(), "big") {
+		t.Errorf("error: expected error naming big, got %v", err)
+	}
+	out, warnings, err := tomlOutput(t, input, "")
+	if err != nil {
+		t.Fatalf("omit: %v", err)
+	}
+	if !strings.Contains(out, `big = "1e400"`) || !strings.Contains(out, `precise = "1.10000000000000000001"`) ||
+		!strings.Contains(out, "plain = 0.1") || len(warnings) != 2 {
+		t.Errorf("omit: got %q, warnings %v", out, warnings)
+	}
+	if _, err := Parse(FormatTOML, []byte(out), nil); err != nil {
+		t.Errorf("output does not parse: %v", err)
+	}
+}
+
+// 对象数组输出为 [[array.of.tables]]，日期时间字符串输出为 TOML 日期时间并能读回
+func TestTOMLTablesAndDates(t *testing.T) {
+	input := `{"server": {"items": [{"id": 1, "at": "2024-01-02T03:04:05Z"}, {"id": 2, "day": "2024-01-02"}]}}`
+	out, _, err := tomlOutput(t, input, "")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{"[[server.items]]", "at = 2024-01-02T03:04:05Z", "day = 2024-01-02"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	doc, err := Parse(FormatTOML, []byte(out), nil)
+	if err != nil {
+		t.Fatalf("parse toml: %v", err)
+	}
+	back, err := Marshal(FormatJSON, doc, &Options{Indent: 1})
+	if err != nil {
+		t.Fatalf("marshal json: %v", err)
+	}
+	if got := strings.Join(strings.Fields(string(back)), ""); got != strings.Join(strings.Fields(input), "") {
+		t.Errorf("round trip: %s", got)
+	}
+}
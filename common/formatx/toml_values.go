@@ -0,0 +1,234 @@
+package formatx
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TOMLFallback 输出 TOML 时无法表示的值的处理方式：TOML 没有 null，1.0 之前的版本也不允许数组混合不同类型的元素，
+// 浮点数也无法无损表示超出 float64 范围或精度的小数
+type TOMLFallback string
+
+const (
+	TOMLFallbackError  TOMLFallback = "error"  // 报错并指出路径
+	TOMLFallbackOmit   TOMLFallback = "omit"   // 省略 null 键与数组中的 null 元素，混合类型的数组按 TOML 1.0 原样输出，小数输出为字符串，均记录提示
+	TOMLFallbackString TOMLFallback = "string" // null 输出为空字符串，混合类型数组的元素与小数均输出为字符串，记录提示
+)
+
+// ParseTOMLFallback 校验处理方式，空字符串表示 omit
+func ParseTOMLFallback(name string) (TOMLFallback, error) {
+	switch fallback := TOMLFallback(strings.ToLower(name)); fallback {
+	case "":
+		return TOMLFallbackOmit, nil
+	case TOMLFallbackError, TOMLFallbackOmit, TOMLFallbackString:
+		return fallback, nil
+	default:
+		return "", fmt.Errorf("invalid toml fallback: %q, expected error, omit or string", name)
+	}
+}
+
+func (o *Options) tomlFallback() TOMLFallback {
+	if o == nil || o.TOMLFallback == "" {
+		return TOMLFallbackOmit
+	}
+	return o.TOMLFallback
+}
+
+// 与前端 TIME_PATTERNS 一致的日期时间字符串，输出 TOML 时写为日期时间值；另含 TOML 的本地时间，保证往返不变
+var (
+	tomlDateTimePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})([T ])(\d{2}:\d{2}:\d{2}(?:\.\d+)?)(Z|[+-]\d{2}:?\d{2})?$`)
+	tomlDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlTimePattern     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+)
+
+// tomlDateTime 字符串为合法的日期时间时返回 TOML 字面量：带时区的为偏移日期时间，
+// 不带时区的为本地日期时间，另有本地日期与本地时间；时区 +0800 补全为 +08:00
+func tomlDateTime(s string) (string, bool) {
+	if tomlDatePattern.MatchString(s) {
+		_, err := time.Parse(time.DateOnly, s)
+		return s, err == nil
+	}
+	if tomlTimePattern.MatchString(s) {
+		_, err := time.Parse("15:04:05.999999999", s)
+		return s, err == nil
+	}
+	m := tomlDateTimePattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	zone := m[4]
+	if len(zone) == 5 {
+		zone = zone[:3] + ":" + zone[3:]
+	}
+	check := m[1] + "T" + m[3] + zone
+	if zone == "" {
+		check += "Z"
+	}
+	if _, err := time.Parse(time.RFC3339Nano, check); err != nil {
+		return "", false
+	}
+	return m[1] + m[2] + m[3] + zone, true
+}
+
+// tomlTimeText TOML 日期时间字面量转为 TIME_PATTERNS 能识别的文本：t、z 改为大写，
+// 带时区或带小数秒的日期与时间之间以 T 分隔，其余保留原文的分隔符
+func tomlTimeText(literal string) string {
+	text := strings.ToUpper(literal)
+	if m := tomlDateTimePattern.FindStringSubmatch(text); m != nil && m[2] == " " && (m[4] != "" || strings.Contains(m[3], ".")) {
+		text = m[1] + "T" + m[3] + m[4]
+	}
+	return text
+}
+
+// tomlKind 数组元素的 TOML 类型，整数与浮点数同为 number，如 [1, 2.5] 不算混合类型
+func tomlKind(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case *Record:
+		if _, ok := v.Values[annotationDate]; ok && v.Len() == 1 {
+			return "datetime"
+		}
+		if _, ok := doubleAnnotation(v); ok {
+			return "number"
+		}
+		return "table"
+	case []any:
+		return "array"
+	case string:
+		if _, ok := tomlDateTime(v); ok {
+			return "datetime"
+		}
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number, float32, float64:
+		return "number"
+	case time.Time:
+		return "datetime"
+	}
+	if isNumber(value) {
+		return "number"
+	}
+	return "string"
+}
+
+// prepareTOML 按 fallback 处理 null 与混合类型的数组，返回可以直接输出的数据
+func prepareTOML(doc *Document, value any, path string, fallback TOMLFallback) (any, error) {
+	switch v := value.(type) {
+	case *Record:
+		out := NewRecord()
+		for _, key := range v.Keys {
+			item := v.Values[key]
+			if item == nil {
+				replacement, keep, err := tomlNull(doc, childPath(path, key), fallback)
+				if err != nil {
+					return nil, err
+				}
+				if keep {
+					out.Set(key, replacement)
+				}
+				continue
+			}
+			item, err := prepareTOML(doc, item, childPath(path, key), fallback)
+			if err != nil {
+				return nil, err
+			}
+			out.Set(key, item)
+		}
+		return out, nil
+	case []any:
+		items := make([]any, 0, len(v))
+		kinds := make(map[string]bool)
+		for i, item := range v {
+			if item == nil {
+				replacement, keep, err := tomlNull(doc, indexPath(path, i), fallback)
+				if err != nil {
+					return nil, err
+				}
+				if keep {
+					items = append(items, replacement)
+					kinds["string"] = true
+				}
+				continue
+			}
+			item, err := prepareTOML(doc, item, indexPath(path, i), fallback)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			kinds[tomlKind(item)] = true
+		}
+		if len(kinds) <= 1 {
+			return items, nil
+		}
+		names := make([]string, 0, len(kinds))
+		for kind := range kinds {
+			names = append(names, kind)
+		}
+		sort.Strings(names)
+		switch fallback {
+		case TOMLFallbackError:
+			return nil, fmt.Errorf("%s: mixed-type array (%s) is rejected by the error fallback, use omit to write it as a toml 1.0 array", path, strings.Join(names, ", "))
+		case TOMLFallbackString:
+			doc.Warn(0, path, "mixed-type array written as array of strings")
+			for i, item := range items {
+				text, err := tomlStringValue(item)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = text
+			}
+		default:
+			doc.Warn(0, path, "mixed-type array (%s) written as a toml 1.0 array, older parsers reject it", strings.Join(names, ", "))
+		}
+		return items, nil
+	case json.Number:
+		if ClassifyNumber(v.String()) == NumberDecimal {
+			return tomlDecimal(doc, path, v, fallback)
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// tomlDecimal 处理 float64 无法无损表示的小数：原样写出的字面量会被解析器拒绝或丢失精度，error 报错，其他写为字符串
+func tomlDecimal(doc *Document, path string, number json.Number, fallback TOMLFallback) (any, error) {
+	if fallback == TOMLFallbackError {
+		return nil, fmt.Errorf("%s: decimal %s cannot be written as a toml float without losing precision", path, number)
+	}
+	doc.Warn(0, path, "decimal %s exceeds toml float precision, written as string", number)
+	return number.String(), nil
+}
+
+// tomlNull 处理 null：error 报错，omit 省略，string 写为空字符串
+func tomlNull(doc *Document, path string, fallback TOMLFallback) (any, bool, error) {
+	switch fallback {
+	case TOMLFallbackError:
+		return nil, false, fmt.Errorf("%s: null is not supported in toml", path)
+	case TOMLFallbackString:
+		doc.Warn(0, path, "null written as empty string")
+		return "", true, nil
+	default:
+		doc.Warn(0, path, "null omitted")
+		return nil, false, nil
+	}
+}
+
+// tomlStringValue 混合类型数组中的元素转为字符串，对象与数组为紧凑的 JSON 文本
+func tomlStringValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case *Record, []any:
+		data, err := json.Marshal(v)
+		return string(data), err
+	default:
+		return scalarString(v), nil
+	}
+}
@@ -0,0 +1,562 @@
+package formatx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderMode 表头识别方式
+type HeaderMode string
+
+const (
+	HeaderAuto HeaderMode = "auto"  // 自动识别：首行全部为不重复的非数字文本时作为表头
+	HeaderYes  HeaderMode = "true"  // 首行固定作为表头
+	HeaderNo   HeaderMode = "false" // 无表头，按二维数组输出
+)
+
+// Sheet 工作表数据
+type Sheet struct {
+	Name   string   `json:"name"`             // 工作表名
+	Header []string `json:"header,omitempty"` // 表头，无表头时为空
+	Rows   []any    `json:"rows"`             // 有表头时元素为 *Record，否则为 []any
+}
+
+// XlsxReadOptions Excel 读取选项
+type XlsxReadOptions struct {
+	Header     HeaderMode // 表头识别方式，默认 auto
+	DateLayout string     // 日期单元格输出格式，为空时按单元格内容选择 DateOnly/TimeOnly/DateTime
+	Sheets     []string   // 仅读取指定工作表，为空时读取全部
+}
+
+const (
+	xlsxRelTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	xlsxDefaultWorkbookPath   = "xl/workbook.xml"
+	xlsxMaxColumn             = 16383     // 最大列 XFD
+	xlsxMaxPartSize           = 128 << 20 // 压缩包内单个文件解压后的大小上限
+	xlsxMaxDateSerial         = 2958465   // 最大日期 9999-12-31
+)
+
+var (
+	// excel 1900 日期系统以 1899-12-30 为 0 点（兼容 1900 闰年问题）
+	xlsxEpoch1900 = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	xlsxEpoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	WorkbookPr struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxText struct {
+	Text string `xml:",chardata"`
+}
+
+type xlsxRichText struct {
+	T *xlsxText `xml:"t"`
+	R []struct {
+		T xlsxText `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt *xlsxRichText) String() string {
+	if rt.T != nil {
+		return rt.T.Text
+	}
+	var sb strings.Builder
+	for _, run := range rt.R {
+		sb.WriteString(run.T.Text)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxCell struct {
+	Ref   string        `xml:"r,attr"`
+	Style int           `xml:"s,attr"`
+	Type  string        `xml:"t,attr"`
+	Value *string       `xml:"v"`
+	Is    *xlsxRichText `xml:"is"`
+}
+
+type xlsxRow struct {
+	Index int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+// xlsxReader excel 文件解析上下文
+type xlsxReader struct {
+	files       map[string]*zip.File
+	shared      []string
+	dateStyles  map[int]bool
+	date1904    bool
+	dateLayout  string
+	workbookDir string
+}
+
+// ReadXlsx 读取 Excel 文件，每个工作表输出一组 JSON 数据
+func ReadXlsx(r io.ReaderAt, size int64, opts *XlsxReadOptions) ([]Sheet, error) {
+	if opts == nil {
+		opts = &XlsxReadOptions{}
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	reader := &xlsxReader{
+		files:      make(map[string]*zip.File, len(zr.File)),
+		dateStyles: make(map[int]bool),
+		dateLayout: opts.DateLayout,
+	}
+	for _, f := range zr.File {
+		reader.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	workbookPath := reader.workbookPath()
+	reader.workbookDir = path.Dir(workbookPath)
+	workbook := &xlsxWorkbook{}
+	if err = reader.decode(workbookPath, workbook); err != nil {
+		return nil, fmt.Errorf("read workbook error: %w", err)
+	}
+	reader.date1904 = workbook.WorkbookPr.Date1904 == "1" || workbook.WorkbookPr.Date1904 == "true"
+
+	rels := &xlsxRelationships{}
+	relsPath := path.Join(reader.workbookDir, "_rels", path.Base(workbookPath)+".rels")
+	if err = reader.decode(relsPath, rels); err != nil {
+		return nil, fmt.Errorf("read workbook relationships error: %w", err)
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		targets[rel.ID] = reader.resolve(rel.Target)
+	}
+
+	if err = reader.loadSharedStrings(); err != nil {
+		return nil, err
+	}
+	if err = reader.loadStyles(); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(opts.Sheets))
+	for _, name := range opts.Sheets {
+		wanted[name] = true
+	}
+	sheets := make([]Sheet, 0, len(workbook.Sheets))
+	for _, ws := range workbook.Sheets {
+		if len(wanted) > 0 && !wanted[ws.Name] {
+			continue
+		}
+		target, ok := targets[ws.RID]
+		if !ok {
+			return nil, fmt.Errorf("sheet %q has no relationship target", ws.Name)
+		}
+		rows, err := reader.readRows(target)
+		if err != nil {
+			return nil, fmt.Errorf("read sheet %q error: %w", ws.Name, err)
+		}
+		sheets = append(sheets, buildSheet(ws.Name, rows, opts.Header))
+	}
+	return sheets, nil
+}
+
+// workbookPath 通过根关系文件定位 workbook.xml
+func (x *xlsxReader) workbookPath() string {
+	rels := &xlsxRelationships{}
+	if err := x.decode("_rels/.rels", rels); err != nil {
+		return xlsxDefaultWorkbookPath
+	}
+	for _, rel := range rels.Relationships {
+		if rel.Type == xlsxRelTypeOfficeDocument {
+			return strings.TrimPrefix(path.Clean("/"+rel.Target), "/")
+		}
+	}
+	return xlsxDefaultWorkbookPath
+}
+
+// resolve 将关系文件中的 Target 转为压缩包内路径
+func (x *xlsxReader) resolve(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return path.Join(x.workbookDir, target)
+}
+
+func (x *xlsxReader) open(name string) (io.ReadCloser, error) {
+	f, ok := x.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, fmt.Errorf("%s exceeds %d MiB uncompressed", name, xlsxMaxPartSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	// 压缩包头中的大小不可信，读取时再次限制
+	return &xlsxPartReader{ReadCloser: rc, name: name, remain: xlsxMaxPartSize}, nil
+}
+
+// xlsxPartReader 读取超过 xlsxMaxPartSize 时返回错误，防止压缩炸弹
+type xlsxPartReader struct {
+	io.ReadCloser
+	name   string
+	remain int64
+}
+
+func (r *xlsxPartReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, fmt.Errorf("%s exceeds %d MiB uncompressed", r.name, xlsxMaxPartSize>>20)
+	}
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remain -= int64(n)
+	if r.remain < 0 {
+		return 0, fmt.Errorf("%s exceeds %d MiB uncompressed", r.name, xlsxMaxPartSize>>20)
+	}
+	return n, err
+}
+
+func (x *xlsxReader) decode(name string, v any) error {
+	rc, err := x.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func (x *xlsxReader) loadSharedStrings() error {
+	name := path.Join(x.workbookDir, "sharedStrings.xml")
+	if _, ok := x.files[name]; !ok {
+		return nil
+	}
+	sst := &xlsxSharedStrings{}
+	if err := x.decode(name, sst); err != nil {
+		return fmt.Errorf("read shared strings error: %w", err)
+	}
+	x.shared = make([]string, len(sst.Items))
+	for i := range sst.Items {
+		x.shared[i] = sst.Items[i].String()
+	}
+	return nil
+}
+
+func (x *xlsxReader) loadStyles() error {
+	name := path.Join(x.workbookDir, "styles.xml")
+	if _, ok := x.files[name]; !ok {
+		return nil
+	}
+	styles := &xlsxStyles{}
+	if err := x.decode(name, styles); err != nil {
+		return fmt.Errorf("read styles error: %w", err)
+	}
+	customFmts := make(map[int]string, len(styles.NumFmts))
+	for _, numFmt := range styles.NumFmts {
+		customFmts[numFmt.ID] = numFmt.Code
+	}
+	for i, xf := range styles.CellXfs {
+		if code, ok := customFmts[xf.NumFmtID]; ok {
+			x.dateStyles[i] = isDateFormatCode(code)
+			continue
+		}
+		x.dateStyles[i] = isBuiltinDateFormat(xf.NumFmtID)
+	}
+	return nil
+}
+
+// readRows 流式读取工作表的行，空行会被跳过
+func (x *xlsxReader) readRows(name string) ([][]any, error) {
+	rc, err := x.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]any
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		row := &xlsxRow{}
+		if err = decoder.DecodeElement(row, &start); err != nil {
+			return nil, err
+		}
+		values, err := x.rowValues(row)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			rows = append(rows, values)
+		}
+	}
+	return rows, nil
+}
+
+func (x *xlsxReader) rowValues(row *xlsxRow) ([]any, error) {
+	var values []any
+	next := 0
+	for i := range row.Cells {
+		cell := &row.Cells[i]
+		col := next
+		if cell.Ref != "" {
+			if c, ok := columnIndex(cell.Ref); ok {
+				col = c
+			}
+		}
+		if col > xlsxMaxColumn {
+			return nil, fmt.Errorf("row %d: cell %q is beyond the last column XFD", row.Index, cell.Ref)
+		}
+		next = col + 1
+		value := x.cellValue(cell)
+		if value == nil {
+			continue
+		}
+		for len(values) <= col {
+			values = append(values, nil)
+		}
+		values[col] = value
+	}
+	return values, nil
+}
+
+func (x *xlsxReader) cellValue(cell *xlsxCell) any {
+	if cell.Type == "inlineStr" {
+		if cell.Is == nil {
+			return nil
+		}
+		return cell.Is.String()
+	}
+	if cell.Value == nil {
+		return nil
+	}
+	raw := *cell.Value
+	switch cell.Type {
+	case "s":
+		idx, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || idx < 0 || idx >= len(x.shared) {
+			return raw
+		}
+		return x.shared[idx]
+	case "b":
+		return strings.TrimSpace(raw) == "1"
+	case "str", "e", "d":
+		return raw
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return raw
+	}
+	if x.dateStyles[cell.Style] && number >= 0 && number < xlsxMaxDateSerial+1 {
+		return x.formatDate(number)
+	}
+	if number == math.Trunc(number) && math.Abs(number) < 1<<53 {
+		return int64(number)
+	}
+	return number
+}
+
+// formatDate 将 excel 日期序列号转为时间字符串，格式与前端 TIME_PATTERNS 可识别的格式一致，超出日期范围的序列号由调用方保留原数值
+func (x *xlsxReader) formatDate(serial float64) string {
+	epoch := xlsxEpoch1900
+	if x.date1904 {
+		epoch = xlsxEpoch1904
+	}
+	// 天数与时间分开计算，time.Duration 只能表示约 292 年
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	if x.dateLayout != "" {
+		return t.Format(x.dateLayout)
+	}
+	switch {
+	case serial == math.Trunc(serial):
+		return t.Format(time.DateOnly)
+	case serial < 1:
+		return t.Format(time.TimeOnly)
+	default:
+		return t.Format(time.DateTime)
+	}
+}
+
+// buildSheet 按表头识别方式组装工作表数据
+func buildSheet(name string, rows [][]any, mode HeaderMode) Sheet {
+	sheet := Sheet{Name: name, Rows: make([]any, 0, len(rows))}
+	useHeader := false
+	switch mode {
+	case HeaderYes:
+		useHeader = len(rows) > 0
+	case HeaderNo:
+	default:
+		useHeader = len(rows) > 1 && looksLikeHeader(rows[0])
+	}
+	if !useHeader {
+		for _, row := range rows {
+			sheet.Rows = append(sheet.Rows, row)
+		}
+		return sheet
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	sheet.Header = headerNames(rows[0], width)
+	for _, row := range rows[1:] {
+		record := NewRecord()
+		for i, column := range sheet.Header {
+			var value any
+			if i < len(row) {
+				value = row[i]
+			}
+			record.Set(column, value)
+		}
+		sheet.Rows = append(sheet.Rows, record)
+	}
+	return sheet
+}
+
+// looksLikeHeader 首行的非空单元格全部为不重复的非数字文本时认为是表头
+func looksLikeHeader(row []any) bool {
+	seen := make(map[string]bool, len(row))
+	for _, cell := range row {
+		if cell == nil {
+			continue
+		}
+		text, ok := cell.(string)
+		text = strings.TrimSpace(text)
+		if !ok || text == "" || seen[text] {
+			return false
+		}
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return false
+		}
+		seen[text] = true
+	}
+	return len(seen) > 0
+}
+
+// headerNames 生成列名，空列使用列字母，重名列追加序号
+func headerNames(row []any, width int) []string {
+	names := make([]string, width)
+	used := make(map[string]int, width)
+	for i := 0; i < width; i++ {
+		name := ""
+		if i < len(row) && row[i] != nil {
+			name = strings.TrimSpace(fmt.Sprint(row[i]))
+		}
+		if name == "" {
+			name = columnName(i)
+		}
+		names[i] = uniqueName(used, name, 2)
+	}
+	return names
+}
+
+// columnIndex 解析单元格引用中的列号，如 AB12 -> 27，超过 XFD 的列返回 xlsxMaxColumn+1
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = min(col*26+int(ch-'A'+1), xlsxMaxColumn+2)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// columnName 列号转列字母，如 27 -> AB
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// isBuiltinDateFormat 内置日期格式编号，27-36、50-58 为中日韩区域的日期格式
+func isBuiltinDateFormat(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+// isDateFormatCode 自定义格式中去除引号文本、转义字符与方括号内容后包含日期时间占位符则认为是日期格式
+func isDateFormatCode(code string) bool {
+	var sb strings.Builder
+	inQuote, inBracket, escaped := false, false, false
+	for _, ch := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote:
+			inQuote = ch != '"'
+		case inBracket:
+			inBracket = ch != ']'
+		case ch == '\\' || ch == '_' || ch == '*':
+			escaped = true
+		case ch == '"':
+			inQuote = true
+		case ch == '[':
+			inBracket = true
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	stripped := strings.ToLower(sb.String())
+	if strings.Contains(stripped, "general") {
+		return false
+	}
+	return strings.ContainsAny(stripped, "ymdhs")
+}
@@ -0,0 +1,143 @@
+package formatx
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 导出再导入得到相同的表头与数据，嵌套对象展开为点号分隔的列名
+func TestXlsxRoundTrip(t *testing.T) {
+	items := []any{
+		map[string]any{"id": int64(1), "name": "alice", "profile": map[string]any{"age": int64(30)}},
+		map[string]any{"id": int64(2), "name": "bob", "active": true},
+	}
+	sheets, err := SheetsFromJSON(items, "users")
+	if err != nil {
+		t.Fatalf("sheets: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteXlsx(&buf, sheets); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	read, err := ReadXlsx(bytes.NewReader(buf.Bytes()), int64(buf.Len()), &XlsxReadOptions{Header: HeaderYes})
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(read) != 1 || read[0].Name != "users" || len(read[0].Rows) != 2 {
+		t.Fatalf("unexpected sheets: %+v", read)
+	}
+	if got := strings.Join(read[0].Header, ","); got != "id,name,profile.age,active" {
+		t.Errorf("header: %s", got)
+	}
+	first := read[0].Rows[0].(*Record)
+	if first.Values["id"] != int64(1) || first.Values["profile.age"] != int64(30) || first.Values["active"] != nil {
+		t.Errorf("first row: %v", first.Values)
+	}
+	if second := read[0].Rows[1].(*Record); second.Values["active"] != true {
+		t.Errorf("second row: %v", second.Values)
+	}
+}
+
+// xlsxWithCells 生成只有一个工作表的最小 Excel 文件
+func xlsxWithCells(t *testing.T, refs ...string) []byte {
+	t.Helper()
+	var cells strings.Builder
+	for _, ref := range refs {
+		fmt.Fprintf(&cells, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, ref)
+	}
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="s" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row r="1">` + cells.String() + `</row></sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// 超过 XFD 的列引用报错，不能溢出或按列号分配内存
+func TestXlsxColumnLimit(t *testing.T) {
+	cases := []struct {
+		refs []string
+		ok   bool
+	}{
+		{[]string{"A1", "XFD1"}, true},
+		{[]string{"XFE1"}, false},
+		{[]string{"AAAAAAA1"}, false},
+		{[]string{"ZZZZZZZZZZZZZZZ1"}, false},
+	}
+	for _, c := range cases {
+		data := xlsxWithCells(t, c.refs...)
+		sheets, err := ReadXlsx(bytes.NewReader(data), int64(len(data)), &XlsxReadOptions{Header: HeaderNo})
+		if !c.ok {
+			if err == nil || !strings.Contains(err.Error(), "XFD") {
+				t.Errorf("%v: expected column limit error, got %v", c.refs, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", c.refs, err)
+		}
+		if row := sheets[0].Rows[0].([]any); len(row) != xlsxMaxColumn+1 || row[xlsxMaxColumn] != "XFD1" {
+			t.Errorf("%v: unexpected row length %d", c.refs, len(row))
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for _, name := range []string{"A", "Z", "AA", "AZ", "ZZ", "XFD"} {
+		index, ok := columnIndex(name + "1")
+		if !ok || columnName(index) != name {
+			t.Errorf("%s: index %d, name %s", name, index, columnName(index))
+		}
+	}
+}
+
+// 重名列追加的序号不能与已有列名冲突
+func TestUniqueColumnNames(t *testing.T) {
+	if got := strings.Join(headerNames([]any{"a", "a", "a_2", nil, "D"}, 5), ","); got != "a,a_2,a_2_2,D,D_2" {
+		t.Errorf("xlsx header: %s", got)
+	}
+	if got := strings.Join(uniqueColumns([]string{"a", "a", "a_1", "a", ""}), ","); got != "a,a_1,a_1_1,a_2,E" {
+		t.Errorf("csv header: %s", got)
+	}
+}
+
+// 日期格式单元格超出 excel 日期范围时保留原数值
+func TestXlsxDateRange(t *testing.T) {
+	reader := &xlsxReader{dateStyles: map[int]bool{1: true}}
+	cases := []struct {
+		raw  string
+		want any
+	}{
+		{"45292", "2024-01-01"},
+		{"2958465", "9999-12-31"},
+		{"2958465.5", "9999-12-31 12:00:00"},
+		{"2958466", int64(2958466)},
+		{"2958466.5", 2958466.5},
+		{"1e300", 1e300},
+		{"-1", int64(-1)},
+	}
+	for _, c := range cases {
+		raw := c.raw
+		if got := reader.cellValue(&xlsxCell{Style: 1, Value: &raw}); got != c.want {
+			t.Errorf("%s: got %#v, want %#v", c.raw, got, c.want)
+		}
+	}
+}
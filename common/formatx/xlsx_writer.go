@@ -0,0 +1,287 @@
+package formatx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	xlsxMaxSheetNameLen = 31
+	xlsxDefaultSheet    = "Sheet1"
+)
+
+const xlsxContentTypesHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// 样式 0 为默认样式，样式 1 为加粗（用于表头）
+const xlsxStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// SheetsFromJSON 将 JSON 数据转为工作表：数组输出为单个工作表，值全部为数组的对象按键输出为多个工作表，
+// 嵌套对象展开为点号分隔的列名。传入 *Record 时工作表与列按字段顺序输出，map 按字典序输出
+func SheetsFromJSON(data any, sheetName string) ([]Sheet, error) {
+	if sheetName == "" {
+		sheetName = xlsxDefaultSheet
+	}
+	switch v := data.(type) {
+	case []any:
+		return []Sheet{sheetFromArray(sheetName, v)}, nil
+	case map[string]any:
+		return SheetsFromJSON(&Record{Keys: sortedKeys(v), Values: v}, sheetName)
+	case *Record:
+		if v.Len() == 0 {
+			return nil, errors.New("no sheet data provided")
+		}
+		sheets := make([]Sheet, 0, v.Len())
+		for _, name := range v.Keys {
+			items, ok := v.Values[name].([]any)
+			if !ok {
+				return nil, fmt.Errorf("value of %q is not an array, expect an array or an object of arrays", name)
+			}
+			sheets = append(sheets, sheetFromArray(name, items))
+		}
+		return sheets, nil
+	default:
+		return nil, errors.New("expect an array or an object of arrays")
+	}
+}
+
+func sheetFromArray(name string, items []any) Sheet {
+	sheet := Sheet{Name: name, Rows: make([]any, 0, len(items))}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		record := Flatten(item)
+		for _, key := range record.Keys {
+			if !seen[key] {
+				seen[key] = true
+				sheet.Header = append(sheet.Header, key)
+			}
+		}
+		sheet.Rows = append(sheet.Rows, record)
+	}
+	return sheet
+}
+
+// WriteXlsx 将工作表写为 Excel 文件，表头行加粗
+func WriteXlsx(w io.Writer, sheets []Sheet) error {
+	if len(sheets) == 0 {
+		return errors.New("no sheet data provided")
+	}
+	names := uniqueSheetNames(sheets)
+	zw := zip.NewWriter(w)
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xlsxContentTypesHead)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i := range sheets {
+		idx := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, idx)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(names[i]), idx, idx)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, idx, idx)
+
+		sw, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", idx))
+		if err != nil {
+			return err
+		}
+		if err = writeSheet(sw, &sheets[i]); err != nil {
+			return fmt.Errorf("write sheet %q error: %w", names[i], err)
+		}
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`,
+		len(sheets)+1)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStylesXML},
+	}
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeSheet(w io.Writer, sheet *Sheet) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	rowNum := 0
+	if len(sheet.Header) > 0 {
+		rowNum++
+		fmt.Fprintf(&buf, `<row r="%d">`, rowNum)
+		for col, name := range sheet.Header {
+			writeCell(&buf, col, rowNum, name, 1)
+		}
+		buf.WriteString(`</row>`)
+	}
+	for _, row := range sheet.Rows {
+		rowNum++
+		fmt.Fprintf(&buf, `<row r="%d">`, rowNum)
+		switch r := row.(type) {
+		case *Record:
+			for col, name := range sheet.Header {
+				value, _ := r.Get(name)
+				writeCell(&buf, col, rowNum, value, 0)
+			}
+		case []any:
+			for col, value := range r {
+				writeCell(&buf, col, rowNum, value, 0)
+			}
+		default:
+			writeCell(&buf, 0, rowNum, r, 0)
+		}
+		buf.WriteString(`</row>`)
+		// 按行刷出，避免整表驻留内存
+		if buf.Len() > 64*1024 {
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	buf.WriteString(`</sheetData></worksheet>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// xlsxNumberDigits Excel 数字单元格保留的有效数字位数，更长的数字写为文本
+const xlsxNumberDigits = 15
+
+// XlsxPrecisionWarnings 返回超出 Excel 数字精度、导出时写为文本的数字
+func XlsxPrecisionWarnings(value any) []Warning {
+	return PrecisionWarnings(value, func(literal string) bool {
+		return SignificantDigits(literal) > xlsxNumberDigits
+	}, "number %s exceeds 15 significant digits, written as text")
+}
+
+func writeCell(buf *bytes.Buffer, col, row int, value any, style int) {
+	if value == nil {
+		return
+	}
+	ref := columnName(col) + strconv.Itoa(row)
+	styleAttr := ""
+	if style > 0 {
+		styleAttr = fmt.Sprintf(` s="%d"`, style)
+	}
+	switch v := value.(type) {
+	case bool:
+		b := 0
+		if v {
+			b = 1
+		}
+		fmt.Fprintf(buf, `<c r="%s"%s t="b"><v>%d</v></c>`, ref, styleAttr, b)
+	case json.Number:
+		if SignificantDigits(v.String()) > xlsxNumberDigits {
+			fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, v.String())
+			return
+		}
+		fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, v.String())
+	case float64:
+		fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, strconv.FormatFloat(v, 'g', -1, 64))
+	case int, int64, int32, uint64, uint32:
+		fmt.Fprintf(buf, `<c r="%s"%s><v>%d</v></c>`, ref, styleAttr, v)
+	case string:
+		fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+			ref, styleAttr, xmlEscape(v))
+	default:
+		text, err := json.Marshal(v)
+		if err != nil {
+			text = []byte(fmt.Sprint(v))
+		}
+		fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+			ref, styleAttr, xmlEscape(string(text)))
+	}
+}
+
+// uniqueSheetNames 工作表名去除非法字符、截断为 31 个字符并去重
+func uniqueSheetNames(sheets []Sheet) []string {
+	names := make([]string, len(sheets))
+	used := make(map[string]bool, len(sheets))
+	for i, sheet := range sheets {
+		base := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`[]:*?/\`, r) {
+				return '_'
+			}
+			return r
+		}, strings.TrimSpace(sheet.Name))
+		if base == "" {
+			base = fmt.Sprintf("Sheet%d", i+1)
+		}
+		base = truncateRunes(base, xlsxMaxSheetNameLen)
+		name := base
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf("_%d", n)
+			name = truncateRunes(base, xlsxMaxSheetNameLen-len(suffix)) + suffix
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// xmlEscape 转义文本并去除 XML 不允许的控制字符
+func xmlEscape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, s)
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,553 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const xmlDefaultRoot = "root"
+
+// XMLConvention XML 与 JSON 之间的映射约定
+type XMLConvention string
+
+const (
+	XMLPrefix     XMLConvention = "prefix"     // 属性键加前缀（默认 @），文本为 #text、CDATA 为 #cdata，仅有文本的元素直接输出文本，不保留根元素
+	XMLBadgerFish XMLConvention = "badgerfish" // 保留根元素，属性键为 @name，文本为 $，命名空间声明合并到 @xmlns
+	XMLParker     XMLConvention = "parker"     // 丢弃属性与根元素，仅有文本的元素按 JSON 标量推断类型
+	XMLGData      XMLConvention = "gdata"      // 保留根元素，属性键不加前缀，文本为 $t，名称中命名空间前缀的冒号替换为 $
+)
+
+// ParseXMLConvention 校验映射约定，空字符串表示 prefix
+func ParseXMLConvention(name string) (XMLConvention, error) {
+	switch convention := XMLConvention(strings.ToLower(name)); convention {
+	case "":
+		return XMLPrefix, nil
+	case XMLPrefix, XMLBadgerFish, XMLParker, XMLGData:
+		return convention, nil
+	default:
+		return "", fmt.Errorf("invalid xml convention: %q, expected prefix, badgerfish, parker or gdata", name)
+	}
+}
+
+// XMLOptions XML 映射选项
+type XMLOptions struct {
+	Convention XMLConvention // 映射约定，默认 prefix
+	AttrPrefix string        // prefix 约定的属性键前缀，默认 @
+	TextKey    string        // prefix 约定的文本键，默认 #text
+	ForceArray []string      // 只出现一次也输出为数组的元素，按 JSON 路径以 . 分隔，不含数组下标
+	Root       string        // 写出 XML 时的根元素名，默认 root；badgerfish 与 gdata 的数据只有一个元素键时以该键为根
+}
+
+// xmlMapping 按约定与选项确定的键名规则
+type xmlMapping struct {
+	convention XMLConvention
+	attrPrefix string // 属性键前缀，gdata 为空
+	textKey    string // 文本键，parker 不保存混合内容中的文本
+	cdataKey   string // CDATA 键，只有 prefix 约定单独保存 CDATA
+	forceArray map[string]bool
+	root       string
+}
+
+func (o *Options) xmlMapping() *xmlMapping {
+	var x XMLOptions
+	if o != nil {
+		x = o.XML
+	}
+	m := &xmlMapping{convention: x.Convention, attrPrefix: "@", textKey: "#text", cdataKey: "#cdata", root: x.Root, forceArray: make(map[string]bool)}
+	switch m.convention {
+	case XMLBadgerFish:
+		m.textKey, m.cdataKey = "$", ""
+	case XMLGData:
+		m.attrPrefix, m.textKey, m.cdataKey = "", "$t", ""
+	case XMLParker:
+		m.attrPrefix, m.textKey, m.cdataKey = "", "", ""
+	default:
+		m.convention = XMLPrefix
+		if x.AttrPrefix != "" {
+			m.attrPrefix = x.AttrPrefix
+		}
+		if x.TextKey != "" {
+			m.textKey = x.TextKey
+		}
+	}
+	if m.root == "" {
+		m.root = xmlDefaultRoot
+	}
+	for _, path := range x.ForceArray {
+		m.forceArray[strings.TrimSpace(path)] = true
+	}
+	return m
+}
+
+// keepRoot 根元素作为顶层对象唯一的键保留
+func (m *xmlMapping) keepRoot() bool {
+	return m.convention == XMLBadgerFish || m.convention == XMLGData
+}
+
+// key 元素或属性的限定名转为键名，前缀与本地名之间 gdata 以 $ 连接，其他约定保留冒号
+func (m *xmlMapping) key(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	if m.convention == XMLGData {
+		return name.Space + "$" + name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlName 键名转回限定名
+func (m *xmlMapping) xmlName(key string) string {
+	if m.convention == XMLGData {
+		return strings.Replace(key, "$", ":", 1)
+	}
+	return key
+}
+
+// xmlNode 解析过程中的元素节点，attrs 保持属性顺序，children 保持子元素首次出现的顺序
+type xmlNode struct {
+	name     xml.Name
+	path     string // 元素在 JSON 中的路径，用于匹配 ForceArray
+	attrs    *Record
+	texts    []any
+	cdata    []any
+	order    []string
+	children map[string][]any
+}
+
+// parseXML 按映射约定解析 XML，默认 prefix 约定与前端 xmlToJson 一致：属性以 @ 前缀、文本以 #text 保存，
+// 重复子元素合并为数组，仅有文本的元素直接输出文本，空元素输出空字符串，根元素名不保留。
+// 元素与属性名保留命名空间前缀，文本去掉首尾空白，CDATA 保留原文
+func parseXML(doc *Document, data []byte, opts *Options) error {
+	m := opts.xmlMapping()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root any
+	found := false
+	for {
+		offset := decoder.InputOffset()
+		// RawToken 不把前缀解析为命名空间 URI，保留原文中的前缀
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && found {
+				return xmlSyntaxError(decoder, "multiple root elements")
+			}
+			node := &xmlNode{name: t.Name, attrs: NewRecord(), children: make(map[string][]any)}
+			switch {
+			case len(stack) > 0:
+				node.path = childPath(stack[len(stack)-1].path, m.key(t.Name))
+			case m.keepRoot():
+				node.path = m.key(t.Name)
+			}
+			for _, attr := range t.Attr {
+				m.setAttr(node.attrs, attr)
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			node := stack[len(stack)-1]
+			if bytes.HasPrefix(data[offset:], []byte("<![CDATA[")) {
+				if len(t) == 0 {
+					continue
+				}
+				if m.cdataKey != "" {
+					node.cdata = append(node.cdata, string(t))
+				} else {
+					node.texts = append(node.texts, string(t))
+				}
+			} else if text := strings.TrimSpace(string(t)); text != "" {
+				node.texts = append(node.texts, text)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != t.Name {
+				open := ""
+				if len(stack) > 0 {
+					open = qualifiedName(stack[len(stack)-1].name)
+				}
+				return xmlSyntaxError(decoder, fmt.Sprintf("element <%s> closed by </%s>", open, qualifiedName(t.Name)))
+			}
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			value := m.value(node)
+			key := m.key(t.Name)
+			if len(stack) == 0 {
+				root, found = value, true
+				if m.keepRoot() {
+					record := NewRecord()
+					record.Set(key, value)
+					root = record
+				}
+				continue
+			}
+			parent := stack[len(stack)-1]
+			if _, ok := parent.children[key]; !ok {
+				parent.order = append(parent.order, key)
+			}
+			parent.children[key] = append(parent.children[key], value)
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unexpected end of input, element <%s> not closed", qualifiedName(stack[len(stack)-1].name))
+	}
+	if !found {
+		return errors.New("no root element found")
+	}
+	doc.SetOrdered(root)
+	return nil
+}
+
+func xmlSyntaxError(decoder *xml.Decoder, msg string) error {
+	line, column := decoder.InputPos()
+	return fmt.Errorf("line %d column %d: %s", line, column, msg)
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlnsPrefix 命名空间声明的前缀，默认命名空间为空字符串
+func xmlnsPrefix(name xml.Name) (string, bool) {
+	switch {
+	case name.Space == "" && name.Local == "xmlns":
+		return "", true
+	case name.Space == "xmlns":
+		return name.Local, true
+	default:
+		return "", false
+	}
+}
+
+// setAttr 按约定保存属性，badgerfish 的命名空间声明合并到 @xmlns，默认命名空间的键为 $
+func (m *xmlMapping) setAttr(attrs *Record, attr xml.Attr) {
+	switch m.convention {
+	case XMLParker:
+		return
+	case XMLBadgerFish:
+		if prefix, ok := xmlnsPrefix(attr.Name); ok {
+			namespaces, _ := attrs.Get("@xmlns")
+			record, ok := namespaces.(*Record)
+			if !ok {
+				record = NewRecord()
+				attrs.Set("@xmlns", record)
+			}
+			if prefix == "" {
+				prefix = "$"
+			}
+			record.Set(prefix, attr.Value)
+			return
+		}
+	}
+	attrs.Set(m.attrPrefix+m.key(attr.Name), attr.Value)
+}
+
+func (m *xmlMapping) value(n *xmlNode) any {
+	if m.convention == XMLParker {
+		return parkerValue(n, m)
+	}
+	obj := n.attrs
+	setXMLText(obj, m.textKey, n.texts)
+	setXMLText(obj, m.cdataKey, n.cdata)
+	m.setChildren(obj, n)
+	if m.convention != XMLPrefix {
+		return obj
+	}
+	if text, ok := obj.Get(m.textKey); ok && obj.Len() == 1 {
+		return text
+	}
+	if obj.Len() == 0 {
+		return ""
+	}
+	return obj
+}
+
+// parkerValue parker 约定：有子元素时只保留子元素，仅有文本时按 JSON 标量推断类型，空元素为 null
+func parkerValue(n *xmlNode, m *xmlMapping) any {
+	if len(n.order) > 0 {
+		obj := NewRecord()
+		m.setChildren(obj, n)
+		return obj
+	}
+	if len(n.texts) == 0 {
+		return nil
+	}
+	var text strings.Builder
+	for _, segment := range n.texts {
+		text.WriteString(segment.(string))
+	}
+	return parkerScalar(text.String())
+}
+
+func parkerScalar(text string) any {
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if number := hjsonNumberPattern.FindString(text); number != "" && number == text {
+		return json.Number(text)
+	}
+	return text
+}
+
+func (m *xmlMapping) setChildren(obj *Record, n *xmlNode) {
+	for _, name := range n.order {
+		values := n.children[name]
+		if len(values) == 1 && !m.forceArray[childPath(n.path, name)] {
+			obj.Set(name, values[0])
+		} else {
+			obj.Set(name, values)
+		}
+	}
+}
+
+// setXMLText 单个文本段直接保存，多个文本段（如被子元素或注释分隔）保存为数组
+func setXMLText(obj *Record, key string, texts []any) {
+	switch {
+	case key == "" || len(texts) == 0:
+	case len(texts) == 1:
+		obj.Set(key, texts[0])
+	default:
+		obj.Set(key, texts)
+	}
+}
+
+// xmlWriter 按映射约定写出 XML
+type xmlWriter struct {
+	buf     bytes.Buffer
+	doc     *Document
+	mapping *xmlMapping
+	indent  string
+}
+
+// marshalXML 按映射约定序列化 XML：数组写为同名的重复元素，prefix 与 badgerfish 约定中带前缀的键写为属性，
+// gdata 约定中标量值写为属性；根元素默认为 root，顶层为数组时每项写为 item 元素
+func marshalXML(doc *Document, opts *Options) ([]byte, error) {
+	w := &xmlWriter{doc: doc, mapping: opts.xmlMapping(), indent: strings.Repeat(" ", opts.indent())}
+	w.buf.WriteString(xml.Header)
+	name, value, _ := w.mapping.rootElement(doc.Ordered(opts))
+	if items, ok := value.([]any); ok {
+		record := NewRecord()
+		record.Set("item", items)
+		value = record
+	}
+	w.element(name, value, "", "")
+	w.buf.Truncate(w.buf.Len() - 1)
+	return w.buf.Bytes(), nil
+}
+
+// rootElement badgerfish 与 gdata 的数据只有一个元素键时以该键为根元素，否则以 Root 包裹，wrapped 为 true
+func (m *xmlMapping) rootElement(value any) (name string, content any, wrapped bool) {
+	if record, ok := value.(*Record); ok && m.keepRoot() && record.Len() == 1 {
+		key := record.Keys[0]
+		if kind := m.kind(key, record.Values[key]); kind == xmlChild {
+			if _, isArray := record.Values[key].([]any); !isArray {
+				return m.xmlName(key), record.Values[key], false
+			}
+		}
+	}
+	return m.root, value, true
+}
+
+type xmlKeyKind int
+
+const (
+	xmlChild xmlKeyKind = iota
+	xmlAttr
+	xmlNamespaces
+	xmlText
+	xmlCDATA
+)
+
+// kind 对象中的键写为子元素、属性、命名空间声明、文本还是 CDATA
+func (m *xmlMapping) kind(key string, value any) xmlKeyKind {
+	switch m.convention {
+	case XMLParker:
+		return xmlChild
+	case XMLGData:
+		if key == m.textKey {
+			return xmlText
+		}
+		switch value.(type) {
+		case *Record, []any:
+			return xmlChild
+		}
+		return xmlAttr
+	}
+	switch {
+	case key == m.textKey:
+		return xmlText
+	case m.cdataKey != "" && key == m.cdataKey:
+		return xmlCDATA
+	case m.convention == XMLBadgerFish && key == "@xmlns":
+		if _, ok := value.(*Record); ok {
+			return xmlNamespaces
+		}
+		return xmlAttr
+	case strings.HasPrefix(key, m.attrPrefix):
+		return xmlAttr
+	}
+	return xmlChild
+}
+
+// element 写出一个元素，value 为数组时逐项写出同名元素，嵌套数组的每一项写为 item 子元素
+func (w *xmlWriter) element(key string, value any, path, current string) {
+	switch v := value.(type) {
+	case []any:
+		for i, item := range v {
+			if items, nested := item.([]any); nested {
+				record := NewRecord()
+				record.Set("item", items)
+				item = record
+			}
+			w.element(key, item, indexPath(path, i), current)
+		}
+		return
+	}
+	name := w.name(key, path)
+	record, ok := value.(*Record)
+	if !ok {
+		w.buf.WriteString(current + "<" + name)
+		if value == nil {
+			w.buf.WriteString("/>\n")
+			return
+		}
+		w.buf.WriteString(">")
+		_ = xml.EscapeText(&w.buf, []byte(scalarString(value)))
+		w.buf.WriteString("</" + name + ">\n")
+		return
+	}
+	var texts, cdata []string
+	var children []string
+	w.buf.WriteString(current + "<" + name)
+	for _, k := range record.Keys {
+		item := record.Values[k]
+		switch w.mapping.kind(k, item) {
+		case xmlAttr:
+			w.attr(w.name(w.mapping.xmlName(strings.TrimPrefix(k, w.mapping.attrPrefix)), childPath(path, k)), item)
+		case xmlNamespaces:
+			namespaces := item.(*Record)
+			for _, prefix := range namespaces.Keys {
+				if prefix == "$" {
+					w.attr("xmlns", namespaces.Values[prefix])
+				} else {
+					w.attr("xmlns:"+prefix, namespaces.Values[prefix])
+				}
+			}
+		case xmlText:
+			texts = append(texts, xmlSegments(item)...)
+		case xmlCDATA:
+			cdata = append(cdata, xmlSegments(item)...)
+		default:
+			// 空数组没有对应的元素
+			if items, isArray := item.([]any); !isArray || len(items) > 0 {
+				children = append(children, k)
+			}
+		}
+	}
+	switch {
+	case len(children) == 0 && len(texts)+len(cdata) == 0:
+		w.buf.WriteString("/>\n")
+		return
+	case len(children) == 0 && len(texts)+len(cdata) == 1:
+		w.buf.WriteString(">")
+		w.text(texts, cdata)
+		w.buf.WriteString("</" + name + ">\n")
+		return
+	}
+	w.buf.WriteString(">\n")
+	// 文本与子元素的相对位置没有保存，文本段写在子元素之前
+	for _, text := range texts {
+		w.buf.WriteString(current + w.indent)
+		w.text([]string{text}, nil)
+		w.buf.WriteString("\n")
+	}
+	for _, text := range cdata {
+		w.buf.WriteString(current + w.indent)
+		w.text(nil, []string{text})
+		w.buf.WriteString("\n")
+	}
+	for _, k := range children {
+		w.element(w.mapping.xmlName(k), record.Values[k], childPath(path, k), current+w.indent)
+	}
+	w.buf.WriteString(current + "</" + name + ">\n")
+}
+
+func (w *xmlWriter) attr(name string, value any) {
+	w.buf.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(&w.buf, []byte(scalarString(value)))
+	w.buf.WriteString(`"`)
+}
+
+// text 写出文本段，CDATA 中的 ]]> 拆分到两个 CDATA 段中
+func (w *xmlWriter) text(texts, cdata []string) {
+	for _, text := range texts {
+		_ = xml.EscapeText(&w.buf, []byte(text))
+	}
+	for _, text := range cdata {
+		w.buf.WriteString("<![CDATA[" + strings.ReplaceAll(text, "]]>", "]]]]><![CDATA[>") + "]]>")
+	}
+}
+
+func xmlSegments(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return []string{scalarString(value)}
+	}
+	segments := make([]string, len(items))
+	for i, item := range items {
+		segments[i] = scalarString(item)
+	}
+	return segments
+}
+
+// name 键不是合法的 XML 名称时将非法字符替换为 _，并记录提示
+func (w *xmlWriter) name(key, path string) string {
+	name := []rune(key)
+	for i, r := range name {
+		if !isXMLNameRune(r, i == 0) {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 {
+		name = []rune{'_'}
+	}
+	if string(name) != key {
+		w.doc.Warn(0, path, "key %q is not a valid XML name, written as <%s>", key, string(name))
+	}
+	return string(name)
+}
+
+func isXMLNameRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' || r == ':' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '-' || r == '.' || unicode.Is(unicode.Mn, r))
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,370 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFidelityWarnings 往返检查最多报告的差异数，超出部分只计数
+const maxFidelityWarnings = 100
+
+// XMLFidelity 按相同选项往返转换一次，报告 XML 映射中丢失或改变的内容：
+// 源格式为 XML 时将解析结果写回 XML 与原文比较，目标格式为 XML 时将输出读回后与转换前的数据比较
+func XMLFidelity(from, to Format, data []byte, doc *Document, out []byte, opts *Options) ([]Warning, error) {
+	report := &fidelityReport{}
+	switch {
+	case from == FormatXML:
+		written, err := marshalXML(&Document{Value: doc.Value, Order: doc.Order}, opts)
+		if err != nil {
+			return nil, err
+		}
+		original, err := parseXMLTree(data)
+		if err != nil {
+			return nil, err
+		}
+		roundTrip, err := parseXMLTree(written)
+		if err != nil {
+			return nil, fmt.Errorf("read back xml error: %w", err)
+		}
+		report.compareElements(original.root, roundTrip.root, "/"+original.root.name)
+		if original.comments > 0 {
+			report.add("", "%d comments dropped", original.comments)
+		}
+		if original.instructions > 0 {
+			report.add("", "%d processing instructions dropped", original.instructions)
+		}
+		if original.directives > 0 {
+			report.add("", "DOCTYPE and other declarations dropped")
+		}
+	case to == FormatXML:
+		back := &Document{}
+		if err := parseXML(back, out, opts); err != nil {
+			return nil, fmt.Errorf("read back xml error: %w", err)
+		}
+		value := back.Value
+		// 保留根元素的约定读回时多出一层包裹用的根元素
+		m := opts.xmlMapping()
+		if _, _, wrapped := m.rootElement(doc.Ordered(opts)); wrapped && m.keepRoot() {
+			if root, ok := value.(map[string]any); ok {
+				value = root[m.key(xml.Name{Local: m.root})]
+			}
+		}
+		report.compareValues(doc.Value, value, "")
+	}
+	return report.result(), nil
+}
+
+// fidelityReport 往返检查发现的差异
+type fidelityReport struct {
+	warnings []Warning
+	count    int
+}
+
+func (r *fidelityReport) add(path, format string, args ...any) {
+	r.count++
+	if len(r.warnings) < maxFidelityWarnings {
+		r.warnings = append(r.warnings, Warning{Path: path, Message: "round trip: " + fmt.Sprintf(format, args...)})
+	}
+}
+
+func (r *fidelityReport) result() []Warning {
+	if r.count > len(r.warnings) {
+		return append(r.warnings, Warning{Message: fmt.Sprintf("round trip: %d more differences", r.count-len(r.warnings))})
+	}
+	return r.warnings
+}
+
+// xmlElement 与映射约定无关的元素树，用于比较往返前后的 XML
+type xmlElement struct {
+	name      string
+	attrs     map[string]string
+	attrOrder []string
+	texts     []string // 去掉首尾空白的文本段，CDATA 保留原文
+	cdata     int      // CDATA 段数
+	layout    []byte   // 内容的先后顺序，t 为文本、e 为子元素
+	children  []*xmlElement
+}
+
+type xmlTree struct {
+	root                               *xmlElement
+	comments, instructions, directives int
+}
+
+func parseXMLTree(data []byte) (*xmlTree, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	tree := &xmlTree{}
+	var stack []*xmlElement
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: qualifiedName(t.Name), attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				name := qualifiedName(attr.Name)
+				element.attrs[name] = attr.Value
+				element.attrOrder = append(element.attrOrder, name)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+				parent.layout = append(parent.layout, 'e')
+			} else if tree.root == nil {
+				tree.root = element
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			element := stack[len(stack)-1]
+			text := strings.TrimSpace(string(t))
+			if bytes.HasPrefix(data[offset:], []byte("<![CDATA[")) {
+				text = string(t)
+				element.cdata++
+			}
+			if text != "" {
+				element.texts = append(element.texts, text)
+				element.layout = append(element.layout, 't')
+			}
+		case xml.Comment:
+			tree.comments++
+		case xml.ProcInst:
+			if t.Target != "xml" {
+				tree.instructions++
+			}
+		case xml.Directive:
+			tree.directives++
+		}
+	}
+	if tree.root == nil {
+		return nil, errors.New("no root element found")
+	}
+	return tree, nil
+}
+
+// compareElements 比较原文与写回的元素，子元素按同名元素的出现次序配对
+func (r *fidelityReport) compareElements(a, b *xmlElement, path string) {
+	if a.name != b.name {
+		r.add(path, "element <%s> written back as <%s>", a.name, b.name)
+	}
+	for _, name := range a.attrOrder {
+		value, ok := b.attrs[name]
+		switch {
+		case !ok:
+			r.add(path+"/@"+name, "attribute lost")
+		case value != a.attrs[name]:
+			r.add(path+"/@"+name, "attribute value %q written back as %q", shorten(a.attrs[name]), shorten(value))
+		}
+	}
+	for _, name := range b.attrOrder {
+		if _, ok := a.attrs[name]; !ok {
+			r.add(path+"/@"+name, "attribute added")
+		}
+	}
+	// 文本段写回时可能合并或换行缩进，比较时将连续空白视为一个空格
+	if textA, textB := collapseSpace(a.texts), collapseSpace(b.texts); textA != textB {
+		r.add(path, "text %q written back as %q", shorten(textA), shorten(textB))
+	} else if len(a.texts) > 0 && len(a.children) > 0 && !bytes.Equal(a.layout, b.layout) {
+		r.add(path, "order of text and child elements in mixed content not preserved")
+	}
+	if a.cdata > 0 && b.cdata == 0 {
+		r.add(path, "CDATA section written back as escaped text")
+	}
+
+	namesA, namesB := elementNames(a.children), elementNames(b.children)
+	if strings.Join(namesA, ",") != strings.Join(namesB, ",") {
+		countA, countB := countNames(namesA), countNames(namesB)
+		changed := false
+		for _, name := range distinct(namesA) {
+			if countB[name] < countA[name] {
+				r.add(path+"/"+name, "%d of %d elements lost", countA[name]-countB[name], countA[name])
+				changed = true
+			}
+		}
+		for _, name := range distinct(namesB) {
+			if countA[name] < countB[name] {
+				r.add(path+"/"+name, "%d elements added", countB[name]-countA[name])
+				changed = true
+			}
+		}
+		if !changed {
+			r.add(path, "child element order changed from <%s> to <%s>", strings.Join(namesA, ","), strings.Join(namesB, ","))
+		}
+	}
+	total := countNames(namesA)
+	seen := make(map[string]int)
+	for _, child := range a.children {
+		seen[child.name]++
+		match := nthElement(b.children, child.name, seen[child.name])
+		if match == nil {
+			continue
+		}
+		childPath := path + "/" + child.name
+		if total[child.name] > 1 {
+			childPath += "[" + strconv.Itoa(seen[child.name]) + "]"
+		}
+		r.compareElements(child, match, childPath)
+	}
+}
+
+func collapseSpace(texts []string) string {
+	return strings.Join(strings.Fields(strings.Join(texts, " ")), " ")
+}
+
+func elementNames(elements []*xmlElement) []string {
+	names := make([]string, len(elements))
+	for i, element := range elements {
+		names[i] = element.name
+	}
+	return names
+}
+
+func countNames(names []string) map[string]int {
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	return counts
+}
+
+func distinct(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// nthElement 第 n 个（从 1 开始）名为 name 的元素
+func nthElement(elements []*xmlElement, name string, n int) *xmlElement {
+	for _, element := range elements {
+		if element.name == name {
+			if n--; n == 0 {
+				return element
+			}
+		}
+	}
+	return nil
+}
+
+// compareValues 比较转换前的数据与从 XML 读回的数据，不比较键顺序
+func (r *fidelityReport) compareValues(a, b any, path string) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			r.add(path, "%s read back as %s", describeValue(a), describeValue(b))
+			return
+		}
+		for _, key := range sortedKeys(av) {
+			if item, ok := bv[key]; ok {
+				r.compareValues(av[key], item, childPath(path, key))
+			} else {
+				r.add(childPath(path, key), "%s lost", describeValue(av[key]))
+			}
+		}
+		for _, key := range sortedKeys(bv) {
+			if _, ok := av[key]; !ok {
+				r.add(childPath(path, key), "unexpected %s after round trip", describeValue(bv[key]))
+			}
+		}
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			r.add(path, "%s read back as %s", describeValue(a), describeValue(b))
+			if !ok {
+				return
+			}
+		}
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			r.compareValues(av[i], bv[i], indexPath(path, i))
+		}
+	default:
+		if !sameScalar(a, b) {
+			r.add(path, "%s read back as %s", describeValue(a), describeValue(b))
+		}
+	}
+}
+
+func sameScalar(a, b any) bool {
+	if isNumber(a) && isNumber(b) {
+		return scalarString(a) == scalarString(b)
+	}
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case time.Time:
+		bv, ok := b.(time.Time)
+		return ok && av.Equal(bv)
+	}
+	return false
+}
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// describeValue 差异报告中值的简短描述
+func describeValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" {
+			return "empty string"
+		}
+		return fmt.Sprintf("string %q", shorten(v))
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case map[string]any:
+		if len(v) == 0 {
+			return "empty object"
+		}
+		return "object"
+	case []any:
+		return fmt.Sprintf("array of %d", len(v))
+	}
+	if isNumber(value) {
+		return "number " + scalarString(value)
+	}
+	return fmt.Sprintf("%T value", value)
+}
+
+func shorten(text string) string {
+	if runes := []rune(text); len(runes) > 40 {
+		return string(runes[:40]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,132 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+)
+
+// xmlNamespaced 带默认命名空间、前缀命名空间、属性与 CDATA 的文档
+const xmlNamespaced = `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:g="urn:g"><g:id lang="en">1</g:id><entry><title><![CDATA[a <b>]]></title></entry></feed>`
+
+// 各约定的键名规则：命名空间前缀、属性、文本与 CDATA
+func TestXMLConventions(t *testing.T) {
+	cases := []struct {
+		convention XMLConvention
+		want       string
+	}{
+		{XMLPrefix, `{"@xmlns":"http://www.w3.org/2005/Atom","@xmlns:g":"urn:g","entry":{"title":{"#cdata":"a <b>"}},"g:id":{"#text":"1","@lang":"en"}}`},
+		{XMLBadgerFish, `{"feed":{"@xmlns":{"$":"http://www.w3.org/2005/Atom","g":"urn:g"},"entry":{"title":{"$":"a <b>"}},"g:id":{"$":"1","@lang":"en"}}}`},
+		{XMLParker, `{"entry":{"title":"a <b>"},"g:id":1}`},
+		{XMLGData, `{"feed":{"entry":{"title":{"$t":"a <b>"}},"g$id":{"$t":"1","lang":"en"},"xmlns":"http://www.w3.org/2005/Atom","xmlns$g":"urn:g"}}`},
+	}
+	for _, c := range cases {
+		t.Run(string(c.convention), func(t *testing.T) {
+			doc, err := Parse(FormatXML, []byte(xmlNamespaced), &Options{XML: XMLOptions{Convention: c.convention}})
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// 写回 XML 时命名空间声明与前缀不变，prefix 约定的 CDATA 仍写为 CDATA，其他约定写为转义文本
+func TestXMLWriteNamespaces(t *testing.T) {
+	for _, convention := range []XMLConvention{XMLPrefix, XMLBadgerFish, XMLGData} {
+		t.Run(string(convention), func(t *testing.T) {
+			opts := &Options{XML: XMLOptions{Convention: convention}}
+			doc, err := Parse(FormatXML, []byte(xmlNamespaced), opts)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			out, err := Marshal(FormatXML, doc, opts)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			text := string(out)
+			if !strings.Contains(text, `xmlns="http://www.w3.org/2005/Atom" xmlns:g="urn:g"`) || !strings.Contains(text, `<g:id lang="en">1</g:id>`) {
+				t.Errorf("namespaces changed: %s", text)
+			}
+			cdata := strings.Contains(text, "<![CDATA[a <b>]]>")
+			if cdata != (convention == XMLPrefix) {
+				t.Errorf("unexpected title encoding: %s", text)
+			}
+			back, err := Parse(FormatXML, out, opts)
+			if err != nil {
+				t.Fatalf("read back: %v", err)
+			}
+			if canonical(t, back) != canonical(t, doc) {
+				t.Errorf("round trip changed data: %s", canonical(t, back))
+			}
+		})
+	}
+}
+
+// ForceArray 中的路径只出现一次也解析为数组，保留根元素的约定路径以根元素开头
+func TestXMLForceArray(t *testing.T) {
+	input := `<r><list><item>1</item></list><other>2</other></r>`
+	cases := []struct {
+		name       string
+		convention XMLConvention
+		force      []string
+		want       string
+	}{
+		{"none", XMLPrefix, nil, `{"list":{"item":"1"},"other":"2"}`},
+		{"nested", XMLPrefix, []string{"list.item", " other "}, `{"list":{"item":["1"]},"other":["2"]}`},
+		{"index ignored", XMLPrefix, []string{"list.0.item"}, `{"list":{"item":"1"},"other":"2"}`},
+		{"badgerfish root", XMLBadgerFish, []string{"r.list.item"}, `{"r":{"list":{"item":[{"$":"1"}]},"other":{"$":"2"}}}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := Parse(FormatXML, []byte(input), &Options{XML: XMLOptions{Convention: c.convention, ForceArray: c.force}})
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// XMLFidelity 报告往返中丢失或改变的内容
+func TestXMLFidelity(t *testing.T) {
+	cases := []struct {
+		name       string
+		from, to   Format
+		input      string
+		convention XMLConvention
+		want       []string // 期望出现的提示，按顺序
+	}{
+		{"root and comments", FormatXML, FormatJSON, `<r><a>1</a><!-- c --><b x="1">t<i>u</i>v</b></r>`, "",
+			[]string{"element <r> written back as <root>", "order of text and child elements in mixed content not preserved", "1 comments dropped"}},
+		{"parker attributes", FormatXML, FormatJSON, xmlNamespaced, XMLParker,
+			[]string{"written back as <root>", "attribute lost", "attribute lost", "attribute lost", "CDATA section written back as escaped text"}},
+		{"badgerfish lossless", FormatXML, FormatJSON, `<r a="1"><b>x</b><b>y</b></r>`, XMLBadgerFish, nil},
+		{"json to xml", FormatJSON, FormatXML, `{"a": 1, "b": [true], "d": null}`, "",
+			[]string{`number 1 read back as string "1"`, `array of 1 read back as string "true"`, "null read back as empty string"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := &Options{XML: XMLOptions{Convention: c.convention}}
+			out, doc, err := Convert(c.from, c.to, []byte(c.input), opts)
+			if err != nil {
+				t.Fatalf("convert: %v", err)
+			}
+			warnings, err := XMLFidelity(c.from, c.to, []byte(c.input), doc, out, opts)
+			if err != nil {
+				t.Fatalf("fidelity: %v", err)
+			}
+			if len(warnings) != len(c.want) {
+				t.Fatalf("got %v, want %d warnings", warnings, len(c.want))
+			}
+			for i, want := range c.want {
+				if !strings.Contains(warnings[i].Message, want) {
+					t.Errorf("warning %d: got %q, want %q", i, warnings[i].Message, want)
+				}
+			}
+		})
+	}
+}
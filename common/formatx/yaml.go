@@ -0,0 +1,451 @@
+package formatx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLAliasNodes 展开别名时复制的节点数上限，防止 billion laughs 一类的输入耗尽内存
+const maxYAMLAliasNodes = 1000000
+
+// parseYAML 解析 YAML，以 --- 分隔的多个文档解析为多文档流：Value 为各文档组成的数组，Stream 为 true。
+// 没有内容的文档（如末尾多出的 ---）跳过并记录提示，显式写出的 null 仍作为文档保留
+func parseYAML(doc *Document, data []byte, opts *Options) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var nodes []*yaml.Node
+	for i := 1; ; i++ {
+		node := &yaml.Node{}
+		if err := decoder.Decode(node); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if line, empty := emptyYAMLDocument(node); empty {
+			doc.Warn(line, "", "empty document %d skipped", i)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	y := newYAMLDecoder(doc, opts.duplicateKeys(FormatYAML), opts.maxAliasNodes())
+	if len(nodes) <= 1 {
+		if len(nodes) == 0 {
+			return nil
+		}
+		value, err := y.decode(nodes[0], "", "")
+		if err != nil {
+			return err
+		}
+		doc.Value = value
+		return nil
+	}
+	items := make([]any, len(nodes))
+	for i, node := range nodes {
+		value, err := y.decode(node, indexPointer("", i), indexPath("", i))
+		if err != nil {
+			return fmt.Errorf("document %d: %w", i+1, err)
+		}
+		items[i] = value
+	}
+	doc.Value, doc.Stream = items, true
+	return nil
+}
+
+// emptyYAMLDocument 文档只有 --- 或注释，没有任何值
+func emptyYAMLDocument(node *yaml.Node) (int, bool) {
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 {
+		return 0, false
+	}
+	value := node.Content[0]
+	return value.Line, value.Kind == yaml.ScalarNode && value.ShortTag() == "!!null" && value.Value == ""
+}
+
+// marshalYAML 序列化 YAML，多文档流逐个写出为以 --- 分隔的文档
+func marshalYAML(doc *Document, opts *Options) ([]byte, error) {
+	value := doc.Ordered(opts)
+	items, ok := value.([]any)
+	if !doc.Stream || !ok {
+		node, err := yamlDocument(doc, value, "", opts)
+		if err != nil {
+			return nil, err
+		}
+		return writeYAML(opts.indent(), node)
+	}
+	nodes := make([]*yaml.Node, len(items))
+	for i, item := range items {
+		node, err := yamlDocument(doc, item, indexPointer("", i), opts)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return writeYAML(opts.indent(), nodes...)
+}
+
+// yamlDocument 将 pointer 处的文档转为 yaml.Node，按选项还原锚点与别名并附加注释
+func yamlDocument(doc *Document, value any, pointer string, opts *Options) (*yaml.Node, error) {
+	var refs *yamlRefs
+	if opts.yamlAnchors() {
+		refs = doc.refs
+	}
+	node, err := newYAMLRefEncoder(refs).node(value, pointer)
+	if err != nil {
+		return nil, err
+	}
+	comments := opts.comments(doc)
+	if len(comments) == 0 {
+		return node, nil
+	}
+	attachYAMLComments(node, comments, pointer)
+	root := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	if c := comments[pointer]; c != nil {
+		root.HeadComment = strings.Join(c.Head, "\n")
+		if len(node.Content) == 0 {
+			node.LineComment = c.Line
+			root.FootComment = strings.Join(c.Foot, "\n")
+		}
+	}
+	return root, nil
+}
+
+// encodeYAML 序列化 YAML，*Record 按字段顺序输出，json.Number 保留原始字面量
+func encodeYAML(value any, indent int) ([]byte, error) {
+	node, err := yamlNode(value)
+	if err != nil {
+		return nil, err
+	}
+	return writeYAML(indent, node)
+}
+
+// writeYAML 依次写出各文档，多个文档之间以 --- 分隔
+func writeYAML(indent int, nodes ...*yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	for _, node := range nodes {
+		if err := encoder.Encode(node); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlDecoder 将 yaml.Node 转为文档数据，记录映射的键顺序，合并 << 键、展开别名并按策略处理重复键；
+// 锚点、别名与合并键的位置记录在文档中，序列化为 YAML 时可以还原
+type yamlDecoder struct {
+	doc        *Document
+	duplicates DuplicateKeyPolicy
+	maxCopied  int // 展开别名最多复制的节点数
+	aliases    int // 正在展开的别名层数
+	copied     int // 展开别名已复制的节点数
+}
+
+func newYAMLDecoder(doc *Document, duplicates DuplicateKeyPolicy, maxCopied int) *yamlDecoder {
+	return &yamlDecoder{doc: doc, duplicates: duplicates, maxCopied: maxCopied}
+}
+
+// yamlNumberPattern 可以原样作为 JSON 数字的 YAML 数字字面量
+var yamlNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+func (y *yamlDecoder) decode(node *yaml.Node, pointer, path string) (any, error) {
+	if y.aliases > 0 {
+		if y.copied++; y.copied > y.maxCopied {
+			return nil, fmt.Errorf("line %d: alias expansion exceeds %d nodes", node.Line, y.maxCopied)
+		}
+	} else if node.Anchor != "" {
+		y.doc.yamlRefs().anchors[pointer] = node.Anchor
+	}
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.DocumentNode:
+		y.doc.AddHeadComment(pointer, yamlComment(node.HeadComment)...)
+		y.doc.AddFootComment(pointer, yamlComment(node.FootComment)...)
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return y.decode(node.Content[0], pointer, path)
+	case yaml.AliasNode:
+		if y.aliases == 0 {
+			y.doc.yamlRefs().aliases[pointer] = node.Value
+		}
+		y.aliases++
+		defer func() { y.aliases-- }()
+		return y.decode(node.Alias, pointer, path)
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			y.comments(child, indexPointer(pointer, len(items)), pointer)
+			item, err := y.decode(child, indexPointer(pointer, len(items)), indexPath(path, len(items)))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case yaml.MappingNode:
+		return y.decodeMapping(node, pointer, path)
+	default:
+		return y.decodeScalar(node, path)
+	}
+}
+
+// decodeScalar .inf 与 .nan 无法用 JSON 数字表示，与 JSON5 一样转为 $numberDouble 注解
+func (y *yamlDecoder) decodeScalar(node *yaml.Node, path string) (any, error) {
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		if yamlNumberPattern.MatchString(node.Value) {
+			return json.Number(node.Value), nil
+		}
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return floatValue(f), nil
+	}
+	return normalizeValue(value), nil
+}
+
+// decodeMapping 显式声明的键优先于 << 合并的键，合并的键放在 << 所在的位置；
+// 显式声明的键重复时按策略处理，合并的键不视为重复
+func (y *yamlDecoder) decodeMapping(node *yaml.Node, pointer, path string) (any, error) {
+	obj := make(map[string]any)
+	var keys []string
+	set := func(key string, value any) {
+		if _, exists := obj[key]; !exists {
+			keys = append(keys, key)
+		}
+		obj[key] = value
+	}
+	explicit := make(map[string]bool)
+	seen := newKeySet(y.doc, y.duplicates)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isMergeKey(node.Content[i]) {
+			key, err := yamlKey(node.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			explicit[key] = true
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if isMergeKey(keyNode) {
+			merged, err := y.decodeMerge(valueNode, pointer, path)
+			if err != nil {
+				return nil, err
+			}
+			var mergedKeys []string
+			for _, source := range merged {
+				y.doc.Warnings = append(y.doc.Warnings, source.Warnings...)
+				m := source.Value.(map[string]any)
+				for _, key := range source.Keys(pointer, m, false) {
+					if _, exists := obj[key]; !exists && !explicit[key] {
+						set(key, m[key])
+						mergedKeys = append(mergedKeys, key)
+						y.doc.copyKeyOrder(source, childPointer(pointer, key))
+					}
+				}
+			}
+			if anchors := mergeAnchors(valueNode); y.aliases == 0 && len(anchors) > 0 {
+				y.doc.yamlRefs().merges[pointer] = &yamlMerge{anchors: anchors, keys: mergedKeys}
+			}
+			continue
+		}
+		key, err := yamlKey(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		doc, target := y.doc, seen.target(key)
+		y.doc = target
+		y.comments(keyNode, childPointer(pointer, key), pointer)
+		y.comments(valueNode, childPointer(pointer, key), pointer)
+		value, err := y.decode(valueNode, childPointer(pointer, key), childPath(path, key))
+		y.doc = doc
+		if err != nil {
+			return nil, err
+		}
+		if value, err = seen.add(pointer, path, key, sourcePos{line: keyNode.Line}, obj[key], value, target); err != nil {
+			return nil, err
+		}
+		set(key, value)
+	}
+	if len(keys) > 0 {
+		y.doc.SetKeyOrder(pointer, keys)
+	}
+	return obj, nil
+}
+
+// decodeMerge 解析 << 的值：映射、映射的别名或它们组成的序列，靠前的映射优先。
+// 每个映射解析到单独的文档中，只有实际被合并的键才复制键顺序
+func (y *yamlDecoder) decodeMerge(node *yaml.Node, pointer, path string) ([]*Document, error) {
+	sources := []*yaml.Node{node}
+	if resolved := resolveAlias(node); resolved.Kind == yaml.SequenceNode {
+		sources = resolved.Content
+	}
+	docs := make([]*Document, 0, len(sources))
+	saved := y.doc
+	defer func() { y.doc = saved }()
+	for _, source := range sources {
+		if resolveAlias(source).Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: merge value must be a mapping or a sequence of mappings", source.Line)
+		}
+		y.doc = &Document{}
+		value, err := y.decode(source, pointer, path)
+		if err != nil {
+			return nil, err
+		}
+		y.doc.Value = value
+		docs = append(docs, y.doc)
+	}
+	return docs, nil
+}
+
+// comments 记录节点的注释，节点之后的注释属于所在的映射或序列 container；
+// 展开别名时不重复记录锚点处的注释
+func (y *yamlDecoder) comments(node *yaml.Node, pointer, container string) {
+	if y.aliases > 0 {
+		return
+	}
+	y.doc.AddHeadComment(pointer, yamlComment(node.HeadComment)...)
+	y.doc.AddLineComment(pointer, strings.Join(yamlComment(node.LineComment), " "))
+	y.doc.AddFootComment(container, yamlComment(node.FootComment)...)
+}
+
+// yamlComment 去掉注释符后的各行
+func yamlComment(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return commentLines(text)
+}
+
+// attachYAMLComments 将注释写入 yaml.Node：前置与行尾注释放在键或序列元素上，
+// 映射与序列的末尾注释放在最后一个键或元素上
+func attachYAMLComments(node *yaml.Node, comments Comments, pointer string) {
+	var last *yaml.Node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			child := childPointer(pointer, keyNode.Value)
+			if c := comments[child]; c != nil {
+				keyNode.HeadComment = strings.Join(c.Head, "\n")
+				if valueNode.Kind == yaml.ScalarNode || len(valueNode.Content) == 0 {
+					valueNode.LineComment = c.Line
+				} else {
+					keyNode.LineComment = c.Line
+				}
+			}
+			attachYAMLComments(valueNode, comments, child)
+			last = keyNode
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := indexPointer(pointer, i)
+			if c := comments[child]; c != nil {
+				item.HeadComment = strings.Join(c.Head, "\n")
+				item.LineComment = c.Line
+			}
+			attachYAMLComments(item, comments, child)
+			last = item
+		}
+	}
+	if c := comments[pointer]; c != nil && last != nil {
+		last.FootComment = strings.Join(c.Foot, "\n")
+	}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func isMergeKey(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!merge"
+}
+
+// yamlKey 映射键统一转为字符串，非字符串的基本类型键按文本输出
+func yamlKey(node *yaml.Node) (string, error) {
+	node = resolveAlias(node)
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("line %d: mapping keys must be scalars", node.Line)
+	}
+	if node.ShortTag() == "!!str" {
+		return node.Value, nil
+	}
+	var key any
+	if err := node.Decode(&key); err != nil {
+		return "", err
+	}
+	return fmt.Sprint(normalizeValue(key)), nil
+}
+
+// yamlNode 将数据转为 yaml.Node，对象键按 *Record 的顺序输出，map 按字典序输出
+func yamlNode(value any) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *Record:
+		if f, ok := doubleAnnotation(v); ok {
+			node := &yaml.Node{}
+			return node, node.Encode(f)
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.Keys {
+			child, err := yamlNode(v.Values[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case map[string]any:
+		return yamlNode(&Record{Keys: sortedKeys(v), Values: v})
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case string:
+		node := &yaml.Node{}
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+		// yaml.v3 生成以换行开头的块标量时会丢失开头的换行，这类文本改用双引号
+		if strings.HasPrefix(v, "\n") && utf8.ValidString(v) {
+			node.Value, node.Style = v, yaml.DoubleQuotedStyle
+		}
+		return node, nil
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+}
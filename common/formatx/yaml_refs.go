@@ -0,0 +1,207 @@
+package formatx
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlRefs YAML 中锚点、别名与 << 合并键的位置，按节点路径（JSON Pointer）记录
+type yamlRefs struct {
+	anchors map[string]string     // 带锚点的节点 → 锚点名
+	aliases map[string]string     // 别名节点 → 引用的锚点名
+	merges  map[string]*yamlMerge // 含 << 且合并来源均为别名的映射
+}
+
+// yamlMerge 一个映射中的 << 合并键
+type yamlMerge struct {
+	anchors []string // 合并来源的锚点名，靠前的优先
+	keys    []string // 实际由合并得到的键
+}
+
+func newYAMLRefs() *yamlRefs {
+	return &yamlRefs{anchors: make(map[string]string), aliases: make(map[string]string), merges: make(map[string]*yamlMerge)}
+}
+
+// yamlRefs 返回文档的锚点记录，不存在时创建
+func (d *Document) yamlRefs() *yamlRefs {
+	if d.refs == nil {
+		d.refs = newYAMLRefs()
+	}
+	return d.refs
+}
+
+// move 取出 from 及其下级节点的记录，路径改为相对 to
+func (r *yamlRefs) move(from, to string) *yamlRefs {
+	if r == nil {
+		return nil
+	}
+	moved := newYAMLRefs()
+	rebase := func(p string) (string, bool) {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			return to + p[len(from):], true
+		}
+		return "", false
+	}
+	for p, name := range r.anchors {
+		if q, ok := rebase(p); ok {
+			moved.anchors[q] = name
+		}
+	}
+	for p, name := range r.aliases {
+		if q, ok := rebase(p); ok {
+			moved.aliases[q] = name
+		}
+	}
+	for p, merge := range r.merges {
+		if q, ok := rebase(p); ok {
+			moved.merges[q] = merge
+		}
+	}
+	return moved
+}
+
+// mergeAnchors << 的值为别名或别名组成的序列时返回引用的锚点名，含内联映射时返回 nil
+func mergeAnchors(node *yaml.Node) []string {
+	if node.Kind == yaml.AliasNode {
+		return []string{node.Value}
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	anchors := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		if item.Kind != yaml.AliasNode {
+			return nil
+		}
+		anchors = append(anchors, item.Value)
+	}
+	return anchors
+}
+
+// Split 多文档流拆分为各自的文档，键顺序、注释与锚点随文档移动；不是多文档流时返回文档本身
+func (d *Document) Split() []*Document {
+	items, ok := d.Value.([]any)
+	if !d.Stream || !ok {
+		return []*Document{d}
+	}
+	docs := make([]*Document, len(items))
+	for i, item := range items {
+		pointer := indexPointer("", i)
+		part := &Document{Format: d.Format, Value: item, refs: d.refs.move(pointer, "")}
+		part.moveKeyOrder(d, pointer, "")
+		part.moveComments(d, pointer, "")
+		docs[i] = part
+	}
+	return docs
+}
+
+// yamlAnchor 已写出的锚点节点及其数据，用于确认别名处的数据与锚点一致
+type yamlAnchor struct {
+	node  *yaml.Node
+	value any
+}
+
+// yamlRefEncoder 生成 yaml.Node 时按记录还原锚点、别名与 << 合并键。
+// 别名处的数据与最近写出的同名锚点不一致（如被重复键策略替换或锚点出现在别名之后）时按展开的数据写出
+type yamlRefEncoder struct {
+	refs    *yamlRefs
+	emitted map[string]*yamlAnchor
+}
+
+func newYAMLRefEncoder(refs *yamlRefs) *yamlRefEncoder {
+	return &yamlRefEncoder{refs: refs, emitted: make(map[string]*yamlAnchor)}
+}
+
+func (e *yamlRefEncoder) node(value any, pointer string) (*yaml.Node, error) {
+	if e.refs == nil {
+		return yamlNode(value)
+	}
+	if name, ok := e.refs.aliases[pointer]; ok {
+		if anchor := e.emitted[name]; anchor != nil && reflect.DeepEqual(anchor.value, value) {
+			return &yaml.Node{Kind: yaml.AliasNode, Value: name, Alias: anchor.node}, nil
+		}
+	}
+	var node *yaml.Node
+	switch v := value.(type) {
+	case *Record:
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		merge, merged := e.merge(v, pointer)
+		for _, key := range v.Keys {
+			if merged[key] {
+				// << 写在第一个合并键的位置
+				if merge != nil {
+					node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "<<"}, merge)
+					merge = nil
+				}
+				continue
+			}
+			child, err := e.node(v.Values[key], childPointer(pointer, key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+	case []any:
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, item := range v {
+			child, err := e.node(item, indexPointer(pointer, i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+	default:
+		var err error
+		if node, err = yamlNode(v); err != nil {
+			return nil, err
+		}
+	}
+	if name, ok := e.refs.anchors[pointer]; ok {
+		node.Anchor = name
+		e.emitted[name] = &yamlAnchor{node: node, value: value}
+	}
+	return node, nil
+}
+
+// merge 映射中记录的合并键与重新按锚点合并的结果一致时，返回 << 的值与由合并得到的键
+func (e *yamlRefEncoder) merge(record *Record, pointer string) (*yaml.Node, map[string]bool) {
+	m := e.refs.merges[pointer]
+	if m == nil || len(m.keys) == 0 {
+		return nil, nil
+	}
+	keys := make(map[string]bool, len(m.keys))
+	for _, key := range m.keys {
+		keys[key] = true
+	}
+	expected := make(map[string]bool)
+	var aliases []*yaml.Node
+	for _, name := range m.anchors {
+		anchor := e.emitted[name]
+		if anchor == nil {
+			return nil, nil
+		}
+		source, ok := anchor.value.(*Record)
+		if !ok {
+			return nil, nil
+		}
+		for _, key := range source.Keys {
+			if _, explicit := record.Values[key]; explicit && !keys[key] || expected[key] {
+				continue
+			}
+			if value, ok := record.Values[key]; !ok || !reflect.DeepEqual(value, source.Values[key]) {
+				return nil, nil
+			}
+			expected[key] = true
+		}
+		aliases = append(aliases, &yaml.Node{Kind: yaml.AliasNode, Value: name, Alias: anchor.node})
+	}
+	if !reflect.DeepEqual(expected, keys) {
+		return nil, nil
+	}
+	if len(aliases) == 1 {
+		return aliases[0], keys
+	}
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: aliases}, keys
+}
@@ -0,0 +1,75 @@
+package formatx
+
+import (
+	"strings"
+	"testing"
+)
+
+// billionLaughs 每层别名引用上一层 9 次，展开后的节点数按 9 的幂增长
+const billionLaughs = `a: &a ["x", "x", "x", "x", "x", "x", "x", "x", "x"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+`
+
+// 请求只能调低别名展开的上限，不能超过默认值
+func TestYAMLAliasLimit(t *testing.T) {
+	for _, limit := range []int{0, 1 << 30} {
+		_, err := Parse(FormatYAML, []byte(billionLaughs), &Options{MaxAliasNodes: limit})
+		if err == nil || !strings.Contains(err.Error(), "alias") {
+			t.Errorf("limit %d: expected alias limit error, got %v", limit, err)
+		}
+	}
+	small := "a: &a [1, 2]\nb: [*a, *a]\n"
+	if _, err := Parse(FormatYAML, []byte(small), &Options{MaxAliasNodes: 4}); err == nil {
+		t.Error("expected error when lowering the limit")
+	}
+	if _, err := Parse(FormatYAML, []byte(small), nil); err != nil {
+		t.Errorf("default limit: %v", err)
+	}
+}
+
+func TestYAMLDocuments(t *testing.T) {
+	outputs, _, err := ConvertDocuments(FormatYAML, FormatJSON, []byte("a: 1\n---\nb: &x [1]\nc: *x\n"), nil)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(outputs))
+	}
+	if got := strings.Join(strings.Fields(string(outputs[1])), ""); got != `{"b":[1],"c":[1]}` {
+		t.Errorf("second document: %s", outputs[1])
+	}
+}
+
+// 末尾多出的 --- 与连续的 --- 不产生 null 文档，显式的 null 文档保留
+func TestYAMLEmptyDocuments(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		want     string
+		warnings int
+	}{
+		{"trailing separator", "a: 1\n---\n", `{"a":1}`, 1},
+		{"trailing comment", "a: 1\n---\n# end\n", `{"a":1}`, 1},
+		{"consecutive separators", "a: 1\n---\n---\nb: 2\n", `[{"a":1},{"b":2}]`, 1},
+		{"explicit null", "a: 1\n--- null\n", `[{"a":1},null]`, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc, err := Parse(FormatYAML, []byte(c.input), nil)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := canonical(t, doc); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+			if len(doc.Warnings) != c.warnings {
+				t.Errorf("unexpected warnings: %v", doc.Warnings)
+			}
+		})
+	}
+}
@@ -178,10 +178,13 @@ func handleFileDownload(c *gin.Context, version string, config *FileDownloadConf
 	// 处理不同的文件来源
 	if config.Filepath != "" {
 		handleFileDownloadFromPath(c, version, config)
+		return
 	} else if config.Reader != nil {
 		handleFileDownloadFromReader(c, version, config)
+		return
 	} else if config.Content != nil {
 		handleFileDownloadFromContent(c, version, config)
+		return
 	}
 
 	ResponseErr(c, version, errors.New("no file content provided"))
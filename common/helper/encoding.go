@@ -0,0 +1,13 @@
+package helper
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// DecodeHex 兼容 0x 前缀、大小写以及空白分隔
+func DecodeHex(text string) ([]byte, error) {
+	text = strings.Join(strings.Fields(text), "")
+	text = strings.TrimPrefix(strings.ToLower(text), "0x")
+	return hex.DecodeString(text)
+}
@@ -0,0 +1,144 @@
+package pinyinx
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Style 汉字转写方式
+type Style string
+
+const (
+	StyleFull     Style = "full"     // 全拼，每个字一个单词：用户名 → yong hu ming
+	StyleInitials Style = "initials" // 首字母，连续的汉字合为一个单词：用户名 → yhm
+)
+
+// ParseStyle 校验转写方式，空字符串表示 full
+func ParseStyle(name string) (Style, error) {
+	switch style := Style(strings.ToLower(strings.TrimSpace(name))); style {
+	case "":
+		return StyleFull, nil
+	case StyleFull, StyleInitials:
+		return style, nil
+	default:
+		return "", fmt.Errorf("invalid pinyin style: %q, expected full or initials", name)
+	}
+}
+
+//go:embed data/chars.txt
+var charsData string
+
+//go:embed data/phrases.txt
+var phrasesData string
+
+var (
+	loadOnce  sync.Once
+	chars     map[rune]string     // 汉字 → 拼音
+	phrases   map[string][]string // 词语 → 每个字的拼音
+	maxPhrase int                 // 最长词语的字数
+)
+
+// load 解析内嵌的字典，首次转写时执行
+func load() {
+	chars = make(map[rune]string, 30000)
+	for _, line := range strings.Split(charsData, "\n") {
+		syllable, text, ok := strings.Cut(line, "\t")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, r := range text {
+			chars[r] = syllable
+		}
+	}
+	phrases = make(map[string][]string)
+	for _, line := range strings.Split(phrasesData, "\n") {
+		phrase, text, ok := strings.Cut(line, "\t")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		syllables := strings.Fields(text)
+		if n := len([]rune(phrase)); n == len(syllables) {
+			phrases[phrase] = syllables
+			maxPhrase = max(maxPhrase, n)
+		}
+	}
+}
+
+// IsHan 是否为汉字
+func IsHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// HasHan 文本中是否含有汉字
+func HasHan(s string) bool {
+	return strings.IndexFunc(s, IsHan) >= 0
+}
+
+// Syllables 逐字转为拼音，词典中的词语按最长匹配取词语读音，非汉字与字典中没有的汉字返回空字符串
+func Syllables(runes []rune) []string {
+	loadOnce.Do(load)
+	out := make([]string, len(runes))
+	for i := 0; i < len(runes); {
+		matched := 0
+		for n := min(maxPhrase, len(runes)-i); n >= 2; n-- {
+			if syllables, ok := phrases[string(runes[i:i+n])]; ok {
+				copy(out[i:], syllables)
+				matched = n
+				break
+			}
+		}
+		if matched > 0 {
+			i += matched
+			continue
+		}
+		out[i] = chars[runes[i]]
+		i++
+	}
+	return out
+}
+
+// Transliterate 将文本中的汉字转为拼音，单词之间以 _ 分隔以便再按命名格式改写，非汉字部分原样保留：
+// 订单ID → ding_dan_ID（全拼）、dd_ID（首字母）；字典中没有的汉字原样保留
+func Transliterate(s string, style Style) string {
+	runes := []rune(s)
+	if !HasHan(s) {
+		return s
+	}
+	syllables := Syllables(runes)
+	var b strings.Builder
+	boundary := func() {
+		if b.Len() == 0 {
+			return
+		}
+		last := b.String()[b.Len()-1]
+		if last != '_' && last != '-' && last != ' ' && last != '.' {
+			b.WriteByte('_')
+		}
+	}
+	prevHan := false
+	for i, r := range runes {
+		syllable := syllables[i]
+		switch {
+		case syllable != "" && style == StyleInitials:
+			if !prevHan {
+				boundary()
+			}
+			b.WriteByte(syllable[0])
+			prevHan = true
+		case syllable != "":
+			boundary()
+			b.WriteString(syllable)
+			prevHan = true
+		default:
+			if prevHan && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				boundary()
+			}
+			b.WriteRune(r)
+			prevHan = false
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package pinyinx
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	cases := []struct {
+		input    string
+		full     string
+		initials string
+	}{
+		{"用户名", "yong_hu_ming", "yhm"},
+		{"订单ID", "ding_dan_ID", "dd_ID"},
+		{"a用户b", "a_yong_hu_b", "a_yh_b"},
+		{"user_名称", "user_ming_cheng", "user_mc"},
+		{"user_name", "user_name", "user_name"},
+		// 多音字按词语取读音
+		{"银行卡号", "yin_hang_ka_hao", "yhkh"},
+		{"行长", "hang_zhang", "hz"},
+		{"长度", "chang_du", "cd"},
+		{"重庆", "chong_qing", "cq"},
+		// 字典中没有的汉字原样保留
+		{"𪚥字", "𪚥_zi", "𪚥_z"},
+	}
+	for _, c := range cases {
+		if got := Transliterate(c.input, StyleFull); got != c.full {
+			t.Errorf("full %q: got %q, want %q", c.input, got, c.full)
+		}
+		if got := Transliterate(c.input, StyleInitials); got != c.initials {
+			t.Errorf("initials %q: got %q, want %q", c.input, got, c.initials)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for name, want := range map[string]Style{"": StyleFull, " Initials ": StyleInitials, "full": StyleFull} {
+		if got, err := ParseStyle(name); err != nil || got != want {
+			t.Errorf("%q: got %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseStyle("tone"); err == nil {
+		t.Error("expected error for unsupported style")
+	}
+}
@@ -0,0 +1,130 @@
+package protox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+// DecodeOptions 二进制转 JSON 选项
+type DecodeOptions struct {
+	Indent          int  // 缩进空格数，默认 2
+	UseProtoNames   bool // 使用 .proto 中的字段名，默认使用 lowerCamelCase 的 JSON 名
+	EmitUnpopulated bool // 输出未赋值的字段
+}
+
+// EncodeOptions JSON 转二进制选项
+type EncodeOptions struct {
+	DiscardUnknown bool // 忽略 schema 中不存在的字段，默认报错
+}
+
+// Decode 按消息类型将二进制数据转为 proto3 JSON，未知字段记录为提示
+func (s *Schema) Decode(message string, data []byte, opts *DecodeOptions) ([]byte, []formatx.Warning, error) {
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	md, err := s.FindMessage(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err = (proto.UnmarshalOptions{Resolver: s.types}).Unmarshal(data, msg); err != nil {
+		return nil, nil, fmt.Errorf("decode %s error: %w", md.FullName(), err)
+	}
+	indent := opts.Indent
+	if indent <= 0 {
+		indent = 2
+	}
+	content, err := protojson.MarshalOptions{
+		UseProtoNames:   opts.UseProtoNames,
+		EmitUnpopulated: opts.EmitUnpopulated,
+		Resolver:        s.types,
+	}.Marshal(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	// protojson 的输出会随机插入空白，重新排版以保证结果稳定
+	var compact, out bytes.Buffer
+	if err = json.Compact(&compact, content); err != nil {
+		return nil, nil, err
+	}
+	if err = json.Indent(&out, compact.Bytes(), "", strings.Repeat(" ", indent)); err != nil {
+		return nil, nil, err
+	}
+	var warnings []formatx.Warning
+	unknownFields(msg, "", &warnings)
+	return out.Bytes(), warnings, nil
+}
+
+// Encode 将 proto3 JSON 按消息类型编码为二进制，字段按编号排序输出
+func (s *Schema) Encode(message string, content []byte, opts *EncodeOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	md, err := s.FindMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	err = protojson.UnmarshalOptions{DiscardUnknown: opts.DiscardUnknown, Resolver: s.types}.Unmarshal(content, msg)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s json error: %w", md.FullName(), err)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// unknownFields 收集 schema 中不存在的字段，这些字段不会出现在 JSON 中
+func unknownFields(msg protoreflect.Message, path string, warnings *[]formatx.Warning) {
+	for raw := msg.GetUnknown(); len(raw) > 0; {
+		number, wireType, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			break
+		}
+		m := protowire.ConsumeFieldValue(number, wireType, raw[n:])
+		if m < 0 {
+			break
+		}
+		*warnings = append(*warnings, formatx.Warning{
+			Path:    path,
+			Message: fmt.Sprintf("unknown field %d (%s, %d bytes) is not in the schema and was dropped", number, wireTypeName(wireType), m),
+		})
+		raw = raw[n+m:]
+	}
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fieldPath := fieldPath(path, string(fd.Name()))
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+					unknownFields(value.Message(), fmt.Sprintf("%s[%v]", fieldPath, key.Interface()), warnings)
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len(); i++ {
+					unknownFields(v.List().Get(i).Message(), fmt.Sprintf("%s[%d]", fieldPath, i), warnings)
+				}
+			}
+		case fd.Message() != nil:
+			unknownFields(v.Message(), fieldPath, warnings)
+		}
+		return true
+	})
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,230 @@
+package protox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string // 字符串为解码后的内容，其余为原文
+	line int
+	col  int
+}
+
+// lexer .proto 源文件词法分析，跳过空白与注释
+type lexer struct {
+	filename string
+	src      string
+	pos      int
+	line     int
+	col      int
+}
+
+func newLexer(filename, src string) *lexer {
+	return &lexer{filename: filename, src: src, line: 1, col: 1}
+}
+
+func (l *lexer) errorf(line, col int, format string, args ...any) error {
+	return fmt.Errorf("%s:%d:%d: %s", l.filename, line, col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.src); i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) skipSpace() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v':
+			l.advance(1)
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.advance(end)
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return l.errorf(l.line, l.col, "unterminated block comment")
+			}
+			l.advance(end + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpace(); err != nil {
+		return token{}, err
+	}
+	tok := token{line: l.line, col: l.col}
+	if l.pos >= len(l.src) {
+		return tok, nil
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case isLetter(c):
+		for l.pos < len(l.src) && (isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.text = tokenIdent, l.src[start:l.pos]
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		hex := strings.HasPrefix(strings.ToLower(l.src[l.pos:]), "0x")
+		for l.pos < len(l.src) {
+			ch := l.src[l.pos]
+			exponentSign := !hex && (ch == '+' || ch == '-') && l.pos > start &&
+				(l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E')
+			if !isLetter(ch) && !isDigit(ch) && ch != '.' && !exponentSign {
+				break
+			}
+			l.advance(1)
+		}
+		tok.text = l.src[start:l.pos]
+		tok.kind = tokenInt
+		if !hex && strings.ContainsAny(tok.text, ".eE") {
+			tok.kind = tokenFloat
+			if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+				return tok, l.errorf(tok.line, tok.col, "invalid number %q", tok.text)
+			}
+		}
+	case c == '"' || c == '\'':
+		text, err := l.quoted(c)
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.text = tokenString, text
+	default:
+		l.advance(1)
+		tok.kind, tok.text = tokenSymbol, string(c)
+	}
+	return tok, nil
+}
+
+// quoted 读取带引号的字符串并处理转义
+func (l *lexer) quoted(quote byte) (string, error) {
+	line, col := l.line, l.col
+	l.advance(1)
+	var sb strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return "", l.errorf(line, col, "unterminated string")
+		}
+		c := l.src[l.pos]
+		if c == quote {
+			l.advance(1)
+			return sb.String(), nil
+		}
+		if c != '\\' {
+			sb.WriteByte(c)
+			l.advance(1)
+			continue
+		}
+		if l.pos+1 >= len(l.src) {
+			return "", l.errorf(line, col, "unterminated string")
+		}
+		e := l.src[l.pos+1]
+		l.advance(2)
+		switch e {
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '\\', '\'', '"', '?':
+			sb.WriteByte(e)
+		case 'x', 'X':
+			n := l.digits(2, 16)
+			if n == "" {
+				return "", l.errorf(l.line, l.col, "invalid hex escape")
+			}
+			v, _ := strconv.ParseUint(n, 16, 8)
+			sb.WriteByte(byte(v))
+		case 'u', 'U':
+			size := 4
+			if e == 'U' {
+				size = 8
+			}
+			n := l.digits(size, 16)
+			v, err := strconv.ParseUint(n, 16, 32)
+			if len(n) != size || err != nil || !utf8.ValidRune(rune(v)) {
+				return "", l.errorf(l.line, l.col, "invalid unicode escape")
+			}
+			sb.WriteRune(rune(v))
+		default:
+			if e >= '0' && e <= '7' {
+				n := string(e) + l.digits(2, 8)
+				v, err := strconv.ParseUint(n, 8, 8)
+				if err != nil {
+					return "", l.errorf(l.line, l.col, "invalid octal escape")
+				}
+				sb.WriteByte(byte(v))
+				continue
+			}
+			return "", l.errorf(l.line, l.col, "invalid escape \\%c", e)
+		}
+	}
+}
+
+// digits 读取最多 max 个指定进制的数字
+func (l *lexer) digits(max, base int) string {
+	start := l.pos
+	for l.pos < len(l.src) && l.pos-start < max {
+		c := l.src[l.pos]
+		valid := c >= '0' && c <= '7'
+		if base >= 10 {
+			valid = isDigit(c)
+		}
+		if base == 16 {
+			valid = valid || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+		}
+		if !valid {
+			break
+		}
+		l.advance(1)
+	}
+	return l.src[start:l.pos]
+}
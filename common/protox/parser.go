@@ -0,0 +1,1028 @@
+package protox
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// 字段号上限，extensions 中的 max 取该值
+const maxFieldNumber = 536870911
+
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+	"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+	"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+	"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+}
+
+// ParseProto 将 .proto 源文件解析为 FileDescriptorProto，类型引用保持原样，由 protodesc 在编译时解析
+func ParseProto(filename, src string) (*descriptorpb.FileDescriptorProto, error) {
+	p := &parser{lex: newLexer(filename, src)}
+	if err := p.read(); err != nil {
+		return nil, err
+	}
+	return p.file(filename)
+}
+
+type parser struct {
+	lex    *lexer
+	tok    token
+	proto3 bool
+}
+
+func (p *parser) read() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return p.lex.errorf(p.tok.line, p.tok.col, format, args...)
+}
+
+func (p *parser) is(text string) bool {
+	return (p.tok.kind == tokenIdent || p.tok.kind == tokenSymbol) && p.tok.text == text
+}
+
+// accept 当前记号为 text 时读取下一个记号
+func (p *parser) accept(text string) (bool, error) {
+	if !p.is(text) {
+		return false, nil
+	}
+	return true, p.read()
+}
+
+func (p *parser) expect(text string) error {
+	if !p.is(text) {
+		return p.errorf("expected %q, found %q", text, p.tok.text)
+	}
+	return p.read()
+}
+
+func (p *parser) ident() (string, error) {
+	if p.tok.kind != tokenIdent {
+		return "", p.errorf("expected identifier, found %q", p.tok.text)
+	}
+	name := p.tok.text
+	return name, p.read()
+}
+
+// fullIdent 读取以点号分隔的名称，允许前导点号
+func (p *parser) fullIdent() (string, error) {
+	var sb strings.Builder
+	if ok, err := p.accept("."); err != nil {
+		return "", err
+	} else if ok {
+		sb.WriteByte('.')
+	}
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(name)
+		if ok, err := p.accept("."); err != nil || !ok {
+			return sb.String(), err
+		}
+		sb.WriteByte('.')
+	}
+}
+
+func (p *parser) stringLiteral() (string, error) {
+	if p.tok.kind != tokenString {
+		return "", p.errorf("expected string, found %q", p.tok.text)
+	}
+	var sb strings.Builder
+	// 相邻的字符串字面量自动拼接
+	for p.tok.kind == tokenString {
+		sb.WriteString(p.tok.text)
+		if err := p.read(); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+func (p *parser) integer() (int64, error) {
+	negative, err := p.accept("-")
+	if err != nil {
+		return 0, err
+	}
+	if p.tok.kind != tokenInt {
+		return 0, p.errorf("expected integer, found %q", p.tok.text)
+	}
+	n, err := strconv.ParseInt(p.tok.text, 0, 64)
+	if err != nil {
+		return 0, p.errorf("invalid integer %q", p.tok.text)
+	}
+	if negative {
+		n = -n
+	}
+	return n, p.read()
+}
+
+func (p *parser) fieldNumber() (int32, error) {
+	n, err := p.integer()
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > maxFieldNumber {
+		return 0, p.errorf("field number %d out of range", n)
+	}
+	return int32(n), nil
+}
+
+// constant 读取选项值，返回其文本形式；字符串返回解码后的内容
+func (p *parser) constant() (string, error) {
+	switch p.tok.kind {
+	case tokenString:
+		return p.stringLiteral()
+	case tokenIdent, tokenInt, tokenFloat:
+		text := p.tok.text
+		return text, p.read()
+	case tokenSymbol:
+		if p.is("-") || p.is("+") {
+			sign := p.tok.text
+			if err := p.read(); err != nil {
+				return "", err
+			}
+			if p.tok.kind != tokenInt && p.tok.kind != tokenFloat && p.tok.kind != tokenIdent {
+				return "", p.errorf("expected number after %s", sign)
+			}
+			text := p.tok.text
+			if sign == "-" {
+				text = "-" + text
+			}
+			return text, p.read()
+		}
+		if p.is("{") {
+			return "", p.skipAggregate()
+		}
+	}
+	return "", p.errorf("unexpected %q in option value", p.tok.text)
+}
+
+// skipAggregate 跳过选项中的消息字面量
+func (p *parser) skipAggregate() error {
+	depth := 0
+	for {
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("unterminated option value")
+		case p.is("{"):
+			depth++
+		case p.is("}"):
+			depth--
+		}
+		if err := p.read(); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// optionName 读取选项名，自定义选项形如 (foo.bar).baz
+func (p *parser) optionName() (string, error) {
+	var sb strings.Builder
+	for {
+		if ok, err := p.accept("("); err != nil {
+			return "", err
+		} else if ok {
+			name, err := p.fullIdent()
+			if err != nil {
+				return "", err
+			}
+			if err = p.expect(")"); err != nil {
+				return "", err
+			}
+			sb.WriteString("(" + name + ")")
+		} else {
+			name, err := p.ident()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(name)
+		}
+		if ok, err := p.accept("."); err != nil || !ok {
+			return sb.String(), err
+		}
+		sb.WriteByte('.')
+	}
+}
+
+// option 解析 option name = value; 语句，返回选项名与值
+func (p *parser) option() (string, string, error) {
+	name, err := p.optionName()
+	if err != nil {
+		return "", "", err
+	}
+	if err = p.expect("="); err != nil {
+		return "", "", err
+	}
+	value, err := p.constant()
+	return name, value, err
+}
+
+func (p *parser) file(filename string) (*descriptorpb.FileDescriptorProto, error) {
+	fd := &descriptorpb.FileDescriptorProto{Name: proto.String(filename)}
+	if p.is("edition") {
+		return nil, p.errorf("protobuf editions are not supported")
+	}
+	p.proto3 = false
+	if ok, err := p.accept("syntax"); err != nil {
+		return nil, err
+	} else if ok {
+		if err = p.expect("="); err != nil {
+			return nil, err
+		}
+		syntax, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if syntax != "proto2" && syntax != "proto3" {
+			return nil, p.errorf("unsupported syntax %q", syntax)
+		}
+		if err = p.expect(";"); err != nil {
+			return nil, err
+		}
+		p.proto3 = syntax == "proto3"
+		if p.proto3 {
+			fd.Syntax = proto.String(syntax)
+		}
+	}
+	for p.tok.kind != tokenEOF {
+		var err error
+		switch {
+		case p.is(";"):
+			err = p.read()
+		case p.is("package"):
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+			var name string
+			if name, err = p.fullIdent(); err == nil {
+				fd.Package = proto.String(name)
+				err = p.expect(";")
+			}
+		case p.is("import"):
+			err = p.importStmt(fd)
+		case p.is("option"):
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+			var name, value string
+			if name, value, err = p.option(); err == nil {
+				setFileOption(fd, name, value)
+				err = p.expect(";")
+			}
+		case p.is("message"):
+			var msg *descriptorpb.DescriptorProto
+			if msg, err = p.message(); err == nil {
+				fd.MessageType = append(fd.MessageType, msg)
+			}
+		case p.is("enum"):
+			var enum *descriptorpb.EnumDescriptorProto
+			if enum, err = p.enum(); err == nil {
+				fd.EnumType = append(fd.EnumType, enum)
+			}
+		case p.is("service"):
+			var svc *descriptorpb.ServiceDescriptorProto
+			if svc, err = p.service(); err == nil {
+				fd.Service = append(fd.Service, svc)
+			}
+		case p.is("extend"):
+			var exts []*descriptorpb.FieldDescriptorProto
+			var groups []*descriptorpb.DescriptorProto
+			if exts, groups, err = p.extend(); err == nil {
+				fd.Extension = append(fd.Extension, exts...)
+				fd.MessageType = append(fd.MessageType, groups...)
+			}
+		default:
+			err = p.errorf("unexpected %q", p.tok.text)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fd, nil
+}
+
+func (p *parser) importStmt(fd *descriptorpb.FileDescriptorProto) error {
+	if err := p.expect("import"); err != nil {
+		return err
+	}
+	public, weak := p.is("public"), p.is("weak")
+	if public || weak {
+		if err := p.read(); err != nil {
+			return err
+		}
+	}
+	path, err := p.stringLiteral()
+	if err != nil {
+		return err
+	}
+	index := int32(len(fd.Dependency))
+	fd.Dependency = append(fd.Dependency, path)
+	if public {
+		fd.PublicDependency = append(fd.PublicDependency, index)
+	}
+	if weak {
+		fd.WeakDependency = append(fd.WeakDependency, index)
+	}
+	return p.expect(";")
+}
+
+// setFileOption 仅保留影响编解码或代码生成定位的常用文件选项
+func setFileOption(fd *descriptorpb.FileDescriptorProto, name, value string) {
+	if fd.Options == nil {
+		fd.Options = &descriptorpb.FileOptions{}
+	}
+	switch name {
+	case "go_package":
+		fd.Options.GoPackage = proto.String(value)
+	case "java_package":
+		fd.Options.JavaPackage = proto.String(value)
+	case "java_multiple_files":
+		fd.Options.JavaMultipleFiles = proto.Bool(value == "true")
+	case "deprecated":
+		fd.Options.Deprecated = proto.Bool(value == "true")
+	}
+}
+
+func (p *parser) message() (*descriptorpb.DescriptorProto, error) {
+	if err := p.expect("message"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	if err = p.messageBody(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (p *parser) messageBody(msg *descriptorpb.DescriptorProto) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	var synthetic []*descriptorpb.FieldDescriptorProto
+	for !p.is("}") {
+		var err error
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("unexpected end of file in message %s", msg.GetName())
+		case p.is(";"):
+			err = p.read()
+		case p.is("message"):
+			var nested *descriptorpb.DescriptorProto
+			if nested, err = p.message(); err == nil {
+				msg.NestedType = append(msg.NestedType, nested)
+			}
+		case p.is("enum"):
+			var enum *descriptorpb.EnumDescriptorProto
+			if enum, err = p.enum(); err == nil {
+				msg.EnumType = append(msg.EnumType, enum)
+			}
+		case p.is("extend"):
+			var exts []*descriptorpb.FieldDescriptorProto
+			var groups []*descriptorpb.DescriptorProto
+			if exts, groups, err = p.extend(); err == nil {
+				msg.Extension = append(msg.Extension, exts...)
+				msg.NestedType = append(msg.NestedType, groups...)
+			}
+		case p.is("option"):
+			if err = p.read(); err != nil {
+				return err
+			}
+			if _, _, err = p.option(); err == nil {
+				err = p.expect(";")
+			}
+		case p.is("oneof"):
+			err = p.oneof(msg)
+		case p.is("reserved"):
+			err = p.reserved(&msg.ReservedRange, &msg.ReservedName)
+		case p.is("extensions"):
+			err = p.extensions(msg)
+		case p.is("map"):
+			var field *descriptorpb.FieldDescriptorProto
+			var entry *descriptorpb.DescriptorProto
+			if field, entry, err = p.mapField(); err == nil {
+				msg.Field = append(msg.Field, field)
+				msg.NestedType = append(msg.NestedType, entry)
+			}
+		default:
+			var field *descriptorpb.FieldDescriptorProto
+			var group *descriptorpb.DescriptorProto
+			if field, group, err = p.field(true); err == nil {
+				msg.Field = append(msg.Field, field)
+				if group != nil {
+					msg.NestedType = append(msg.NestedType, group)
+				}
+				if field.GetProto3Optional() {
+					synthetic = append(synthetic, field)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// proto3 optional 字段各自放入合成的 oneof，且须排在显式 oneof 之后
+	for _, field := range synthetic {
+		field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+		msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(syntheticOneofName(msg, field.GetName()))})
+	}
+	return p.read()
+}
+
+// syntheticOneofName 合成 oneof 名为 _字段名，与已有名称冲突时前置 X
+func syntheticOneofName(msg *descriptorpb.DescriptorProto, field string) string {
+	name := "_" + field
+	for {
+		conflict := false
+		for _, f := range msg.Field {
+			conflict = conflict || f.GetName() == name
+		}
+		for _, o := range msg.OneofDecl {
+			conflict = conflict || o.GetName() == name
+		}
+		if !conflict {
+			return name
+		}
+		name = "X" + name
+	}
+}
+
+// field 解析字段定义，proto2 的 group 同时返回对应的嵌套消息
+func (p *parser) field(allowLabel bool) (*descriptorpb.FieldDescriptorProto, *descriptorpb.DescriptorProto, error) {
+	field := &descriptorpb.FieldDescriptorProto{}
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	if allowLabel {
+		switch {
+		case p.is("repeated"):
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		case p.is("required"):
+			if p.proto3 {
+				return nil, nil, p.errorf("required fields are not allowed in proto3")
+			}
+			label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED
+		case p.is("optional"):
+			if p.proto3 {
+				field.Proto3Optional = proto.Bool(true)
+			}
+		}
+		if p.is("repeated") || p.is("required") || p.is("optional") {
+			if err := p.read(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	field.Label = label.Enum()
+
+	typeName, err := p.fullIdent()
+	if err != nil {
+		return nil, nil, err
+	}
+	if typeName == "group" {
+		return p.group(field)
+	}
+	if t, ok := scalarTypes[typeName]; ok {
+		field.Type = t.Enum()
+	} else {
+		field.TypeName = proto.String(typeName)
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, nil, err
+	}
+	field.Name = proto.String(name)
+	if err = p.expect("="); err != nil {
+		return nil, nil, err
+	}
+	number, err := p.fieldNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	field.Number = proto.Int32(number)
+	if err = p.fieldOptions(field); err != nil {
+		return nil, nil, err
+	}
+	return field, nil, p.expect(";")
+}
+
+// group 解析 proto2 group：生成同名嵌套消息，字段名为组名的小写形式
+func (p *parser) group(field *descriptorpb.FieldDescriptorProto) (*descriptorpb.FieldDescriptorProto, *descriptorpb.DescriptorProto, error) {
+	if p.proto3 {
+		return nil, nil, p.errorf("groups are not allowed in proto3")
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = p.expect("="); err != nil {
+		return nil, nil, err
+	}
+	number, err := p.fieldNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	field.Name = proto.String(strings.ToLower(name))
+	field.Number = proto.Int32(number)
+	field.Type = descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+	field.TypeName = proto.String(name)
+	if err = p.fieldOptions(field); err != nil {
+		return nil, nil, err
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	if err = p.messageBody(msg); err != nil {
+		return nil, nil, err
+	}
+	return field, msg, nil
+}
+
+// fieldOptions 解析 [packed = false, json_name = "x", default = 1] 形式的字段选项
+func (p *parser) fieldOptions(field *descriptorpb.FieldDescriptorProto) error {
+	if ok, err := p.accept("["); err != nil || !ok {
+		return err
+	}
+	for {
+		name, value, err := p.option()
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "default":
+			field.DefaultValue = proto.String(value)
+		case "json_name":
+			field.JsonName = proto.String(value)
+		case "packed":
+			if field.Options == nil {
+				field.Options = &descriptorpb.FieldOptions{}
+			}
+			field.Options.Packed = proto.Bool(value == "true")
+		case "deprecated":
+			if field.Options == nil {
+				field.Options = &descriptorpb.FieldOptions{}
+			}
+			field.Options.Deprecated = proto.Bool(value == "true")
+		}
+		if ok, err := p.accept(","); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+	}
+	return p.expect("]")
+}
+
+func (p *parser) mapField() (*descriptorpb.FieldDescriptorProto, *descriptorpb.DescriptorProto, error) {
+	if err := p.expect("map"); err != nil {
+		return nil, nil, err
+	}
+	if err := p.expect("<"); err != nil {
+		return nil, nil, err
+	}
+	keyType, err := p.ident()
+	if err != nil {
+		return nil, nil, err
+	}
+	kt, ok := scalarTypes[keyType]
+	if !ok || kt == descriptorpb.FieldDescriptorProto_TYPE_DOUBLE || kt == descriptorpb.FieldDescriptorProto_TYPE_FLOAT ||
+		kt == descriptorpb.FieldDescriptorProto_TYPE_BYTES {
+		return nil, nil, p.errorf("invalid map key type %q", keyType)
+	}
+	if err = p.expect(","); err != nil {
+		return nil, nil, err
+	}
+	valueType, err := p.fullIdent()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = p.expect(">"); err != nil {
+		return nil, nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = p.expect("="); err != nil {
+		return nil, nil, err
+	}
+	number, err := p.fieldNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	entryName := camelCase(name) + "Entry"
+	value := &descriptorpb.FieldDescriptorProto{Name: proto.String("value"), Number: proto.Int32(2), Label: optional, JsonName: proto.String("value")}
+	if t, ok := scalarTypes[valueType]; ok {
+		value.Type = t.Enum()
+	} else {
+		value.TypeName = proto.String(valueType)
+	}
+	entry := &descriptorpb.DescriptorProto{
+		Name: proto.String(entryName),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{Name: proto.String("key"), Number: proto.Int32(1), Label: optional, Type: kt.Enum(), JsonName: proto.String("key")},
+			value,
+		},
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	}
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(entryName),
+	}
+	if err = p.fieldOptions(field); err != nil {
+		return nil, nil, err
+	}
+	return field, entry, p.expect(";")
+}
+
+// camelCase map 字段对应的 Entry 消息名，与 protoc 规则一致
+func camelCase(name string) string {
+	var sb strings.Builder
+	upper := true
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func (p *parser) oneof(msg *descriptorpb.DescriptorProto) error {
+	if err := p.expect("oneof"); err != nil {
+		return err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	index := int32(len(msg.OneofDecl))
+	msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+	if err = p.expect("{"); err != nil {
+		return err
+	}
+	for !p.is("}") {
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("unexpected end of file in oneof %s", name)
+		case p.is(";"):
+			err = p.read()
+		case p.is("option"):
+			if err = p.read(); err == nil {
+				if _, _, err = p.option(); err == nil {
+					err = p.expect(";")
+				}
+			}
+		default:
+			var field *descriptorpb.FieldDescriptorProto
+			var group *descriptorpb.DescriptorProto
+			if field, group, err = p.field(false); err == nil {
+				field.OneofIndex = proto.Int32(index)
+				msg.Field = append(msg.Field, field)
+				if group != nil {
+					msg.NestedType = append(msg.NestedType, group)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return p.read()
+}
+
+// ranges 解析 1, 2 to 5, 9 to max 形式的范围列表，返回左闭右开区间
+func (p *parser) ranges(max int64) ([][2]int32, error) {
+	var result [][2]int32
+	for {
+		start, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if ok, err := p.accept("to"); err != nil {
+			return nil, err
+		} else if ok {
+			if ok, err = p.accept("max"); err != nil {
+				return nil, err
+			} else if ok {
+				end = max
+			} else if end, err = p.integer(); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			return nil, p.errorf("invalid range %d to %d", start, end)
+		}
+		result = append(result, [2]int32{int32(start), int32(end + 1)})
+		if ok, err := p.accept(","); err != nil || !ok {
+			return result, err
+		}
+	}
+}
+
+func (p *parser) reserved(ranges *[]*descriptorpb.DescriptorProto_ReservedRange, names *[]string) error {
+	if err := p.expect("reserved"); err != nil {
+		return err
+	}
+	if p.tok.kind == tokenString || p.tok.kind == tokenIdent {
+		for {
+			var name string
+			var err error
+			if p.tok.kind == tokenString {
+				name, err = p.stringLiteral()
+			} else {
+				name, err = p.ident()
+			}
+			if err != nil {
+				return err
+			}
+			*names = append(*names, name)
+			if ok, err := p.accept(","); err != nil {
+				return err
+			} else if !ok {
+				return p.expect(";")
+			}
+		}
+	}
+	list, err := p.ranges(maxFieldNumber)
+	if err != nil {
+		return err
+	}
+	for _, r := range list {
+		*ranges = append(*ranges, &descriptorpb.DescriptorProto_ReservedRange{Start: proto.Int32(r[0]), End: proto.Int32(r[1])})
+	}
+	return p.expect(";")
+}
+
+func (p *parser) extensions(msg *descriptorpb.DescriptorProto) error {
+	if err := p.expect("extensions"); err != nil {
+		return err
+	}
+	list, err := p.ranges(maxFieldNumber)
+	if err != nil {
+		return err
+	}
+	for _, r := range list {
+		msg.ExtensionRange = append(msg.ExtensionRange, &descriptorpb.DescriptorProto_ExtensionRange{Start: proto.Int32(r[0]), End: proto.Int32(r[1])})
+	}
+	if ok, err := p.accept("["); err != nil {
+		return err
+	} else if ok {
+		for !p.is("]") {
+			if p.tok.kind == tokenEOF {
+				return p.errorf("unterminated extension range options")
+			}
+			if err = p.read(); err != nil {
+				return err
+			}
+		}
+		if err = p.read(); err != nil {
+			return err
+		}
+	}
+	return p.expect(";")
+}
+
+func (p *parser) extend() ([]*descriptorpb.FieldDescriptorProto, []*descriptorpb.DescriptorProto, error) {
+	if err := p.expect("extend"); err != nil {
+		return nil, nil, err
+	}
+	extendee, err := p.fullIdent()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = p.expect("{"); err != nil {
+		return nil, nil, err
+	}
+	var fields []*descriptorpb.FieldDescriptorProto
+	var groups []*descriptorpb.DescriptorProto
+	for !p.is("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, nil, p.errorf("unexpected end of file in extend %s", extendee)
+		}
+		if ok, err := p.accept(";"); err != nil {
+			return nil, nil, err
+		} else if ok {
+			continue
+		}
+		field, group, err := p.field(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		field.Extendee = proto.String(extendee)
+		field.Proto3Optional = nil
+		fields = append(fields, field)
+		if group != nil {
+			groups = append(groups, group)
+		}
+	}
+	return fields, groups, p.read()
+}
+
+func (p *parser) enum() (*descriptorpb.EnumDescriptorProto, error) {
+	if err := p.expect("enum"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		switch {
+		case p.tok.kind == tokenEOF:
+			return nil, p.errorf("unexpected end of file in enum %s", name)
+		case p.is(";"):
+			err = p.read()
+		case p.is("option"):
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+			var optName, value string
+			if optName, value, err = p.option(); err == nil {
+				if optName == "allow_alias" {
+					enum.Options = &descriptorpb.EnumOptions{AllowAlias: proto.Bool(value == "true")}
+				}
+				err = p.expect(";")
+			}
+		case p.is("reserved"):
+			var ranges []*descriptorpb.DescriptorProto_ReservedRange
+			if err = p.reserved(&ranges, &enum.ReservedName); err == nil {
+				// 枚举保留范围为闭区间
+				for _, r := range ranges {
+					enum.ReservedRange = append(enum.ReservedRange, &descriptorpb.EnumDescriptorProto_EnumReservedRange{
+						Start: r.Start, End: proto.Int32(r.GetEnd() - 1),
+					})
+				}
+			}
+		default:
+			var value *descriptorpb.EnumValueDescriptorProto
+			if value, err = p.enumValue(); err == nil {
+				enum.Value = append(enum.Value, value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return enum, p.read()
+}
+
+func (p *parser) enumValue() (*descriptorpb.EnumValueDescriptorProto, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect("="); err != nil {
+		return nil, err
+	}
+	number, err := p.integer()
+	if err != nil {
+		return nil, err
+	}
+	value := &descriptorpb.EnumValueDescriptorProto{Name: proto.String(name), Number: proto.Int32(int32(number))}
+	if ok, err := p.accept("["); err != nil {
+		return nil, err
+	} else if ok {
+		for {
+			if _, _, err = p.option(); err != nil {
+				return nil, err
+			}
+			if ok, err = p.accept(","); err != nil {
+				return nil, err
+			} else if !ok {
+				break
+			}
+		}
+		if err = p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	return value, p.expect(";")
+}
+
+func (p *parser) service() (*descriptorpb.ServiceDescriptorProto, error) {
+	if err := p.expect("service"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(name)}
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		switch {
+		case p.tok.kind == tokenEOF:
+			return nil, p.errorf("unexpected end of file in service %s", name)
+		case p.is(";"):
+			err = p.read()
+		case p.is("option"):
+			if err = p.read(); err == nil {
+				if _, _, err = p.option(); err == nil {
+					err = p.expect(";")
+				}
+			}
+		case p.is("rpc"):
+			var method *descriptorpb.MethodDescriptorProto
+			if method, err = p.rpc(); err == nil {
+				svc.Method = append(svc.Method, method)
+			}
+		default:
+			err = p.errorf("unexpected %q in service %s", p.tok.text, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return svc, p.read()
+}
+
+func (p *parser) rpc() (*descriptorpb.MethodDescriptorProto, error) {
+	if err := p.expect("rpc"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	method := &descriptorpb.MethodDescriptorProto{Name: proto.String(name)}
+	input, clientStreaming, err := p.rpcType()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect("returns"); err != nil {
+		return nil, err
+	}
+	output, serverStreaming, err := p.rpcType()
+	if err != nil {
+		return nil, err
+	}
+	method.InputType, method.ClientStreaming = proto.String(input), proto.Bool(clientStreaming)
+	method.OutputType, method.ServerStreaming = proto.String(output), proto.Bool(serverStreaming)
+	if p.is("{") {
+		if err = p.skipAggregate(); err != nil {
+			return nil, err
+		}
+		_, err = p.accept(";")
+		return method, err
+	}
+	return method, p.expect(";")
+}
+
+// rpcType 解析 (stream Type) 形式的请求或响应类型
+func (p *parser) rpcType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	stream, err := p.accept("stream")
+	if err != nil {
+		return "", false, err
+	}
+	typeName, err := p.fullIdent()
+	if err != nil {
+		return "", false, err
+	}
+	return typeName, stream, p.expect(")")
+}
@@ -0,0 +1,313 @@
+package protox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const testProto = `syntax = "proto3";
+package demo.v1;
+
+import "google/protobuf/timestamp.proto";
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+}
+
+message Address {
+  string city = 1;
+}
+
+message User {
+  int64 id = 1;
+  string user_name = 2;
+  repeated string tags = 3;
+  Status status = 4;
+  Address address = 5;
+  map<string, int32> scores = 6;
+  google.protobuf.Timestamp created_at = 7;
+  oneof contact {
+    string email = 8;
+    string phone = 9;
+  }
+  optional bool verified = 10;
+}
+`
+
+func compileTestSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := (&Compiler{Sources: map[string]string{"demo/v1/user.proto": testProto}}).Compile()
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	return schema
+}
+
+// JSON 编码为二进制再解码，得到相同的 proto3 JSON
+func TestEncodeDecode(t *testing.T) {
+	schema := compileTestSchema(t)
+	content := `{
+  "id": "42",
+  "userName": "alice",
+  "tags": ["a", "b"],
+  "status": "STATUS_ACTIVE",
+  "address": {"city": "Hangzhou"},
+  "scores": {"math": 90},
+  "createdAt": "2024-01-02T03:04:05Z",
+  "email": "a@b.c",
+  "verified": false
+}`
+	data, err := schema.Encode("demo.v1.User", []byte(content), nil)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	out, warnings, err := schema.Decode("demo.v1.User", data, nil)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	var got, want any
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("decoded json: %v", err)
+	}
+	_ = json.Unmarshal([]byte(content), &want)
+	gotText, _ := json.Marshal(got)
+	wantText, _ := json.Marshal(want)
+	if string(gotText) != string(wantText) {
+		t.Errorf("round trip changed data\n got: %s\nwant: %s", gotText, wantText)
+	}
+}
+
+func TestEncodeUnknownField(t *testing.T) {
+	schema := compileTestSchema(t)
+	if _, err := schema.Encode("demo.v1.User", []byte(`{"unknown": 1}`), nil); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	if _, err := schema.Encode("demo.v1.User", []byte(`{"unknown": 1}`), &EncodeOptions{DiscardUnknown: true}); err != nil {
+		t.Errorf("discard unknown: %v", err)
+	}
+}
+
+// schema 中不存在的字段在解码时记录为提示
+func TestDecodeUnknownFields(t *testing.T) {
+	schema := compileTestSchema(t)
+	data := protowire.AppendTag(nil, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 7)
+	data = protowire.AppendTag(data, 99, protowire.BytesType)
+	data = protowire.AppendString(data, "x")
+	out, warnings, err := schema.Decode("demo.v1.User", data, &DecodeOptions{UseProtoNames: true})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.Contains(string(out), `"id": "7"`) {
+		t.Errorf("unexpected output: %s", out)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "99") {
+		t.Errorf("expected a warning for field 99, got %v", warnings)
+	}
+}
+
+func TestFindMessage(t *testing.T) {
+	schema := compileTestSchema(t)
+	for _, name := range []string{"demo.v1.User", ".demo.v1.User"} {
+		if _, err := schema.FindMessage(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := schema.FindMessage("demo.v1.Missing"); err == nil {
+		t.Error("expected error for missing message")
+	}
+}
+
+// 解析 .proto 得到的描述中字段编号、标签与类型名正确，类型名在编译时解析
+func TestParseProto(t *testing.T) {
+	fd, err := ParseProto("demo/v1/user.proto", testProto)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if fd.GetPackage() != "demo.v1" || len(fd.GetMessageType()) != 2 || len(fd.GetEnumType()) != 1 {
+		t.Fatalf("unexpected descriptor: %v", fd)
+	}
+	user := fd.GetMessageType()[1]
+	fields := make(map[string]*descriptorpb.FieldDescriptorProto)
+	for _, field := range user.GetField() {
+		fields[field.GetName()] = field
+	}
+	if f := fields["tags"]; f.GetNumber() != 3 || f.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		t.Errorf("tags: %v", f)
+	}
+	if f := fields["address"]; f.GetNumber() != 5 || f.GetTypeName() != "Address" {
+		t.Errorf("address: %v", f)
+	}
+	if f := fields["verified"]; !f.GetProto3Optional() {
+		t.Errorf("verified should be proto3 optional: %v", f)
+	}
+}
+
+// 导入路径不能跳出导入目录，文件内容也不能出现在错误信息中
+func TestCompileImportOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	idl := filepath.Join(root, "idl")
+	if err := os.Mkdir(idl, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "secret.proto"), []byte("root:x:0:0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../secret.proto", filepath.ToSlash(filepath.Join(root, "secret.proto")), "a/../../secret.proto"} {
+		compiler := &Compiler{
+			Sources:     map[string]string{"a.proto": "syntax = \"proto3\";\nimport \"" + name + "\";\n"},
+			ImportPaths: []string{idl},
+		}
+		_, err := compiler.Compile()
+		if err == nil || !strings.Contains(err.Error(), "relative path") || strings.Contains(err.Error(), "root:x") {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestParseProtoErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{"missing semicolon", "syntax = \"proto3\";\nmessage A {\n  string a = 1\n}\n"},
+		{"unterminated message", "syntax = \"proto3\";\nmessage A {\n"},
+		{"invalid field number", "syntax = \"proto3\";\nmessage A {\n  string a = 0;\n}\n"},
+		{"unterminated string", "syntax = \"proto3;\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := ParseProto("a.proto", c.src); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// LoadDescriptorSet 加载 protoc 生成的描述集合，缺少依赖时报错
+func TestLoadDescriptorSet(t *testing.T) {
+	schema := compileTestSchema(t)
+	md, err := schema.FindMessage("demo.v1.User")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(md.ParentFile())}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal set: %v", err)
+	}
+	loaded, err := LoadDescriptorSet(data)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err = loaded.FindMessage("demo.v1.User"); err != nil {
+		t.Errorf("find in loaded set: %v", err)
+	}
+	if _, err = LoadDescriptorSet(nil); err == nil {
+		t.Error("expected error for empty descriptor set")
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	var nested []byte
+	nested = protowire.AppendTag(nested, 1, protowire.VarintType)
+	nested = protowire.AppendVarint(nested, 150)
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, protowire.EncodeZigZag(-3))
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "hello")
+	data = protowire.AppendTag(data, 3, protowire.BytesType)
+	data = protowire.AppendBytes(data, nested)
+	data = protowire.AppendTag(data, 4, protowire.Fixed32Type)
+	data = protowire.AppendFixed32(data, 0x3fc00000) // 1.5
+	data = protowire.AppendTag(data, 5, protowire.BytesType)
+	data = protowire.AppendBytes(data, []byte{0xff, 0x00})
+	fields, err := DecodeRaw(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(fields) != 5 {
+		t.Fatalf("expected 5 fields, got %d", len(fields))
+	}
+	if f := fields[0]; f.WireType != "varint" || f.Sint == nil || *f.Sint != -3 {
+		t.Errorf("field 1: %+v", f)
+	}
+	if f := fields[1]; f.Kind != "string" || f.Value != "hello" {
+		t.Errorf("field 2: %+v", f)
+	}
+	if f := fields[2]; f.Kind != "message" {
+		t.Errorf("field 3: %+v", f)
+	} else if inner := f.Value.([]RawField); len(inner) != 1 || inner[0].Value != uint64(150) {
+		t.Errorf("field 3 nested: %+v", inner)
+	}
+	if f := fields[3]; f.Float == nil || *f.Float != 1.5 {
+		t.Errorf("field 4: %+v", f)
+	}
+	if f := fields[4]; f.Kind != "bytes" || f.Value != "/wA=" {
+		t.Errorf("field 5: %+v", f)
+	}
+	if _, err = DecodeRaw([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Error("expected error for truncated data")
+	}
+}
+
+// 嵌套层数超过 maxRawDepth 的分组不再展开，输出为 base64
+func TestDecodeRawGroupDepth(t *testing.T) {
+	const depth = 1000
+	var data []byte
+	for i := 0; i < depth; i++ {
+		data = protowire.AppendTag(data, 1, protowire.StartGroupType)
+	}
+	for i := 0; i < depth; i++ {
+		data = protowire.AppendTag(data, 1, protowire.EndGroupType)
+	}
+	fields, err := DecodeRaw(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	levels := 0
+	for len(fields) == 1 {
+		nested, ok := fields[0].Value.([]RawField)
+		if !ok {
+			if fields[0].Kind != "bytes" {
+				t.Errorf("expected bytes at depth limit, got %+v", fields[0])
+			}
+			break
+		}
+		fields = nested
+		levels++
+	}
+	if levels != maxRawDepth {
+		t.Errorf("expanded %d levels, want %d", levels, maxRawDepth)
+	}
+}
+
+// FuzzDecodeRaw 任意字节只能返回错误，不能 panic
+func FuzzDecodeRaw(f *testing.F) {
+	f.Add([]byte{0x08, 0x96, 0x01})
+	f.Add([]byte{0x12, 0x05, 'h', 'e', 'l', 'l', 'o'})
+	f.Add([]byte{0x0b, 0x08, 0x01, 0x0c})
+	f.Add([]byte{0x1a, 0x03, 0x08, 0x96, 0x01})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fields, err := DecodeRaw(data)
+		if err != nil {
+			return
+		}
+		if _, err = json.Marshal(fields); err != nil {
+			t.Fatalf("marshal fields: %v", err)
+		}
+	})
+}
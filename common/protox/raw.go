@@ -0,0 +1,159 @@
+package protox
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 无 schema 解码时尝试将 length-delimited 字段解析为嵌套消息、展开分组的最大深度
+const maxRawDepth = 32
+
+// RawField 无 schema 解码出的字段
+type RawField struct {
+	Field    int32    `json:"field"`            // 字段号
+	WireType string   `json:"wire_type"`        // varint|fixed64|bytes|group|fixed32
+	Kind     string   `json:"kind,omitempty"`   // bytes 内容的推测类型: string|message|bytes，超过最大深度的 group 为 bytes
+	Value    any      `json:"value"`            // 整数为无符号值，string 为文本，message/group 为嵌套字段，bytes 为 base64
+	Signed   *int64   `json:"signed,omitempty"` // varint 按 int64 解释（最高位为 1 时）或 fixed 按有符号解释
+	Sint     *int64   `json:"sint,omitempty"`   // varint 按 zigzag（sint32/sint64）解释
+	Float    *float64 `json:"float,omitempty"`  // fixed32/fixed64 按 float/double 解释
+}
+
+// DecodeRaw 不依赖 schema 解码 protobuf 二进制，输出字段号、wire type 与可能的取值
+func DecodeRaw(data []byte) ([]RawField, error) {
+	return decodeRaw(data, 0)
+}
+
+func decodeRaw(data []byte, depth int) ([]RawField, error) {
+	fields := make([]RawField, 0)
+	offset := 0
+	for offset < len(data) {
+		number, wireType, n := protowire.ConsumeTag(data[offset:])
+		if n < 0 {
+			return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(n))
+		}
+		offset += n
+		field := RawField{Field: int32(number), WireType: wireTypeName(wireType)}
+		switch wireType {
+		case protowire.VarintType:
+			v, m := protowire.ConsumeVarint(data[offset:])
+			if m < 0 {
+				return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(m))
+			}
+			offset += m
+			field.Value = v
+			if v > math.MaxInt64 {
+				field.Signed = int64Ptr(int64(v))
+			}
+			field.Sint = int64Ptr(protowire.DecodeZigZag(v))
+		case protowire.Fixed32Type:
+			v, m := protowire.ConsumeFixed32(data[offset:])
+			if m < 0 {
+				return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(m))
+			}
+			offset += m
+			field.Value = v
+			if int32(v) < 0 {
+				field.Signed = int64Ptr(int64(int32(v)))
+			}
+			field.Float = floatPtr(float64(math.Float32frombits(v)))
+		case protowire.Fixed64Type:
+			v, m := protowire.ConsumeFixed64(data[offset:])
+			if m < 0 {
+				return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(m))
+			}
+			offset += m
+			field.Value = v
+			if int64(v) < 0 {
+				field.Signed = int64Ptr(int64(v))
+			}
+			field.Float = floatPtr(math.Float64frombits(v))
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(data[offset:])
+			if m < 0 {
+				return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(m))
+			}
+			offset += m
+			field.Kind, field.Value = guessBytes(v, depth)
+		case protowire.StartGroupType:
+			v, m := protowire.ConsumeGroup(number, data[offset:])
+			if m < 0 {
+				return nil, fmt.Errorf("offset %d: %w", offset, protowire.ParseError(m))
+			}
+			offset += m
+			if depth >= maxRawDepth {
+				// 超过最大深度的分组不再展开，与 length-delimited 字段一样输出 base64
+				field.Kind, field.Value = "bytes", base64.StdEncoding.EncodeToString(v)
+				break
+			}
+			nested, err := decodeRaw(v, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			field.Value = nested
+		default:
+			return nil, fmt.Errorf("offset %d: unexpected wire type %d", offset, wireType)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// guessBytes 可打印的 UTF-8 文本按字符串输出，能完整解析的按嵌套消息输出，否则输出 base64
+func guessBytes(data []byte, depth int) (string, any) {
+	if printable(data) {
+		return "string", string(data)
+	}
+	if depth < maxRawDepth && len(data) > 0 {
+		if nested, err := decodeRaw(data, depth+1); err == nil {
+			return "message", nested
+		}
+	}
+	return "bytes", base64.StdEncoding.EncodeToString(data)
+}
+
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func wireTypeName(t protowire.Type) string {
+	switch t {
+	case protowire.VarintType:
+		return "varint"
+	case protowire.Fixed32Type:
+		return "fixed32"
+	case protowire.Fixed64Type:
+		return "fixed64"
+	case protowire.BytesType:
+		return "bytes"
+	case protowire.StartGroupType:
+		return "group"
+	default:
+		return fmt.Sprintf("wire type %d", t)
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// floatPtr NaN 与 Infinity 无法输出为 JSON，返回 nil
+func floatPtr(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
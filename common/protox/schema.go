@@ -0,0 +1,229 @@
+package protox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// 注册 google/protobuf/*.proto，供 .proto 文件直接导入
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/apipb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/sourcecontextpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/typepb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Schema 编译后的 protobuf 描述集合
+type Schema struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+}
+
+func newSchema(files *protoregistry.Files) *Schema {
+	return &Schema{files: files, types: dynamicpb.NewTypes(files)}
+}
+
+// Compiler 编译 .proto 源文件，导入的文件依次从 Sources、ImportPaths 和内置的 google/protobuf 中查找
+type Compiler struct {
+	Sources     map[string]string // 直接提供的源文件，键为导入路径
+	ImportPaths []string          // 查找导入文件的目录，如 idl
+}
+
+// Compile 编译指定文件及其依赖，未指定时编译 Sources 中的全部文件
+func (c *Compiler) Compile(names ...string) (*Schema, error) {
+	if len(names) == 0 {
+		for name := range c.Sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no .proto files provided")
+	}
+	b := newBuilder(c.load)
+	for _, name := range names {
+		if err := b.build(name); err != nil {
+			return nil, err
+		}
+	}
+	return newSchema(b.files), nil
+}
+
+// load 查找并解析导入的文件，内置文件返回 nil
+func (c *Compiler) load(name string) (*descriptorpb.FileDescriptorProto, error) {
+	// 导入路径来自上传的文件，不能是绝对路径或跳出导入目录
+	local := filepath.FromSlash(path.Clean(name))
+	if !filepath.IsLocal(local) {
+		return nil, fmt.Errorf("import %q must be a relative path inside the import directories", name)
+	}
+	if src, ok := c.Sources[name]; ok {
+		return ParseProto(name, src)
+	}
+	for _, dir := range c.ImportPaths {
+		data, err := os.ReadFile(filepath.Join(dir, local))
+		if err == nil {
+			return ParseProto(name, string(data))
+		}
+	}
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(name); err == nil {
+		return nil, nil
+	}
+	// 上传的文件没有目录信息，按文件名唯一匹配
+	var matched []string
+	for source := range c.Sources {
+		if path.Base(source) == path.Base(name) {
+			matched = append(matched, source)
+		}
+	}
+	if len(matched) == 1 {
+		return ParseProto(name, c.Sources[matched[0]])
+	}
+	return nil, fmt.Errorf("import %q not found", name)
+}
+
+// LoadDescriptorSet 加载 protoc --descriptor_set_out 生成的 FileDescriptorSet，缺少的 google/protobuf 文件使用内置版本
+func LoadDescriptorSet(data []byte) (*Schema, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	if len(set.File) == 0 {
+		return nil, errors.New("descriptor set contains no files")
+	}
+	protos := make(map[string]*descriptorpb.FileDescriptorProto, len(set.File))
+	for _, fd := range set.File {
+		protos[fd.GetName()] = fd
+	}
+	b := newBuilder(func(name string) (*descriptorpb.FileDescriptorProto, error) {
+		if fd, ok := protos[name]; ok {
+			return fd, nil
+		}
+		if _, err := protoregistry.GlobalFiles.FindFileByPath(name); err == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%q is missing from the descriptor set, generate it with --include_imports", name)
+	})
+	for _, fd := range set.File {
+		if err := b.build(fd.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return newSchema(b.files), nil
+}
+
+// builder 按依赖顺序注册文件描述
+type builder struct {
+	files    *protoregistry.Files
+	load     func(name string) (*descriptorpb.FileDescriptorProto, error)
+	visiting map[string]bool
+}
+
+func newBuilder(load func(name string) (*descriptorpb.FileDescriptorProto, error)) *builder {
+	return &builder{files: new(protoregistry.Files), load: load, visiting: make(map[string]bool)}
+}
+
+func (b *builder) build(name string) error {
+	if _, err := b.files.FindFileByPath(name); err == nil {
+		return nil
+	}
+	if b.visiting[name] {
+		return fmt.Errorf("import cycle involving %q", name)
+	}
+	fdp, err := b.load(name)
+	if err != nil {
+		return err
+	}
+	if fdp == nil {
+		fd, _ := protoregistry.GlobalFiles.FindFileByPath(name)
+		return b.files.RegisterFile(fd)
+	}
+	b.visiting[name] = true
+	for _, dep := range fdp.Dependency {
+		if err = b.build(dep); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	delete(b.visiting, name)
+	fd, err := protodesc.NewFile(fdp, b.files)
+	if err != nil {
+		return err
+	}
+	return b.files.RegisterFile(fd)
+}
+
+// FindMessage 按全名查找消息类型，名称唯一时也可省略包名
+func (s *Schema) FindMessage(name string) (protoreflect.MessageDescriptor, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), ".")
+	if name == "" {
+		return nil, fmt.Errorf("message name is required, available: %s", strings.Join(s.messages(20), ", "))
+	}
+	if d, err := s.files.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
+		if md, ok := d.(protoreflect.MessageDescriptor); ok {
+			return md, nil
+		}
+	}
+	var matched []protoreflect.MessageDescriptor
+	s.rangeMessages(func(md protoreflect.MessageDescriptor) {
+		if strings.HasSuffix(string(md.FullName()), "."+name) {
+			matched = append(matched, md)
+		}
+	})
+	switch len(matched) {
+	case 1:
+		return matched[0], nil
+	case 0:
+		return nil, fmt.Errorf("message %q not found, available: %s", name, strings.Join(s.messages(20), ", "))
+	default:
+		names := make([]string, len(matched))
+		for i, md := range matched {
+			names[i] = string(md.FullName())
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("message %q is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// messages 返回排序后的消息全名，最多 limit 个
+func (s *Schema) messages(limit int) []string {
+	var names []string
+	s.rangeMessages(func(md protoreflect.MessageDescriptor) {
+		if !md.IsMapEntry() {
+			names = append(names, string(md.FullName()))
+		}
+	})
+	sort.Strings(names)
+	if len(names) > limit {
+		names = append(names[:limit], "...")
+	}
+	return names
+}
+
+func (s *Schema) rangeMessages(f func(md protoreflect.MessageDescriptor)) {
+	var walk func(mds protoreflect.MessageDescriptors)
+	walk = func(mds protoreflect.MessageDescriptors) {
+		for i := 0; i < mds.Len(); i++ {
+			f(mds.Get(i))
+			walk(mds.Get(i).Messages())
+		}
+	}
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		walk(fd.Messages())
+		return true
+	})
+}
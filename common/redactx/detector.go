@@ -0,0 +1,138 @@
+package redactx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Detector 按取值识别敏感信息的检测器
+type Detector string
+
+const (
+	DetectorPhone    Detector = "phone"    // 中国大陆手机号，可带 +86 前缀
+	DetectorIDCard   Detector = "idcard"   // 18 位居民身份证号，校验末位校验码
+	DetectorEmail    Detector = "email"    // 邮箱地址
+	DetectorBankCard Detector = "bankcard" // 13-19 位银行卡号，校验 Luhn
+	DetectorJWT      Detector = "jwt"      // JSON Web Token
+)
+
+// AllDetectors 全部检测器，按匹配顺序排列，先匹配较长、特征更明确的格式
+var AllDetectors = []Detector{DetectorJWT, DetectorEmail, DetectorIDCard, DetectorBankCard, DetectorPhone}
+
+// ParseDetector 解析检测器名称
+func ParseDetector(name string) (Detector, error) {
+	d := Detector(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := detectorSpecs[d]; !ok {
+		return "", fmt.Errorf("unsupported detector: %q, expected phone, idcard, email, bankcard or jwt", name)
+	}
+	return d, nil
+}
+
+type detectorSpec struct {
+	re       *regexp.Regexp
+	boundary func(b byte) bool // 匹配两侧不能出现的字符
+	validate func(s string) bool
+	partial  func(s string) string // 部分遮盖
+}
+
+var detectorSpecs = map[Detector]detectorSpec{
+	DetectorPhone: {
+		re:       regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d{9}`),
+		boundary: isDigit,
+		partial: func(s string) string {
+			n := countDigits(s)
+			return maskDigits(s, n-8, 4)
+		},
+	},
+	DetectorIDCard: {
+		re:       regexp.MustCompile(`[1-9]\d{16}[\dXx]`),
+		boundary: isAlnum,
+		validate: validIDCard,
+		partial: func(s string) string {
+			return maskMiddle(s, 3, 4)
+		},
+	},
+	DetectorEmail: {
+		re:       regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+		boundary: func(b byte) bool { return isAlnum(b) || b == '.' || b == '_' },
+		partial: func(s string) string {
+			at := strings.LastIndexByte(s, '@')
+			return s[:1] + "***" + s[at:]
+		},
+	},
+	DetectorBankCard: {
+		re:       regexp.MustCompile(`[3-6]\d{3}(?:[ -]?\d){9,15}`),
+		boundary: isDigit,
+		validate: validBankCard,
+		partial: func(s string) string {
+			return maskDigits(s, 6, 4)
+		},
+	},
+	DetectorJWT: {
+		re:       regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+		boundary: func(b byte) bool { return isAlnum(b) || b == '_' || b == '-' || b == '.' },
+		partial: func(s string) string {
+			return s[:strings.IndexByte(s, '.')] + "." + fullMask
+		},
+	},
+}
+
+// find 查找文本中命中检测器的区间
+func (d Detector) find(text string) [][]int {
+	spec := detectorSpecs[d]
+	var spans [][]int
+	for _, span := range spec.re.FindAllStringIndex(text, -1) {
+		if span[0] > 0 && spec.boundary(text[span[0]-1]) || span[1] < len(text) && spec.boundary(text[span[1]]) {
+			continue
+		}
+		if spec.validate != nil && !spec.validate(text[span[0]:span[1]]) {
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+var idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// validIDCard 校验身份证号末位校验码（GB 11643）
+func validIDCard(s string) bool {
+	sum := 0
+	for i, w := range idCardWeights {
+		sum += int(s[i]-'0') * w
+	}
+	return strings.ToUpper(s[17:]) == string("10X98765432"[sum%11])
+}
+
+// validBankCard 长度 13-19 且通过 Luhn 校验
+func validBankCard(s string) bool {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			digits = append(digits, s[i]-'0')
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i])
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isAlnum(b byte) bool {
+	return isDigit(b) || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,117 @@
+package redactx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Style 遮盖方式
+type Style string
+
+const (
+	StyleFull    Style = "full"    // 整体替换为 ******，不保留长度
+	StylePartial Style = "partial" // 保留首尾部分字符
+	StyleHash    Style = "hash"    // 替换为 SHA-256 摘要前 16 位，相同取值得到相同结果
+)
+
+const fullMask = "******"
+
+// ParseStyle 解析遮盖方式，为空时为 partial
+func ParseStyle(name string) (Style, error) {
+	switch s := Style(strings.ToLower(strings.TrimSpace(name))); s {
+	case "":
+		return StylePartial, nil
+	case StyleFull, StylePartial, StyleHash:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unsupported mask style: %q, expected full, partial or hash", name)
+	}
+}
+
+// maskValue 遮盖整个值，对象与数组逐个遮盖其中的标量，null 保持不变
+func maskValue(style Style, value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		for key, item := range v {
+			v[key] = maskValue(style, item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = maskValue(style, item)
+		}
+		return v
+	case string:
+		return maskString(style, v, genericPartial)
+	case json.Number:
+		return maskString(style, v.String(), genericPartial)
+	default:
+		return maskString(style, fmt.Sprint(v), genericPartial)
+	}
+}
+
+func maskString(style Style, s string, partial func(string) string) string {
+	switch style {
+	case StyleFull:
+		return fullMask
+	case StyleHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	default:
+		return partial(s)
+	}
+}
+
+// genericPartial 保留约四分之一的首尾字符，短值整体遮盖
+func genericPartial(s string) string {
+	n := utf8.RuneCountInString(s)
+	if n <= 4 {
+		return strings.Repeat("*", n)
+	}
+	return maskMiddle(s, min(n/4, 3), min(n/4, 4))
+}
+
+// maskMiddle 保留首尾各若干字符，中间替换为 *
+func maskMiddle(s string, keepStart, keepEnd int) string {
+	runes := []rune(s)
+	if keepStart+keepEnd >= len(runes) {
+		return s
+	}
+	return string(runes[:keepStart]) + strings.Repeat("*", len(runes)-keepStart-keepEnd) + string(runes[len(runes)-keepEnd:])
+}
+
+// maskDigits 只遮盖数字，保留首尾各若干位数字以及分隔符
+func maskDigits(s string, keepStart, keepEnd int) string {
+	total := countDigits(s)
+	var b strings.Builder
+	seen := 0
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if seen < keepStart || seen >= total-keepEnd {
+			b.WriteByte(s[i])
+		} else {
+			b.WriteByte('*')
+		}
+		seen++
+	}
+	return b.String()
+}
+
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,463 @@
+package redactx
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	mathrand "math/rand/v2"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+)
+
+// FakeKind 假名化生成的取值类型
+type FakeKind string
+
+const (
+	FakeAuto     FakeKind = "auto"     // 按取值识别：邮箱、手机号、身份证号、银行卡号、数字，其余按字符类别替换
+	FakeName     FakeKind = "name"     // 人名，含汉字时生成中文姓名
+	FakeEmail    FakeKind = "email"    // 邮箱
+	FakePhone    FakeKind = "phone"    // 电话号码，保留分隔符与国家码
+	FakeIDCard   FakeKind = "idcard"   // 18 位居民身份证号，校验码有效
+	FakeBankCard FakeKind = "bankcard" // 银行卡号，保留发卡行标识（前 6 位），Luhn 有效
+	FakeAddress  FakeKind = "address"  // 地址，含汉字时生成中文地址
+	FakeNumber   FakeKind = "number"   // 数字，指定 Min/Max 时落在区间内，否则保持位数与小数位
+	FakeID       FakeKind = "id"       // 标识符，如 UUID、订单号，保持长度、字符类别与分隔符
+)
+
+// ParseFakeKind 解析假名化类型，为空时为 auto
+func ParseFakeKind(name string) (FakeKind, error) {
+	switch k := FakeKind(strings.ToLower(strings.TrimSpace(name))); k {
+	case "":
+		return FakeAuto, nil
+	case FakeAuto, FakeName, FakeEmail, FakePhone, FakeIDCard, FakeBankCard, FakeAddress, FakeNumber, FakeID:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unsupported pseudonym kind: %q, expected auto, name, email, phone, idcard, bankcard, address, number or id", name)
+	}
+}
+
+// PseudonymRule 假名化规则，命中路径的值（对象与数组为其中所有标量）替换为同类型的假数据
+type PseudonymRule struct {
+	Paths []string `json:"paths"`          // 路径模式，如 $.users[*].name、**.email
+	Kind  FakeKind `json:"kind,omitempty"` // 假数据类型，默认 auto
+	Min   *float64 `json:"min,omitempty"`  // number 类型的取值下限
+	Max   *float64 `json:"max,omitempty"`  // number 类型的取值上限
+}
+
+// Pseudonymization 一处假名化记录
+type Pseudonymization struct {
+	Path      string   `json:"path"`                // JSON Pointer
+	Kind      FakeKind `json:"kind"`                // 实际使用的假数据类型
+	Collision bool     `json:"collision,omitempty"` // 与其他原值得到了相同的假数据，关联时无法区分
+}
+
+type compiledPseudonymRule struct {
+	paths    []*transformx.PathPattern
+	kind     FakeKind
+	min, max *float64
+}
+
+// Pseudonymizer 假名化器。假数据只由盐、类型与原值决定，与处理顺序无关：
+// 同一实例内相同取值总是得到相同的假数据，使用相同的盐创建的实例之间结果也一致，便于跨文档关联
+type Pseudonymizer struct {
+	rules      []compiledPseudonymRule
+	salt       []byte
+	forward    map[string]string // 类型+区间+原值 -> 假数据
+	reverse    map[string]string // 类型+假数据 -> 原值，用于发现不同原值得到相同假数据
+	collisions map[string]bool   // 发生冲突的类型+假数据
+}
+
+// NewPseudonymizer 编译假名化规则，salt 为空时使用随机盐，结果只在本实例内一致
+func NewPseudonymizer(rules []PseudonymRule, salt string) (*Pseudonymizer, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("at least one pseudonym rule is required")
+	}
+	p := &Pseudonymizer{
+		salt:       []byte(salt),
+		forward:    make(map[string]string),
+		reverse:    make(map[string]string),
+		collisions: make(map[string]bool),
+	}
+	if salt == "" {
+		p.salt = make([]byte, 32)
+		if _, err := rand.Read(p.salt); err != nil {
+			return nil, err
+		}
+	}
+	for i, rule := range rules {
+		kind, err := ParseFakeKind(string(rule.Kind))
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if len(rule.Paths) == 0 {
+			return nil, fmt.Errorf("rule %d: paths is required", i+1)
+		}
+		patterns, err := transformx.ParsePathPatterns(rule.Paths)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+			return nil, fmt.Errorf("rule %d: min %v is greater than max %v", i+1, *rule.Min, *rule.Max)
+		}
+		p.rules = append(p.rules, compiledPseudonymRule{paths: patterns, kind: kind, min: rule.Min, max: rule.Max})
+	}
+	return p, nil
+}
+
+// Pseudonymize 假名化数据，对象与数组原地修改
+func (p *Pseudonymizer) Pseudonymize(value any) (any, []Pseudonymization) {
+	var records []Pseudonymization
+	result := p.walk(value, nil, &records)
+	return result, records
+}
+
+func (p *Pseudonymizer) walk(value any, tokens []transformx.PathToken, records *[]Pseudonymization) any {
+	for _, rule := range p.rules {
+		for _, pattern := range rule.paths {
+			if pattern.Match(tokens) {
+				return p.fakeNode(rule, value, tokens, records)
+			}
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			v[key] = p.walk(v[key], append(tokens, transformx.PathToken{Key: key}), records)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = p.walk(item, append(tokens, transformx.PathToken{Index: i, IsIndex: true}), records)
+		}
+	}
+	return value
+}
+
+func (p *Pseudonymizer) fakeNode(rule compiledPseudonymRule, value any, tokens []transformx.PathToken, records *[]Pseudonymization) any {
+	switch v := value.(type) {
+	case nil, bool:
+		return value
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			v[key] = p.fakeNode(rule, v[key], append(tokens, transformx.PathToken{Key: key}), records)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = p.fakeNode(rule, item, append(tokens, transformx.PathToken{Index: i, IsIndex: true}), records)
+		}
+		return v
+	case string:
+		kind := resolveKind(rule.kind, v)
+		fake, collision := p.fake(kind, v, rule)
+		*records = append(*records, Pseudonymization{Path: transformx.Pointer(tokens), Kind: kind, Collision: collision})
+		return fake
+	default:
+		text := fmt.Sprint(v)
+		if n, ok := v.(json.Number); ok {
+			text = n.String()
+		}
+		kind := resolveKind(rule.kind, text)
+		fake, collision := p.fake(kind, text, rule)
+		*records = append(*records, Pseudonymization{Path: transformx.Pointer(tokens), Kind: kind, Collision: collision})
+		if _, err := strconv.ParseFloat(fake, 64); err == nil {
+			return json.Number(fake)
+		}
+		return fake
+	}
+}
+
+// phoneLike 带国家码或分隔符的电话号码，如 +1 (415) 555-0100
+var phoneLike = regexp.MustCompile(`^\+?\d*[ \-(]+[\d ()\-]*\d$`)
+
+// resolveKind auto 类型按取值识别
+func resolveKind(kind FakeKind, value string) FakeKind {
+	if kind != FakeAuto {
+		return kind
+	}
+	whole := func(d Detector) bool {
+		spans := d.find(value)
+		return len(spans) == 1 && spans[0][0] == 0 && spans[0][1] == len(value)
+	}
+	switch {
+	case whole(DetectorEmail):
+		return FakeEmail
+	case whole(DetectorIDCard):
+		return FakeIDCard
+	case whole(DetectorBankCard):
+		return FakeBankCard
+	case whole(DetectorPhone), phoneLike.MatchString(value) && countDigits(value) >= 7:
+		return FakePhone
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return FakeNumber
+	}
+	return FakeID
+}
+
+// fake 生成假数据。重试换种子会使结果取决于取值出现的先后，因此不同原值得到相同的假数据时不重试，只报告冲突
+func (p *Pseudonymizer) fake(kind FakeKind, value string, rule compiledPseudonymRule) (string, bool) {
+	seed := string(kind) + "\x00" + value
+	cacheKey := seed
+	if rule.min != nil || rule.max != nil {
+		cacheKey += "\x00" + bound(rule.min) + "\x00" + bound(rule.max)
+	}
+	fake, ok := p.forward[cacheKey]
+	if !ok {
+		fake = generateFake(kind, value, rule, p.rng(seed))
+		p.forward[cacheKey] = fake
+	}
+	fakeKey := string(kind) + "\x00" + fake
+	if owner, ok := p.reverse[fakeKey]; !ok {
+		p.reverse[fakeKey] = value
+	} else if owner != value {
+		p.collisions[fakeKey] = true
+	}
+	return fake, p.collisions[fakeKey]
+}
+
+func bound(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'g', -1, 64)
+}
+
+// rng 由盐与原值派生确定性的随机源
+func (p *Pseudonymizer) rng(seed string) *mathrand.Rand {
+	mac := hmac.New(sha256.New, p.salt)
+	mac.Write([]byte(seed))
+	sum := mac.Sum(nil)
+	return mathrand.New(mathrand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+}
+
+func generateFake(kind FakeKind, value string, rule compiledPseudonymRule, r *mathrand.Rand) string {
+	switch kind {
+	case FakeName:
+		return fakeName(value, r)
+	case FakeEmail:
+		return fakeEmail(r)
+	case FakePhone:
+		return fakePhone(value, r)
+	case FakeIDCard:
+		return fakeIDCard(r)
+	case FakeBankCard:
+		return fakeBankCard(value, r)
+	case FakeAddress:
+		return fakeAddress(value, r)
+	case FakeNumber:
+		return fakeNumber(value, rule.min, rule.max, r)
+	default:
+		return fakeID(value, r)
+	}
+}
+
+var (
+	chineseSurnames   = []rune("王李张刘陈杨黄赵吴周徐孙马朱胡郭何高林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董袁潘于蒋蔡余杜叶程苏魏吕丁任沈姚卢")
+	chineseGivenChars = []rune("伟芳娜秀敏静丽强磊军洋勇艳杰娟涛明超秀霞平刚桂英华玉萍红娥玲芬燕彬鹏辉宇浩然子轩梓涵欣怡俊晨博文嘉悦思远")
+	firstNames        = []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen", "Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Emily"}
+	lastNames         = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker"}
+	fakeDomains       = []string{"example.com", "example.net", "example.org", "mail.example.com", "test.example.cn"}
+	idCardRegions     = []string{"110101", "110105", "120101", "310101", "310104", "320102", "330106", "340102", "350203", "370102", "410105", "420106", "430104", "440106", "440305", "500103", "510107", "610113"}
+	chineseCities     = []string{"北京市朝阳区", "上海市浦东新区", "广州市天河区", "深圳市南山区", "杭州市西湖区", "成都市武侯区", "武汉市洪山区", "南京市鼓楼区", "西安市雁塔区", "重庆市渝中区"}
+	chineseStreets    = []string{"建国路", "人民路", "中山路", "解放路", "长江路", "和平街", "文化路", "科技园路", "滨江大道", "新华街"}
+	englishStreets    = []string{"Maple Street", "Oak Avenue", "Pine Road", "Cedar Lane", "Elm Street", "Washington Avenue", "Lake Drive", "Hill Road", "Park Avenue", "Sunset Boulevard"}
+	englishCities     = []string{"Springfield, IL 62701", "Riverside, CA 92501", "Fairview, TX 75069", "Franklin, TN 37064", "Madison, WI 53703", "Georgetown, KY 40324"}
+)
+
+func pick[T any](r *mathrand.Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
+
+func containsHan(s string) bool {
+	for _, c := range s {
+		if unicode.Is(unicode.Han, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeName 中文姓名保持字数，英文姓名为 名 姓
+func fakeName(value string, r *mathrand.Rand) string {
+	if containsHan(value) {
+		n := len([]rune(strings.TrimSpace(value)))
+		n = min(max(n, 2), 4)
+		name := []rune{pick(r, chineseSurnames)}
+		for len(name) < n {
+			name = append(name, pick(r, chineseGivenChars))
+		}
+		return string(name)
+	}
+	return pick(r, firstNames) + " " + pick(r, lastNames)
+}
+
+func fakeEmail(r *mathrand.Rand) string {
+	return fmt.Sprintf("%s.%s%04d@%s", strings.ToLower(pick(r, firstNames)), strings.ToLower(pick(r, lastNames)), r.IntN(10000), pick(r, fakeDomains))
+}
+
+// fakePhone 替换数字，保留分隔符、国家码与手机号的号段首位
+func fakePhone(value string, r *mathrand.Rand) string {
+	if spans := DetectorPhone.find(value); len(spans) == 1 && spans[0][0] == 0 && spans[0][1] == len(value) {
+		head := value[:len(value)-11]
+		return head + "1" + string(byte('3'+r.IntN(7))) + randomDigits(r, 9)
+	}
+	b := []byte(value)
+	i := 0
+	if country := strings.IndexAny(value, " -("); strings.HasPrefix(value, "+") && country > 1 && country <= 4 {
+		// 国家码保持不变
+		i = country
+	}
+	keepFirst := true
+	for ; i < len(b); i++ {
+		if !isDigit(b[i]) {
+			continue
+		}
+		if keepFirst {
+			keepFirst = false
+			continue
+		}
+		b[i] = byte('0' + r.IntN(10))
+	}
+	return string(b)
+}
+
+// fakeIDCard 生成地区码、出生日期、顺序码随机且校验码正确的身份证号
+func fakeIDCard(r *mathrand.Rand) string {
+	year := 1960 + r.IntN(46)
+	month := 1 + r.IntN(12)
+	day := 1 + r.IntN(28)
+	id := fmt.Sprintf("%s%04d%02d%02d%03d", pick(r, idCardRegions), year, month, day, r.IntN(1000))
+	sum := 0
+	for i, w := range idCardWeights {
+		sum += int(id[i]-'0') * w
+	}
+	return id + string("10X98765432"[sum%11])
+}
+
+// fakeBankCard 保留前 6 位与分隔符，其余随机，末位重新计算 Luhn 校验位
+func fakeBankCard(value string, r *mathrand.Rand) string {
+	b := []byte(value)
+	var positions []int
+	for i := range b {
+		if isDigit(b[i]) {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) < 8 {
+		return fakeID(value, r)
+	}
+	for _, i := range positions[6 : len(positions)-1] {
+		b[i] = byte('0' + r.IntN(10))
+	}
+	sum := 0
+	for n, i := range positions[:len(positions)-1] {
+		d := int(b[i] - '0')
+		if (len(positions)-1-n)%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	b[positions[len(positions)-1]] = byte('0' + (10-sum%10)%10)
+	return string(b)
+}
+
+// fakeAddress 门牌号之外附加房间号，减少不同地址得到相同假数据
+func fakeAddress(value string, r *mathrand.Rand) string {
+	if containsHan(value) {
+		return fmt.Sprintf("%s%s%d号%d室", pick(r, chineseCities), pick(r, chineseStreets), 1+r.IntN(999), 101+r.IntN(30)*100+r.IntN(8))
+	}
+	return fmt.Sprintf("%d %s Apt %d, %s", 1+r.IntN(9999), pick(r, englishStreets), 1+r.IntN(999), pick(r, englishCities))
+}
+
+// fakeNumber 指定区间时在区间内取值，否则保持符号、整数位数与小数位数
+func fakeNumber(value string, lo, hi *float64, r *mathrand.Rand) string {
+	decimals := 0
+	if dot := strings.IndexByte(value, '.'); dot >= 0 && !strings.ContainsAny(value, "eE") {
+		decimals = len(value) - dot - 1
+	}
+	if lo != nil || hi != nil {
+		low, high := -math.MaxInt32*1.0, math.MaxInt32*1.0
+		if lo != nil {
+			low = *lo
+		}
+		if hi != nil {
+			high = *hi
+		}
+		n := low + r.Float64()*(high-low)
+		if decimals == 0 {
+			n = math.Min(math.Max(math.Round(n), math.Ceil(low)), math.Floor(high))
+		}
+		return strconv.FormatFloat(n, 'f', decimals, 64)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil || strings.ContainsAny(value, "eE") {
+		return fakeID(value, r)
+	}
+	b := []byte(value)
+	leading := true
+	for i := range b {
+		if !isDigit(b[i]) {
+			continue
+		}
+		switch {
+		case leading && b[i] != '0':
+			b[i] = byte('1' + r.IntN(9))
+			leading = false
+		case leading:
+			// 前导 0（如 0.5）保持不变
+		default:
+			b[i] = byte('0' + r.IntN(10))
+		}
+	}
+	return string(b)
+}
+
+// fakeID 按字符类别替换：数字换数字，字母换同大小写字母（纯十六进制串保持十六进制），汉字换常用汉字，其他字符保留
+func fakeID(value string, r *mathrand.Rand) string {
+	isHex := strings.Trim(value, "0123456789abcdefABCDEF-") == ""
+	runes := []rune(value)
+	for i, c := range runes {
+		switch {
+		case c >= '0' && c <= '9':
+			runes[i] = rune('0' + r.IntN(10))
+		case isHex && c >= 'a' && c <= 'f':
+			runes[i] = rune('a' + r.IntN(6))
+		case isHex && c >= 'A' && c <= 'F':
+			runes[i] = rune('A' + r.IntN(6))
+		case c >= 'a' && c <= 'z':
+			runes[i] = rune('a' + r.IntN(26))
+		case c >= 'A' && c <= 'Z':
+			runes[i] = rune('A' + r.IntN(26))
+		case unicode.Is(unicode.Han, c):
+			runes[i] = pick(r, chineseGivenChars)
+		}
+	}
+	return string(runes)
+}
+
+func randomDigits(r *mathrand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + r.IntN(10))
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package redactx
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func pseudonymize(t *testing.T, p *Pseudonymizer, content string) (map[string]any, []Pseudonymization) {
+	t.Helper()
+	var value map[string]any
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	result, records := p.Pseudonymize(value)
+	return result.(map[string]any), records
+}
+
+// 相同的盐下假数据只由原值决定，与文档中取值出现的先后无关
+func TestPseudonymOrderIndependent(t *testing.T) {
+	rules := []PseudonymRule{{Paths: []string{"**.email"}}, {Paths: []string{"**.name"}, Kind: FakeName}}
+	first, err := NewPseudonymizer(rules, "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewPseudonymizer(rules, "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := pseudonymize(t, first, `{"users": [{"name": "张三", "email": "a@corp.com"}, {"name": "李四", "email": "b@corp.com"}]}`)
+	b, _ := pseudonymize(t, second, `{"users": [{"name": "李四", "email": "b@corp.com"}, {"name": "张三", "email": "a@corp.com"}]}`)
+	usersA, usersB := a["users"].([]any), b["users"].([]any)
+	for i := range usersA {
+		x, y := usersA[i].(map[string]any), usersB[len(usersB)-1-i].(map[string]any)
+		if x["name"] != y["name"] || x["email"] != y["email"] {
+			t.Errorf("user %d: %v != %v", i, x, y)
+		}
+		if x["email"] == "a@corp.com" || x["email"] == "b@corp.com" {
+			t.Errorf("email not replaced: %v", x["email"])
+		}
+	}
+	other, err := NewPseudonymizer(rules, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := pseudonymize(t, other, `{"users": [{"email": "a@corp.com"}]}`)
+	if c["users"].([]any)[0].(map[string]any)["email"] == usersA[0].(map[string]any)["email"] {
+		t.Error("different salts should give different fakes")
+	}
+}
+
+// 不同原值得到相同的假数据时不重试，在记录中标记冲突
+func TestPseudonymCollision(t *testing.T) {
+	one := 1.0
+	p, err := NewPseudonymizer([]PseudonymRule{{Paths: []string{"$.a", "$.b", "$.c"}, Kind: FakeNumber, Min: &one, Max: &one}}, "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, records := pseudonymize(t, p, `{"a": 5, "b": 5, "c": 7}`)
+	if value["a"] != json.Number("1") || value["c"] != json.Number("1") {
+		t.Errorf("unexpected value: %v", value)
+	}
+	collisions := map[string]bool{}
+	for _, record := range records {
+		collisions[record.Path] = record.Collision
+	}
+	if collisions["/a"] || collisions["/b"] || !collisions["/c"] {
+		t.Errorf("unexpected collisions: %v", records)
+	}
+}
+
+// 同一类型与原值在不同区间规则下分别生成
+func TestPseudonymRanges(t *testing.T) {
+	low, high := 10.0, 20.0
+	big, bigger := 1000.0, 2000.0
+	p, err := NewPseudonymizer([]PseudonymRule{
+		{Paths: []string{"$.a"}, Kind: FakeNumber, Min: &low, Max: &high},
+		{Paths: []string{"$.b"}, Kind: FakeNumber, Min: &big, Max: &bigger},
+	}, "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := pseudonymize(t, p, `{"a": 15, "b": 15}`)
+	a, _ := value["a"].(json.Number).Float64()
+	b, _ := value["b"].(json.Number).Float64()
+	if a < low || a > high || b < big || b > bigger {
+		t.Errorf("values out of range: %v", value)
+	}
+}
@@ -0,0 +1,310 @@
+package redactx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+)
+
+// Rule 脱敏规则。Paths 与 Keys 命中时遮盖整个值，Detectors 在所有字符串中查找并遮盖命中的片段
+type Rule struct {
+	Name      string   `json:"name,omitempty"`      // 规则名称，记录在脱敏结果中
+	Paths     []string `json:"paths,omitempty"`     // 路径模式，如 $.user.phone、**.token
+	Keys      []string `json:"keys,omitempty"`      // 键名通配模式，不区分大小写，如 password、*token*
+	Detectors []string `json:"detectors,omitempty"` // 取值检测器: phone|idcard|email|bankcard|jwt
+	Style     Style    `json:"style,omitempty"`     // 遮盖方式: full|partial|hash，默认 partial
+}
+
+// Redaction 一处脱敏记录
+type Redaction struct {
+	Path     string `json:"path"`               // JSON Pointer
+	Rule     string `json:"rule"`               // 命中的规则
+	Detector string `json:"detector,omitempty"` // 命中的检测器，按路径或键名命中时为空
+	Style    Style  `json:"style"`              // 遮盖方式
+}
+
+// DefaultKeys 内置的敏感键名
+var DefaultKeys = []string{
+	"*password*", "passwd", "pwd", "*secret*", "*token*", "authorization", "cookie", "set-cookie",
+	"api_key", "apikey", "api-key", "access_key*", "accesskey*", "private_key", "privatekey", "*credential*", "*salt*",
+}
+
+// DefaultRules 内置规则：敏感键名整体遮盖，不保留长度与首尾字符；全部检测器部分遮盖
+func DefaultRules() []Rule {
+	detectors := make([]string, 0, len(AllDetectors))
+	for _, d := range AllDetectors {
+		detectors = append(detectors, string(d))
+	}
+	return []Rule{
+		{Name: "sensitive-keys", Keys: DefaultKeys, Style: StyleFull},
+		{Name: "detectors", Detectors: detectors, Style: StylePartial},
+	}
+}
+
+type compiledRule struct {
+	name      string
+	paths     []*transformx.PathPattern
+	keys      []string
+	detectors []Detector
+	style     Style
+}
+
+// Redactor 脱敏引擎，创建后可并发使用
+type Redactor struct {
+	rules []compiledRule
+}
+
+// New 编译脱敏规则，rules 为空时使用 DefaultRules
+func New(rules []Rule) (*Redactor, error) {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	r := &Redactor{}
+	for i, rule := range rules {
+		c := compiledRule{name: rule.Name}
+		if c.name == "" {
+			c.name = fmt.Sprintf("rule-%d", i+1)
+		}
+		var err error
+		if c.style, err = ParseStyle(string(rule.Style)); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", c.name, err)
+		}
+		if c.paths, err = transformx.ParsePathPatterns(rule.Paths); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", c.name, err)
+		}
+		for _, key := range rule.Keys {
+			key = strings.ToLower(strings.TrimSpace(key))
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid key pattern %q", c.name, key)
+			}
+			c.keys = append(c.keys, key)
+		}
+		for _, name := range rule.Detectors {
+			d, err := ParseDetector(name)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", c.name, err)
+			}
+			c.detectors = append(c.detectors, d)
+		}
+		if len(c.paths) == 0 && len(c.keys) == 0 && len(c.detectors) == 0 {
+			return nil, fmt.Errorf("rule %s: at least one of paths, keys or detectors is required", c.name)
+		}
+		r.rules = append(r.rules, c)
+	}
+	return r, nil
+}
+
+func (c compiledRule) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range c.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNode 按路径或键名命中整个值的规则
+func (r *Redactor) matchNode(tokens []transformx.PathToken) (compiledRule, bool) {
+	for _, rule := range r.rules {
+		for _, p := range rule.paths {
+			if p.Match(tokens) {
+				return rule, true
+			}
+		}
+		if len(tokens) > 0 && !tokens[len(tokens)-1].IsIndex && rule.matchKey(tokens[len(tokens)-1].Key) {
+			return rule, true
+		}
+	}
+	return compiledRule{}, false
+}
+
+// Redact 脱敏数据，对象与数组原地修改，返回脱敏后的根节点与脱敏记录
+func (r *Redactor) Redact(value any) (any, []Redaction) {
+	var redactions []Redaction
+	result := r.walk(value, nil, &redactions)
+	return result, redactions
+}
+
+func (r *Redactor) walk(value any, tokens []transformx.PathToken, redactions *[]Redaction) any {
+	if rule, ok := r.matchNode(tokens); ok {
+		*redactions = append(*redactions, Redaction{Path: transformx.Pointer(tokens), Rule: rule.name, Style: rule.style})
+		return maskValue(rule.style, value)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			v[key] = r.walk(v[key], append(tokens, transformx.PathToken{Key: key}), redactions)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.walk(item, append(tokens, transformx.PathToken{Index: i, IsIndex: true}), redactions)
+		}
+	case string:
+		return r.redactString(v, transformx.Pointer(tokens), redactions)
+	case json.Number:
+		// 整个数字命中检测器时（如以数字存储的手机号）替换为遮盖后的字符串
+		if masked := r.redactString(v.String(), transformx.Pointer(tokens), redactions); masked != v.String() {
+			return masked
+		}
+	}
+	return value
+}
+
+// redactString 遮盖字符串中键名命中规则的键值对（如嵌入的 JSON、查询串）以及命中检测器的片段
+func (r *Redactor) redactString(s, pointer string, redactions *[]Redaction) string {
+	s = r.redactPairs(s, func(rule compiledRule) {
+		if redactions != nil {
+			*redactions = append(*redactions, Redaction{Path: pointer, Rule: rule.name, Style: rule.style})
+		}
+	})
+	for _, rule := range r.rules {
+		for _, d := range rule.detectors {
+			spans := d.find(s)
+			if len(spans) == 0 {
+				continue
+			}
+			if redactions != nil {
+				*redactions = append(*redactions, Redaction{Path: pointer, Rule: rule.name, Detector: string(d), Style: rule.style})
+			}
+			s = replaceSpans(s, spans, func(match string) string {
+				return maskString(rule.style, match, detectorSpecs[d].partial)
+			})
+		}
+	}
+	return s
+}
+
+func replaceSpans(s string, spans [][]int, fn func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(s[last:span[0]])
+		b.WriteString(fn(s[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// keyPattern 文本中键值对的键与分隔符，如 "key": 、key= 以及 JSON 字符串内转义的 \"key\":
+var keyPattern = regexp.MustCompile(`(\\?"?)([A-Za-z0-9_.\-]+)(\\?"?[ \t]*[:=][ \t]*)`)
+
+// redactPairs 遮盖文本中键名命中规则的键值对，值可以是带引号（含转义引号）的字符串或到分隔符为止的片段
+func (r *Redactor) redactPairs(s string, record func(rule compiledRule)) string {
+	if !r.hasKeys() {
+		return s
+	}
+	var b strings.Builder
+	last, offset := 0, 0
+	for offset < len(s) {
+		loc := keyPattern.FindStringSubmatchIndex(s[offset:])
+		if loc == nil {
+			break
+		}
+		key := s[offset+loc[4] : offset+loc[5]]
+		valueStart := offset + loc[1]
+		offset = valueStart
+		rule, ok := r.matchKeyRule(key)
+		if !ok {
+			continue
+		}
+		quote := ""
+		switch {
+		case strings.HasPrefix(s[valueStart:], `\"`):
+			quote = `\"`
+		case strings.HasPrefix(s[valueStart:], `"`):
+			quote = `"`
+		case strings.HasPrefix(s[valueStart:], "{"), strings.HasPrefix(s[valueStart:], "["):
+			continue
+		}
+		begin := valueStart + len(quote)
+		end := valueEnd(s, begin, quote)
+		if end <= begin {
+			continue
+		}
+		record(rule)
+		b.WriteString(s[last:begin])
+		b.WriteString(maskString(rule.style, s[begin:end], genericPartial))
+		last, offset = end, end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// valueEnd 值的结束位置：带引号时到匹配的引号为止，否则到空白或分隔符为止
+func valueEnd(s string, begin int, quote string) int {
+	switch quote {
+	case `"`:
+		for i := begin; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				return i
+			}
+		}
+		return len(s)
+	case `\"`:
+		if i := strings.Index(s[begin:], `\"`); i >= 0 {
+			return begin + i
+		}
+		return len(s)
+	default:
+		i := strings.IndexAny(s[begin:], " \t\r\n&,;\"'{}[]")
+		if i < 0 {
+			return len(s)
+		}
+		return begin + i
+	}
+}
+
+func (r *Redactor) hasKeys() bool {
+	for _, rule := range r.rules {
+		if len(rule.keys) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) matchKeyRule(key string) (compiledRule, bool) {
+	for _, rule := range r.rules {
+		if rule.matchKey(key) {
+			return rule, true
+		}
+	}
+	return compiledRule{}, false
+}
+
+// RedactText 脱敏无法解析的文本，如被截断的 JSON 或表单，按键名遮盖键值对后再执行检测器
+func (r *Redactor) RedactText(text string) string {
+	return r.redactString(text, "", nil)
+}
+
+// RedactBytes 脱敏日志内容，合法 JSON 按结构脱敏后紧凑输出，否则按文本脱敏
+func (r *Redactor) RedactBytes(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err == nil {
+			value, _ = r.Redact(value)
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(value); err == nil {
+				return bytes.TrimRight(buf.Bytes(), "\n")
+			}
+		}
+	}
+	return []byte(r.RedactText(string(data)))
+}
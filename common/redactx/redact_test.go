@@ -0,0 +1,139 @@
+package redactx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectors(t *testing.T) {
+	cases := []struct {
+		name     string
+		detector Detector
+		input    string
+		want     string // 未命中时与输入相同
+	}{
+		{"phone", DetectorPhone, "call 13812345678 now", "call 138****5678 now"},
+		{"phone with prefix", DetectorPhone, "+86 13812345678", "+86 138****5678"},
+		{"phone inside longer number", DetectorPhone, "1381234567890", "1381234567890"},
+		{"idcard", DetectorIDCard, "id 11010519491231002X", "id 110***********002X"},
+		{"idcard bad checksum", DetectorIDCard, "id 110105194912310021", "id 110105194912310021"},
+		{"email", DetectorEmail, "mail alice@example.com", "mail a***@example.com"},
+		{"bankcard", DetectorBankCard, "card 4111 1111 1111 1111", "card 4111 11** **** 1111"},
+		{"bankcard bad luhn", DetectorBankCard, "card 4111111111111112", "card 4111111111111112"},
+		{"jwt", DetectorJWT, "Bearer eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln", "Bearer eyJhbGciOiJIUzI1NiJ9.******"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := New([]Rule{{Detectors: []string{string(c.detector)}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.RedactText(c.input); got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid func(string) bool
+		input string
+		want  bool
+	}{
+		{"idcard X", validIDCard, "11010519491231002X", true},
+		{"idcard lower x", validIDCard, "11010519491231002x", true},
+		{"idcard digit", validIDCard, "110105194912310021", false},
+		{"luhn visa", validBankCard, "4111111111111111", true},
+		{"luhn separators", validBankCard, "4111-1111-1111-1111", true},
+		{"luhn wrong", validBankCard, "4111111111111112", false},
+		{"luhn too short", validBankCard, "4111111111", false},
+	}
+	for _, c := range cases {
+		if got := c.valid(c.input); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+// 默认规则整体遮盖敏感键名的值，不泄露长度与首尾字符
+func TestDefaultRulesMaskKeysFully(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, redactions := r.Redact(map[string]any{"password": "hunter2", "salt": "team-secret-salt", "user": map[string]any{"api_key": "k-123456789"}})
+	m := value.(map[string]any)
+	if m["password"] != fullMask || m["salt"] != fullMask || m["user"].(map[string]any)["api_key"] != fullMask {
+		t.Errorf("unexpected result: %v", m)
+	}
+	if len(redactions) != 3 || redactions[0].Style != StyleFull {
+		t.Errorf("unexpected redactions: %+v", redactions)
+	}
+}
+
+// 字符串中的键值对按键名遮盖：JSON 文本、转义的嵌入 JSON、查询串与 key=value
+func TestRedactPairs(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"json", `{"password": "hunter2", "user": "bob"}`, `{"password": "******", "user": "bob"}`},
+		{"escaped json", `{\"token\":\"abc123\",\"n\":1}`, `{\"token\":\"******\",\"n\":1}`},
+		{"query", "user=bob&password=hunter2&x=1", "user=bob&password=******&x=1"},
+		{"dsn", "host=db pwd=s3cret;port=5432", "host=db pwd=******;port=5432"},
+		{"nested value skipped", `{"secret": {"a": 1}}`, `{"secret": {"a": 1}}`},
+		{"escaped quote in value", `{"password": "a\"b", "n": 1}`, `{"password": "******", "n": 1}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := r.RedactText(c.input); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+// 合法 JSON 按结构脱敏，截断的 JSON 退回按文本脱敏
+func TestRedactBytes(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"json", `{"phone": "13812345678", "password": "hunter2"}`, `{"password":"******","phone":"138****5678"}`},
+		{"embedded json", `{"payload": "{\"token\":\"abc\"}"}`, `{"payload":"{\"token\":\"******\"}"}`},
+		{"truncated", `{"password": "hunter2", "phone": "13812345678", "note": "unfini`, `{"password": "******", "phone": "138****5678", "note": "unfini`},
+		{"form", "password=hunter2&email=alice@example.com", "password=******&email=a***@example.com"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := string(r.RedactBytes([]byte(c.input))); got != c.want {
+				t.Errorf("got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Name: "empty"}},
+		{{Keys: []string{"[bad"}}},
+		{{Detectors: []string{"ssn"}}},
+		{{Keys: []string{"a"}, Style: "blur"}},
+	} {
+		if _, err := New(rules); err == nil || !strings.Contains(err.Error(), "rule ") {
+			t.Errorf("%+v: expected rule error, got %v", rules, err)
+		}
+	}
+}
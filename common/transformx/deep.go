@@ -0,0 +1,423 @@
+package transformx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+// EncodingType 嵌入值的编码方式
+type EncodingType string
+
+const (
+	EncodingJSON    EncodingType = "json"    // 字符串化的 JSON
+	EncodingBase64  EncodingType = "base64"  // base64 编码的文本
+	EncodingQuery   EncodingType = "query"   // URL 查询串，如 a=1&b=2
+	EncodingPercent EncodingType = "percent" // 百分号编码的文本，如 %7B%22a%22%3A1%7D
+)
+
+const defaultMaxDepth = 8
+
+// Step 一次解码，Variant 记录还原时需要的编码细节
+type Step struct {
+	Type    EncodingType `json:"type"`
+	Variant string       `json:"variant,omitempty"` // base64: std|url|raw-std|raw-url；percent: component|query
+}
+
+// Decoding 某一路径上依次应用的解码，Steps 从外到内排列
+type Decoding struct {
+	Path  string `json:"path"` // JSON Pointer
+	Steps []Step `json:"steps"`
+}
+
+// DeepDecodeOptions 深度解码选项
+type DeepDecodeOptions struct {
+	MaxDepth int            // 单个值最多连续解码的层数，默认 8
+	Types    []EncodingType // 启用的编码类型，默认全部
+}
+
+func (o *DeepDecodeOptions) maxDepth() int {
+	if o == nil || o.MaxDepth <= 0 {
+		return defaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+func (o *DeepDecodeOptions) enabled(t EncodingType) bool {
+	if o == nil || len(o.Types) == 0 {
+		return true
+	}
+	for _, enabled := range o.Types {
+		if enabled == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEncodingType 校验编码类型名
+func ParseEncodingType(name string) (EncodingType, error) {
+	switch t := EncodingType(strings.ToLower(strings.TrimSpace(name))); t {
+	case EncodingJSON, EncodingBase64, EncodingQuery, EncodingPercent:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unsupported encoding type: %q, expected json, base64, query or percent", name)
+	}
+}
+
+// DeepDecode 遍历数据，将字符串中嵌入的 JSON、base64、查询串等递归展开为结构，
+// 返回展开后的数据与每个路径上应用的解码（父路径在前）。展开得到的对象为 *formatx.Record，保持原文中键的顺序
+func DeepDecode(value any, opts *DeepDecodeOptions) (any, []Decoding) {
+	d := &deepDecoder{opts: opts}
+	return d.walk(value, ""), d.decodings
+}
+
+type deepDecoder struct {
+	opts      *DeepDecodeOptions
+	decodings []Decoding
+}
+
+func (d *deepDecoder) walk(value any, pointer string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			v[key] = d.walk(v[key], childPointer(pointer, key))
+		}
+		return v
+	case *formatx.Record:
+		for _, key := range v.Keys {
+			v.Values[key] = d.walk(v.Values[key], childPointer(pointer, key))
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = d.walk(item, indexPointer(pointer, i))
+		}
+		return v
+	case string:
+		decoded, steps, ok := d.expand(v, 0)
+		if !ok {
+			return v
+		}
+		d.decodings = append(d.decodings, Decoding{Path: pointer, Steps: steps})
+		return d.walk(decoded, pointer)
+	default:
+		return v
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expand 尝试逐层解码字符串，只有最终得到对象或数组时才视为成功，避免把普通文本误判为 base64 等编码
+func (d *deepDecoder) expand(s string, depth int) (any, []Step, bool) {
+	if depth >= d.opts.maxDepth() {
+		return nil, nil, false
+	}
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return nil, nil, false
+	}
+	if d.opts.enabled(EncodingJSON) {
+		if value, ok := decodeJSONText(text); ok {
+			step := Step{Type: EncodingJSON}
+			if inner, isString := value.(string); isString {
+				// 多次字符串化的 JSON
+				if decoded, steps, ok := d.expand(inner, depth+1); ok {
+					return decoded, append([]Step{step}, steps...), true
+				}
+				return nil, nil, false
+			}
+			return value, []Step{step}, true
+		}
+	}
+	if d.opts.enabled(EncodingQuery) {
+		if value, ok := d.decodeQuery(text, depth); ok {
+			return value, []Step{{Type: EncodingQuery}}, true
+		}
+	}
+	if d.opts.enabled(EncodingBase64) {
+		if decoded, variant, ok := decodeBase64Text(text); ok {
+			if value, steps, ok := d.expand(decoded, depth+1); ok {
+				return value, append([]Step{{Type: EncodingBase64, Variant: variant}}, steps...), true
+			}
+		}
+	}
+	if d.opts.enabled(EncodingPercent) && percentPattern.MatchString(text) {
+		variant := "component"
+		unescape := url.PathUnescape
+		if strings.Contains(text, "+") {
+			variant, unescape = "query", url.QueryUnescape
+		}
+		if decoded, err := unescape(text); err == nil && decoded != text {
+			if value, steps, ok := d.expand(decoded, depth+1); ok {
+				return value, append([]Step{{Type: EncodingPercent, Variant: variant}}, steps...), true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// decodeJSONText 仅解码对象、数组与带引号的字符串，数字、布尔值等保持为字符串
+func decodeJSONText(text string) (any, bool) {
+	first, last := text[0], text[len(text)-1]
+	if !(first == '{' && last == '}') && !(first == '[' && last == ']') && !(first == '"' && last == '"' && len(text) > 1) {
+		return nil, false
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, false
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, false
+	}
+	return value, true
+}
+
+// decodeJSONValue 逐个读取 token，对象解码为 *formatx.Record 以保持键的顺序，重复的键保留首次出现的位置
+func decodeJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		record := formatx.NewRecord()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			record.Set(key.(string), value)
+		}
+		_, err = decoder.Token()
+		return record, err
+	case json.Delim('['):
+		items := make([]any, 0)
+		for decoder.More() {
+			item, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = decoder.Token()
+		return items, err
+	default:
+		return token, nil
+	}
+}
+
+var (
+	base64Pattern  = regexp.MustCompile(`^(?:[A-Za-z0-9+/]+|[A-Za-z0-9_-]+)={0,2}$`)
+	percentPattern = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+	queryPattern   = regexp.MustCompile(`^[A-Za-z0-9_.~\-\[\]%]+=[^&\s]*(?:&[A-Za-z0-9_.~\-\[\]%]+=[^&\s]*)*$`)
+)
+
+// decodeBase64Text 解码 base64 文本，结果须为合法的 UTF-8，返回使用的字母表与填充方式
+func decodeBase64Text(text string) (string, string, bool) {
+	if len(text) < 4 || !base64Pattern.MatchString(text) {
+		return "", "", false
+	}
+	padded := strings.HasSuffix(text, "=")
+	urlSafe := strings.ContainsAny(text, "-_")
+	variant := "std"
+	switch {
+	case urlSafe && (padded || len(text)%4 == 0):
+		variant = "url"
+	case urlSafe:
+		variant = "raw-url"
+	case !padded && len(text)%4 != 0:
+		variant = "raw-std"
+	}
+	data, err := base64Encoding(variant).DecodeString(text)
+	if err != nil || !utf8.Valid(data) {
+		return "", "", false
+	}
+	return string(data), variant, true
+}
+
+func base64Encoding(variant string) *base64.Encoding {
+	switch variant {
+	case "url":
+		return base64.URLEncoding
+	case "raw-url":
+		return base64.RawURLEncoding
+	case "raw-std":
+		return base64.RawStdEncoding
+	default:
+		return base64.StdEncoding
+	}
+}
+
+// decodeQuery 解析 URL 查询串：至少两个键值对，或唯一的值本身可以继续展开；键按原文顺序排列，重复的键输出为数组
+func (d *deepDecoder) decodeQuery(text string, depth int) (*formatx.Record, bool) {
+	if !queryPattern.MatchString(text) {
+		return nil, false
+	}
+	values, err := url.ParseQuery(text)
+	if err != nil {
+		return nil, false
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(text, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		key, _ = url.QueryUnescape(key)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if !strings.Contains(text, "&") {
+		value := values.Get(keys[0])
+		if _, _, ok := d.expand(value, depth+1); !ok {
+			return nil, false
+		}
+	}
+	result := formatx.NewRecord()
+	for _, key := range keys {
+		list := values[key]
+		if len(list) == 1 {
+			result.Set(key, list[0])
+			continue
+		}
+		items := make([]any, len(list))
+		for i, item := range list {
+			items[i] = item
+		}
+		result.Set(key, items)
+	}
+	return result, true
+}
+
+// ReEncode 按 DeepDecode 记录的解码逆序还原原始表示，子路径先于父路径处理
+func ReEncode(value any, decodings []Decoding) (any, error) {
+	var err error
+	for i := len(decodings) - 1; i >= 0; i-- {
+		decoding := decodings[i]
+		value, err = replaceAt(value, decoding.Path, func(v any) (any, error) {
+			for j := len(decoding.Steps) - 1; j >= 0; j-- {
+				if v, err = encodeStep(v, decoding.Steps[j]); err != nil {
+					return nil, fmt.Errorf("path %s: %w", decoding.Path, err)
+				}
+			}
+			return v, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+func encodeStep(value any, step Step) (any, error) {
+	switch step.Type {
+	case EncodingJSON:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+		return strings.TrimRight(buf.String(), "\n"), nil
+	case EncodingBase64:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("base64 step expects a string, got %T", value)
+		}
+		return base64Encoding(step.Variant).EncodeToString([]byte(text)), nil
+	case EncodingPercent:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("percent step expects a string, got %T", value)
+		}
+		if step.Variant == "query" {
+			return url.QueryEscape(text), nil
+		}
+		return escapeComponent(text), nil
+	case EncodingQuery:
+		switch v := value.(type) {
+		case *formatx.Record:
+			return encodeQuery(v)
+		case map[string]any:
+			record := formatx.NewRecord()
+			for _, key := range sortedKeys(v) {
+				record.Set(key, v[key])
+			}
+			return encodeQuery(record)
+		default:
+			return nil, fmt.Errorf("query step expects an object, got %T", value)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding type: %q", step.Type)
+	}
+}
+
+// encodeQuery 按记录中键的顺序输出，编辑时新增的键保持其在内容中的位置
+func encodeQuery(record *formatx.Record) (string, error) {
+	var pairs []string
+	for _, key := range record.Keys {
+		values, ok := record.Values[key].([]any)
+		if !ok {
+			values = []any{record.Values[key]}
+		}
+		for _, item := range values {
+			text, err := queryValue(item)
+			if err != nil {
+				return "", fmt.Errorf("query key %q: %w", key, err)
+			}
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(text))
+		}
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+func queryValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]any, *formatx.Record, []any:
+		return "", fmt.Errorf("nested %T cannot be encoded as a query value", v)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// escapeComponent 与 JavaScript encodeURIComponent 一致的百分号编码
+func escapeComponent(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || strings.IndexByte("-_.!~*'()", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
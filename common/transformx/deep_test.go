@@ -0,0 +1,88 @@
+package transformx
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+// deepDecodeJSON 解析 JSON 后深度解码
+func deepDecodeJSON(t *testing.T, content string) (string, []Decoding) {
+	t.Helper()
+	doc, err := formatx.Parse(formatx.FormatJSON, []byte(content), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	value, decodings := DeepDecode(doc.Ordered(nil), nil)
+	return compact(t, doc, value), decodings
+}
+
+func reEncodeJSON(t *testing.T, content string, decodings []Decoding) string {
+	t.Helper()
+	doc, err := formatx.Parse(formatx.FormatJSON, []byte(content), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	value, err := ReEncode(doc.Ordered(nil), decodings)
+	if err != nil {
+		t.Fatalf("re-encode: %v", err)
+	}
+	return compact(t, doc, value)
+}
+
+// compact 按文档格式序列化并去掉缩进，便于比较键的顺序
+func compact(t *testing.T, doc *formatx.Document, value any) string {
+	t.Helper()
+	out, err := formatx.Remarshal(doc, value, nil)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = json.Compact(&buf, []byte(out)); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	return buf.String()
+}
+
+// 嵌入的 JSON 与查询串展开后保持原文中键的顺序，还原后与原文一致
+func TestDeepDecodeKeepsKeyOrder(t *testing.T) {
+	content := `{"payload":"{\"z\":1,\"a\":{\"y\":true,\"b\":null}}","query":"zeta=1&zeta=3&alpha=2"}`
+	out, decodings := deepDecodeJSON(t, content)
+	want := `{"payload":{"z":1,"a":{"y":true,"b":null}},"query":{"zeta":["1","3"],"alpha":"2"}}`
+	if out != want {
+		t.Fatalf("decoded\n got: %s\nwant: %s", out, want)
+	}
+	if len(decodings) != 2 {
+		t.Fatalf("unexpected decodings: %+v", decodings)
+	}
+	if got := reEncodeJSON(t, out, decodings); got != content {
+		t.Errorf("re-encoded\n got: %s\nwant: %s", got, content)
+	}
+}
+
+// 编辑时新增的查询参数按其在内容中的位置输出，不再按字母序追加
+func TestReEncodeQueryNewKeys(t *testing.T) {
+	_, decodings := deepDecodeJSON(t, `{"q":"b=1&a=2"}`)
+	got := reEncodeJSON(t, `{"q":{"z":"0","b":"1","a":"2"}}`, decodings)
+	if want := `{"q":"z=0&b=1&a=2"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// 多层编码逐层展开，普通文本与数字字符串保持不变
+func TestDeepDecodeLayers(t *testing.T) {
+	inner := `{"k":"v","a":1}`
+	content := `{"b64":"` + base64Encoding("std").EncodeToString([]byte(inner)) + `","text":"hello","num":"123"}`
+	out, decodings := deepDecodeJSON(t, content)
+	if want := `{"b64":{"k":"v","a":1},"text":"hello","num":"123"}`; out != want {
+		t.Fatalf("decoded\n got: %s\nwant: %s", out, want)
+	}
+	if len(decodings) != 1 || len(decodings[0].Steps) != 2 || decodings[0].Steps[0].Type != EncodingBase64 {
+		t.Fatalf("unexpected decodings: %+v", decodings)
+	}
+	if got := reEncodeJSON(t, out, decodings); got != content {
+		t.Errorf("re-encoded\n got: %s\nwant: %s", got, content)
+	}
+}
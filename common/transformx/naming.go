@@ -0,0 +1,160 @@
+package transformx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ReservedWords 某种语言的保留字及转义方式
+type ReservedWords struct {
+	Words  []string `json:"words"`
+	Escape string   `json:"escape"` // 转义模板，{name} 为原名，如 {name}_、r#{name}、`{name}`
+}
+
+// DefaultReservedWords 各语言内置的保留字，字段名或类型名与之相同时按 Escape 转义
+var DefaultReservedWords = map[string]ReservedWords{
+	"go": {Escape: "{name}_", Words: []string{
+		"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+		"func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return",
+		"select", "struct", "switch", "type", "var",
+	}},
+	"typescript": {Escape: "{name}_", Words: []string{
+		"any", "boolean", "break", "case", "catch", "class", "const", "continue", "debugger", "default",
+		"delete", "do", "else", "enum", "export", "extends", "false", "finally", "for", "function",
+		"if", "implements", "import", "in", "instanceof", "interface", "let", "new", "null", "number",
+		"package", "private", "protected", "public", "return", "static", "string", "super", "switch", "symbol",
+		"this", "throw", "true", "try", "type", "typeof", "var", "void", "while", "with", "yield",
+	}},
+	"java": {Escape: "{name}_", Words: []string{
+		"abstract", "assert", "boolean", "break", "byte", "case", "catch", "char", "class", "const",
+		"continue", "default", "do", "double", "else", "enum", "extends", "final", "finally", "float",
+		"for", "goto", "if", "implements", "import", "instanceof", "int", "interface", "long", "native",
+		"new", "package", "private", "protected", "public", "return", "short", "static", "strictfp", "super",
+		"switch", "synchronized", "this", "throw", "throws", "transient", "try", "void", "volatile", "while",
+		"true", "false", "null", "var", "record", "yield",
+	}},
+	"python": {Escape: "{name}_", Words: []string{
+		"False", "None", "True", "and", "as", "assert", "async", "await", "break", "class",
+		"continue", "def", "del", "elif", "else", "except", "finally", "for", "from", "global",
+		"if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise",
+		"return", "try", "while", "with", "yield",
+	}},
+	"kotlin": {Escape: "`{name}`", Words: []string{
+		"as", "break", "class", "continue", "do", "else", "false", "for", "fun", "if",
+		"in", "interface", "is", "null", "object", "package", "return", "super", "this", "throw",
+		"true", "try", "typealias", "typeof", "val", "var", "when", "while",
+	}},
+	"rust": {Escape: "r#{name}", Words: []string{
+		"as", "async", "await", "break", "const", "continue", "crate", "dyn", "else", "enum",
+		"extern", "false", "fn", "for", "if", "impl", "in", "let", "loop", "match",
+		"mod", "move", "mut", "pub", "ref", "return", "static", "struct", "trait", "true",
+		"type", "unsafe", "use", "where", "while", "abstract", "become", "box", "do", "final",
+		"macro", "override", "priv", "try", "typeof", "unsized", "virtual", "yield",
+	}},
+}
+
+// NamingDictionary 代码生成使用的团队命名词典：额外的缩略词、按 JSON 键指定的字段名、各语言的保留字与结构体名前后缀
+type NamingDictionary struct {
+	Initialisms  []string                 `json:"initialisms"`   // 缩略词，含 CommonInitialisms
+	Overrides    map[string]string        `json:"overrides"`     // JSON 键 → 字段名，原样使用，不再按命名格式改写
+	Reserved     map[string]ReservedWords `json:"reserved"`      // 语言 → 保留字，含 DefaultReservedWords
+	StructPrefix string                   `json:"struct_prefix"` // 结构体名前缀，如 Api
+	StructSuffix string                   `json:"struct_suffix"` // 结构体名后缀，如 DTO
+}
+
+// NewNamingDictionary 在内置缩略词与保留字的基础上合并团队配置；同一语言配置了 Escape 时替换内置的转义方式，保留字追加
+func NewNamingDictionary(initialisms []string, overrides map[string]string, reserved map[string]ReservedWords, prefix, suffix string) *NamingDictionary {
+	d := &NamingDictionary{
+		Overrides:    make(map[string]string, len(overrides)),
+		Reserved:     make(map[string]ReservedWords, len(DefaultReservedWords)),
+		StructPrefix: strings.TrimSpace(prefix),
+		StructSuffix: strings.TrimSpace(suffix),
+	}
+	seen := make(map[string]bool)
+	for _, word := range append(append([]string(nil), CommonInitialisms...), initialisms...) {
+		if word = strings.ToUpper(strings.TrimSpace(word)); word != "" && !seen[word] {
+			seen[word] = true
+			d.Initialisms = append(d.Initialisms, word)
+		}
+	}
+	for key, name := range overrides {
+		if key != "" && strings.TrimSpace(name) != "" {
+			d.Overrides[key] = strings.TrimSpace(name)
+		}
+	}
+	for lang, words := range DefaultReservedWords {
+		d.Reserved[lang] = ReservedWords{Words: append([]string(nil), words.Words...), Escape: words.Escape}
+	}
+	for lang, words := range reserved {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		merged := d.Reserved[lang]
+		merged.Words = append(merged.Words, words.Words...)
+		if words.Escape != "" {
+			merged.Escape = words.Escape
+		}
+		if merged.Escape == "" {
+			merged.Escape = "{name}_"
+		}
+		d.Reserved[lang] = merged
+	}
+	return d
+}
+
+// Namer 使用词典中缩略词与字段名映射的命名器
+func (d *NamingDictionary) Namer(c KeyCase) *KeyNamer {
+	n := NewKeyNamer(c, d.Initialisms)
+	n.overrides = d.Overrides
+	return n
+}
+
+// FieldName JSON 键对应的字段名：按命名器改写（含字段名映射），数字开头时加 field 前缀，与保留字相同时转义
+func (d *NamingDictionary) FieldName(lang string, n *KeyNamer, key string) string {
+	return d.Escape(lang, digitPrefix(n.Name(key)))
+}
+
+// StructName 结构体名：按命名器改写（不使用字段名映射），Go 的首字母大写，数字开头时加 field 前缀，
+// 再加前缀与后缀，已带有的（不区分大小写）统一为配置的写法，与保留字相同时转义
+func (d *NamingDictionary) StructName(lang string, n *KeyNamer, name string) string {
+	name = n.convert(name)
+	if lang == "go" {
+		if runes := []rune(name); len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+			name = string(runes)
+		}
+	}
+	name = digitPrefix(name)
+	if prefix := d.StructPrefix; prefix != "" {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			name = name[len(prefix):]
+		}
+		name = prefix + name
+	}
+	if suffix := d.StructSuffix; suffix != "" {
+		if len(name) >= len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+			name = name[:len(name)-len(suffix)]
+		}
+		name += suffix
+	}
+	return d.Escape(lang, name)
+}
+
+func digitPrefix(name string) string {
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		return "field" + name
+	}
+	return name
+}
+
+// Escape 名称与该语言的保留字相同时按转义模板改写，保留字区分大小写
+func (d *NamingDictionary) Escape(lang, name string) string {
+	reserved, ok := d.Reserved[strings.ToLower(lang)]
+	if !ok {
+		return name
+	}
+	for _, word := range reserved.Words {
+		if word == name {
+			return strings.ReplaceAll(reserved.Escape, "{name}", name)
+		}
+	}
+	return name
+}
@@ -0,0 +1,267 @@
+package transformx
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 路径模式支持两种写法：
+//   - 点号路径：$.data.items[0].password、items[*].token、**.secret、user.*_key
+//   - JSON Pointer：/data/items/0/password，同样允许 * 与 ** 段
+// * 匹配任意一个键或下标，** 匹配任意多层（含零层），键段中可使用 path.Match 通配符
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentGlob
+	segmentIndex
+	segmentAny
+	segmentRecursive
+)
+
+type pathSegment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// PathPattern 已解析的路径模式
+type PathPattern struct {
+	expr     string
+	segments []pathSegment
+}
+
+func (p *PathPattern) String() string {
+	return p.expr
+}
+
+// ParsePathPattern 解析点号路径或 JSON Pointer 形式的路径模式
+func ParsePathPattern(expr string) (*PathPattern, error) {
+	expr = strings.TrimSpace(expr)
+	var (
+		segments []pathSegment
+		err      error
+	)
+	if strings.HasPrefix(expr, "/") || expr == "" {
+		segments, err = parsePointerPattern(expr)
+	} else {
+		segments, err = parseDotPattern(expr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", expr, err)
+	}
+	return &PathPattern{expr: expr, segments: segments}, nil
+}
+
+// ParsePathPatterns 批量解析路径模式
+func ParsePathPatterns(exprs []string) ([]*PathPattern, error) {
+	patterns := make([]*PathPattern, 0, len(exprs))
+	for _, expr := range exprs {
+		p, err := ParsePathPattern(expr)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+func parsePointerPattern(expr string) ([]pathSegment, error) {
+	tokens, err := splitPointer(expr)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]pathSegment, 0, len(tokens))
+	for _, token := range tokens {
+		seg, err := keySegment(token)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+func parseDotPattern(expr string) ([]pathSegment, error) {
+	if expr == "$" {
+		return nil, nil
+	}
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+	var segments []pathSegment
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			i++
+			if i >= len(expr) || expr[i] == '.' || expr[i] == '[' {
+				return nil, fmt.Errorf("empty key at offset %d", i)
+			}
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ at offset %d", i)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			i += end + 1
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{kind: segmentAny})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{kind: segmentKey, key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid index [%s]", inner)
+				}
+				segments = append(segments, pathSegment{kind: segmentIndex, index: n})
+			}
+		default:
+			end := strings.IndexAny(expr[i:], ".[")
+			if end < 0 {
+				end = len(expr) - i
+			}
+			seg, err := keySegment(expr[i : i+end])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+func keySegment(token string) (pathSegment, error) {
+	switch {
+	case token == "**":
+		return pathSegment{kind: segmentRecursive}, nil
+	case token == "*":
+		return pathSegment{kind: segmentAny}, nil
+	case strings.ContainsAny(token, "*?["):
+		if _, err := path.Match(token, ""); err != nil {
+			return pathSegment{}, fmt.Errorf("invalid pattern %q", token)
+		}
+		return pathSegment{kind: segmentGlob, key: token}, nil
+	default:
+		return pathSegment{kind: segmentKey, key: token}, nil
+	}
+}
+
+// PathToken 实际路径上的一段，键或下标
+type PathToken struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// Match 判断实际路径是否命中模式
+func (p *PathPattern) Match(tokens []PathToken) bool {
+	return matchSegments(p.segments, tokens)
+}
+
+func matchSegments(segments []pathSegment, tokens []PathToken) bool {
+	if len(segments) == 0 {
+		return len(tokens) == 0
+	}
+	seg := segments[0]
+	if seg.kind == segmentRecursive {
+		for i := 0; i <= len(tokens); i++ {
+			if matchSegments(segments[1:], tokens[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(tokens) == 0 || !seg.matchToken(tokens[0]) {
+		return false
+	}
+	return matchSegments(segments[1:], tokens[1:])
+}
+
+func (s pathSegment) matchToken(token PathToken) bool {
+	switch s.kind {
+	case segmentAny:
+		return true
+	case segmentIndex:
+		return token.IsIndex && token.Index == s.index
+	case segmentGlob:
+		ok, _ := path.Match(s.key, token.Key)
+		return !token.IsIndex && ok
+	default:
+		if token.IsIndex {
+			return s.key == strconv.Itoa(token.Index)
+		}
+		return s.key == token.Key
+	}
+}
+
+// Rewrite 遍历数据，将命中任一模式的值替换为 fn 的返回值，命中的节点不再向下遍历。
+// 对象与数组原地修改，返回替换后的根节点以及每个模式命中的 JSON Pointer 列表
+func Rewrite(value any, patterns []*PathPattern, fn func(pointer string, value any) (any, error)) (any, map[string][]string, error) {
+	matched := make(map[string][]string, len(patterns))
+	w := &rewriter{patterns: patterns, fn: fn, matched: matched}
+	result, err := w.walk(value, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, matched, nil
+}
+
+type rewriter struct {
+	patterns []*PathPattern
+	fn       func(pointer string, value any) (any, error)
+	matched  map[string][]string
+}
+
+func (w *rewriter) walk(value any, pointer string, tokens []PathToken) (any, error) {
+	hit := false
+	for _, p := range w.patterns {
+		if p.Match(tokens) {
+			w.matched[p.expr] = append(w.matched[p.expr], pointer)
+			hit = true
+		}
+	}
+	if hit {
+		return w.fn(pointer, value)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			replaced, err := w.walk(v[key], childPointer(pointer, key), append(tokens, PathToken{Key: key}))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = replaced
+		}
+	case []any:
+		for i, item := range v {
+			replaced, err := w.walk(item, indexPointer(pointer, i), append(tokens, PathToken{Index: i, IsIndex: true}))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+	}
+	return value, nil
+}
+
+// Pointer 将实际路径转为 JSON Pointer
+func Pointer(tokens []PathToken) string {
+	pointer := ""
+	for _, token := range tokens {
+		if token.IsIndex {
+			pointer = indexPointer(pointer, token.Index)
+		} else {
+			pointer = childPointer(pointer, token.Key)
+		}
+	}
+	return pointer
+}
@@ -0,0 +1,94 @@
+package transformx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+// 路径使用 JSON Pointer（RFC 6901），如 /data/items/0/payload，根节点为空字符串
+
+// escapeToken 转义 JSON Pointer 中的 ~ 与 /
+func escapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func childPointer(pointer, key string) string {
+	return pointer + "/" + escapeToken(key)
+}
+
+func indexPointer(pointer string, i int) string {
+	return pointer + "/" + strconv.Itoa(i)
+}
+
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q, expected a JSON pointer starting with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapeToken(token)
+	}
+	return tokens, nil
+}
+
+// replaceAt 将 pointer 指向的值替换为 fn 的返回值
+func replaceAt(root any, pointer string, fn func(value any) (any, error)) (any, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return replaceTokens(root, tokens, pointer, fn)
+}
+
+func replaceTokens(value any, tokens []string, pointer string, fn func(value any) (any, error)) (any, error) {
+	if len(tokens) == 0 {
+		return fn(value)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("path %s: key %q not found", pointer, tokens[0])
+		}
+		replaced, err := replaceTokens(child, tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = replaced
+		return v, nil
+	case *formatx.Record:
+		child, ok := v.Values[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("path %s: key %q not found", pointer, tokens[0])
+		}
+		replaced, err := replaceTokens(child, tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		v.Values[tokens[0]] = replaced
+		return v, nil
+	case []any:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, fmt.Errorf("path %s: index %q out of range", pointer, tokens[0])
+		}
+		replaced, err := replaceTokens(v[i], tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = replaced
+		return v, nil
+	default:
+		return nil, fmt.Errorf("path %s: cannot descend into %T", pointer, value)
+	}
+}
@@ -0,0 +1,271 @@
+package transformx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jasonlabz/json-converter-server/common/pinyinx"
+)
+
+// KeyCase 键的命名格式，与前端 CASE_FORMATS 一致
+type KeyCase string
+
+const (
+	CasePascal KeyCase = "pascal" // UserID、HTTPServer
+	CaseCamel  KeyCase = "camel"  // userID、httpServer
+	CaseSnake  KeyCase = "snake"  // user_id、http_server
+	CaseKebab  KeyCase = "kebab"  // user-id、http-server
+)
+
+// ParseKeyCase 校验命名格式
+func ParseKeyCase(name string) (KeyCase, error) {
+	switch c := KeyCase(strings.ToLower(strings.TrimSpace(name))); c {
+	case CasePascal, CaseCamel, CaseSnake, CaseKebab:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unsupported key case: %q, expected pascal, camel, snake or kebab", name)
+	}
+}
+
+// CommonInitialisms 常见缩略词，与前端 COMMON_INITIALISMS 一致，大驼峰与小驼峰中保持全大写
+var CommonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA",
+	"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID",
+	"URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// KeyNamer 将键改写为目标命名格式
+type KeyNamer struct {
+	Case        KeyCase
+	Pinyin      pinyinx.Style // 非空时先将键中的汉字转为拼音，为空时汉字原样保留
+	initialisms map[string]bool
+	overrides   map[string]string // 按原键指定的新名称，见 NamingDictionary.Overrides
+}
+
+// NewKeyNamer 创建命名器，initialisms 追加在 CommonInitialisms 之后
+func NewKeyNamer(c KeyCase, initialisms []string) *KeyNamer {
+	n := &KeyNamer{Case: c, initialisms: make(map[string]bool)}
+	for _, word := range append(append([]string(nil), CommonInitialisms...), initialisms...) {
+		if word = strings.ToUpper(strings.TrimSpace(word)); word != "" {
+			n.initialisms[word] = true
+		}
+	}
+	return n
+}
+
+// Name 按单词边界拆分键后重新拼接；键首尾的 _、$、@ 等符号原样保留，没有字母与数字的键不变
+func (n *KeyNamer) Name(key string) string {
+	if name, ok := n.overrides[key]; ok {
+		return name
+	}
+	return n.convert(key)
+}
+
+// convert 按命名格式改写，不使用字段名映射
+func (n *KeyNamer) convert(key string) string {
+	if n.Pinyin != "" {
+		key = pinyinx.Transliterate(key, n.Pinyin)
+	}
+	runes := []rune(key)
+	start, end := 0, len(runes)
+	for start < end && !isWordRune(runes[start]) {
+		start++
+	}
+	for end > start && !isWordRune(runes[end-1]) {
+		end--
+	}
+	if start == end {
+		return key
+	}
+	words := splitWords(runes[start:end])
+	var b strings.Builder
+	b.WriteString(string(runes[:start]))
+	for i, word := range words {
+		switch n.Case {
+		case CasePascal:
+			b.WriteString(n.title(word))
+		case CaseCamel:
+			if i == 0 {
+				b.WriteString(strings.ToLower(word))
+			} else {
+				b.WriteString(n.title(word))
+			}
+		case CaseSnake, CaseKebab:
+			if i > 0 {
+				b.WriteString(map[KeyCase]string{CaseSnake: "_", CaseKebab: "-"}[n.Case])
+			}
+			b.WriteString(strings.ToLower(word))
+		}
+	}
+	b.WriteString(string(runes[end:]))
+	return b.String()
+}
+
+// title 缩略词全大写（复数形式如 IDs 保留小写 s），其余单词首字母大写
+func (n *KeyNamer) title(word string) string {
+	upper := strings.ToUpper(word)
+	if n.initialisms[upper] {
+		return upper
+	}
+	if strings.HasSuffix(upper, "S") && n.initialisms[upper[:len(upper)-1]] {
+		return upper[:len(upper)-1] + "s"
+	}
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitWords 按分隔符与大小写拆分单词：userId → user Id，HTTPServer → HTTP Server，
+// UTF8Value → UTF8 Value，userIDs → user IDs
+func splitWords(runes []rune) []string {
+	var words []string
+	start := -1
+	for i, r := range runes {
+		if !isWordRune(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(r) || unicode.IsDigit(prev) && unicode.IsUpper(r)
+		if unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			// 连续大写字母之后是小写字母时，最后一个大写字母开始新单词，缩略词的复数 s 除外
+			plural := runes[i+1] == 's' && (i+2 == len(runes) || !unicode.IsLower(runes[i+2]))
+			boundary = !plural
+		}
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// KeyCollision 同一对象中映射到同一新键名的多个键，这些键保留原名
+type KeyCollision struct {
+	Path string   `json:"path"` // 对象所在路径（JSON Pointer）
+	Name string   `json:"name"` // 冲突的新键名
+	Keys []string `json:"keys"` // 原键，按字典序
+}
+
+// RekeyOptions 键名改写选项
+type RekeyOptions struct {
+	Namer *KeyNamer
+	// Paths 只改写命中路径的键及其下级的键，如 data.* 改写 data 下的所有键而不改写 data 本身，为空时改写全部
+	Paths []*PathPattern
+	// FailOnCollision 出现冲突时返回错误，默认冲突的键保留原名
+	FailOnCollision bool
+}
+
+// Rekey 计算改写键名的映射，按对象所在路径（JSON Pointer）记录原键到新键，供 formatx.Document.RenameKeys 使用。
+// 同一对象中多个键映射到同一新键名时报告冲突，这些键均保留原名
+func Rekey(value any, opts *RekeyOptions) (map[string]map[string]string, []KeyCollision, error) {
+	r := &rekeyer{opts: opts, renames: make(map[string]map[string]string)}
+	r.walk(value, "", nil, len(opts.Paths) == 0)
+	if len(r.collisions) > 0 && opts.FailOnCollision {
+		c := r.collisions[0]
+		path := c.Path
+		if path == "" {
+			path = "$"
+		}
+		return nil, r.collisions, fmt.Errorf("%s: keys %s all map to %q", path, strings.Join(c.Keys, ", "), c.Name)
+	}
+	return r.renames, r.collisions, nil
+}
+
+type rekeyer struct {
+	opts       *RekeyOptions
+	renames    map[string]map[string]string
+	collisions []KeyCollision
+}
+
+// walk selected 表示祖先节点已命中路径
+func (r *rekeyer) walk(value any, pointer string, tokens []PathToken, selected bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		names := make(map[string]string, len(keys))
+		children := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			child := append(tokens[:len(tokens):len(tokens)], PathToken{Key: key})
+			children[key] = selected || r.match(child)
+			names[key] = key
+			if children[key] {
+				names[key] = r.opts.Namer.Name(key)
+			}
+		}
+		r.resolve(pointer, keys, names)
+		for _, key := range keys {
+			if names[key] != key {
+				if r.renames[pointer] == nil {
+					r.renames[pointer] = make(map[string]string)
+				}
+				r.renames[pointer][key] = names[key]
+			}
+			child := append(tokens[:len(tokens):len(tokens)], PathToken{Key: key})
+			r.walk(v[key], childPointer(pointer, key), child, children[key])
+		}
+	case []any:
+		for i, item := range v {
+			child := append(tokens[:len(tokens):len(tokens)], PathToken{Index: i, IsIndex: true})
+			r.walk(item, indexPointer(pointer, i), child, selected || r.match(child))
+		}
+	}
+}
+
+func (r *rekeyer) match(tokens []PathToken) bool {
+	for _, p := range r.opts.Paths {
+		if p.Match(tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve 找出映射到同一新键名的键并恢复原名，恢复后可能与其他新键名重名，重复直到没有冲突
+func (r *rekeyer) resolve(pointer string, keys []string, names map[string]string) {
+	reported := make(map[string]bool)
+	for {
+		groups := make(map[string][]string)
+		for _, key := range keys {
+			groups[names[key]] = append(groups[names[key]], key)
+		}
+		changed := false
+		for _, key := range keys {
+			name := names[key]
+			group := groups[name]
+			if len(group) < 2 || name == key {
+				continue
+			}
+			if !reported[name] {
+				reported[name] = true
+				r.collisions = append(r.collisions, KeyCollision{Path: pointer, Name: name, Keys: group})
+			}
+			names[key] = key
+			changed = true
+		}
+		if !changed {
+			return
+		}
+	}
+}
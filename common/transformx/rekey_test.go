@@ -0,0 +1,147 @@
+package transformx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/json-converter-server/common/pinyinx"
+)
+
+func TestKeyNamer(t *testing.T) {
+	cases := []struct {
+		c    KeyCase
+		key  string
+		want string
+	}{
+		{CasePascal, "user_id", "UserID"},
+		{CasePascal, "httpServer", "HTTPServer"},
+		{CasePascal, "HTTPServer", "HTTPServer"},
+		{CaseCamel, "HTTPServer", "httpServer"},
+		{CaseCamel, "user-ids", "userIDs"},
+		{CaseSnake, "userIDs", "user_ids"},
+		{CaseSnake, "UTF8Value", "utf8_value"},
+		{CaseKebab, "APIKey", "api-key"},
+		{CaseSnake, "_id", "_id"},
+		{CasePascal, "@type", "@Type"},
+		{CaseCamel, "$ref_id", "$refID"},
+		{CaseSnake, "--", "--"},
+		{CasePascal, "k8s_url", "K8sURL"},
+	}
+	for _, c := range cases {
+		n := NewKeyNamer(c.c, nil)
+		if got := n.Name(c.key); got != c.want {
+			t.Errorf("%s %q: got %q, want %q", c.c, c.key, got, c.want)
+		}
+	}
+}
+
+// 追加的缩略词与内置缩略词一样保持全大写，大小写与首尾空白不影响
+func TestKeyNamerInitialisms(t *testing.T) {
+	n := NewKeyNamer(CasePascal, []string{" sku ", "", "oauth"})
+	for key, want := range map[string]string{"sku_code": "SKUCode", "oauth_token": "OAUTHToken", "skus": "SKUs", "user_id": "UserID"} {
+		if got := n.Name(key); got != want {
+			t.Errorf("%q: got %q, want %q", key, got, want)
+		}
+	}
+}
+
+// 设置 Pinyin 时先将汉字转为拼音再按命名格式改写
+func TestKeyNamerPinyin(t *testing.T) {
+	cases := []struct {
+		c     KeyCase
+		style pinyinx.Style
+		key   string
+		want  string
+	}{
+		{CaseCamel, pinyinx.StyleFull, "订单ID", "dingDanID"},
+		{CasePascal, pinyinx.StyleFull, "银行卡号", "YinHangKaHao"},
+		{CaseSnake, pinyinx.StyleInitials, "用户名", "yhm"},
+		{CaseSnake, "", "用户名", "用户名"},
+	}
+	for _, c := range cases {
+		n := NewKeyNamer(c.c, nil)
+		n.Pinyin = c.style
+		if got := n.Name(c.key); got != c.want {
+			t.Errorf("%s %q %q: got %q, want %q", c.c, c.style, c.key, got, c.want)
+		}
+	}
+}
+
+func TestParseKeyCase(t *testing.T) {
+	if c, err := ParseKeyCase(" Snake "); err != nil || c != CaseSnake {
+		t.Errorf("got %q, %v", c, err)
+	}
+	if _, err := ParseKeyCase("upper"); err == nil {
+		t.Error("expected error for unsupported case")
+	}
+}
+
+func TestRekey(t *testing.T) {
+	value := map[string]any{
+		"user_id": 1,
+		"data": map[string]any{
+			"first_name": "a",
+			"items":      []any{map[string]any{"item_id": 2}},
+		},
+	}
+	cases := []struct {
+		name  string
+		paths []string
+		want  map[string]map[string]string
+	}{
+		{"all", nil, map[string]map[string]string{
+			"":              {"user_id": "userID"},
+			"/data":         {"first_name": "firstName"},
+			"/data/items/0": {"item_id": "itemID"},
+		}},
+		{"children only", []string{"data.*"}, map[string]map[string]string{
+			"/data":         {"first_name": "firstName"},
+			"/data/items/0": {"item_id": "itemID"},
+		}},
+		{"array items", []string{"data.items[*].*"}, map[string]map[string]string{
+			"/data/items/0": {"item_id": "itemID"},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			paths, err := ParsePathPatterns(c.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			renames, collisions, err := Rekey(value, &RekeyOptions{Namer: NewKeyNamer(CaseCamel, nil), Paths: paths})
+			if err != nil || len(collisions) != 0 {
+				t.Fatalf("unexpected error %v, collisions %v", err, collisions)
+			}
+			if !reflect.DeepEqual(renames, c.want) {
+				t.Errorf("got %v, want %v", renames, c.want)
+			}
+		})
+	}
+}
+
+// 映射到同一新键名的键均保留原名并按对象报告冲突，FailOnCollision 时返回第一个冲突
+func TestRekeyCollisions(t *testing.T) {
+	value := map[string]any{
+		"user_id": 1, "userId": 2, "user-id": 3,
+		"nested": map[string]any{"a_b": 1, "aB": 2, "A_B": 3, "c_d": 4},
+	}
+	renames, collisions, err := Rekey(value, &RekeyOptions{Namer: NewKeyNamer(CaseCamel, nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeyCollision{
+		{Path: "", Name: "userID", Keys: []string{"user-id", "userId", "user_id"}},
+		{Path: "/nested", Name: "aB", Keys: []string{"A_B", "aB", "a_b"}},
+	}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Errorf("collisions: got %+v, want %+v", collisions, want)
+	}
+	if !reflect.DeepEqual(renames, map[string]map[string]string{"/nested": {"c_d": "cD"}}) {
+		t.Errorf("renames: got %v", renames)
+	}
+	_, _, err = Rekey(value, &RekeyOptions{Namer: NewKeyNamer(CaseCamel, nil), FailOnCollision: true})
+	if err == nil || !strings.Contains(err.Error(), `$: keys user-id, userId, user_id all map to "userID"`) {
+		t.Errorf("expected collision error, got %v", err)
+	}
+}
@@ -80,7 +80,7 @@ func main() {
 	srv := startServer(r, serverConfig)
 
 	// receive quit signal, ready to exit
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
 	<-quit
@@ -135,7 +135,7 @@ func fileServer(c *bootstrap.Config) {
 	if err != nil {
 		log.Fatalf("file server listen: %s\n", err)
 	}
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	<-quit
 	log.Println("shutdown file server ...")
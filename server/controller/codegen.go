@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/codegen"
+	"github.com/jasonlabz/json-converter-server/server/service/codegen/body"
+)
+
+// Naming 命名词典
+//
+//	@Summary	返回 conf/naming.yaml 中的缩略词、字段名映射、各语言保留字与结构体名前后缀，已合并内置规则
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Router		/api/v1/codegen/naming [get]
+func Naming(c *gin.Context) {
+	res, err := codegen.GetService().Naming(c)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// Pinyin 汉字键名转拼音
+//
+//	@Summary	按内嵌字典将键中的汉字转为全拼或首字母，单词之间以 _ 分隔，供生成代码的字段名按命名格式改写
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Param		pinyin_info	body	body.PinyinReqDto	true	"含汉字的键与转写方式"
+//	@Router		/api/v1/codegen/pinyin [post]
+func Pinyin(c *gin.Context) {
+	req := &body.PinyinReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := codegen.GetService().Pinyin(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// GenerateCode 按模板生成代码
+//
+//	@Summary	从内容推断类型树，按内置模板（与语言同名）、上传的模板或请求中的 Go text/template 模板生成代码
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Param		generate_info	body	body.GenerateReqDto	true	"内容、目标语言、模板与命名选项"
+//	@Router		/api/v1/codegen/generate [post]
+func GenerateCode(c *gin.Context) {
+	req := &body.GenerateReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := codegen.GetService().Generate(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// CodeTemplates 代码模板列表
+//
+//	@Summary	返回内置模板与已上传的模板名
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Router		/api/v1/codegen/templates [get]
+func CodeTemplates(c *gin.Context) {
+	res, err := codegen.GetService().Templates(c)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// CodeTemplate 代码模板内容
+//
+//	@Summary	返回内置或已上传模板的内容
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Param		template_info	body	body.TemplateReqDto	true	"模板名"
+//	@Router		/api/v1/codegen/templates/get [post]
+func CodeTemplate(c *gin.Context) {
+	req := &body.TemplateReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := codegen.GetService().Template(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// SaveCodeTemplate 上传代码模板
+//
+//	@Summary	校验模板语法并以示例数据试执行，通过后按名称保存到 codegen.template_dir，同名模板被覆盖
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Param		template_info	body	body.SaveTemplateReqDto	true	"模板名与模板内容"
+//	@Router		/api/v1/codegen/templates [post]
+func SaveCodeTemplate(c *gin.Context) {
+	req := &body.SaveTemplateReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := codegen.GetService().SaveTemplate(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// DeleteCodeTemplate 删除代码模板
+//
+//	@Summary	删除已上传的模板，内置模板不能删除
+//	@Tags		代码生成
+//	@Accept		json
+//	@Produce	json
+//	@Param		template_info	body	body.TemplateReqDto	true	"模板名"
+//	@Router		/api/v1/codegen/templates/delete [post]
+func DeleteCodeTemplate(c *gin.Context) {
+	req := &body.TemplateReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := codegen.GetService().DeleteTemplate(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/converter"
+	"github.com/jasonlabz/json-converter-server/server/service/converter/body"
+)
+
+// warningsTrailer 流式转换完成后通过 HTTP Trailer 返回提示信息，文件导出时作为响应头
+const warningsTrailer = "X-Convert-Warnings"
+
+// 响应头中提示信息的条数与长度上限，避免超出代理与客户端的响应头大小限制
+const (
+	maxHeaderWarnings     = 20
+	maxHeaderWarningBytes = 4096
+)
+
+// warningsHeader 提示信息编码为响应头的值，超出上限时截断并说明省略的条数
+func warningsHeader(warnings []formatx.Warning) string {
+	kept := warnings[:min(len(warnings), maxHeaderWarnings)]
+	data, _ := json.Marshal(kept)
+	for len(kept) > 0 && len(data) > maxHeaderWarningBytes {
+		kept = kept[:len(kept)-1]
+		data, _ = json.Marshal(kept)
+	}
+	if omitted := len(warnings) - len(kept); omitted > 0 {
+		kept = append(kept[:len(kept):len(kept)], formatx.Warning{Message: fmt.Sprintf("%d more warnings omitted", omitted)})
+		data, _ = json.Marshal(kept)
+	}
+	return string(data)
+}
+
+// Convert 格式转换
+//
+//	@Summary	在 JSON、JSON5、HJSON、XML、YAML、TOML、INI、NDJSON、CSV、Properties、dotenv、HCL 之间转换
+//	@Tags		格式转换
+//	@Accept		json
+//	@Produce	json
+//	@Param		convert_info	body	body.ConvertReqDto	true	"转换内容"
+//	@Router		/api/v1/convert [post]
+func Convert(c *gin.Context) {
+	req := &body.ConvertReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := converter.GetService().Convert(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// ConvertNDJSON NDJSON 文件流式转换
+//
+//	@Summary	NDJSON 文件逐行转换为 JSON 数组、NDJSON、YAML 多文档或 CSV，提示信息通过 X-Convert-Warnings Trailer 返回
+//	@Tags		格式转换
+//	@Accept		multipart/form-data
+//	@Produce	octet-stream
+//	@Param		file			formData	file		true	"NDJSON 文件"
+//	@Param		to				formData	string		true	"目标格式: json|ndjson|yaml|csv"
+//	@Param		indent			formData	int			false	"缩进空格数"
+//	@Param		on_line_error	formData	string		false	"错误行处理方式: fail|skip"
+//	@Param		columns			formData	[]string	false	"CSV 输出列"
+//	@Router		/api/v1/convert/ndjson [post]
+func ConvertNDJSON(c *gin.Context) {
+	req := &body.StreamConvertReqDto{}
+	if err := c.ShouldBind(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	req.From = string(formatx.FormatNDJSON)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	defer file.Close()
+
+	filename := strings.TrimSuffix(fileHeader.Filename, filepath.Ext(fileHeader.Filename))
+	streamConvert(c, file, filename, req)
+}
+
+// StreamConvert 流式格式转换
+//
+//	@Summary	请求体按记录逐条转换并写出：NDJSON 逐行、JSON 根数组逐个元素、YAML 逐个文档，其余输入整体作为一条记录，单条记录最大 32 MiB；提示信息通过 X-Convert-Warnings Trailer 返回
+//	@Tags		格式转换
+//	@Accept		octet-stream
+//	@Produce	octet-stream
+//	@Param		from			query	string		true	"源格式: json|ndjson|yaml"
+//	@Param		to				query	string		true	"目标格式: json|ndjson|yaml|csv"
+//	@Param		indent			query	int			false	"缩进空格数"
+//	@Param		on_line_error	query	string		false	"NDJSON 错误行处理方式: fail|skip"
+//	@Param		columns			query	[]string	false	"CSV 输出列"
+//	@Router		/api/v1/stream/convert [post]
+func StreamConvert(c *gin.Context) {
+	req := &body.StreamConvertReqDto{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	if req.From == "" {
+		base.ResponseErr(c, consts.APIVersionV1, errors.New("query parameter from is required"))
+		return
+	}
+	// 大文件转换耗时较长，取消服务端的读写超时
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+	streamConvert(c, c.Request.Body, "converted", req)
+}
+
+// streamConvert 边转换边写出响应；输出开始前出错返回普通错误响应，之后出错只能中断并通过 Trailer 说明
+func streamConvert(c *gin.Context, src io.Reader, basename string, req *body.StreamConvertReqDto) {
+	to, err := formatx.ParseFormat(req.To)
+	if err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	c.Header("Content-Type", to.ContentType())
+	c.Header("Content-Disposition", "attachment; filename="+basename+"."+to.Ext())
+	c.Header("Trailer", warningsTrailer)
+
+	warnings, err := converter.GetService().StreamConvert(c, src, c.Writer, req)
+	if err != nil && !c.Writer.Written() {
+		for _, key := range []string{"Content-Type", "Content-Disposition", "Trailer"} {
+			c.Writer.Header().Del(key)
+		}
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	if err != nil {
+		warnings = append(warnings, formatx.Warning{Message: "conversion aborted: " + err.Error()})
+		_ = c.Error(errors.New("stream convert aborted: " + err.Error()))
+	}
+	if len(warnings) > 0 {
+		c.Writer.Header().Set(warningsTrailer, warningsHeader(warnings))
+	}
+}
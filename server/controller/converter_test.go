@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+func newStreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/v1/stream/convert", StreamConvert)
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// 提示信息在响应体之后通过 Trailer 返回，读完响应体前取不到
+func TestStreamConvertTrailer(t *testing.T) {
+	server := newStreamServer(t)
+	input := "{\"a\":1}\nbad\n{\"a\":2}\n"
+	resp, err := http.Post(server.URL+"/api/v1/stream/convert?from=ndjson&to=ndjson&on_line_error=skip", "application/x-ndjson", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if _, declared := resp.Trailer[warningsTrailer]; !declared || resp.Trailer.Get(warningsTrailer) != "" {
+		t.Fatalf("trailer before body: %v", resp.Trailer)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{\"a\":1}\n{\"a\":2}\n" {
+		t.Errorf("body: %q", data)
+	}
+	var warnings []formatx.Warning
+	if err = json.Unmarshal([]byte(resp.Trailer.Get(warningsTrailer)), &warnings); err != nil {
+		t.Fatalf("trailer %q: %v", resp.Trailer.Get(warningsTrailer), err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 2 || !strings.HasPrefix(warnings[0].Message, "skipped invalid line") {
+		t.Errorf("warnings: %+v", warnings)
+	}
+}
+
+// 输出开始前出错返回普通错误响应，不声明 Trailer
+func TestStreamConvertError(t *testing.T) {
+	server := newStreamServer(t)
+	resp, err := http.Post(server.URL+"/api/v1/stream/convert?from=ndjson&to=ndjson", "application/x-ndjson", strings.NewReader("bad\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if len(resp.Trailer) != 0 || !strings.Contains(string(data), "line 1:") {
+		t.Errorf("trailer %v, body %s", resp.Trailer, data)
+	}
+}
+
+// 响应头中的提示信息按条数与长度截断，并说明省略的条数
+func TestWarningsHeader(t *testing.T) {
+	decode := func(value string) []formatx.Warning {
+		var warnings []formatx.Warning
+		if err := json.Unmarshal([]byte(value), &warnings); err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+		return warnings
+	}
+	many := make([]formatx.Warning, 50)
+	for i := range many {
+		many[i] = formatx.Warning{Line: i + 1, Message: fmt.Sprintf("warning %d", i)}
+	}
+	got := decode(warningsHeader(many))
+	if len(got) != maxHeaderWarnings+1 || got[maxHeaderWarnings].Message != "30 more warnings omitted" {
+		t.Errorf("count limit: %d, last %+v", len(got), got[len(got)-1])
+	}
+	long := []formatx.Warning{{Message: strings.Repeat("x", 3000)}, {Message: strings.Repeat("y", 3000)}}
+	value := warningsHeader(long)
+	got = decode(value)
+	if len(value) > maxHeaderWarningBytes+100 || len(got) != 2 || got[1].Message != "1 more warnings omitted" {
+		t.Errorf("length limit: %d bytes, %d warnings", len(value), len(got))
+	}
+	if got = decode(warningsHeader(many[:2])); len(got) != 2 {
+		t.Errorf("no truncation: %+v", got)
+	}
+}
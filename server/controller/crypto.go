@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/crypto"
+	"github.com/jasonlabz/json-converter-server/server/service/crypto/body"
+)
+
+// EncryptFields 字段加密
+//
+//	@Summary	使用 crypto 配置中的 AES/DES 密钥加密命中路径的字段值，保持文档结构，密文为 base64
+//	@Tags		加解密
+//	@Accept		json
+//	@Produce	json
+//	@Param		crypto_info	body	body.FieldCryptoReqDto	true	"待加密内容与字段路径"
+//	@Router		/api/v1/crypto/encrypt [post]
+func EncryptFields(c *gin.Context) {
+	req := &body.FieldCryptoReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := crypto.GetService().Encrypt(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// DecryptFields 字段解密
+//
+//	@Summary	使用 crypto 配置中的 AES/DES 密钥解密命中路径的 base64 密文
+//	@Tags		加解密
+//	@Accept		json
+//	@Produce	json
+//	@Param		crypto_info	body	body.FieldCryptoReqDto	true	"待解密内容与字段路径"
+//	@Router		/api/v1/crypto/decrypt [post]
+func DecryptFields(c *gin.Context) {
+	req := &body.FieldCryptoReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := crypto.GetService().Decrypt(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
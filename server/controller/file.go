@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/file"
+	"github.com/jasonlabz/json-converter-server/server/service/file/body"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// UploadFile 上传文件
+//
+//	@Summary	上传文件，Excel 文件按工作表转为 JSON
+//	@Tags		文件
+//	@Accept		multipart/form-data
+//	@Produce	json
+//	@Param		file		formData	file	true	"上传文件"
+//	@Param		header		formData	string	false	"表头识别方式: auto|true|false"
+//	@Param		date_layout	formData	string	false	"日期输出格式"
+//	@Param		sheets		formData	[]string	false	"仅读取指定工作表"
+//	@Router		/api/v1/file/upload [post]
+func UploadFile(c *gin.Context) {
+	req := &body.FileUploadReqDto{}
+	if err := c.ShouldBind(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := file.GetService().Upload(c, fileHeader, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// ExportXlsx 导出 Excel
+//
+//	@Summary	JSON 数组导出为 Excel，嵌套对象展开为点号分隔的列名，超出 15 位有效数字的数字写为文本并通过 X-Convert-Warnings 响应头说明（最多 20 条）
+//	@Tags		文件
+//	@Accept		json
+//	@Produce	application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Param		export_info	body	body.XlsxExportReqDto	true	"导出数据"
+//	@Router		/api/v1/file/export/xlsx [post]
+func ExportXlsx(c *gin.Context) {
+	req := &body.XlsxExportReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	content, warnings, err := file.GetService().ExportXlsx(c, req)
+	filename := req.Filename
+	if filename == "" {
+		filename = "export.xlsx"
+	} else if !strings.HasSuffix(strings.ToLower(filename), ".xlsx") {
+		filename += ".xlsx"
+	}
+	if len(warnings) > 0 {
+		c.Header(warningsTrailer, warningsHeader(warnings))
+	}
+	base.FileResultWithError(c, consts.APIVersionV1, &base.FileDownloadConfig{
+		Filename:    filename,
+		ContentType: xlsxContentType,
+		Content:     content,
+	}, err)
+}
+
+// ExportBinary 导出二进制格式
+//
+//	@Summary	JSON 编码为 MessagePack、CBOR 或 BSON 文件下载，无法编码的值通过 X-Convert-Warnings 响应头说明（最多 20 条）；Excel 请使用 /api/v1/file/export/xlsx
+//	@Tags		文件
+//	@Accept		json
+//	@Produce	application/octet-stream
+//	@Param		export_info	body	body.BinaryExportReqDto	true	"导出数据"
+//	@Router		/api/v1/file/export/binary [post]
+func ExportBinary(c *gin.Context) {
+	req := &body.BinaryExportReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	content, warnings, err := file.GetService().ExportBinary(c, req)
+	if err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	format, _ := formatx.ParseFormat(req.Format)
+	filename := req.Filename
+	if filename == "" {
+		filename = "export." + format.Ext()
+	}
+	if len(warnings) > 0 {
+		c.Header(warningsTrailer, warningsHeader(warnings))
+	}
+	base.FileResult(c, consts.APIVersionV1, &base.FileDownloadConfig{
+		Filename:    filename,
+		ContentType: format.ContentType(),
+		Content:     content,
+	})
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/privacy"
+	"github.com/jasonlabz/json-converter-server/server/service/privacy/body"
+)
+
+// Redact 数据脱敏
+//
+//	@Summary	按路径、键名与取值检测器（手机号、身份证号、邮箱、银行卡号、JWT）脱敏文档，支持全部、部分与哈希遮盖
+//	@Tags		隐私
+//	@Accept		json
+//	@Produce	json
+//	@Param		redact_info	body	body.RedactReqDto	true	"待脱敏内容与规则"
+//	@Router		/api/v1/privacy/redact [post]
+func Redact(c *gin.Context) {
+	req := &body.RedactReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := privacy.GetService().Redact(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// Pseudonymize 数据假名化
+//
+//	@Summary	将命中路径的姓名、邮箱、手机号、证件号、地址、数字等替换为保持格式的假数据，相同取值得到相同假数据
+//	@Tags		隐私
+//	@Accept		json
+//	@Produce	json
+//	@Param		pseudonymize_info	body	body.PseudonymizeReqDto	true	"待假名化内容与规则"
+//	@Router		/api/v1/privacy/pseudonymize [post]
+func Pseudonymize(c *gin.Context) {
+	req := &body.PseudonymizeReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := privacy.GetService().Pseudonymize(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// ScanSecrets 密钥扫描
+//
+//	@Summary	扫描文档中的私钥、AWS/GCP 密钥、JWT、连接串密码、敏感键名与高熵字符串，返回路径、行号、规则与置信度
+//	@Tags		隐私
+//	@Accept		json
+//	@Produce	json
+//	@Param		scan_info	body	body.SecretScanReqDto	true	"待扫描内容"
+//	@Router		/api/v1/privacy/scan-secrets [post]
+func ScanSecrets(c *gin.Context) {
+	req := &body.SecretScanReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := privacy.GetService().ScanSecrets(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
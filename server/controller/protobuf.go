@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/protobuf"
+	"github.com/jasonlabz/json-converter-server/server/service/protobuf/body"
+)
+
+const protobufContentType = "application/x-protobuf"
+
+// DecodeProtobuf protobuf 解码
+//
+//	@Summary	protobuf 二进制按 .proto 或描述集解码为 proto3 JSON，raw 模式不需要 schema
+//	@Tags		Protobuf
+//	@Accept		multipart/form-data
+//	@Produce	json
+//	@Param		protos				formData	file		false	".proto 文件，可多个"
+//	@Param		descriptor_set		formData	file		false	"FileDescriptorSet"
+//	@Param		payload_file		formData	file		false	"待解码的二进制文件"
+//	@Param		proto				formData	string		false	".proto 源文本"
+//	@Param		idl_files			formData	[]string	false	"idl 目录下的 .proto 文件"
+//	@Param		message				formData	string		false	"消息类型"
+//	@Param		payload				formData	string		false	"base64 或 hex 编码的数据"
+//	@Param		encoding			formData	string		false	"payload 编码: base64|hex"
+//	@Param		raw					formData	bool		false	"不使用 schema 解码"
+//	@Param		use_proto_names		formData	bool		false	"使用 .proto 中的字段名"
+//	@Param		emit_unpopulated	formData	bool		false	"输出未赋值的字段"
+//	@Router		/api/v1/protobuf/decode [post]
+func DecodeProtobuf(c *gin.Context) {
+	req := &body.ProtobufDecodeReqDto{}
+	if err := c.ShouldBind(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := protobuf.GetService().Decode(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// EncodeProtobuf protobuf 编码
+//
+//	@Summary	proto3 JSON 按 .proto 或描述集编码为 protobuf 二进制，默认返回 base64，download=true 时下载文件
+//	@Tags		Protobuf
+//	@Accept		multipart/form-data
+//	@Produce	json
+//	@Param		protos			formData	file		false	".proto 文件，可多个"
+//	@Param		descriptor_set	formData	file		false	"FileDescriptorSet"
+//	@Param		proto			formData	string		false	".proto 源文本"
+//	@Param		idl_files		formData	[]string	false	"idl 目录下的 .proto 文件"
+//	@Param		message			formData	string		true	"消息类型"
+//	@Param		content			formData	string		true	"proto3 JSON"
+//	@Param		discard_unknown	formData	bool		false	"忽略 schema 中不存在的字段"
+//	@Param		download		formData	bool		false	"下载二进制文件"
+//	@Router		/api/v1/protobuf/encode [post]
+func EncodeProtobuf(c *gin.Context) {
+	req := &body.ProtobufEncodeReqDto{}
+	if err := c.ShouldBind(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := protobuf.GetService().Encode(c, req)
+	if err != nil || !req.Download {
+		base.JsonResult(c, consts.APIVersionV1, res, err)
+		return
+	}
+	base.FileResult(c, consts.APIVersionV1, &base.FileDownloadConfig{
+		Filename:    res.Message + ".bin",
+		ContentType: protobufContentType,
+		Content:     res.Data,
+	})
+}
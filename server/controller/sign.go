@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/sign"
+	"github.com/jasonlabz/json-converter-server/server/service/sign/body"
+)
+
+// Digest 计算摘要与签名
+//
+//	@Summary	按 RFC 8785 规范化 JSON 后计算 SHA-256/SHA-512 摘要，并使用配置的密钥计算 HMAC 签名
+//	@Tags		签名
+//	@Accept		json
+//	@Produce	json
+//	@Param		digest_info	body	body.DigestReqDto	true	"待签名内容"
+//	@Router		/api/v1/sign/digest [post]
+func Digest(c *gin.Context) {
+	req := &body.DigestReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := sign.GetService().Digest(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// Verify 校验签名
+//
+//	@Summary	按 RFC 8785 规范化 JSON 后校验 HMAC 签名
+//	@Tags		签名
+//	@Accept		json
+//	@Produce	json
+//	@Param		verify_info	body	body.VerifyReqDto	true	"待校验内容与签名"
+//	@Router		/api/v1/sign/verify [post]
+func Verify(c *gin.Context) {
+	req := &body.VerifyReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := sign.GetService().Verify(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jasonlabz/potato/consts"
+
+	base "github.com/jasonlabz/json-converter-server/common/ginx"
+	"github.com/jasonlabz/json-converter-server/server/service/transform"
+	"github.com/jasonlabz/json-converter-server/server/service/transform/body"
+)
+
+// DeepDecode 深度解码
+//
+//	@Summary	递归展开字段值中嵌入的 JSON 字符串、base64、URL 查询串与百分号编码，并返回每个路径上的解码记录
+//	@Tags		数据处理
+//	@Accept		json
+//	@Produce	json
+//	@Param		decode_info	body	body.DeepDecodeReqDto	true	"待解码内容"
+//	@Router		/api/v1/transform/deep-decode [post]
+func DeepDecode(c *gin.Context) {
+	req := &body.DeepDecodeReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := transform.GetService().DeepDecode(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// ReEncode 还原编码
+//
+//	@Summary	按深度解码返回的解码记录，将展开的结构还原为原始的编码表示
+//	@Tags		数据处理
+//	@Accept		json
+//	@Produce	json
+//	@Param		encode_info	body	body.ReEncodeReqDto	true	"展开后的内容与解码记录"
+//	@Router		/api/v1/transform/re-encode [post]
+func ReEncode(c *gin.Context) {
+	req := &body.ReEncodeReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := transform.GetService().ReEncode(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
+
+// Rekey 键名转换
+//
+//	@Summary	将全部键或指定路径下的键改写为大驼峰、小驼峰、蛇形或短横线格式，缩略词保持全大写，并报告映射到同一新键名的冲突
+//	@Tags		数据处理
+//	@Accept		json
+//	@Produce	json
+//	@Param		rekey_info	body	body.RekeyReqDto	true	"待改写键名的内容与目标命名格式"
+//	@Router		/api/v1/transform/rekey [post]
+func Rekey(c *gin.Context) {
+	req := &body.RekeyReqDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		base.ResponseErr(c, consts.APIVersionV1, err)
+		return
+	}
+	res, err := transform.GetService().Rekey(c, req)
+	base.JsonResult(c, consts.APIVersionV1, res, err)
+}
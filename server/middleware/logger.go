@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,7 @@ import (
 	"github.com/jasonlabz/potato/log"
 	"github.com/jasonlabz/potato/utils"
 
+	"github.com/jasonlabz/json-converter-server/common/redactx"
 	"github.com/jasonlabz/json-converter-server/global/resource"
 )
 
@@ -19,37 +21,116 @@ const (
 	requestBodyMaxLen = 204800
 )
 
+// LogOptions 请求日志选项
+type LogOptions struct {
+	bodySamples map[string]int
+	redactor    *redactx.Redactor
+}
+
+type LogOption func(options *LogOptions)
+
+// WithBodySample 指定路径前缀的请求只记录请求体与响应体的前 maxLen 字节，maxLen 为 0 时不记录，
+// 用于流式接口，避免为记录日志而缓存整个请求体
+func WithBodySample(maxLen int, pathPrefixes ...string) LogOption {
+	return func(options *LogOptions) {
+		for _, prefix := range pathPrefixes {
+			options.bodySamples[prefix] = maxLen
+		}
+	}
+}
+
+// WithRedactor 记录前对请求体与响应体脱敏，redactor 为 nil 时原样记录
+func WithRedactor(redactor *redactx.Redactor) LogOption {
+	return func(options *LogOptions) {
+		options.redactor = redactor
+	}
+}
+
+// redact 脱敏日志内容
+func (o *LogOptions) redact(data []byte) string {
+	if o.redactor == nil || len(data) == 0 {
+		return string(data)
+	}
+	return string(o.redactor.RedactBytes(data))
+}
+
+// bodyLogLen 请求路径对应的日志记录长度
+func (o *LogOptions) bodyLogLen(path string) int {
+	maxLen, matched := requestBodyMaxLen, ""
+	for prefix, sample := range o.bodySamples {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			maxLen, matched = sample, prefix
+		}
+	}
+	return maxLen
+}
+
+// BodyLog 记录响应体，最多保留 maxLen 字节
 type BodyLog struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body   *bytes.Buffer
+	maxLen int
+	size   int
 }
 
-func (bl BodyLog) Header() http.Header {
+func (bl *BodyLog) Header() http.Header {
 	return bl.ResponseWriter.Header()
 }
 
-func (bl BodyLog) Write(b []byte) (int, error) {
-	bl.body.Write(b)
+func (bl *BodyLog) Write(b []byte) (int, error) {
+	if remain := bl.maxLen - bl.body.Len(); remain > 0 {
+		bl.body.Write(b[:min(remain, len(b))])
+	}
+	bl.size += len(b)
 	return bl.ResponseWriter.Write(b)
 }
 
-func (bl BodyLog) WriteHeader(statusCode int) {
+func (bl *BodyLog) WriteString(s string) (int, error) {
+	return bl.Write([]byte(s))
+}
+
+func (bl *BodyLog) WriteHeader(statusCode int) {
 	bl.ResponseWriter.WriteHeader(statusCode)
 }
 
-func RequestMiddleware() gin.HandlerFunc {
+// Unwrap 供 http.ResponseController 访问底层连接
+func (bl *BodyLog) Unwrap() http.ResponseWriter {
+	if unwrapper, ok := bl.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); ok {
+		return unwrapper.Unwrap()
+	}
+	return bl.ResponseWriter
+}
+
+// logged 记录的响应体，超出部分以省略号标记
+func (bl *BodyLog) logged() []byte {
+	if bl.size > bl.body.Len() {
+		return append(bl.body.Bytes(), []byte(" ......")...)
+	}
+	return bl.body.Bytes()
+}
+
+func RequestMiddleware(opts ...LogOption) gin.HandlerFunc {
+	options := &LogOptions{bodySamples: make(map[string]int)}
+	for _, opt := range opts {
+		opt(options)
+	}
 	return func(c *gin.Context) {
 		traceID := utils.StringValue(c.Value(consts.ContextTraceID))
 		if traceID != "" {
 			c.Writer.Header().Set(consts.HeaderRequestID, traceID)
 		}
 
+		maxLen := options.bodyLogLen(c.Request.URL.Path)
+		// 只预读需要记录的部分，剩余内容仍由原请求体流式读取
 		var requestBodyBytes []byte
-		if c.Request.Body != nil {
-			requestBodyBytes, _ = io.ReadAll(c.Request.Body)
+		if c.Request.Body != nil && maxLen > 0 {
+			requestBodyBytes, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxLen)+1))
+			c.Request.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(requestBodyBytes), c.Request.Body),
+				Closer: c.Request.Body,
+			}
 		}
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBodyBytes))
-		bodyLog := &BodyLog{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		bodyLog := &BodyLog{body: bytes.NewBufferString(""), ResponseWriter: c.Writer, maxLen: maxLen}
 		c.Writer = bodyLog
 
 		start := time.Now() // Start timer
@@ -58,7 +139,7 @@ func RequestMiddleware() gin.HandlerFunc {
 			log.String("client_ip", c.ClientIP()),
 			log.Int64("content_length", c.Request.ContentLength),
 			log.String("agent", c.Request.UserAgent()),
-			log.String("request_body", string(logBytes(requestBodyBytes, requestBodyMaxLen))),
+			log.String("request_body", options.redact(logBytes(requestBodyBytes, maxLen))),
 			log.String("method", c.Request.Method),
 			log.String("path", c.Request.URL.Path))
 
@@ -67,12 +148,18 @@ func RequestMiddleware() gin.HandlerFunc {
 		resource.Logger.Info(c, "	[GIN] response",
 			log.Int("status_code", c.Writer.Status()),
 			log.String("error_message", c.Errors.ByType(gin.ErrorTypePrivate).String()),
-			log.String("response_body", string(logBytes(bodyLog.body.Bytes(), requestBodyMaxLen))),
+			log.String("response_body", options.redact(bodyLog.logged())),
+			log.Int("response_size", bodyLog.size),
 			log.String("path", c.Request.URL.Path),
 			log.String("cost", fmt.Sprintf("%dms", time.Since(start).Milliseconds())))
 	}
 }
 
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 func logBytes(src []byte, maxLen int) []byte {
 	srcLen := len(src)
 	length := srcLen
@@ -1,17 +1,26 @@
 package routers
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
 	knife4go "github.com/jasonlabz/knife4go"
 	"github.com/jasonlabz/potato/configx"
-	"github.com/jasonlabz/potato/middleware"
+	"github.com/jasonlabz/potato/log"
+	potatomw "github.com/jasonlabz/potato/middleware"
 
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/redactx"
 	_ "github.com/jasonlabz/json-converter-server/docs"
+	"github.com/jasonlabz/json-converter-server/global/resource"
 	"github.com/jasonlabz/json-converter-server/server/controller"
+	"github.com/jasonlabz/json-converter-server/server/middleware"
 )
 
+// streamBodySampleLen 流式接口只记录请求体与响应体的前 1KB
+const streamBodySampleLen = 1024
+
 // InitApiRouter 封装路由
 func InitApiRouter() *gin.Engine {
 	router := gin.New()
@@ -40,7 +49,12 @@ func InitApiRouter() *gin.Engine {
 
 	// 中间件拦截器
 	groupMiddleware(apiGroup,
-		middleware.RecoveryLog(true), middleware.SetContext(), middleware.RequestMiddleware())
+		potatomw.RecoveryLog(true), potatomw.SetContext(),
+		middleware.RequestMiddleware(middleware.WithBodySample(streamBodySampleLen,
+			apiGroup.BasePath()+"/v1/stream/", apiGroup.BasePath()+"/v1/convert/ndjson"),
+			// 隐私、加解密与签名接口的请求体含密钥、盐与明文，不记录请求体与响应体
+			middleware.WithBodySample(0, apiGroup.BasePath()+"/v1/privacy/", apiGroup.BasePath()+"/v1/crypto/", apiGroup.BasePath()+"/v1/sign/"),
+			middleware.WithRedactor(logRedactor())))
 
 	// v1 group api
 	v1Group := apiGroup.Group("/v1")
@@ -49,6 +63,35 @@ func InitApiRouter() *gin.Engine {
 	return router
 }
 
+// logRedactor 按 redaction 配置构建请求日志脱敏器，未启用时返回 nil，配置有误时退回内置规则。
+// 键名与路径命中的值是密码、令牌等，部分遮盖会泄露长度与首尾字符，因此整体遮盖，style 只用于检测器
+func logRedactor() *redactx.Redactor {
+	conf := bootstrap.GetConfig().Redaction
+	if !conf.Enable {
+		return nil
+	}
+	keys, detectors := conf.Keys, conf.Detectors
+	if len(keys) == 0 {
+		keys = redactx.DefaultKeys
+	}
+	if len(detectors) == 0 {
+		for _, d := range redactx.AllDetectors {
+			detectors = append(detectors, string(d))
+		}
+	}
+	style := redactx.Style(conf.Style)
+	redactor, err := redactx.New([]redactx.Rule{
+		{Name: "log-fields", Paths: conf.Paths, Keys: keys, Style: redactx.StyleFull},
+		{Name: "log-detectors", Detectors: detectors, Style: style},
+	})
+	if err != nil {
+		resource.Logger.Warn(context.Background(), "invalid redaction config, using default rules",
+			log.String("error_message", err.Error()))
+		redactor, _ = redactx.New(nil)
+	}
+	return redactor
+}
+
 func rootMiddleware(r *gin.Engine, middlewares ...gin.HandlerFunc) {
 	r.Use(middlewares...)
 }
@@ -68,4 +111,53 @@ func registerBaseAPI(router *gin.RouterGroup) {}
 // 注册組路由 http://ip:port/server_name/api/v1/**
 func registerV1GroupAPI(router *gin.RouterGroup) {
 	// v1.RegisterSchedulerManagerGroup(router)
+	fileGroup := router.Group("/file")
+	{
+		fileGroup.POST("/upload", controller.UploadFile)
+		fileGroup.POST("/export/xlsx", controller.ExportXlsx)
+		fileGroup.POST("/export/binary", controller.ExportBinary)
+	}
+	router.POST("/convert", controller.Convert)
+	router.POST("/convert/ndjson", controller.ConvertNDJSON)
+	transformGroup := router.Group("/transform")
+	{
+		transformGroup.POST("/deep-decode", controller.DeepDecode)
+		transformGroup.POST("/re-encode", controller.ReEncode)
+		transformGroup.POST("/rekey", controller.Rekey)
+	}
+	codegenGroup := router.Group("/codegen")
+	{
+		codegenGroup.GET("/naming", controller.Naming)
+		codegenGroup.POST("/pinyin", controller.Pinyin)
+		codegenGroup.POST("/generate", controller.GenerateCode)
+		codegenGroup.GET("/templates", controller.CodeTemplates)
+		codegenGroup.POST("/templates", controller.SaveCodeTemplate)
+		codegenGroup.POST("/templates/get", controller.CodeTemplate)
+		codegenGroup.POST("/templates/delete", controller.DeleteCodeTemplate)
+	}
+	signGroup := router.Group("/sign")
+	{
+		signGroup.POST("/digest", controller.Digest)
+		signGroup.POST("/verify", controller.Verify)
+	}
+	cryptoGroup := router.Group("/crypto")
+	{
+		cryptoGroup.POST("/encrypt", controller.EncryptFields)
+		cryptoGroup.POST("/decrypt", controller.DecryptFields)
+	}
+	privacyGroup := router.Group("/privacy")
+	{
+		privacyGroup.POST("/redact", controller.Redact)
+		privacyGroup.POST("/pseudonymize", controller.Pseudonymize)
+		privacyGroup.POST("/scan-secrets", controller.ScanSecrets)
+	}
+	protobufGroup := router.Group("/protobuf")
+	{
+		protobufGroup.POST("/decode", controller.DecodeProtobuf)
+		protobufGroup.POST("/encode", controller.EncodeProtobuf)
+	}
+	streamGroup := router.Group("/stream")
+	{
+		streamGroup.POST("/convert", controller.StreamConvert)
+	}
 }
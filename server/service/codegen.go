@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/codegen/body"
+)
+
+type CodegenService interface {
+	Naming(ctx context.Context) (*body.NamingResDto, error)
+	Pinyin(ctx context.Context, req *body.PinyinReqDto) (*body.PinyinResDto, error)
+	Generate(ctx context.Context, req *body.GenerateReqDto) (*body.GenerateResDto, error)
+	Templates(ctx context.Context) (*body.TemplatesResDto, error)
+	Template(ctx context.Context, req *body.TemplateReqDto) (*body.TemplateResDto, error)
+	SaveTemplate(ctx context.Context, req *body.SaveTemplateReqDto) (*body.TemplatesResDto, error)
+	DeleteTemplate(ctx context.Context, req *body.TemplateReqDto) (*body.TemplatesResDto, error)
+}
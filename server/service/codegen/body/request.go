@@ -0,0 +1,36 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/codegenx"
+
+type PinyinReqDto struct {
+	Keys  []string `json:"keys" binding:"required"` // 含汉字的 JSON 键
+	Style string   `json:"style"`                   // 转写方式: full 全拼|initials 首字母，默认 full
+}
+
+type GenerateReqDto struct {
+	Format          string          `json:"format"`                     // 内容格式，默认 json
+	Content         string          `json:"content" binding:"required"` // 推断类型的内容，二进制格式为 base64 编码
+	DuplicateKeys   string          `json:"duplicate_keys"`             // 重复键处理方式: error|keep-first|keep-last|merge-into-array，默认 TOML 报错、其他格式保留最后一个值
+	Lang            string          `json:"lang" binding:"required"`    // 目标语言: go|typescript|java|python|kotlin|rust，决定字段类型的写法
+	Template        string          `json:"template"`                   // 模板名：内置模板与语言同名，为空时使用 lang 的内置模板
+	TemplateContent string          `json:"template_content"`           // 模板内容，不为空时忽略 template
+	StructName      string          `json:"struct_name"`                // 根类型名，默认 Response
+	Case            string          `json:"case"`                       // 字段名命名格式: pascal|camel|snake|kebab，默认 pascal
+	Pinyin          string          `json:"pinyin"`                     // 中文键名: full 全拼|initials 首字母，为空时原样保留
+	PinyinComment   bool            `json:"pinyin_comment"`             // 转为拼音的字段以原键作为注释
+	GoTags          codegenx.GoTags `json:"go_tags"`                    // Go 结构体标签
+	Inline          bool            `json:"inline"`                     // Go 的嵌套对象内联
+	DetectTime      bool            `json:"detect_time"`                // 识别时间字段
+	MergeArrays     bool            `json:"merge_arrays"`               // 数组中的对象按所有元素的字段并集推断
+	Comments        bool            `json:"comments"`                   // 使用原文中的注释
+	WithModel       bool            `json:"with_model"`                 // 同时返回模板使用的类型树，便于编写模板
+}
+
+type TemplateReqDto struct {
+	Name string `json:"name" binding:"required"` // 模板名
+}
+
+type SaveTemplateReqDto struct {
+	Name    string `json:"name" binding:"required"`    // 模板名：字母、数字、_、.、-，不能与内置模板重名，同名模板被覆盖
+	Content string `json:"content" binding:"required"` // Go text/template 模板内容
+}
@@ -0,0 +1,31 @@
+package body
+
+import (
+	"github.com/jasonlabz/json-converter-server/common/codegenx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+)
+
+type NamingResDto struct {
+	transformx.NamingDictionary // 合并内置规则后的命名词典
+}
+
+type PinyinResDto struct {
+	Names map[string]string `json:"names"` // 原键 → 转写结果，单词之间以 _ 分隔，再按命名格式改写
+}
+
+type GenerateResDto struct {
+	Code     string          `json:"code"`            // 生成的代码
+	Template string          `json:"template"`        // 使用的模板名，模板内容由请求传入时为空
+	Model    *codegenx.Model `json:"model,omitempty"` // 模板使用的类型树，with_model 为 true 时返回
+}
+
+type TemplatesResDto struct {
+	Builtin []string `json:"builtin"` // 内置模板，与语言同名
+	Custom  []string `json:"custom"`  // 上传的模板
+}
+
+type TemplateResDto struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,183 @@
+package codegen
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/codegenx"
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/pinyinx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/codegen/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.CodegenService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{store: codegenx.NewTemplateStore(bootstrap.GetConfig().GetTemplateDir())}
+	})
+
+	return svc
+}
+
+type Service struct {
+	store *codegenx.TemplateStore
+}
+
+// Naming 返回合并内置缩略词与保留字后的命名词典，供各语言的代码生成统一使用
+func (s Service) Naming(ctx context.Context) (*body.NamingResDto, error) {
+	return &body.NamingResDto{NamingDictionary: *Dictionary()}, nil
+}
+
+// Pinyin 将键中的汉字按内嵌字典转为拼音，供生成代码的字段名使用，标签中仍为原键
+func (s Service) Pinyin(ctx context.Context, req *body.PinyinReqDto) (*body.PinyinResDto, error) {
+	style, err := pinyinx.ParseStyle(req.Style)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(req.Keys))
+	for _, key := range req.Keys {
+		names[key] = pinyinx.Transliterate(key, style)
+	}
+	return &body.PinyinResDto{Names: names}, nil
+}
+
+// Generate 从内容推断类型树，按内置或上传的模板生成代码；命名规则与前端生成一致
+func (s Service) Generate(ctx context.Context, req *body.GenerateReqDto) (*body.GenerateResDto, error) {
+	lang, err := codegenx.ParseLang(req.Lang)
+	if err != nil {
+		return nil, err
+	}
+	opts := &codegenx.Options{
+		Lang:          lang,
+		Name:          req.StructName,
+		Case:          transformx.CasePascal,
+		PinyinComment: req.PinyinComment,
+		Dictionary:    Dictionary(),
+		GoTags:        req.GoTags,
+		Inline:        req.Inline,
+		DetectTime:    req.DetectTime,
+		MergeArrays:   req.MergeArrays,
+		Comments:      req.Comments,
+	}
+	if req.Case != "" {
+		if opts.Case, err = transformx.ParseKeyCase(req.Case); err != nil {
+			return nil, err
+		}
+	}
+	if req.Pinyin != "" {
+		if opts.Pinyin, err = pinyinx.ParseStyle(req.Pinyin); err != nil {
+			return nil, err
+		}
+	}
+	duplicates, err := formatx.ParseDuplicateKeyPolicy(req.DuplicateKeys)
+	if err != nil {
+		return nil, err
+	}
+	name, text, err := s.resolveTemplate(lang, req)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, &formatx.Options{DuplicateKeys: duplicates})
+	if err != nil {
+		return nil, err
+	}
+	model, err := codegenx.Build(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	code, err := codegenx.Render(model, name, text)
+	if err != nil {
+		return nil, err
+	}
+	res := &body.GenerateResDto{Code: code}
+	if req.TemplateContent == "" {
+		res.Template = name
+	}
+	if req.WithModel {
+		res.Model = model
+	}
+	return res, nil
+}
+
+// resolveTemplate 请求中的模板内容优先，其次为模板名，都为空时使用该语言的内置模板
+func (s Service) resolveTemplate(lang string, req *body.GenerateReqDto) (string, string, error) {
+	if req.TemplateContent != "" {
+		return "custom", req.TemplateContent, nil
+	}
+	name := req.Template
+	if name == "" {
+		name = lang
+	}
+	if codegenx.IsBuiltin(name) {
+		text, err := codegenx.Builtin(name)
+		return name, text, err
+	}
+	text, err := s.store.Get(name)
+	return name, text, err
+}
+
+// Templates 列出内置模板与上传的模板
+func (s Service) Templates(ctx context.Context) (*body.TemplatesResDto, error) {
+	custom, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	if custom == nil {
+		custom = []string{}
+	}
+	return &body.TemplatesResDto{Builtin: codegenx.BuiltinNames(), Custom: custom}, nil
+}
+
+// Template 返回模板内容，内置模板可作为编写新模板的起点
+func (s Service) Template(ctx context.Context, req *body.TemplateReqDto) (*body.TemplateResDto, error) {
+	if codegenx.IsBuiltin(req.Name) {
+		text, err := codegenx.Builtin(req.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &body.TemplateResDto{Name: req.Name, Builtin: true, Content: text}, nil
+	}
+	text, err := s.store.Get(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &body.TemplateResDto{Name: req.Name, Content: text}, nil
+}
+
+// SaveTemplate 校验模板的语法并以示例数据试执行，通过后按名称保存
+func (s Service) SaveTemplate(ctx context.Context, req *body.SaveTemplateReqDto) (*body.TemplatesResDto, error) {
+	if err := s.store.Save(req.Name, req.Content); err != nil {
+		return nil, err
+	}
+	return s.Templates(ctx)
+}
+
+// DeleteTemplate 删除上传的模板，内置模板不能删除
+func (s Service) DeleteTemplate(ctx context.Context, req *body.TemplateReqDto) (*body.TemplatesResDto, error) {
+	if err := s.store.Delete(req.Name); err != nil {
+		return nil, err
+	}
+	return s.Templates(ctx)
+}
+
+// Dictionary 按 naming.yaml 构建命名词典
+func Dictionary() *transformx.NamingDictionary {
+	conf := bootstrap.GetNamingConfig()
+	overrides := make(map[string]string, len(conf.Overrides))
+	for _, o := range conf.Overrides {
+		overrides[o.Key] = o.Name
+	}
+	reserved := make(map[string]transformx.ReservedWords, len(conf.Reserved))
+	for lang, r := range conf.Reserved {
+		reserved[lang] = transformx.ReservedWords{Words: r.Words, Escape: r.Escape}
+	}
+	return transformx.NewNamingDictionary(conf.Initialisms, overrides, reserved, conf.Struct.Prefix, conf.Struct.Suffix)
+}
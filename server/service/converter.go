@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/server/service/converter/body"
+)
+
+type ConverterService interface {
+	Convert(ctx context.Context, req *body.ConvertReqDto) (*body.ConvertResDto, error)
+	StreamConvert(ctx context.Context, src io.Reader, dst io.Writer, req *body.StreamConvertReqDto) ([]formatx.Warning, error)
+}
@@ -0,0 +1,42 @@
+package body
+
+type ConvertReqDto struct {
+	From               string   `json:"from" binding:"required"` // 源格式: json|jsonc|json5|hjson|xml|yaml|toml|ini|text|ndjson|csv|properties|env|hcl|msgpack|cbor|bson
+	To                 string   `json:"to" binding:"required"`   // 目标格式，取值同 from；json 输出不含注释，需要保留注释时使用 jsonc
+	Content            string   `json:"content"`                 // 待转换内容，二进制格式（msgpack|cbor|bson）为 base64 编码
+	Indent             int      `json:"indent"`                  // 缩进空格数，默认 2
+	OnLineError        string   `json:"on_line_error"`           // NDJSON 错误行处理方式: fail|skip，默认 fail
+	Columns            []string `json:"columns"`                 // CSV 输出列，为空时取所有字段
+	Canonical          bool     `json:"canonical"`               // 目标为 json 时输出 RFC 8785 规范形式
+	SortKeys           bool     `json:"sort_keys"`               // 对象键按字典序输出，默认保持原文顺序
+	SecretCheck        string   `json:"secret_check"`            // 疑似密钥检查: off|warn|block，默认 off
+	DuplicateKeys      string   `json:"duplicate_keys"`          // 重复键处理方式: error|keep-first|keep-last|merge-into-array，默认 TOML 报错、其他格式保留最后一个值
+	DropComments       bool     `json:"drop_comments"`           // 丢弃注释，默认 JSONC、JSON5、HJSON、YAML、TOML、INI 中的注释转换到带注释的输出
+	XMLConvention      string   `json:"xml_convention"`          // XML 映射约定: prefix|badgerfish|parker|gdata，默认 prefix
+	XMLAttrPrefix      string   `json:"xml_attr_prefix"`         // prefix 约定的属性键前缀，默认 @
+	XMLTextKey         string   `json:"xml_text_key"`            // prefix 约定的文本键，默认 #text
+	XMLForceArray      []string `json:"xml_force_array"`         // 始终输出为数组的元素路径，以 . 分隔，如 catalog.book
+	XMLRoot            string   `json:"xml_root"`                // 输出 XML 的根元素名，默认 root
+	XMLFidelity        bool     `json:"xml_fidelity"`            // 源或目标为 XML 时往返转换一次，报告丢失或改变的内容
+	YAMLDocuments      string   `json:"yaml_documents"`          // 多文档 YAML 的转换方式: array|separate，默认 array 合并为数组，separate 逐个文档转换
+	YAMLAnchors        bool     `json:"yaml_anchors"`            // 目标为 YAML 时保留锚点、别名与 << 合并键，默认展开
+	MaxAliasNodes      int      `json:"max_alias_nodes"`         // 展开 YAML 别名最多复制的节点数，默认且最大为 1000000
+	TOMLFallback       string   `json:"toml_fallback"`           // 目标为 TOML 时 null 与混合类型数组的处理方式: error|omit|string，默认 omit
+	ININestedSections  bool     `json:"ini_nested_sections"`     // INI 节名按 . 拆分为嵌套对象，如 [server.http]
+	INISubsections     bool     `json:"ini_subsections"`         // INI 识别 git config 风格的子节 [remote "origin"]
+	INIDelimiters      string   `json:"ini_delimiters"`          // INI 键值分隔符: = | : | =:，输出使用第一个，默认 =
+	INIMultiline       bool     `json:"ini_multiline"`           // INI 以空白开头的行接续上一个值
+	INIArrayKeys       bool     `json:"ini_array_keys"`          // INI key[]=value 逐个追加为数组
+	INIInlineComments  bool     `json:"ini_inline_comments"`     // INI 值之后的 ; 或 # 为行尾注释
+	INICaseInsensitive bool     `json:"ini_case_insensitive"`    // INI 节名与键名转为小写，子节名除外
+}
+
+type StreamConvertReqDto struct {
+	From          string   `form:"from"`                  // 源格式: json|ndjson|yaml，默认 ndjson
+	To            string   `form:"to" binding:"required"` // 目标格式: json|ndjson|yaml|csv
+	Indent        int      `form:"indent"`                // 缩进空格数，默认 2
+	OnLineError   string   `form:"on_line_error"`         // 错误行处理方式: fail|skip，默认 fail
+	Columns       []string `form:"columns"`               // CSV 输出列，为空时取第一条记录的字段
+	SortKeys      bool     `form:"sort_keys"`             // 对象键按字典序输出，默认保持原文顺序
+	DuplicateKeys string   `form:"duplicate_keys"`        // 重复键处理方式: error|keep-first|keep-last|merge-into-array，默认保留最后一个值
+}
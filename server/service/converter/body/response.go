@@ -0,0 +1,11 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/formatx"
+
+type ConvertResDto struct {
+	Format    string            `json:"format"`              // 目标格式
+	Content   string            `json:"content"`             // 转换结果
+	Encoding  string            `json:"encoding,omitempty"`  // 结果编码，目标为二进制格式时为 base64
+	Warnings  []formatx.Warning `json:"warnings,omitempty"`  // 转换提示，如被跳过的错误行
+	Documents []string          `json:"documents,omitempty"` // 多文档 YAML 逐个转换时各文档的结果，编码同 content
+}
@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/secretx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/converter/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.ConverterService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// Convert 在两种格式之间转换，二进制格式的输入输出使用 base64 编码
+func (s Service) Convert(ctx context.Context, req *body.ConvertReqDto) (*body.ConvertResDto, error) {
+	from, err := formatx.ParseFormat(req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := formatx.ParseFormat(req.To)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := options(req.Indent, req.OnLineError, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+	opts.Canonical = req.Canonical
+	opts.SortKeys = req.SortKeys
+	opts.DropComments = req.DropComments
+	if opts.DuplicateKeys, err = formatx.ParseDuplicateKeyPolicy(req.DuplicateKeys); err != nil {
+		return nil, err
+	}
+	if opts.XML.Convention, err = formatx.ParseXMLConvention(req.XMLConvention); err != nil {
+		return nil, err
+	}
+	opts.XML.AttrPrefix = req.XMLAttrPrefix
+	opts.XML.TextKey = req.XMLTextKey
+	opts.XML.ForceArray = req.XMLForceArray
+	opts.XML.Root = req.XMLRoot
+	opts.YAMLAnchors = req.YAMLAnchors
+	opts.MaxAliasNodes = req.MaxAliasNodes
+	if opts.TOMLFallback, err = formatx.ParseTOMLFallback(req.TOMLFallback); err != nil {
+		return nil, err
+	}
+	if opts.INI.Delimiters, err = formatx.ParseINIDelimiters(req.INIDelimiters); err != nil {
+		return nil, err
+	}
+	opts.INI.NestedSections = req.ININestedSections
+	opts.INI.Subsections = req.INISubsections
+	opts.INI.Multiline = req.INIMultiline
+	opts.INI.ArrayKeys = req.INIArrayKeys
+	opts.INI.InlineComments = req.INIInlineComments
+	opts.INI.CaseInsensitive = req.INICaseInsensitive
+	separate := false
+	switch req.YAMLDocuments {
+	case "", "array":
+	case "separate":
+		separate = true
+	default:
+		return nil, fmt.Errorf("invalid yaml_documents: %q, expected array or separate", req.YAMLDocuments)
+	}
+	policy, err := secretx.ParsePolicy(req.SecretCheck)
+	if err != nil {
+		return nil, err
+	}
+	data, err := formatx.DecodeContent(from, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	var documents [][]byte
+	var doc *formatx.Document
+	if separate {
+		documents, doc, err = formatx.ConvertDocuments(from, to, data, opts)
+		content = joinDocuments(to, documents)
+	} else {
+		content, doc, err = formatx.Convert(from, to, data, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	secretWarnings, err := policy.Check(doc, data)
+	if err != nil {
+		return nil, err
+	}
+	warnings := append(doc.Warnings, secretWarnings...)
+	if req.XMLFidelity && (from == formatx.FormatXML || to == formatx.FormatXML) && len(documents) <= 1 {
+		fidelity, err := formatx.XMLFidelity(from, to, data, doc, content, opts)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, fidelity...)
+	}
+	res := &body.ConvertResDto{
+		Format:   string(to),
+		Content:  formatx.EncodeContent(to, content),
+		Warnings: warnings,
+	}
+	if to.IsBinary() {
+		res.Encoding = "base64"
+	}
+	if doc.Stream && separate {
+		for _, document := range documents {
+			res.Documents = append(res.Documents, formatx.EncodeContent(to, document))
+		}
+	}
+	return res, nil
+}
+
+// joinDocuments 逐个转换的结果依次拼接，YAML 以 --- 分隔；二进制格式无法拼接，只在 documents 中返回
+func joinDocuments(to formatx.Format, documents [][]byte) []byte {
+	if to.IsBinary() && len(documents) > 1 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for i, document := range documents {
+		if i > 0 && to == formatx.FormatYAML {
+			buf.WriteString("---\n")
+		}
+		buf.Write(document)
+		if len(document) > 0 && document[len(document)-1] != '\n' && i < len(documents)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// StreamConvert 逐条读取记录并写出为目标格式；NDJSON、JSON 根数组与 YAML 多文档逐条读取，其余输入整体读入且受单条记录大小上限限制
+func (s Service) StreamConvert(ctx context.Context, src io.Reader, dst io.Writer, req *body.StreamConvertReqDto) ([]formatx.Warning, error) {
+	from := formatx.FormatNDJSON
+	if req.From != "" {
+		var err error
+		if from, err = formatx.ParseFormat(req.From); err != nil {
+			return nil, err
+		}
+	}
+	to, err := formatx.ParseFormat(req.To)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := options(req.Indent, req.OnLineError, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+	opts.SortKeys = req.SortKeys
+	if opts.DuplicateKeys, err = formatx.ParseDuplicateKeyPolicy(req.DuplicateKeys); err != nil {
+		return nil, err
+	}
+	return formatx.StreamConvert(src, from, dst, to, opts)
+}
+
+func options(indent int, onLineError string, columns []string) (*formatx.Options, error) {
+	mode := formatx.LineErrorMode(onLineError)
+	switch mode {
+	case "", formatx.LineErrorFail, formatx.LineErrorSkip:
+	default:
+		return nil, fmt.Errorf("invalid on_line_error: %q, expected fail or skip", onLineError)
+	}
+	return &formatx.Options{Indent: indent, OnLineError: mode, Columns: columns}, nil
+}
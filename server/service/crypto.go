@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/crypto/body"
+)
+
+type CryptoService interface {
+	Encrypt(ctx context.Context, req *body.FieldCryptoReqDto) (*body.FieldCryptoResDto, error)
+	Decrypt(ctx context.Context, req *body.FieldCryptoReqDto) (*body.FieldCryptoResDto, error)
+}
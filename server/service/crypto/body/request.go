@@ -0,0 +1,9 @@
+package body
+
+type FieldCryptoReqDto struct {
+	Format  string   `json:"format"`                     // 内容格式，默认 json
+	Content string   `json:"content" binding:"required"` // 待处理内容，二进制格式为 base64 编码
+	Paths   []string `json:"paths" binding:"required"`   // 字段路径或通配模式，如 $.db.password、**.secret、/items/*/token
+	Type    string   `json:"type"`                       // 加密类型: aes|des，默认 aes，使用 crypto 配置中对应类型的密钥
+	Indent  int      `json:"indent"`                     // 缩进空格数，默认 2
+}
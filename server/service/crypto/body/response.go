@@ -0,0 +1,9 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/formatx"
+
+type FieldCryptoResDto struct {
+	Content  string              `json:"content"`            // 处理后的内容，密文为 base64
+	Matched  map[string][]string `json:"matched"`            // 每个路径模式命中的字段（JSON Pointer）
+	Warnings []formatx.Warning   `json:"warnings,omitempty"` // 未命中任何字段的路径等提示
+}
@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+)
+
+// fieldCipher 字段加解密，密文格式为 随机 nonce/IV + 密文
+type fieldCipher interface {
+	seal(plaintext []byte) ([]byte, error)
+	open(data []byte) ([]byte, error)
+}
+
+// newFieldCipher 按加密类型从 crypto 配置中取密钥。
+// AES 使用 GCM 模式（16/24/32 字节密钥），DES 使用 CBC 模式与 PKCS7 填充（8 字节密钥，24 字节时为 3DES）
+func newFieldCipher(cryptoType bootstrap.CryptoType) (fieldCipher, error) {
+	key, ok := bootstrap.GetConfig().GetCryptoKey(cryptoType)
+	if !ok {
+		return nil, fmt.Errorf("no %s key is configured in the crypto section", cryptoType)
+	}
+	switch cryptoType {
+	case bootstrap.CryptoTypeAES:
+		block, err := aes.NewCipher([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid aes key: %w", err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		return gcmCipher{aead: gcm}, nil
+	case bootstrap.CryptoTypeDES:
+		var (
+			block cipher.Block
+			err   error
+		)
+		if len(key) == 24 {
+			block, err = des.NewTripleDESCipher([]byte(key))
+		} else {
+			block, err = des.NewCipher([]byte(key))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid des key: %w", err)
+		}
+		return cbcCipher{block: block}, nil
+	default:
+		return nil, fmt.Errorf("unsupported crypto type: %q, expected aes or des", cryptoType)
+	}
+}
+
+type gcmCipher struct {
+	aead cipher.AEAD
+}
+
+func (c gcmCipher) seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c gcmCipher) open(data []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(data) < size+c.aead.Overhead() {
+		return nil, errors.New("ciphertext too short")
+	}
+	plaintext, err := c.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return nil, errors.New("message authentication failed, wrong key or corrupted ciphertext")
+	}
+	return plaintext, nil
+}
+
+type cbcCipher struct {
+	block cipher.Block
+}
+
+func (c cbcCipher) seal(plaintext []byte) ([]byte, error) {
+	size := c.block.BlockSize()
+	padding := size - len(plaintext)%size
+	padded := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	data := make([]byte, size+len(padded))
+	if _, err := rand.Read(data[:size]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(c.block, data[:size]).CryptBlocks(data[size:], padded)
+	return data, nil
+}
+
+func (c cbcCipher) open(data []byte) ([]byte, error) {
+	size := c.block.BlockSize()
+	if len(data) < 2*size || len(data)%size != 0 {
+		return nil, errors.New("ciphertext is not a whole number of blocks")
+	}
+	plaintext := make([]byte, len(data)-size)
+	cipher.NewCBCDecrypter(c.block, data[:size]).CryptBlocks(plaintext, data[size:])
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > size || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid padding, wrong key or corrupted ciphertext")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+)
+
+func newGCM(t *testing.T, key string) fieldCipher {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatalf("aes key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("gcm: %v", err)
+	}
+	return gcmCipher{aead: aead}
+}
+
+func newCBC(t *testing.T, key string) fieldCipher {
+	t.Helper()
+	var (
+		block cipher.Block
+		err   error
+	)
+	if len(key) == 24 {
+		block, err = des.NewTripleDESCipher([]byte(key))
+	} else {
+		block, err = des.NewCipher([]byte(key))
+	}
+	if err != nil {
+		t.Fatalf("des key: %v", err)
+	}
+	return cbcCipher{block: block}
+}
+
+// 各种长度的明文加密后都能解密还原，同一明文每次加密的结果不同
+func TestCipherRoundTrip(t *testing.T) {
+	ciphers := map[string]fieldCipher{
+		"aes-128": newGCM(t, "0123456789abcdef"),
+		"aes-256": newGCM(t, "0123456789abcdef0123456789abcdef"),
+		"des":     newCBC(t, "01234567"),
+		"3des":    newCBC(t, "0123456789abcdef01234567"),
+	}
+	for name, c := range ciphers {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{0, 1, 7, 8, 9, 16, 100} {
+				plaintext := bytes.Repeat([]byte{'x'}, size)
+				sealed, err := c.seal(plaintext)
+				if err != nil {
+					t.Fatalf("seal %d bytes: %v", size, err)
+				}
+				again, err := c.seal(plaintext)
+				if err != nil {
+					t.Fatalf("seal %d bytes: %v", size, err)
+				}
+				if bytes.Equal(sealed, again) {
+					t.Errorf("%d bytes: ciphertext should differ between calls", size)
+				}
+				opened, err := c.open(sealed)
+				if err != nil {
+					t.Fatalf("open %d bytes: %v", size, err)
+				}
+				if !bytes.Equal(opened, plaintext) {
+					t.Errorf("%d bytes: got %q", size, opened)
+				}
+			}
+		})
+	}
+}
+
+// 篡改或长度不足的密文、AES 使用错误的密钥时解密失败；CBC 没有认证，错误的密钥约有 1/256 的概率恰好得到合法填充，不作为用例
+func TestCipherOpenErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		seal  fieldCipher
+		open  fieldCipher
+		alter func([]byte) []byte
+	}{
+		{"aes tampered", newGCM(t, "0123456789abcdef"), newGCM(t, "0123456789abcdef"), func(data []byte) []byte {
+			data[len(data)-1] ^= 1
+			return data
+		}},
+		{"aes wrong key", newGCM(t, "0123456789abcdef"), newGCM(t, "fedcba9876543210"), nil},
+		{"aes too short", newGCM(t, "0123456789abcdef"), newGCM(t, "0123456789abcdef"), func(data []byte) []byte {
+			return data[:10]
+		}},
+		{"des partial block", newCBC(t, "01234567"), newCBC(t, "01234567"), func(data []byte) []byte {
+			return data[:len(data)-1]
+		}},
+		{"des iv only", newCBC(t, "01234567"), newCBC(t, "01234567"), func(data []byte) []byte {
+			return data[:8]
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sealed, err := c.seal.seal([]byte(`{"card":"6222020200112233445"}`))
+			if err != nil {
+				t.Fatalf("seal: %v", err)
+			}
+			if c.alter != nil {
+				sealed = c.alter(sealed)
+			}
+			if opened, err := c.open.open(sealed); err == nil {
+				t.Errorf("expected error, got %q", opened)
+			}
+		})
+	}
+}
+
+// 加密再解密得到相同类型的值，形如数字、布尔的字符串仍为字符串
+func TestValueRoundTrip(t *testing.T) {
+	c := newGCM(t, "0123456789abcdef")
+	doc, err := formatx.Parse(formatx.FormatJSON, []byte(`{"pin": "12345678", "flag": "true", "empty": "", "n": 12345678,
+		"big": 9007199254740993, "ok": true, "none": null, "obj": {"b": 1, "a": [1, "x"]}}`), nil)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	record := doc.Ordered(nil).(*formatx.Record)
+	for _, key := range record.Keys {
+		sealed, err := encryptValue(c, "/"+key, record.Values[key])
+		if err != nil {
+			t.Fatalf("%s: encrypt: %v", key, err)
+		}
+		opened, err := decryptValue(c, "/"+key, sealed)
+		if err != nil {
+			t.Fatalf("%s: decrypt: %v", key, err)
+		}
+		want, _ := json.Marshal(record.Values[key])
+		got, _ := json.Marshal(opened)
+		if string(got) != string(want) {
+			t.Errorf("%s: got %s, want %s", key, got, want)
+		}
+	}
+}
+
+// 其他工具加密的非 JSON 明文按字符串返回
+func TestDecryptPlainText(t *testing.T) {
+	c := newGCM(t, "0123456789abcdef")
+	sealed, err := c.seal([]byte("hello world"))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	opened, err := decryptValue(c, "/a", base64.StdEncoding.EncodeToString(sealed))
+	if err != nil || opened != "hello world" {
+		t.Errorf("got %v, %v", opened, err)
+	}
+	if _, err = decryptValue(c, "/a", 1); err == nil {
+		t.Error("expected error for non-string ciphertext")
+	}
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/crypto/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.CryptoService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// Encrypt 加密命中路径的字段值，密文以 base64 字符串写回
+func (s Service) Encrypt(ctx context.Context, req *body.FieldCryptoReqDto) (*body.FieldCryptoResDto, error) {
+	return s.rewrite(req, encryptValue)
+}
+
+// Decrypt 解密命中路径的 base64 密文，还原为加密前的值
+func (s Service) Decrypt(ctx context.Context, req *body.FieldCryptoReqDto) (*body.FieldCryptoResDto, error) {
+	return s.rewrite(req, decryptValue)
+}
+
+// encryptValue 加密值的 JSON 表示，字符串也带引号加密，解密时据此还原原类型；对象按原文的键顺序序列化
+func encryptValue(c fieldCipher, pointer string, value any) (any, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("path %s: %w", pointer, err)
+	}
+	data, err := c.seal(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("path %s: %w", pointer, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decryptValue 明文按 JSON 解析还原为加密前的值，其他工具加密的非 JSON 明文按字符串返回
+func decryptValue(c fieldCipher, pointer string, value any) (any, error) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("path %s: expected a base64 ciphertext string, got %T", pointer, value)
+	}
+	data, err := formatx.DecodeBase64(text)
+	if err != nil {
+		return nil, fmt.Errorf("path %s: ciphertext is not valid base64", pointer)
+	}
+	plaintext, err := c.open(data)
+	if err != nil {
+		return nil, fmt.Errorf("path %s: %w", pointer, err)
+	}
+	doc, err := formatx.Parse(formatx.FormatJSON, plaintext, nil)
+	if err != nil {
+		return string(plaintext), nil
+	}
+	return doc.Ordered(nil), nil
+}
+
+func (s Service) rewrite(req *body.FieldCryptoReqDto, fn func(c fieldCipher, pointer string, value any) (any, error)) (*body.FieldCryptoResDto, error) {
+	cryptoType := bootstrap.CryptoTypeAES
+	if req.Type != "" {
+		cryptoType = bootstrap.CryptoType(strings.ToLower(req.Type))
+	}
+	c, err := newFieldCipher(cryptoType)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Paths) == 0 {
+		return nil, fmt.Errorf("paths is required")
+	}
+	patterns, err := transformx.ParsePathPatterns(req.Paths)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	value, matched, err := transformx.Rewrite(doc.Value, patterns, func(pointer string, value any) (any, error) {
+		return fn(c, pointer, doc.OrderedAt(value, pointer))
+	})
+	if err != nil {
+		return nil, err
+	}
+	var warnings []formatx.Warning
+	for _, p := range patterns {
+		if len(matched[p.String()]) == 0 {
+			warnings = append(warnings, formatx.Warning{Path: p.String(), Message: "path matched no fields"})
+		}
+	}
+	content, err := formatx.Remarshal(doc, value, &formatx.Options{Indent: req.Indent})
+	if err != nil {
+		return nil, err
+	}
+	return &body.FieldCryptoResDto{Content: content, Matched: matched, Warnings: append(warnings, doc.Warnings...)}, nil
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/server/service/file/body"
+)
+
+type FileService interface {
+	Upload(ctx context.Context, fileHeader *multipart.FileHeader, req *body.FileUploadReqDto) (*body.FileUploadResDto, error)
+	ExportXlsx(ctx context.Context, req *body.XlsxExportReqDto) ([]byte, []formatx.Warning, error)
+	ExportBinary(ctx context.Context, req *body.BinaryExportReqDto) ([]byte, []formatx.Warning, error)
+}
@@ -0,0 +1,23 @@
+package body
+
+import "encoding/json"
+
+type FileUploadReqDto struct {
+	Header     string   `form:"header" json:"header"`           // 表头识别方式: auto|true|false，默认 auto
+	DateLayout string   `form:"date_layout" json:"date_layout"` // 日期单元格输出格式（Go 时间格式），为空时自动选择
+	Sheets     []string `form:"sheets" json:"sheets"`           // 仅读取指定工作表
+}
+
+type XlsxExportReqDto struct {
+	Data        json.RawMessage `json:"data" binding:"required" swaggertype:"object"` // JSON 数组，或值为数组的对象（每个键输出为一个工作表），列按字段顺序输出
+	SheetName   string          `json:"sheet_name"`                                   // data 为数组时的工作表名，默认 Sheet1
+	Filename    string          `json:"filename"`                                     // 下载文件名，默认 export.xlsx
+	SecretCheck string          `json:"secret_check"`                                 // 疑似密钥检查: off|warn|block，默认 off
+}
+
+type BinaryExportReqDto struct {
+	Format      string `json:"format" binding:"required"` // 目标格式: msgpack|cbor|bson
+	Content     string `json:"content"`                   // JSON 内容，$binary、$date 等注解对象编码为对应的二进制类型
+	Filename    string `json:"filename"`                  // 下载文件名，默认 export.<format>
+	SecretCheck string `json:"secret_check"`              // 疑似密钥检查: off|warn|block，默认 off
+}
@@ -0,0 +1,11 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/formatx"
+
+type FileUploadResDto struct {
+	Filename string            `json:"filename"`           // 文件名
+	Format   string            `json:"format"`             // 编辑器中展示的数据格式
+	Content  string            `json:"content"`            // 编辑器内容，二进制文件为转换后的 JSON
+	Sheets   []formatx.Sheet   `json:"sheets,omitempty"`   // Excel 文件的工作表数据
+	Warnings []formatx.Warning `json:"warnings,omitempty"` // 二进制文件解码提示
+}
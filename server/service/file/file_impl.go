@@ -0,0 +1,172 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/secretx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/file/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.FileService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// Upload 解析上传文件，Excel 文件每个工作表转为一组 JSON，MessagePack/CBOR/BSON 转为 JSON，文本文件原样返回并识别格式
+func (s Service) Upload(ctx context.Context, fileHeader *multipart.FileHeader, req *body.FileUploadReqDto) (*body.FileUploadResDto, error) {
+	if fileHeader == nil {
+		return nil, errors.New("no file uploaded")
+	}
+	if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".xls") {
+		return nil, errors.New("legacy .xls is not supported, please save as .xlsx")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open uploaded file error: %w", err)
+	}
+	defer file.Close()
+
+	format := formatx.FormatFromExt(fileHeader.Filename)
+	res := &body.FileUploadResDto{
+		Filename: fileHeader.Filename,
+		Format:   string(format),
+	}
+	if !format.IsBinary() {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("read uploaded file error: %w", err)
+		}
+		res.Content = string(content)
+		return res, nil
+	}
+	if format != formatx.FormatXLSX {
+		return decodeBinary(file, format, res)
+	}
+
+	sheets, err := formatx.ReadXlsx(file, fileHeader.Size, &formatx.XlsxReadOptions{
+		Header:     formatx.HeaderMode(req.Header),
+		DateLayout: req.DateLayout,
+		Sheets:     req.Sheets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	content, err := sheetsContent(sheets)
+	if err != nil {
+		return nil, err
+	}
+	res.Format = string(formatx.FormatJSON)
+	res.Content = content
+	res.Sheets = sheets
+	return res, nil
+}
+
+// ExportXlsx 将 JSON 数组（或多个数组）导出为 Excel 文件，返回文件内容与疑似密钥提示
+func (s Service) ExportXlsx(ctx context.Context, req *body.XlsxExportReqDto) ([]byte, []formatx.Warning, error) {
+	policy, err := secretx.ParsePolicy(req.SecretCheck)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := formatx.Parse(formatx.FormatJSON, req.Data, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings, err := policy.Check(doc, req.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	sheets, err := formatx.SheetsFromJSON(doc.Ordered(nil), req.SheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err = formatx.WriteXlsx(&buf, sheets); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), append(formatx.XlsxPrecisionWarnings(doc.Value), warnings...), nil
+}
+
+// ExportBinary 将 JSON 编码为 MessagePack/CBOR/BSON，返回文件内容与编码提示
+func (s Service) ExportBinary(ctx context.Context, req *body.BinaryExportReqDto) ([]byte, []formatx.Warning, error) {
+	format, err := formatx.ParseFormat(req.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format == formatx.FormatXLSX {
+		return nil, nil, errors.New("xlsx is not supported by binary export, use /api/v1/file/export/xlsx")
+	}
+	if !format.IsBinary() {
+		return nil, nil, fmt.Errorf("%s is not a binary format", format)
+	}
+	policy, err := secretx.ParsePolicy(req.SecretCheck)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, doc, err := formatx.Convert(formatx.FormatJSON, format, []byte(req.Content), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	secretWarnings, err := policy.Check(doc, []byte(req.Content))
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, append(doc.Warnings, secretWarnings...), nil
+}
+
+// decodeBinary 二进制文件解码为带类型注解的 JSON
+func decodeBinary(file io.Reader, format formatx.Format, res *body.FileUploadResDto) (*body.FileUploadResDto, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("read uploaded file error: %w", err)
+	}
+	content, doc, err := formatx.Convert(format, formatx.FormatJSON, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Format = string(formatx.FormatJSON)
+	res.Content = string(content)
+	res.Warnings = doc.Warnings
+	return res, nil
+}
+
+// sheetsContent 生成编辑器内容：单个工作表直接输出行数组，多个工作表按表名组成对象
+func sheetsContent(sheets []formatx.Sheet) (string, error) {
+	var data any = make([]any, 0)
+	if len(sheets) == 1 {
+		data = sheets[0].Rows
+	} else if len(sheets) > 1 {
+		record := formatx.NewRecord()
+		for _, sheet := range sheets {
+			record.Set(sheet.Name, sheet.Rows)
+		}
+		data = record
+	}
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
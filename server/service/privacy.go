@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/privacy/body"
+)
+
+type PrivacyService interface {
+	Redact(ctx context.Context, req *body.RedactReqDto) (*body.RedactResDto, error)
+	Pseudonymize(ctx context.Context, req *body.PseudonymizeReqDto) (*body.PseudonymizeResDto, error)
+	ScanSecrets(ctx context.Context, req *body.SecretScanReqDto) (*body.SecretScanResDto, error)
+}
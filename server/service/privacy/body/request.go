@@ -0,0 +1,26 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/redactx"
+
+type RedactReqDto struct {
+	Format  string         `json:"format"`                     // 内容格式，默认 json
+	Content string         `json:"content" binding:"required"` // 待脱敏内容，二进制格式为 base64 编码
+	Rules   []redactx.Rule `json:"rules"`                      // 脱敏规则，为空时使用内置规则（敏感键名与全部检测器）
+	Indent  int            `json:"indent"`                     // 缩进空格数，默认 2
+}
+
+type PseudonymizeReqDto struct {
+	Format    string                  `json:"format"`                     // 内容格式，默认 json
+	Content   string                  `json:"content" binding:"required"` // 待假名化内容，二进制格式为 base64 编码
+	Documents []string                `json:"documents"`                  // 同一格式的其他文档，与 content 共用映射，相同取值得到相同假数据
+	Rules     []redactx.PseudonymRule `json:"rules" binding:"required"`   // 假名化规则
+	Salt      string                  `json:"salt"`                       // 密钥盐，相同的盐在多次请求间得到相同假数据，为空时只在本次请求内一致
+	Indent    int                     `json:"indent"`                     // 缩进空格数，默认 2
+}
+
+type SecretScanReqDto struct {
+	Format        string   `json:"format"`                     // 内容格式，默认 json
+	Content       string   `json:"content" binding:"required"` // 待扫描内容，二进制格式为 base64 编码
+	Rules         []string `json:"rules"`                      // 启用的规则，默认全部
+	MinConfidence float64  `json:"min_confidence"`             // 只报告不低于该置信度的结果，默认 0
+}
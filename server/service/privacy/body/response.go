@@ -0,0 +1,21 @@
+package body
+
+import (
+	"github.com/jasonlabz/json-converter-server/common/redactx"
+	"github.com/jasonlabz/json-converter-server/common/secretx"
+)
+
+type RedactResDto struct {
+	Content    string              `json:"content"`    // 脱敏后的内容
+	Redactions []redactx.Redaction `json:"redactions"` // 脱敏记录
+}
+
+type PseudonymizeResDto struct {
+	Content           string                       `json:"content"`             // 假名化后的内容
+	Documents         []string                     `json:"documents,omitempty"` // 假名化后的其他文档
+	Pseudonymizations [][]redactx.Pseudonymization `json:"pseudonymizations"`   // 每个文档的替换记录，第一个为 content
+}
+
+type SecretScanResDto struct {
+	Findings []secretx.Finding `json:"findings"` // 疑似密钥，按行号排序
+}
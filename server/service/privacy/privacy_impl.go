@@ -0,0 +1,101 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/redactx"
+	"github.com/jasonlabz/json-converter-server/common/secretx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/privacy/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.PrivacyService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// Redact 按规则脱敏文档，保持原格式输出
+func (s Service) Redact(ctx context.Context, req *body.RedactReqDto) (*body.RedactResDto, error) {
+	redactor, err := redactx.New(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	value, redactions := redactor.Redact(doc.Value)
+	if redactions == nil {
+		redactions = make([]redactx.Redaction, 0)
+	}
+	content, err := formatx.Remarshal(doc, value, &formatx.Options{Indent: req.Indent})
+	if err != nil {
+		return nil, err
+	}
+	return &body.RedactResDto{Content: content, Redactions: redactions}, nil
+}
+
+// Pseudonymize 将命中路径的值替换为保持格式的假数据，content 与 documents 共用同一映射
+func (s Service) Pseudonymize(ctx context.Context, req *body.PseudonymizeReqDto) (*body.PseudonymizeResDto, error) {
+	pseudonymizer, err := redactx.NewPseudonymizer(req.Rules, req.Salt)
+	if err != nil {
+		return nil, err
+	}
+	res := &body.PseudonymizeResDto{}
+	for i, content := range append([]string{req.Content}, req.Documents...) {
+		doc, _, err := formatx.ParseNamed(req.Format, content, nil)
+		if err != nil {
+			if i > 0 {
+				return nil, fmt.Errorf("document %d: %w", i, err)
+			}
+			return nil, err
+		}
+		value, records := pseudonymizer.Pseudonymize(doc.Value)
+		if records == nil {
+			records = make([]redactx.Pseudonymization, 0)
+		}
+		output, err := formatx.Remarshal(doc, value, &formatx.Options{Indent: req.Indent})
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			res.Content = output
+		} else {
+			res.Documents = append(res.Documents, output)
+		}
+		res.Pseudonymizations = append(res.Pseudonymizations, records)
+	}
+	return res, nil
+}
+
+// ScanSecrets 扫描文档中的疑似密钥与凭据
+func (s Service) ScanSecrets(ctx context.Context, req *body.SecretScanReqDto) (*body.SecretScanResDto, error) {
+	opts := &secretx.Options{MinConfidence: req.MinConfidence}
+	for _, name := range req.Rules {
+		rule, err := secretx.ParseRule(name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Rules = append(opts.Rules, rule)
+	}
+	doc, data, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &body.SecretScanResDto{Findings: secretx.Scan(doc, data, opts)}, nil
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/protobuf/body"
+)
+
+type ProtobufService interface {
+	Decode(ctx context.Context, req *body.ProtobufDecodeReqDto) (*body.ProtobufDecodeResDto, error)
+	Encode(ctx context.Context, req *body.ProtobufEncodeReqDto) (*body.ProtobufEncodeResDto, error)
+}
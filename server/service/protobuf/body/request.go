@@ -0,0 +1,29 @@
+package body
+
+import "mime/multipart"
+
+type ProtobufSchemaReqDto struct {
+	Protos        []*multipart.FileHeader `form:"protos" swaggerignore:"true"`         // 上传的 .proto 文件，可多个
+	Proto         string                  `form:"proto"`                               // .proto 源文本
+	DescriptorSet *multipart.FileHeader   `form:"descriptor_set" swaggerignore:"true"` // protoc --descriptor_set_out --include_imports 生成的描述集
+	IDLFiles      []string                `form:"idl_files"`                           // idl 目录下的 .proto 文件（相对路径），均未提供时在整个目录中查找消息
+	Message       string                  `form:"message"`                             // 消息类型，全名或唯一的短名
+}
+
+type ProtobufDecodeReqDto struct {
+	ProtobufSchemaReqDto
+	Payload         string                `form:"payload"`                           // 待解码数据的文本形式
+	PayloadFile     *multipart.FileHeader `form:"payload_file" swaggerignore:"true"` // 待解码的二进制文件，优先于 payload
+	Encoding        string                `form:"encoding"`                          // payload 编码: base64|hex，默认 base64
+	Raw             bool                  `form:"raw"`                               // 不使用 schema，输出字段号与 wire type
+	UseProtoNames   bool                  `form:"use_proto_names"`                   // 使用 .proto 中的字段名
+	EmitUnpopulated bool                  `form:"emit_unpopulated"`                  // 输出未赋值的字段
+	Indent          int                   `form:"indent"`                            // 缩进空格数，默认 2
+}
+
+type ProtobufEncodeReqDto struct {
+	ProtobufSchemaReqDto
+	Content        string `form:"content" binding:"required"` // proto3 JSON
+	DiscardUnknown bool   `form:"discard_unknown"`            // 忽略 schema 中不存在的字段
+	Download       bool   `form:"download"`                   // 下载二进制文件，默认返回 base64
+}
@@ -0,0 +1,17 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/formatx"
+
+type ProtobufDecodeResDto struct {
+	Message  string            `json:"message,omitempty"`  // 消息全名，raw 模式为空
+	Content  string            `json:"content"`            // proto3 JSON，raw 模式为字段列表
+	Warnings []formatx.Warning `json:"warnings,omitempty"` // schema 中不存在的字段等提示
+}
+
+type ProtobufEncodeResDto struct {
+	Message  string `json:"message"`  // 消息全名
+	Content  string `json:"content"`  // 编码结果
+	Encoding string `json:"encoding"` // 结果编码，固定为 base64
+	Size     int    `json:"size"`     // 二进制字节数
+	Data     []byte `json:"-"`        // 二进制内容，供下载
+}
@@ -0,0 +1,224 @@
+package protobuf
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/helper"
+	"github.com/jasonlabz/json-converter-server/common/protox"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/protobuf/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.ProtobufService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// Decode 将 protobuf 二进制解码为 proto3 JSON，raw 模式下不使用 schema
+func (s Service) Decode(ctx context.Context, req *body.ProtobufDecodeReqDto) (*body.ProtobufDecodeResDto, error) {
+	payload, err := readPayload(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Raw {
+		fields, err := protox.DecodeRaw(payload)
+		if err != nil {
+			return nil, fmt.Errorf("raw decode error: %w", err)
+		}
+		content, err := json.MarshalIndent(fields, "", strings.Repeat(" ", indent(req.Indent)))
+		if err != nil {
+			return nil, err
+		}
+		return &body.ProtobufDecodeResDto{Content: string(content)}, nil
+	}
+	schema, err := loadSchema(&req.ProtobufSchemaReqDto)
+	if err != nil {
+		return nil, err
+	}
+	md, err := schema.FindMessage(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	content, warnings, err := schema.Decode(string(md.FullName()), payload, &protox.DecodeOptions{
+		Indent:          indent(req.Indent),
+		UseProtoNames:   req.UseProtoNames,
+		EmitUnpopulated: req.EmitUnpopulated,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &body.ProtobufDecodeResDto{
+		Message:  string(md.FullName()),
+		Content:  string(content),
+		Warnings: warnings,
+	}, nil
+}
+
+// Encode 将 proto3 JSON 编码为 protobuf 二进制
+func (s Service) Encode(ctx context.Context, req *body.ProtobufEncodeReqDto) (*body.ProtobufEncodeResDto, error) {
+	schema, err := loadSchema(&req.ProtobufSchemaReqDto)
+	if err != nil {
+		return nil, err
+	}
+	md, err := schema.FindMessage(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	data, err := schema.Encode(string(md.FullName()), []byte(req.Content), &protox.EncodeOptions{DiscardUnknown: req.DiscardUnknown})
+	if err != nil {
+		return nil, err
+	}
+	return &body.ProtobufEncodeResDto{
+		Message:  string(md.FullName()),
+		Content:  base64.StdEncoding.EncodeToString(data),
+		Encoding: "base64",
+		Size:     len(data),
+		Data:     data,
+	}, nil
+}
+
+func indent(n int) int {
+	if n <= 0 {
+		return 2
+	}
+	return n
+}
+
+// readPayload 读取待解码数据，上传文件优先，文本按 encoding 解码
+func readPayload(req *body.ProtobufDecodeReqDto) ([]byte, error) {
+	if req.PayloadFile != nil {
+		return readFile(req.PayloadFile)
+	}
+	switch strings.ToLower(req.Encoding) {
+	case "", "base64":
+		data, err := formatx.DecodeBase64(req.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 payload: %w", err)
+		}
+		return data, nil
+	case "hex":
+		data, err := helper.DecodeHex(req.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex payload: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("invalid encoding: %q, expected base64 or hex", req.Encoding)
+	}
+}
+
+func readFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open uploaded file error: %w", err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// loadSchema 按优先级加载 schema：描述集、上传或指定的 .proto 文件，否则在 idl 目录中查找包含该消息的文件
+func loadSchema(req *body.ProtobufSchemaReqDto) (*protox.Schema, error) {
+	if req.DescriptorSet != nil {
+		data, err := readFile(req.DescriptorSet)
+		if err != nil {
+			return nil, err
+		}
+		return protox.LoadDescriptorSet(data)
+	}
+	idlDir := bootstrap.GetConfig().GetIDLDir()
+	compiler := &protox.Compiler{
+		Sources: make(map[string]string),
+		// 与 script/generate_idl.sh 一致，client、server 子目录中的文件也可以按文件名相互导入
+		ImportPaths: []string{idlDir, filepath.Join(idlDir, "client"), filepath.Join(idlDir, "server")},
+	}
+	for _, fileHeader := range req.Protos {
+		data, err := readFile(fileHeader)
+		if err != nil {
+			return nil, err
+		}
+		compiler.Sources[path.Base(filepath.ToSlash(fileHeader.Filename))] = string(data)
+	}
+	if req.Proto != "" {
+		compiler.Sources["input.proto"] = req.Proto
+	}
+	names := make([]string, 0, len(compiler.Sources)+len(req.IDLFiles))
+	for name := range compiler.Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range req.IDLFiles {
+		name = path.Clean(filepath.ToSlash(name))
+		if !strings.HasSuffix(name, ".proto") || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return nil, fmt.Errorf("invalid idl file: %q", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		return compiler.Compile(names...)
+	}
+	return findInIDL(compiler, idlDir, req.Message)
+}
+
+// findInIDL 逐个编译 idl 目录中的 .proto 文件，返回第一个包含该消息的 schema；
+// client、server 目录可能存放同一份 IDL，分开编译以避免重复定义
+func findInIDL(compiler *protox.Compiler, idlDir, message string) (*protox.Schema, error) {
+	var files []string
+	err := filepath.WalkDir(idlDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".proto") {
+			rel, _ := filepath.Rel(idlDir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .proto files provided and none found in %s", idlDir)
+	}
+	var lastErr error
+	for _, name := range files {
+		schema, err := compiler.Compile(name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if _, err = schema.FindMessage(message); err == nil {
+			return schema, nil
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("message %q not found in %s (last compile error: %w)", message, idlDir, lastErr)
+	}
+	return nil, fmt.Errorf("message %q not found in %s", message, idlDir)
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/sign/body"
+)
+
+type SignService interface {
+	Digest(ctx context.Context, req *body.DigestReqDto) (*body.DigestResDto, error)
+	Verify(ctx context.Context, req *body.VerifyReqDto) (*body.VerifyResDto, error)
+}
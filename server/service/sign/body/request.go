@@ -0,0 +1,16 @@
+package body
+
+type DigestReqDto struct {
+	Format     string   `json:"format"`                     // 内容格式，默认 json，其他格式先转为 JSON 数据模型
+	Content    string   `json:"content" binding:"required"` // 待签名内容，二进制格式为 base64 编码
+	Algorithms []string `json:"algorithms"`                 // 摘要算法: sha256|sha512，默认全部
+	KeyIDs     []string `json:"key_ids"`                    // 使用配置中的哪些密钥计算 HMAC 签名
+	Encoding   string   `json:"encoding"`                   // 摘要与签名的编码: hex|base64，默认 hex
+}
+
+type VerifyReqDto struct {
+	Format    string `json:"format"`                       // 内容格式，默认 json
+	Content   string `json:"content" binding:"required"`   // 待校验内容，二进制格式为 base64 编码
+	KeyID     string `json:"key_id" binding:"required"`    // 签名密钥标识
+	Signature string `json:"signature" binding:"required"` // hex 或 base64 编码的签名，允许 sha256= 前缀
+}
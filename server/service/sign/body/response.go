@@ -0,0 +1,19 @@
+package body
+
+type DigestResDto struct {
+	Canonical  string            `json:"canonical"`            // RFC 8785 规范化后的 JSON
+	Digests    map[string]string `json:"digests"`              // 算法到摘要的映射
+	Signatures []SignatureDto    `json:"signatures,omitempty"` // HMAC 签名
+}
+
+type SignatureDto struct {
+	KeyID     string `json:"key_id"`    // 密钥标识
+	Algorithm string `json:"algorithm"` // 签名算法
+	Signature string `json:"signature"` // 签名
+}
+
+type VerifyResDto struct {
+	Valid     bool   `json:"valid"`     // 签名是否匹配
+	KeyID     string `json:"key_id"`    // 密钥标识
+	Algorithm string `json:"algorithm"` // 签名算法
+}
@@ -0,0 +1,171 @@
+package sign
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/sign/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.SignService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+const (
+	algorithmHMACSHA256 = "hmac-sha256"
+	algorithmHMACSHA512 = "hmac-sha512"
+)
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Digest 计算规范化 JSON 的摘要与 HMAC 签名
+func (s Service) Digest(ctx context.Context, req *body.DigestReqDto) (*body.DigestResDto, error) {
+	encode, err := encoder(req.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := canonicalize(req.Format, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	algorithms := req.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{"sha256", "sha512"}
+	}
+	res := &body.DigestResDto{Canonical: string(canonical), Digests: make(map[string]string, len(algorithms))}
+	for _, name := range algorithms {
+		name = strings.ToLower(strings.ReplaceAll(name, "-", ""))
+		newHash, ok := digestAlgorithms[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported digest algorithm: %q, expected sha256 or sha512", name)
+		}
+		h := newHash()
+		h.Write(canonical)
+		res.Digests[name] = encode(h.Sum(nil))
+	}
+	for _, keyID := range req.KeyIDs {
+		algorithm, mac, err := sign(keyID, canonical)
+		if err != nil {
+			return nil, err
+		}
+		res.Signatures = append(res.Signatures, body.SignatureDto{KeyID: keyID, Algorithm: algorithm, Signature: encode(mac)})
+	}
+	return res, nil
+}
+
+// Verify 校验规范化 JSON 的 HMAC 签名，使用常量时间比较
+func (s Service) Verify(ctx context.Context, req *body.VerifyReqDto) (*body.VerifyResDto, error) {
+	canonical, err := canonicalize(req.Format, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, expected, err := sign(req.KeyID, canonical)
+	if err != nil {
+		return nil, err
+	}
+	return &body.VerifyResDto{
+		Valid:     hmac.Equal(decodeSignature(req.Signature, len(expected)), expected),
+		KeyID:     req.KeyID,
+		Algorithm: algorithm,
+	}, nil
+}
+
+// canonicalize 解析内容并输出 RFC 8785 规范化的 JSON。RFC 8785 要求 I-JSON，同一对象中不能有重复键，
+// 否则不同的解析器可能取到不同的值，因此重复键直接报错，不按策略取舍后签名
+func canonicalize(name, content string) ([]byte, error) {
+	doc, _, err := formatx.ParseNamed(name, content, &formatx.Options{DuplicateKeys: formatx.DuplicateKeyError})
+	if formatx.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w, canonical json requires unique keys", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return formatx.CanonicalJSON(doc.Value)
+}
+
+// sign 使用配置的密钥计算 HMAC
+func sign(keyID string, data []byte) (string, []byte, error) {
+	key, ok := bootstrap.GetConfig().GetSigningKey(keyID)
+	if !ok {
+		return "", nil, fmt.Errorf("signing key %q is not configured", keyID)
+	}
+	secret := key.GetSecret()
+	if secret == "" && key.SecretEnv != "" {
+		return "", nil, fmt.Errorf("signing key %q has no secret, set the %s environment variable", keyID, key.SecretEnv)
+	}
+	if secret == "" {
+		return "", nil, fmt.Errorf("signing key %q has no secret, configure secret or secret_env", keyID)
+	}
+	algorithm := strings.ToLower(key.Algorithm)
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "", algorithmHMACSHA256:
+		algorithm, newHash = algorithmHMACSHA256, sha256.New
+	case algorithmHMACSHA512:
+		newHash = sha512.New
+	default:
+		return "", nil, fmt.Errorf("signing key %q has unsupported algorithm %q", keyID, key.Algorithm)
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(data)
+	return algorithm, mac.Sum(nil), nil
+}
+
+func encoder(encoding string) (func([]byte) string, error) {
+	switch strings.ToLower(encoding) {
+	case "", "hex":
+		return hex.EncodeToString, nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString, nil
+	default:
+		return nil, fmt.Errorf("invalid encoding: %q, expected hex or base64", encoding)
+	}
+}
+
+var hexPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// decodeSignature 识别 hex 或 base64 编码的签名，去掉 sha256= 等 webhook 常见前缀
+func decodeSignature(signature string, size int) []byte {
+	signature = strings.TrimSpace(signature)
+	if prefix, value, ok := strings.Cut(signature, "="); ok && len(prefix) <= len("sha512") && strings.HasPrefix(strings.ToLower(prefix), "sha") {
+		signature = value
+	}
+	if len(signature) == size*2 && hexPattern.MatchString(signature) {
+		data, _ := hex.DecodeString(signature)
+		return data
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(signature); err == nil {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jasonlabz/json-converter-server/server/service/transform/body"
+)
+
+type TransformService interface {
+	DeepDecode(ctx context.Context, req *body.DeepDecodeReqDto) (*body.DeepDecodeResDto, error)
+	ReEncode(ctx context.Context, req *body.ReEncodeReqDto) (*body.ReEncodeResDto, error)
+	Rekey(ctx context.Context, req *body.RekeyReqDto) (*body.RekeyResDto, error)
+}
@@ -0,0 +1,28 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/transformx"
+
+type DeepDecodeReqDto struct {
+	Format   string   `json:"format"`                     // 内容格式，默认 json
+	Content  string   `json:"content" binding:"required"` // 待解码内容，二进制格式为 base64 编码
+	MaxDepth int      `json:"max_depth"`                  // 单个值最多连续解码的层数，默认 8
+	Types    []string `json:"types"`                      // 启用的编码: json|base64|query|percent，默认全部
+	Indent   int      `json:"indent"`                     // 缩进空格数，默认 2
+}
+
+type ReEncodeReqDto struct {
+	Format    string                `json:"format"`                       // 内容格式，默认 json
+	Content   string                `json:"content" binding:"required"`   // 深度解码后（可能已编辑）的内容，二进制格式为 base64 编码
+	Decodings []transformx.Decoding `json:"decodings" binding:"required"` // 深度解码返回的解码记录
+	Indent    int                   `json:"indent"`                       // 缩进空格数，默认 2
+}
+
+type RekeyReqDto struct {
+	Format      string   `json:"format"`                     // 内容格式，默认 json
+	Content     string   `json:"content" binding:"required"` // 待改写键名的内容，二进制格式为 base64 编码
+	Case        string   `json:"case" binding:"required"`    // 目标命名格式: pascal|camel|snake|kebab
+	Paths       []string `json:"paths"`                      // 只改写命中路径及其下级的键，如 data.*、items[*].user_info，为空时改写全部
+	Initialisms []string `json:"initialisms"`                // 额外的缩略词，追加在内置与 naming.yaml 的缩略词之后
+	OnCollision string   `json:"on_collision"`               // 多个键映射到同一新键名时: keep 保留原名（默认）|error 报错
+	Indent      int      `json:"indent"`                     // 缩进空格数，默认 2
+}
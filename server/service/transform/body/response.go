@@ -0,0 +1,18 @@
+package body
+
+import "github.com/jasonlabz/json-converter-server/common/transformx"
+
+type DeepDecodeResDto struct {
+	Content   string                `json:"content"`   // 展开后的内容
+	Decodings []transformx.Decoding `json:"decodings"` // 每个路径上应用的解码，父路径在前
+}
+
+type ReEncodeResDto struct {
+	Content string `json:"content"` // 还原编码后的内容
+}
+
+type RekeyResDto struct {
+	Content    string                    `json:"content"`    // 改写键名后的内容
+	Renamed    int                       `json:"renamed"`    // 改写的键数
+	Collisions []transformx.KeyCollision `json:"collisions"` // 映射到同一新键名而保留原名的键
+}
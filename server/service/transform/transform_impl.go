@@ -0,0 +1,117 @@
+package transform
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jasonlabz/json-converter-server/bootstrap"
+	"github.com/jasonlabz/json-converter-server/common/formatx"
+	"github.com/jasonlabz/json-converter-server/common/transformx"
+	"github.com/jasonlabz/json-converter-server/server/service"
+	"github.com/jasonlabz/json-converter-server/server/service/transform/body"
+)
+
+var svc *Service
+var once sync.Once
+
+func GetService() service.TransformService {
+	if svc != nil {
+		return svc
+	}
+	once.Do(func() {
+		svc = &Service{}
+	})
+
+	return svc
+}
+
+type Service struct {
+}
+
+// DeepDecode 递归展开字符串中嵌入的 JSON、base64、查询串与百分号编码
+func (s Service) DeepDecode(ctx context.Context, req *body.DeepDecodeReqDto) (*body.DeepDecodeResDto, error) {
+	opts := &transformx.DeepDecodeOptions{MaxDepth: req.MaxDepth}
+	for _, name := range req.Types {
+		t, err := transformx.ParseEncodingType(name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Types = append(opts.Types, t)
+	}
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	// 按有序形式展开，嵌入的对象保持原文中键的顺序
+	value, decodings := transformx.DeepDecode(doc.Ordered(nil), opts)
+	if decodings == nil {
+		decodings = make([]transformx.Decoding, 0)
+	}
+	content, err := formatx.Remarshal(doc, value, &formatx.Options{Indent: req.Indent})
+	if err != nil {
+		return nil, err
+	}
+	return &body.DeepDecodeResDto{Content: content, Decodings: decodings}, nil
+}
+
+// ReEncode 按解码记录还原原始编码
+func (s Service) ReEncode(ctx context.Context, req *body.ReEncodeReqDto) (*body.ReEncodeResDto, error) {
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	value, err := transformx.ReEncode(doc.Ordered(nil), req.Decodings)
+	if err != nil {
+		return nil, err
+	}
+	content, err := formatx.Remarshal(doc, value, &formatx.Options{Indent: req.Indent})
+	if err != nil {
+		return nil, err
+	}
+	return &body.ReEncodeResDto{Content: content}, nil
+}
+
+// Rekey 将键改写为目标命名格式，键顺序与注释随之保留
+func (s Service) Rekey(ctx context.Context, req *body.RekeyReqDto) (*body.RekeyResDto, error) {
+	keyCase, err := transformx.ParseKeyCase(req.Case)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := transformx.ParsePathPatterns(req.Paths)
+	if err != nil {
+		return nil, err
+	}
+	initialisms := append(append([]string(nil), bootstrap.GetNamingConfig().Initialisms...), req.Initialisms...)
+	opts := &transformx.RekeyOptions{Namer: transformx.NewKeyNamer(keyCase, initialisms), Paths: paths}
+	switch req.OnCollision {
+	case "", "keep":
+	case "error":
+		opts.FailOnCollision = true
+	default:
+		return nil, fmt.Errorf("invalid on_collision: %q, expected keep or error", req.OnCollision)
+	}
+	doc, _, err := formatx.ParseNamed(req.Format, req.Content, nil)
+	if err != nil {
+		return nil, err
+	}
+	renames, collisions, err := transformx.Rekey(doc.Value, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.RenameKeys(renames); err != nil {
+		return nil, err
+	}
+	renamed := 0
+	for _, keys := range renames {
+		renamed += len(keys)
+	}
+	if collisions == nil {
+		collisions = make([]transformx.KeyCollision, 0)
+	}
+	content, err := formatx.Remarshal(doc, doc.Value, &formatx.Options{Indent: req.Indent})
+	if err != nil {
+		return nil, err
+	}
+	return &body.RekeyResDto{Content: content, Renamed: renamed, Collisions: collisions}, nil
+}